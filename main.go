@@ -4,6 +4,7 @@
 // Usage:
 //
 //	lazyfire
+//	lazyfire --export-dir ./backup   # browse a `gcloud firestore export` offline
 //
 // Configuration is loaded from ~/.lazyfire/config.yaml
 package main
@@ -12,6 +13,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/marjoballabani/lazyfire/pkg/app"
 )
@@ -38,7 +40,13 @@ func main() {
 		Date:    date,
 	}
 
-	application, err := app.NewApp(buildInfo)
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	application, err := app.NewApp(buildInfo, opts)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -51,3 +59,25 @@ func main() {
 		}
 	}
 }
+
+// parseOptions reads command-line flags into app options.
+// Supports "--export-dir <dir>" and "--export-dir=<dir>".
+func parseOptions(args []string) (app.Options, error) {
+	var opts app.Options
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--export-dir":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--export-dir requires a directory")
+			}
+			i++
+			opts.ExportDir = args[i]
+		case strings.HasPrefix(arg, "--export-dir="):
+			opts.ExportDir = strings.TrimPrefix(arg, "--export-dir=")
+		default:
+			return opts, fmt.Errorf("unknown argument %q", arg)
+		}
+	}
+	return opts, nil
+}
@@ -20,18 +20,24 @@ type BuildInfo struct {
 	Date    string
 }
 
+// Options contains startup options passed on the command line.
+type Options struct {
+	ExportDir string // Browse a Firestore managed export offline instead of a live project
+}
+
 // App is the main application struct that holds all components.
 type App struct {
 	buildInfo      *BuildInfo
+	options        Options
 	config         *config.Config
 	firebaseClient *firebase.Client
 	gui            *gui.Gui
 	ctx            context.Context
 }
 
-// NewApp creates a new App instance with the given build information.
+// NewApp creates a new App instance with the given build information and options.
 // It loads configuration but does not initialize Firebase or GUI yet.
-func NewApp(buildInfo *BuildInfo) (*App, error) {
+func NewApp(buildInfo *BuildInfo, opts Options) (*App, error) {
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to load config")
@@ -39,6 +45,7 @@ func NewApp(buildInfo *BuildInfo) (*App, error) {
 
 	return &App{
 		buildInfo: buildInfo,
+		options:   opts,
 		config:    cfg,
 		ctx:       context.Background(),
 	}, nil
@@ -47,6 +54,16 @@ func NewApp(buildInfo *BuildInfo) (*App, error) {
 // Run starts the application by initializing Firebase, creating the GUI,
 // and running the main event loop. It blocks until the user quits.
 func (app *App) Run() error {
+	if app.options.ExportDir != "" {
+		// Offline mode: serve documents from an export directory, no auth needed
+		firebaseClient, err := firebase.NewExportClient(app.ctx, app.config, app.options.ExportDir)
+		if err != nil {
+			return errors.Wrap(err, "failed to load export")
+		}
+		app.firebaseClient = firebaseClient
+		return app.runGui()
+	}
+
 	// Initialize Firebase client using existing auth credentials
 	firebaseClient, err := firebase.NewClient(app.ctx, app.config)
 	if err != nil {
@@ -64,6 +81,11 @@ func (app *App) Run() error {
 	}
 	app.firebaseClient = firebaseClient
 
+	return app.runGui()
+}
+
+// runGui creates the terminal UI and runs its event loop.
+func (app *App) runGui() error {
 	// Initialize and run the terminal UI
 	gui, err := gui.NewGui(app.config, app.firebaseClient, app.buildInfo.Version)
 	if err != nil {
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDatabase is the database of bookmarks that don't name one.
+const DefaultDatabase = "(default)"
+
+// Bookmark is a saved document, collection or query, keyed by project,
+// database and path.
+type Bookmark struct {
+	Name     string         `yaml:"name"`
+	Project  string         `yaml:"project"`
+	Database string         `yaml:"database,omitempty"`
+	Path     string         `yaml:"path"`
+	Query    *BookmarkQuery `yaml:"query,omitempty"` // Query builder state, for a collection
+}
+
+// BookmarkQuery is the query builder state of a bookmarked query.
+type BookmarkQuery struct {
+	Filters  []QueryFilter `yaml:"filters,omitempty"`
+	OrderBy  string        `yaml:"orderBy,omitempty"`
+	OrderDir string        `yaml:"orderDir,omitempty"`
+	Limit    int           `yaml:"limit,omitempty"`
+}
+
+// Key identifies the bookmarked location: project/database/path.
+func (b Bookmark) Key() string {
+	database := b.Database
+	if database == "" {
+		database = DefaultDatabase
+	}
+	return b.Project + "/" + database + "/" + b.Path
+}
+
+// BookmarksPath returns ~/.lazyfire/bookmarks.yaml.
+func BookmarksPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".lazyfire", "bookmarks.yaml")
+}
+
+type bookmarksFile struct {
+	Bookmarks []Bookmark `yaml:"bookmarks"`
+}
+
+// LoadBookmarks reads a bookmarks file. A missing file has no bookmarks.
+func LoadBookmarks(path string) ([]Bookmark, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file bookmarksFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return file.Bookmarks, nil
+}
+
+// SaveBookmarks writes a bookmarks file, creating its directory if needed.
+func SaveBookmarks(path string, bookmarks []Bookmark) error {
+	out, err := yaml.Marshal(bookmarksFile{Bookmarks: bookmarks})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// AddBookmark adds a bookmark to a list. A document or collection bookmark
+// replaces an existing one for the same location; queries are always added.
+func AddBookmark(bookmarks []Bookmark, b Bookmark) []Bookmark {
+	if b.Query == nil {
+		for i, existing := range bookmarks {
+			if existing.Query == nil && existing.Key() == b.Key() {
+				bookmarks[i] = b
+				return bookmarks
+			}
+		}
+	}
+	return append(bookmarks, b)
+}
+
+// FindBookmark returns the index of b in a list, matched by location, name and
+// query rather than position, or -1.
+func FindBookmark(bookmarks []Bookmark, b Bookmark) int {
+	for i, existing := range bookmarks {
+		if existing.Key() == b.Key() && existing.Name == b.Name && reflect.DeepEqual(existing.Query, b.Query) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBookmarksRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lazyfire", "bookmarks.yaml")
+
+	bookmarks, err := LoadBookmarks(path)
+	if err != nil || bookmarks != nil {
+		t.Fatalf("LoadBookmarks(missing) = %v, %v, expected no bookmarks", bookmarks, err)
+	}
+
+	saved := []Bookmark{
+		{Name: "Flags", Project: "prod", Path: "config/flags"},
+		{Name: "Big orders", Project: "prod", Database: "(default)", Path: "users/a/orders", Query: &BookmarkQuery{
+			Filters:  []QueryFilter{{Field: "total", Operator: ">", Value: "100", ValueType: "integer"}},
+			OrderBy:  "total",
+			OrderDir: "DESC",
+			Limit:    20,
+		}},
+	}
+	if err := SaveBookmarks(path, saved); err != nil {
+		t.Fatalf("SaveBookmarks() error: %v", err)
+	}
+
+	loaded, err := LoadBookmarks(path)
+	if err != nil {
+		t.Fatalf("LoadBookmarks() error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("LoadBookmarks() = %+v, expected %+v", loaded, saved)
+	}
+}
+
+func TestAddBookmark(t *testing.T) {
+	flags := Bookmark{Name: "Flags", Project: "prod", Path: "config/flags"}
+	query := Bookmark{Name: "Recent", Project: "prod", Path: "config", Query: &BookmarkQuery{Limit: 5}}
+
+	bookmarks := AddBookmark(nil, flags)
+	bookmarks = AddBookmark(bookmarks, query)
+	bookmarks = AddBookmark(bookmarks, query)
+
+	// Same location, database spelled out: replaces the first bookmark
+	renamed := Bookmark{Name: "Feature flags", Project: "prod", Database: DefaultDatabase, Path: "config/flags"}
+	bookmarks = AddBookmark(bookmarks, renamed)
+
+	if len(bookmarks) != 3 {
+		t.Fatalf("AddBookmark() = %+v, expected 3 bookmarks", bookmarks)
+	}
+	if bookmarks[0].Name != "Feature flags" {
+		t.Errorf("bookmarks[0] = %+v, expected it replaced", bookmarks[0])
+	}
+
+	other := Bookmark{Name: "Flags", Project: "staging", Path: "config/flags"}
+	if len(AddBookmark(bookmarks, other)) != 4 {
+		t.Error("a bookmark in another project should be added")
+	}
+}
+
+func TestFindBookmark(t *testing.T) {
+	flags := Bookmark{Name: "Flags", Project: "prod", Path: "config/flags"}
+	recent := Bookmark{Name: "Recent", Project: "prod", Path: "config", Query: &BookmarkQuery{Limit: 5}}
+	older := Bookmark{Name: "Recent", Project: "prod", Path: "config", Query: &BookmarkQuery{Limit: 50}}
+	bookmarks := []Bookmark{flags, older, recent}
+
+	if i := FindBookmark(bookmarks, recent); i != 2 {
+		t.Errorf("FindBookmark(recent) = %d, expected 2", i)
+	}
+	flags.Database = DefaultDatabase
+	if i := FindBookmark(bookmarks, flags); i != 0 {
+		t.Errorf("FindBookmark(flags) = %d, expected 0", i)
+	}
+	flags.Name = "Renamed"
+	if i := FindBookmark(bookmarks, flags); i != -1 {
+		t.Errorf("FindBookmark(renamed) = %d, expected -1", i)
+	}
+}
@@ -11,7 +11,13 @@ import (
 
 // Config is the root configuration structure for LazyFire.
 type Config struct {
-	UI UIConfig `mapstructure:"ui"`
+	UI           UIConfig      `mapstructure:"ui"`
+	Tables       []TableLayout `mapstructure:"tables"`       // Saved table view layouts
+	Schemas      []SchemaRule  `mapstructure:"schemas"`      // JSON Schemas to validate collections against
+	SavedQueries []SavedQuery  `mapstructure:"savedQueries"` // Named query builder states
+	// Keybinding maps action IDs to keys in lazygit's notation, e.g.
+	// copyJSON: y or nextItem: [j, <down>]; <disabled> unbinds an action
+	Keybinding map[string][]string `mapstructure:"keybinding"`
 }
 
 // UIConfig contains user interface configuration options.
@@ -1,6 +1,12 @@
 package config
 
-import "testing"
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
 
 func TestLoadConfig(t *testing.T) {
 	cfg, err := LoadConfig()
@@ -49,3 +55,27 @@ func TestConfigStructure(t *testing.T) {
 		t.Error("Should support hex color values")
 	}
 }
+
+func TestKeybindingConfig(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	yaml := "keybinding:\n  copyJSON: y\n  goToPath: <c-p>\n  quit: <disabled>\n  nextItem: [j, <down>]\n"
+	if err := v.ReadConfig(strings.NewReader(yaml)); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	// Viper lowercases keys, so the GUI matches action IDs ignoring case
+	expected := map[string][]string{
+		"copyjson": {"y"},
+		"gotopath": {"<c-p>"},
+		"quit":     {"<disabled>"},
+		"nextitem": {"j", "<down>"},
+	}
+	if !reflect.DeepEqual(cfg.Keybinding, expected) {
+		t.Errorf("Keybinding = %v, expected %v", cfg.Keybinding, expected)
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxHistory is how many executed queries a project's history keeps.
+const MaxHistory = 200
+
+// HistoryEntry is an executed query with its outcome.
+type HistoryEntry struct {
+	Collection string        `yaml:"collection"`
+	Filters    []QueryFilter `yaml:"filters,omitempty"` // Filters as run, parameters bound
+	OrderBy    string        `yaml:"orderBy,omitempty"`
+	OrderDir   string        `yaml:"orderDir,omitempty"`
+	Limit      int           `yaml:"limit,omitempty"`
+	Time       time.Time     `yaml:"time"`
+	Count      int           `yaml:"count"`     // Documents returned
+	LatencyMs  int64         `yaml:"latencyMs"` // Time the query took
+}
+
+// HistoryPath returns ~/.lazyfire/history/<project>.yaml.
+func HistoryPath(project string) string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".lazyfire", "history", project+".yaml")
+}
+
+type historyFile struct {
+	Queries []HistoryEntry `yaml:"queries"`
+}
+
+// LoadHistory reads a history file, oldest entry first. A missing file has no entries.
+func LoadHistory(path string) ([]HistoryEntry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file historyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return file.Queries, nil
+}
+
+// AppendHistory adds an entry to a history file, dropping the oldest entries
+// beyond MaxHistory.
+func AppendHistory(path string, entry HistoryEntry) error {
+	entries, err := LoadHistory(path)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+	if len(entries) > MaxHistory {
+		entries = entries[len(entries)-MaxHistory:]
+	}
+
+	out, err := yaml.Marshal(historyFile{Queries: entries})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
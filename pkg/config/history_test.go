@@ -0,0 +1,48 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAppendHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "prod.yaml")
+
+	entries, err := LoadHistory(path)
+	if err != nil || entries != nil {
+		t.Fatalf("LoadHistory(missing) = %v, %v, expected no entries", entries, err)
+	}
+
+	first := HistoryEntry{
+		Collection: "users",
+		Filters:    []QueryFilter{{Field: "age", Operator: ">", Value: "18", ValueType: "integer"}},
+		OrderBy:    "age",
+		OrderDir:   "ASC",
+		Limit:      50,
+		Time:       time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+		Count:      12,
+		LatencyMs:  85,
+	}
+	if err := AppendHistory(path, first); err != nil {
+		t.Fatalf("AppendHistory() error: %v", err)
+	}
+	entries, err = LoadHistory(path)
+	if err != nil || len(entries) != 1 || !reflect.DeepEqual(entries[0], first) {
+		t.Fatalf("LoadHistory() = %+v, %v, expected [%+v]", entries, err, first)
+	}
+
+	for i := 0; i < MaxHistory; i++ {
+		if err := AppendHistory(path, HistoryEntry{Collection: "orders", Count: i}); err != nil {
+			t.Fatalf("AppendHistory() error: %v", err)
+		}
+	}
+	entries, _ = LoadHistory(path)
+	if len(entries) != MaxHistory {
+		t.Fatalf("history holds %d entries, expected %d", len(entries), MaxHistory)
+	}
+	if entries[0].Collection != "orders" || entries[len(entries)-1].Count != MaxHistory-1 {
+		t.Errorf("history should drop the oldest entries, got first %+v, last %+v", entries[0], entries[len(entries)-1])
+	}
+}
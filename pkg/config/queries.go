@@ -0,0 +1,70 @@
+package config
+
+// QueryFilter is one WHERE clause of a saved or bookmarked query. Value may
+// hold parameters like $userId, asked for when the query runs.
+type QueryFilter struct {
+	Field     string `mapstructure:"field" yaml:"field"`
+	Operator  string `mapstructure:"operator" yaml:"operator"`
+	Value     string `mapstructure:"value" yaml:"value"`
+	ValueType string `mapstructure:"type" yaml:"type,omitempty"`
+}
+
+// SavedQuery is a named query builder state. It is offered for collections
+// matching Collection in Project; an empty Project or Collection matches any.
+type SavedQuery struct {
+	Name       string        `mapstructure:"name" yaml:"name"`
+	Project    string        `mapstructure:"project" yaml:"project,omitempty"`
+	Collection string        `mapstructure:"collection" yaml:"collection,omitempty"` // Collection path pattern, "*" matching any segment
+	Filters    []QueryFilter `mapstructure:"filters" yaml:"filters,omitempty"`
+	OrderBy    string        `mapstructure:"orderBy" yaml:"orderBy,omitempty"`
+	OrderDir   string        `mapstructure:"orderDir" yaml:"orderDir,omitempty"`
+	Limit      int           `mapstructure:"limit" yaml:"limit,omitempty"`
+}
+
+// sameScope reports whether two saved queries have the same name and scope.
+func (q SavedQuery) sameScope(other SavedQuery) bool {
+	return q.Name == other.Name && q.Project == other.Project && q.Collection == other.Collection
+}
+
+// SavedQueriesFor returns the saved queries offered for a collection in a project.
+func (c *Config) SavedQueriesFor(project, collection string) []SavedQuery {
+	var queries []SavedQuery
+	for _, q := range c.SavedQueries {
+		if q.Project != "" && q.Project != project {
+			continue
+		}
+		if q.Collection != "" && !matchCollectionPattern(q.Collection, collection) {
+			continue
+		}
+		queries = append(queries, q)
+	}
+	return queries
+}
+
+// SaveQuery stores a query, replacing one with the same name and scope, and
+// writes the savedQueries section of the config file.
+func (c *Config) SaveQuery(query SavedQuery) error {
+	replaced := false
+	for i := range c.SavedQueries {
+		if c.SavedQueries[i].sameScope(query) {
+			c.SavedQueries[i] = query
+			replaced = true
+		}
+	}
+	if !replaced {
+		c.SavedQueries = append(c.SavedQueries, query)
+	}
+	return writeConfigKey(configFilePath(), "savedQueries", c.SavedQueries)
+}
+
+// DeleteSavedQuery removes a query and writes the savedQueries section of the config file.
+func (c *Config) DeleteSavedQuery(query SavedQuery) error {
+	kept := c.SavedQueries[:0]
+	for _, q := range c.SavedQueries {
+		if !q.sameScope(query) {
+			kept = append(kept, q)
+		}
+	}
+	c.SavedQueries = kept
+	return writeConfigKey(configFilePath(), "savedQueries", c.SavedQueries)
+}
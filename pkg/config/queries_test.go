@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestSavedQueriesFor(t *testing.T) {
+	cfg := &Config{SavedQueries: []SavedQuery{
+		{Name: "Everywhere"},
+		{Name: "Active users", Collection: "users"},
+		{Name: "Prod users", Project: "prod", Collection: "users"},
+		{Name: "Big orders", Collection: "users/*/orders"},
+	}}
+
+	tests := []struct {
+		project, collection string
+		expected            []string
+	}{
+		{"prod", "users", []string{"Everywhere", "Active users", "Prod users"}},
+		{"staging", "users", []string{"Everywhere", "Active users"}},
+		{"staging", "users/abc/orders", []string{"Everywhere", "Big orders"}},
+		{"prod", "cities", []string{"Everywhere"}},
+	}
+
+	for _, tt := range tests {
+		var names []string
+		for _, q := range cfg.SavedQueriesFor(tt.project, tt.collection) {
+			names = append(names, q.Name)
+		}
+		if len(names) != len(tt.expected) {
+			t.Errorf("SavedQueriesFor(%q, %q) = %v, expected %v", tt.project, tt.collection, names, tt.expected)
+			continue
+		}
+		for i := range names {
+			if names[i] != tt.expected[i] {
+				t.Errorf("SavedQueriesFor(%q, %q) = %v, expected %v", tt.project, tt.collection, names, tt.expected)
+				break
+			}
+		}
+	}
+}
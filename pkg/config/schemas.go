@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SchemaRule maps a collection path pattern to a JSON Schema file. In the
+// pattern "*" matches any single path segment, so "users/*/orders" covers the
+// orders subcollection of every user.
+type SchemaRule struct {
+	Collection string `mapstructure:"collection"` // Collection path pattern
+	File       string `mapstructure:"file"`       // JSON Schema file, relative to the config file's directory
+}
+
+// SchemaFor returns the first schema rule whose pattern matches a collection path.
+func (c *Config) SchemaFor(collection string) (SchemaRule, bool) {
+	for _, rule := range c.Schemas {
+		if matchCollectionPattern(rule.Collection, collection) {
+			return rule, true
+		}
+	}
+	return SchemaRule{}, false
+}
+
+// SchemaPath resolves the rule's file: "~/" is the home directory and relative
+// paths start at the directory of the config file.
+func (r SchemaRule) SchemaPath() string {
+	path := r.File
+	if strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[2:])
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Join(filepath.Dir(configFilePath()), path)
+	}
+	return path
+}
+
+// matchCollectionPattern matches a collection path segment by segment, "*" matching any segment.
+func matchCollectionPattern(pattern, collection string) bool {
+	want := strings.Split(strings.Trim(pattern, "/"), "/")
+	got := strings.Split(strings.Trim(collection, "/"), "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if want[i] != "*" && want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
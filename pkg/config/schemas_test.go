@@ -0,0 +1,51 @@
+package config
+
+import "testing"
+
+func TestMatchCollectionPattern(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		collection string
+		expected   bool
+	}{
+		{"users", "users", true},
+		{"users", "orders", false},
+		{"users/*/orders", "users/abc/orders", true},
+		{"users/*/orders", "users/abc/payments", false},
+		{"users/*/orders", "users", false},
+		{"*", "anything", true},
+		{"/users/", "users", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.collection, func(t *testing.T) {
+			if got := matchCollectionPattern(tt.pattern, tt.collection); got != tt.expected {
+				t.Errorf("matchCollectionPattern(%q, %q) = %v, expected %v", tt.pattern, tt.collection, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSchemaFor(t *testing.T) {
+	c := &Config{Schemas: []SchemaRule{
+		{Collection: "users/*/orders", File: "order.json"},
+		{Collection: "users", File: "user.json"},
+		{Collection: "*/*/orders", File: "any-order.json"},
+	}}
+
+	if rule, ok := c.SchemaFor("users/u1/orders"); !ok || rule.File != "order.json" {
+		t.Errorf("SchemaFor(users/u1/orders) = %+v, %v, expected order.json", rule, ok)
+	}
+	if rule, ok := c.SchemaFor("shops/s1/orders"); !ok || rule.File != "any-order.json" {
+		t.Errorf("SchemaFor(shops/s1/orders) = %+v, %v, expected any-order.json", rule, ok)
+	}
+	if _, ok := c.SchemaFor("products"); ok {
+		t.Error("SchemaFor(products) should not match")
+	}
+}
+
+func TestSchemaPath(t *testing.T) {
+	if got := (SchemaRule{File: "/etc/schemas/user.json"}).SchemaPath(); got != "/etc/schemas/user.json" {
+		t.Errorf("SchemaPath() = %q, expected the absolute path unchanged", got)
+	}
+}
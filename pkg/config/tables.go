@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// TableLayout is the saved column layout of the table view for one collection.
+// Collection is a path with document IDs replaced by "*" (e.g. "users/*/orders"),
+// so a layout applies to every subcollection with that shape.
+type TableLayout struct {
+	Collection string   `mapstructure:"collection" yaml:"collection"`
+	Columns    []string `mapstructure:"columns" yaml:"columns,omitempty"`   // Column order
+	Hidden     []string `mapstructure:"hidden" yaml:"hidden,omitempty"`     // Hidden columns
+	Pinned     int      `mapstructure:"pinned" yaml:"pinned,omitempty"`     // Leading columns kept visible while scrolling
+	SortBy     string   `mapstructure:"sortBy" yaml:"sortBy,omitempty"`     // Column to sort rows by
+	SortDesc   bool     `mapstructure:"sortDesc" yaml:"sortDesc,omitempty"` // Sort descending
+}
+
+// GetTableLayout returns the saved layout for a collection key.
+func (c *Config) GetTableLayout(collection string) (TableLayout, bool) {
+	for _, layout := range c.Tables {
+		if layout.Collection == collection {
+			return layout, true
+		}
+	}
+	return TableLayout{}, false
+}
+
+// SaveTableLayout stores a layout and writes the tables section of the config file.
+// The rest of the file, including comments, is left as it is.
+func (c *Config) SaveTableLayout(layout TableLayout) error {
+	replaced := false
+	for i := range c.Tables {
+		if c.Tables[i].Collection == layout.Collection {
+			c.Tables[i] = layout
+			replaced = true
+		}
+	}
+	if !replaced {
+		c.Tables = append(c.Tables, layout)
+	}
+	return writeConfigKey(configFilePath(), "tables", c.Tables)
+}
+
+// configFilePath returns the config file in use, or ~/.lazyfire/config.yaml when there is none.
+func configFilePath() string {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".lazyfire", "config.yaml")
+}
+
+// writeConfigKey sets a top-level key of a YAML file to value, creating the file if needed.
+func writeConfigKey(path, key string, value interface{}) error {
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: top level is not a mapping", path)
+	}
+
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return err
+	}
+
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			root.Content[i+1] = &valueNode
+			found = true
+			break
+		}
+	}
+	if !found {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &valueNode)
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWriteConfigKeyKeepsOtherSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := "# My settings\nui:\n  showIcons: false # no icons\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	layouts := []TableLayout{{Collection: "users", Columns: []string{"name", "age"}, Pinned: 1}}
+	if err := writeConfigKey(path, "tables", layouts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# My settings", "showIcons: false # no icons", "collection: users", "pinned: 1"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("config file missing %q:\n%s", want, data)
+		}
+	}
+
+	// Writing again replaces the section instead of adding a second one
+	layouts[0].Pinned = 2
+	if err := writeConfigKey(path, "tables", layouts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Count(string(data), "tables:") != 1 || !strings.Contains(string(data), "pinned: 2") {
+		t.Errorf("expected one updated tables section:\n%s", data)
+	}
+
+	var parsed struct {
+		Tables []TableLayout `yaml:"tables"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Tables) != 1 || parsed.Tables[0].Columns[1] != "age" {
+		t.Errorf("tables = %+v, expected the saved layout", parsed.Tables)
+	}
+}
+
+func TestWriteConfigKeyCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "config.yaml")
+	if err := writeConfigKey(path, "tables", []TableLayout{{Collection: "users/*/orders"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "collection: users/*/orders") {
+		t.Errorf("unexpected file content:\n%s", data)
+	}
+}
+
+func TestGetTableLayout(t *testing.T) {
+	cfg := &Config{Tables: []TableLayout{{Collection: "users", Pinned: 1}, {Collection: "users/*/orders", Pinned: 2}}}
+
+	if layout, ok := cfg.GetTableLayout("users/*/orders"); !ok || layout.Pinned != 2 {
+		t.Errorf("GetTableLayout(users/*/orders) = %+v, %v", layout, ok)
+	}
+	if _, ok := cfg.GetTableLayout("cities"); ok {
+		t.Error("GetTableLayout(cities) should not find a layout")
+	}
+}
@@ -0,0 +1,263 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/marjoballabani/lazyfire/pkg/config"
+)
+
+// ExportStore holds the documents of a Firestore managed export
+// (`gcloud firestore export`) loaded into memory for offline browsing.
+type ExportStore struct {
+	Dir       string // Export directory
+	ProjectID string // Project the export was taken from (from entity keys)
+
+	docs        map[string]Document // Documents by path
+	collections map[string][]string // Collection IDs by parent document path ("" for root)
+	children    map[string][]string // Document paths by collection path
+}
+
+// LoadExport reads every output-N file under dir and indexes the decoded documents.
+// dir must contain the export's metadata files (*.overall_export_metadata or *.export_metadata).
+func LoadExport(dir string) (*ExportStore, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	var outputs []string
+	hasMetadata := false
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if strings.HasSuffix(name, "export_metadata") {
+			hasMetadata = true
+		}
+		if strings.HasPrefix(name, "output-") {
+			outputs = append(outputs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !hasMetadata {
+		return nil, fmt.Errorf("%s is not a Firestore export (no .export_metadata found)", dir)
+	}
+	sort.Strings(outputs)
+
+	store := &ExportStore{
+		Dir:         dir,
+		docs:        make(map[string]Document),
+		collections: make(map[string][]string),
+		children:    make(map[string][]string),
+	}
+
+	for _, path := range outputs {
+		if err := store.loadFile(path); err != nil {
+			return nil, fmt.Errorf("%s: %v", filepath.Base(path), err)
+		}
+	}
+
+	store.sortIndexes()
+	return store, nil
+}
+
+// loadFile decodes all entities from one LevelDB log file.
+func (s *ExportStore) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	lr := newLogReader(f)
+	for {
+		record, err := lr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entity, err := decodeEntity(record)
+		if err != nil {
+			return err
+		}
+		if len(entity.Key.Path) == 0 {
+			continue
+		}
+		if s.ProjectID == "" {
+			s.ProjectID = entity.Key.ProjectID()
+		}
+		s.addDocument(entity)
+	}
+}
+
+// addDocument stores an entity as a Document and registers its ancestors.
+// Ancestor documents that have no data of their own (Firestore allows subcollections
+// under missing documents) are added as empty documents so they stay reachable.
+func (s *ExportStore) addDocument(entity *exportEntity) {
+	docPath := entity.Key.DocumentPath()
+	segments := strings.Split(docPath, "/")
+
+	for i := 0; i+1 < len(segments); i += 2 {
+		parentDoc := strings.Join(segments[:i], "/")
+		collectionPath := strings.Join(segments[:i+1], "/")
+		ancestorPath := strings.Join(segments[:i+2], "/")
+
+		s.collections[parentDoc] = appendUnique(s.collections[parentDoc], segments[i])
+		s.children[collectionPath] = appendUnique(s.children[collectionPath], ancestorPath)
+
+		if _, ok := s.docs[ancestorPath]; !ok {
+			s.docs[ancestorPath] = Document{
+				ID:     segments[i+1],
+				Path:   ancestorPath,
+				Data:   map[string]interface{}{},
+				Fields: map[string]interface{}{},
+			}
+		}
+	}
+
+	s.docs[docPath] = Document{
+		ID:     segments[len(segments)-1],
+		Path:   docPath,
+		Data:   parseFirestoreFields(entity.Fields),
+		Fields: entity.Fields,
+	}
+}
+
+// sortIndexes orders collections and documents by ID, as Firestore lists them.
+func (s *ExportStore) sortIndexes() {
+	for _, ids := range s.collections {
+		sort.Strings(ids)
+	}
+	for _, paths := range s.children {
+		sort.Strings(paths)
+	}
+}
+
+// appendUnique appends value unless it is already present.
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// DocumentCount returns the number of documents in the export.
+func (s *ExportStore) DocumentCount() int {
+	return len(s.docs)
+}
+
+// ListCollections returns the collections under a document ("" for root collections).
+func (s *ExportStore) ListCollections(docPath string) []Collection {
+	var collections []Collection
+	for _, id := range s.collections[docPath] {
+		path := id
+		if docPath != "" {
+			path = docPath + "/" + id
+		}
+		collections = append(collections, Collection{Name: id, Path: path})
+	}
+	return collections
+}
+
+// ListDocuments returns up to limit documents of a collection.
+func (s *ExportStore) ListDocuments(collectionPath string, limit int) []Document {
+	var documents []Document
+	for _, path := range s.children[collectionPath] {
+		if limit > 0 && len(documents) >= limit {
+			break
+		}
+		documents = append(documents, s.docs[path])
+	}
+	return documents
+}
+
+// ListDocumentsAfter returns up to limit documents of a collection whose path
+// sorts after startAfter.
+func (s *ExportStore) ListDocumentsAfter(collectionPath, startAfter string, limit int) []Document {
+	var documents []Document
+	for _, path := range s.children[collectionPath] {
+		if path <= startAfter {
+			continue
+		}
+		if limit > 0 && len(documents) >= limit {
+			break
+		}
+		documents = append(documents, s.docs[path])
+	}
+	return documents
+}
+
+// SearchDocumentIDs returns up to limit documents of a collection whose ID
+// starts with prefix.
+func (s *ExportStore) SearchDocumentIDs(collectionPath, prefix string, limit int) []Document {
+	var documents []Document
+	for _, path := range s.children[collectionPath] {
+		if !strings.HasPrefix(path[strings.LastIndex(path, "/")+1:], prefix) {
+			continue
+		}
+		if limit > 0 && len(documents) >= limit {
+			break
+		}
+		documents = append(documents, s.docs[path])
+	}
+	return documents
+}
+
+// GetDocument returns a single document by path.
+func (s *ExportStore) GetDocument(docPath string) (*Document, error) {
+	doc, ok := s.docs[docPath]
+	if !ok {
+		return nil, fmt.Errorf("document %s not found in export", docPath)
+	}
+	return &doc, nil
+}
+
+// NewExportClient creates a Client that serves data from a managed export
+// directory instead of the Firestore API. No authentication is required.
+func NewExportClient(ctx context.Context, cfg *config.Config, dir string) (*Client, error) {
+	store, err := LoadExport(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		ctx:    ctx,
+		config: cfg,
+		backup: store,
+	}, nil
+}
+
+// IsOffline returns true if the client is browsing an export backup.
+func (c *Client) IsOffline() bool {
+	return c.backup != nil
+}
+
+// exportProjectID returns the project ID shown for an export backup.
+func (c *Client) exportProjectID() string {
+	if c.backup.ProjectID != "" {
+		return c.backup.ProjectID
+	}
+	return filepath.Base(c.backup.Dir)
+}
@@ -0,0 +1,377 @@
+package firebase
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// protoWriter builds protobuf messages for test fixtures.
+type protoWriter struct {
+	buf []byte
+}
+
+func (w *protoWriter) tag(field, wire int) *protoWriter {
+	w.buf = binary.AppendUvarint(w.buf, uint64(field<<3|wire))
+	return w
+}
+
+func (w *protoWriter) varint(field int, v uint64) *protoWriter {
+	w.tag(field, wireVarint)
+	w.buf = binary.AppendUvarint(w.buf, v)
+	return w
+}
+
+func (w *protoWriter) double(field int, v float64) *protoWriter {
+	w.tag(field, wireFixed64)
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, math.Float64bits(v))
+	return w
+}
+
+func (w *protoWriter) bytes(field int, b []byte) *protoWriter {
+	w.tag(field, wireBytes)
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(b)))
+	w.buf = append(w.buf, b...)
+	return w
+}
+
+func (w *protoWriter) str(field int, s string) *protoWriter {
+	return w.bytes(field, []byte(s))
+}
+
+// keyBytes encodes a Reference with alternating kind/name segments.
+// Numeric segments prefixed with "#" are encoded as IDs.
+func keyBytes(app string, segments ...string) []byte {
+	path := &protoWriter{}
+	for i := 0; i+1 < len(segments); i += 2 {
+		path.tag(pathElementGroup, wireStartGroup)
+		path.str(pathElementTypeField, segments[i])
+		if id, ok := strings.CutPrefix(segments[i+1], "#"); ok {
+			var n uint64
+			for _, c := range id {
+				n = n*10 + uint64(c-'0')
+			}
+			path.varint(pathElementIDField, n)
+		} else {
+			path.str(pathElementNameField, segments[i+1])
+		}
+		path.tag(pathElementGroup, wireEndGroup)
+	}
+	key := &protoWriter{}
+	key.str(referenceAppField, app)
+	key.bytes(referencePathField, path.buf)
+	return key.buf
+}
+
+// property encodes a Property message.
+func property(name string, meaning int, multiple bool, value []byte) []byte {
+	w := &protoWriter{}
+	if meaning != 0 {
+		w.varint(propertyMeaningField, uint64(meaning))
+	}
+	w.str(propertyNameField, name)
+	if multiple {
+		w.varint(propertyMultipleField, 1)
+	}
+	w.bytes(propertyValueField, value)
+	return w.buf
+}
+
+// entityBytes encodes an EntityProto from a key and properties.
+func entityBytes(key []byte, properties ...[]byte) []byte {
+	w := &protoWriter{}
+	w.bytes(entityKeyField, key)
+	for _, p := range properties {
+		w.bytes(entityPropertyField, p)
+	}
+	return w.buf
+}
+
+// maskCRC applies LevelDB's checksum masking.
+func maskCRC(crc uint32) uint32 {
+	return (crc>>15 | crc<<17) + logCRCMaskDelta
+}
+
+// writeLog encodes records in LevelDB log format, fragmenting across blocks.
+func writeLog(records ...[]byte) []byte {
+	var out []byte
+	for _, record := range records {
+		first := true
+		for {
+			left := logBlockSize - len(out)%logBlockSize
+			if left < logHeaderSize {
+				out = append(out, make([]byte, left)...)
+				continue
+			}
+			avail := left - logHeaderSize
+			n := min(len(record), avail)
+			last := n == len(record)
+
+			var recordType byte
+			switch {
+			case first && last:
+				recordType = logRecordFull
+			case first:
+				recordType = logRecordFirst
+			case last:
+				recordType = logRecordLast
+			default:
+				recordType = logRecordMiddle
+			}
+
+			crc := crc32.Update(crc32.Checksum([]byte{recordType}, crc32cTable), crc32cTable, record[:n])
+			out = binary.LittleEndian.AppendUint32(out, maskCRC(crc))
+			out = binary.LittleEndian.AppendUint16(out, uint16(n))
+			out = append(out, recordType)
+			out = append(out, record[:n]...)
+
+			record = record[n:]
+			first = false
+			if last {
+				break
+			}
+		}
+	}
+	return out
+}
+
+func TestLogReader(t *testing.T) {
+	small := []byte("hello")
+	large := bytes.Repeat([]byte("x"), logBlockSize*2+100) // Spans three blocks
+	data := writeLog(small, large, small)
+
+	lr := newLogReader(bytes.NewReader(data))
+	for i, want := range [][]byte{small, large, small} {
+		got, err := lr.Next()
+		if err != nil {
+			t.Fatalf("record %d: unexpected error: %v", i, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("record %d: got %d bytes, expected %d", i, len(got), len(want))
+		}
+	}
+	if _, err := lr.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF after last record, got %v", err)
+	}
+}
+
+func TestLogReaderChecksumMismatch(t *testing.T) {
+	data := writeLog([]byte("hello"))
+	data[len(data)-1] ^= 0xff
+
+	if _, err := newLogReader(bytes.NewReader(data)).Next(); err == nil {
+		t.Error("expected checksum error, got nil")
+	}
+}
+
+func TestDecodeEntity(t *testing.T) {
+	mapEntity := entityBytes(nil, property("city", 0, false, (&protoWriter{}).str(valueStringField, "Tirana").buf))
+	point := (&protoWriter{}).tag(valuePointGroup, wireStartGroup).
+		double(valuePointXField, 41.3).double(valuePointYField, 19.8).
+		tag(valuePointGroup, wireEndGroup).buf
+	ref := (&protoWriter{}).tag(valueReferenceGroup, wireStartGroup).
+		str(refValueAppField, "s~my-project").
+		tag(refValueElementGroup, wireStartGroup).str(refValueTypeField, "users").str(refValueNameField, "u2").tag(refValueElementGroup, wireEndGroup).
+		tag(valueReferenceGroup, wireEndGroup).buf
+
+	data := entityBytes(keyBytes("s~my-project", "users", "u1"),
+		property("name", 0, false, (&protoWriter{}).str(valueStringField, "Ana").buf),
+		property("age", 0, false, (&protoWriter{}).varint(valueInt64Field, 30).buf),
+		property("active", 0, false, (&protoWriter{}).varint(valueBooleanField, 1).buf),
+		property("score", 0, false, (&protoWriter{}).double(valueDoubleField, 9.5).buf),
+		property("created", meaningGDWhen, false, (&protoWriter{}).varint(valueInt64Field, 1700000000123456).buf),
+		property("avatar", meaningBlob, false, (&protoWriter{}).str(valueStringField, "\x01\x02").buf),
+		property("address", meaningEntityProto, false, (&protoWriter{}).bytes(valueStringField, mapEntity).buf),
+		property("tags", 0, true, (&protoWriter{}).str(valueStringField, "a").buf),
+		property("tags", 0, true, (&protoWriter{}).str(valueStringField, "b").buf),
+		property("empty", meaningEmptyList, false, nil),
+		property("deleted", 0, false, nil),
+		property("location", 0, false, point),
+		property("friend", 0, false, ref),
+	)
+
+	entity, err := decodeEntity(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := entity.Key.DocumentPath(); got != "users/u1" {
+		t.Errorf("DocumentPath() = %q, expected %q", got, "users/u1")
+	}
+	if got := entity.Key.ProjectID(); got != "my-project" {
+		t.Errorf("ProjectID() = %q, expected %q", got, "my-project")
+	}
+
+	expected := map[string]interface{}{
+		"name":    map[string]interface{}{"stringValue": "Ana"},
+		"age":     map[string]interface{}{"integerValue": "30"},
+		"active":  map[string]interface{}{"booleanValue": true},
+		"score":   map[string]interface{}{"doubleValue": 9.5},
+		"created": map[string]interface{}{"timestampValue": "2023-11-14T22:13:20.123456Z"},
+		"avatar":  map[string]interface{}{"bytesValue": "AQI="},
+		"address": map[string]interface{}{"mapValue": map[string]interface{}{"fields": map[string]interface{}{
+			"city": map[string]interface{}{"stringValue": "Tirana"},
+		}}},
+		"tags": map[string]interface{}{"arrayValue": map[string]interface{}{"values": []interface{}{
+			map[string]interface{}{"stringValue": "a"},
+			map[string]interface{}{"stringValue": "b"},
+		}}},
+		"empty":    map[string]interface{}{"arrayValue": map[string]interface{}{}},
+		"deleted":  map[string]interface{}{"nullValue": nil},
+		"location": map[string]interface{}{"geoPointValue": map[string]interface{}{"latitude": 41.3, "longitude": 19.8}},
+		"friend":   map[string]interface{}{"referenceValue": "projects/my-project/databases/(default)/documents/users/u2"},
+	}
+
+	for name, want := range expected {
+		if got := entity.Fields[name]; !reflect.DeepEqual(got, want) {
+			t.Errorf("field %q = %#v, expected %#v", name, got, want)
+		}
+	}
+	if len(entity.Fields) != len(expected) {
+		t.Errorf("decoded %d fields, expected %d", len(entity.Fields), len(expected))
+	}
+}
+
+func TestEntityKeyDocumentPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      entityKey
+		expected string
+	}{
+		{
+			name:     "top-level document",
+			key:      entityKey{Path: []keyElement{{Kind: "users", Name: "abc"}}},
+			expected: "users/abc",
+		},
+		{
+			name:     "numeric ID",
+			key:      entityKey{Path: []keyElement{{Kind: "orders", ID: 42}}},
+			expected: "orders/42",
+		},
+		{
+			name: "subcollection document",
+			key: entityKey{Path: []keyElement{
+				{Kind: "users", Name: "abc"},
+				{Kind: "orders", Name: "o1"},
+			}},
+			expected: "users/abc/orders/o1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key.DocumentPath(); got != tt.expected {
+				t.Errorf("DocumentPath() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// writeExport creates an export directory with one output file.
+func writeExport(t *testing.T, entities ...[]byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "all_namespaces", "all_kinds")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "backup.overall_export_metadata"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "all_namespaces_all_kinds.export_metadata"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "output-0"), writeLog(entities...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoadExport(t *testing.T) {
+	name := func(v string) []byte {
+		return property("name", 0, false, (&protoWriter{}).str(valueStringField, v).buf)
+	}
+	dir := writeExport(t,
+		entityBytes(keyBytes("s~demo", "users", "bob"), name("Bob")),
+		entityBytes(keyBytes("s~demo", "users", "alice"), name("Alice")),
+		entityBytes(keyBytes("s~demo", "users", "alice", "orders", "#7"), name("Order 7")),
+		entityBytes(keyBytes("s~demo", "ghosts", "g1", "items", "i1"), name("Item")),
+	)
+
+	store, err := LoadExport(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if store.ProjectID != "demo" {
+		t.Errorf("ProjectID = %q, expected %q", store.ProjectID, "demo")
+	}
+	// 4 stored documents plus the implied parent ghosts/g1
+	if got := store.DocumentCount(); got != 5 {
+		t.Errorf("DocumentCount() = %d, expected 5", got)
+	}
+
+	var roots []string
+	for _, c := range store.ListCollections("") {
+		roots = append(roots, c.Path)
+	}
+	if !reflect.DeepEqual(roots, []string{"ghosts", "users"}) {
+		t.Errorf("root collections = %v, expected [ghosts users]", roots)
+	}
+
+	var users []string
+	for _, d := range store.ListDocuments("users", 0) {
+		users = append(users, d.Path)
+	}
+	if !reflect.DeepEqual(users, []string{"users/alice", "users/bob"}) {
+		t.Errorf("users documents = %v, expected [users/alice users/bob]", users)
+	}
+	if got := store.ListDocuments("users", 1); len(got) != 1 {
+		t.Errorf("ListDocuments with limit 1 returned %d documents", len(got))
+	}
+
+	if got := store.SearchDocumentIDs("users", "al", 0); len(got) != 1 || got[0].Path != "users/alice" {
+		t.Errorf("SearchDocumentIDs(users, al) = %v, expected [users/alice]", got)
+	}
+	if got := store.SearchDocumentIDs("users", "lice", 0); len(got) != 0 {
+		t.Errorf("SearchDocumentIDs(users, lice) = %v, expected no documents", got)
+	}
+
+	subs := store.ListCollections("users/alice")
+	if len(subs) != 1 || subs[0].Path != "users/alice/orders" {
+		t.Errorf("users/alice subcollections = %v, expected [users/alice/orders]", subs)
+	}
+
+	doc, err := store.GetDocument("users/alice/orders/7")
+	if err != nil {
+		t.Fatalf("GetDocument: unexpected error: %v", err)
+	}
+	if doc.Data["name"] != "Order 7" {
+		t.Errorf("order name = %v, expected %q", doc.Data["name"], "Order 7")
+	}
+
+	ghost, err := store.GetDocument("ghosts/g1")
+	if err != nil {
+		t.Fatalf("implied parent document missing: %v", err)
+	}
+	if len(ghost.Data) != 0 {
+		t.Errorf("implied parent should be empty, got %v", ghost.Data)
+	}
+
+	if _, err := store.GetDocument("users/nobody"); err == nil {
+		t.Error("expected error for missing document, got nil")
+	}
+}
+
+func TestLoadExportWithoutMetadata(t *testing.T) {
+	if _, err := LoadExport(t.TempDir()); err == nil {
+		t.Error("expected error for directory without export metadata, got nil")
+	}
+}
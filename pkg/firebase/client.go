@@ -21,6 +21,7 @@ type Client struct {
 	config         *config.Config
 	currentProject string
 	usingLocalAuth bool
+	backup         *ExportStore // Set when browsing an export offline
 }
 
 // Project represents a Firebase project.
@@ -61,6 +62,14 @@ func NewClient(ctx context.Context, cfg *config.Config) (*Client, error) {
 // ListProjects returns all Firebase projects accessible to the authenticated user.
 // It calls 'firebase projects:list' and parses the JSON output.
 func (c *Client) ListProjects() ([]Project, error) {
+	if c.backup != nil {
+		return []Project{{
+			ID:          c.exportProjectID(),
+			DisplayName: fmt.Sprintf("%s (export)", c.exportProjectID()),
+			Environment: "export",
+		}}, nil
+	}
+
 	cmd := exec.Command("firebase", "projects:list", "--json")
 	output, err := cmd.Output()
 	if err != nil {
@@ -115,6 +124,13 @@ func (c *Client) IsUsingLocalAuth() bool {
 
 // GetProjectDetails fetches extended information about a Firebase project.
 func (c *Client) GetProjectDetails(projectID string) (*ProjectDetails, error) {
+	if c.backup != nil {
+		return &ProjectDetails{
+			ProjectID:   projectID,
+			DisplayName: fmt.Sprintf("Export %s (%d documents)", c.backup.Dir, c.backup.DocumentCount()),
+		}, nil
+	}
+
 	token, err := c.getFirebaseToken()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,531 @@
+package firebase
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Languages supported by GenerateTypes.
+const (
+	LangTypeScript = "typescript"
+	LangGo         = "go"
+	LangDataclass  = "dataclass" // Python dataclasses
+	LangPydantic   = "pydantic"  // Python pydantic models
+)
+
+// TypeExtensions maps each language to the extension of a generated file.
+var TypeExtensions = map[string]string{
+	LangTypeScript: ".ts",
+	LangGo:         ".go",
+	LangDataclass:  ".py",
+	LangPydantic:   ".py",
+}
+
+// kindObject marks a generated type; kindAny a value of unknown type.
+const (
+	kindObject = "object"
+	kindAny    = "any"
+)
+
+// genRef is the type of a generated field.
+type genRef struct {
+	Kind   string   // Firestore type, kindObject or kindAny
+	Object string   // Generated type name when Kind is kindObject
+	Elem   *genRef  // Element type of arrays
+	Union  []genRef // Alternatives when the field holds several types
+}
+
+// genField is one field of a generated type.
+type genField struct {
+	Key      string // Firestore field name
+	Type     genRef
+	Optional bool   // Missing from some documents that have the parent
+	Nullable bool   // Null in some documents
+	Presence string // Share of parents that have the field, e.g. "87% of documents"
+}
+
+// genObject is a generated type for a map, or the document itself.
+type genObject struct {
+	Name   string
+	Fields []genField
+}
+
+// typeModel is the language-independent result of reading a schema.
+type typeModel struct {
+	schema  *Schema
+	names   map[string]bool
+	Objects []genObject     // Nested types first, the document type last
+	Kinds   map[string]bool // Firestore types used anywhere
+}
+
+// GenerateTypes writes model definitions for the documents described by s.
+// Fields present in fewer documents, array elements or maps than their parent
+// holds are optional, with their presence ratio noted in a comment.
+func GenerateTypes(s *Schema, lang string) (string, error) {
+	m := &typeModel{schema: s, names: make(map[string]bool), Kinds: make(map[string]bool)}
+	m.object(TypeName(s.Collection), "", s.Documents)
+
+	switch lang {
+	case LangTypeScript:
+		return m.typeScript(), nil
+	case LangGo:
+		return m.goStructs(), nil
+	case LangDataclass:
+		return m.python(false), nil
+	case LangPydantic:
+		return m.python(true), nil
+	}
+	return "", fmt.Errorf("unknown language %q", lang)
+}
+
+// TypeName derives a type name from a collection path: users/a/orders -> Order.
+func TypeName(collection string) string {
+	name := collection[strings.LastIndex(collection, "/")+1:]
+	return singular(exportedName(name))
+}
+
+// object adds the type for the map at path and returns its unique name.
+// parentPresent counts the map's values; a field with fewer values is optional.
+// Counting values rather than documents decides optional fields of array
+// elements per element, and of a map that is sometimes null per map.
+func (m *typeModel) object(hint, path string, parentPresent int) string {
+	name := hint
+	for i := 2; m.names[name]; i++ {
+		name = fmt.Sprintf("%s%d", hint, i)
+	}
+	m.names[name] = true
+
+	// Presence is relative to the documents, array elements or parent maps
+	of := "documents"
+	switch {
+	case strings.HasSuffix(path, "[]"):
+		of = "elements"
+	case path != "":
+		of = m.schema.Field(path).Name + " maps"
+	}
+
+	obj := genObject{Name: name}
+	for _, f := range m.schema.Children(path) {
+		field := genField{
+			Key:      f.Name,
+			Type:     m.ref(f, name+exportedName(f.Name)),
+			Optional: f.Values < parentPresent,
+			Nullable: f.Nulls > 0,
+		}
+		if field.Optional {
+			field.Presence = fmt.Sprintf("%d%% of %s", f.Values*100/parentPresent, of)
+		}
+		obj.Fields = append(obj.Fields, field)
+	}
+	m.Objects = append(m.Objects, obj)
+	return name
+}
+
+// ref resolves the type of a field; hint names a generated type for maps.
+func (m *typeModel) ref(f *SchemaField, hint string) genRef {
+	var refs []genRef
+	for _, typ := range f.TypeNames() {
+		m.Kinds[typ] = true
+		switch typ {
+		case TypeMap:
+			if len(m.schema.Children(f.Path)) == 0 {
+				refs = append(refs, genRef{Kind: TypeMap})
+			} else {
+				refs = append(refs, genRef{Kind: kindObject, Object: m.object(hint, f.Path, f.Types[TypeMap])})
+			}
+		case TypeArray:
+			elem := genRef{Kind: kindAny}
+			if e := m.schema.Field(f.Path + "[]"); e != nil {
+				elem = m.ref(e, singular(hint))
+			}
+			refs = append(refs, genRef{Kind: TypeArray, Elem: &elem})
+		default:
+			refs = append(refs, genRef{Kind: typ})
+		}
+	}
+
+	switch len(refs) {
+	case 0:
+		return genRef{Kind: kindAny}
+	case 1:
+		return refs[0]
+	}
+	return genRef{Kind: "union", Union: refs}
+}
+
+// typeScript renders interfaces using the Firestore web SDK types.
+func (m *typeModel) typeScript() string {
+	var b strings.Builder
+	var imports []string
+	for kind, name := range map[string]string{TypeTimestamp: "Timestamp", TypeReference: "DocumentReference", TypeGeoPoint: "GeoPoint", TypeBytes: "Bytes"} {
+		if m.Kinds[kind] {
+			imports = append(imports, name)
+		}
+	}
+	if len(imports) > 0 {
+		sort.Strings(imports)
+		b.WriteString(fmt.Sprintf("import type { %s } from \"firebase/firestore\";\n\n", strings.Join(imports, ", ")))
+	}
+
+	for i, obj := range m.Objects {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(fmt.Sprintf("export interface %s {\n", obj.Name))
+		for _, f := range obj.Fields {
+			if f.Optional {
+				b.WriteString(fmt.Sprintf("  /** Present in %s */\n", f.Presence))
+			}
+			key := f.Key
+			if !tsIdentifier.MatchString(key) {
+				quoted, _ := json.Marshal(key)
+				key = string(quoted)
+			}
+			if f.Optional {
+				key += "?"
+			}
+			typ := tsType(f.Type)
+			if f.Nullable {
+				typ += " | null"
+			}
+			b.WriteString(fmt.Sprintf("  %s: %s;\n", key, typ))
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func tsType(r genRef) string {
+	switch r.Kind {
+	case kindObject:
+		return r.Object
+	case "union":
+		var parts []string
+		for _, u := range r.Union {
+			parts = append(parts, tsType(u))
+		}
+		return strings.Join(parts, " | ")
+	case TypeArray:
+		elem := tsType(*r.Elem)
+		if r.Elem.Kind == "union" {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	}
+	return map[string]string{
+		TypeString:    "string",
+		TypeInteger:   "number",
+		TypeDouble:    "number",
+		TypeBoolean:   "boolean",
+		TypeTimestamp: "Timestamp",
+		TypeReference: "DocumentReference",
+		TypeGeoPoint:  "GeoPoint",
+		TypeBytes:     "Bytes",
+		TypeMap:       "Record<string, unknown>",
+		kindAny:       "unknown",
+	}[r.Kind]
+}
+
+// goStructs renders structs for the Go server SDK with firestore tags.
+func (m *typeModel) goStructs() string {
+	var body strings.Builder
+	for _, obj := range m.Objects {
+		body.WriteString(fmt.Sprintf("\ntype %s struct {\n", obj.Name))
+		for _, f := range obj.Fields {
+			if f.Optional {
+				body.WriteString(fmt.Sprintf("\t// Present in %s\n", f.Presence))
+			}
+			typ := goType(f.Type)
+			// Pointers hold null, and let omitempty skip a missing nested struct
+			if f.Nullable && goPointable[f.Type.Kind] || (f.Nullable || f.Optional) && f.Type.Kind == kindObject {
+				typ = "*" + typ
+			}
+			tag := f.Key
+			if f.Optional {
+				tag += ",omitempty"
+			}
+			body.WriteString(fmt.Sprintf("\t%s %s `firestore:%q`\n", exportedName(f.Key), typ, tag))
+		}
+		body.WriteString("}\n")
+	}
+
+	// Import only what the fields use; types inside unions become interface{}
+	var std, sdk []string
+	if strings.Contains(body.String(), "time.Time") {
+		std = append(std, `"time"`)
+	}
+	if strings.Contains(body.String(), "firestore.DocumentRef") {
+		sdk = append(sdk, `"cloud.google.com/go/firestore"`)
+	}
+	if strings.Contains(body.String(), "latlng.LatLng") {
+		sdk = append(sdk, `"google.golang.org/genproto/googleapis/type/latlng"`)
+	}
+
+	var b strings.Builder
+	b.WriteString("package models\n")
+	if len(std)+len(sdk) > 0 {
+		b.WriteString("\nimport (\n")
+		for _, imp := range std {
+			b.WriteString("\t" + imp + "\n")
+		}
+		if len(std) > 0 && len(sdk) > 0 {
+			b.WriteString("\n")
+		}
+		for _, imp := range sdk {
+			b.WriteString("\t" + imp + "\n")
+		}
+		b.WriteString(")\n")
+	}
+	b.WriteString(body.String())
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String()
+	}
+	return string(src)
+}
+
+// goPointable lists Go value types that need a pointer to hold null.
+var goPointable = map[string]bool{
+	TypeString:    true,
+	TypeInteger:   true,
+	TypeDouble:    true,
+	TypeBoolean:   true,
+	TypeTimestamp: true,
+}
+
+func goType(r genRef) string {
+	switch r.Kind {
+	case kindObject:
+		return r.Object
+	case TypeArray:
+		return "[]" + goType(*r.Elem)
+	}
+	return map[string]string{
+		TypeString:    "string",
+		TypeInteger:   "int64",
+		TypeDouble:    "float64",
+		TypeBoolean:   "bool",
+		TypeTimestamp: "time.Time",
+		TypeReference: "*firestore.DocumentRef",
+		TypeGeoPoint:  "*latlng.LatLng",
+		TypeBytes:     "[]byte",
+		TypeMap:       "map[string]interface{}",
+		"union":       "interface{}",
+		kindAny:       "interface{}",
+	}[r.Kind]
+}
+
+// python renders dataclasses, or pydantic models, for the Python server SDK.
+func (m *typeModel) python(pydantic bool) string {
+	typing := make(map[string]bool)
+	var body strings.Builder
+
+	for _, obj := range m.Objects {
+		body.WriteString("\n\n")
+		if pydantic {
+			body.WriteString(fmt.Sprintf("class %s(BaseModel):\n", obj.Name))
+			if m.Kinds[TypeReference] || m.Kinds[TypeGeoPoint] {
+				body.WriteString("    model_config = ConfigDict(arbitrary_types_allowed=True)\n\n")
+			}
+		} else {
+			body.WriteString(fmt.Sprintf("@dataclass\nclass %s:\n", obj.Name))
+		}
+		if len(obj.Fields) == 0 {
+			body.WriteString("    pass\n")
+			continue
+		}
+
+		// Fields with defaults must follow the required ones
+		fields := append([]genField(nil), obj.Fields...)
+		sort.SliceStable(fields, func(i, j int) bool { return !fields[i].Optional && fields[j].Optional })
+
+		for _, f := range fields {
+			typ := pyType(f.Type, typing)
+			if f.Optional || f.Nullable {
+				typing["Optional"] = true
+				typ = "Optional[" + typ + "]"
+			}
+
+			name := pythonName(f.Key)
+			line := fmt.Sprintf("    %s: %s", name, typ)
+			switch {
+			case pydantic && name != f.Key && f.Optional:
+				line += fmt.Sprintf(" = Field(default=None, alias=%q)", f.Key)
+			case pydantic && name != f.Key:
+				line += fmt.Sprintf(" = Field(alias=%q)", f.Key)
+			case f.Optional:
+				line += " = None"
+			}
+
+			var notes []string
+			if !pydantic && name != f.Key {
+				notes = append(notes, fmt.Sprintf("Firestore field %q", f.Key))
+			}
+			if f.Optional {
+				notes = append(notes, "present in "+f.Presence)
+			}
+			if len(notes) > 0 {
+				line += "  # " + strings.Join(notes, ", ")
+			}
+			body.WriteString(line + "\n")
+		}
+	}
+
+	var b strings.Builder
+	if !pydantic {
+		b.WriteString("from dataclasses import dataclass\n")
+	}
+	if m.Kinds[TypeTimestamp] {
+		b.WriteString("from datetime import datetime\n")
+	}
+	if len(typing) > 0 {
+		var names []string
+		for name := range typing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		b.WriteString(fmt.Sprintf("from typing import %s\n", strings.Join(names, ", ")))
+	}
+	if m.Kinds[TypeReference] || m.Kinds[TypeGeoPoint] {
+		var names []string
+		if m.Kinds[TypeReference] {
+			names = append(names, "DocumentReference")
+		}
+		if m.Kinds[TypeGeoPoint] {
+			names = append(names, "GeoPoint")
+		}
+		b.WriteString(fmt.Sprintf("\nfrom google.cloud.firestore import %s\n", strings.Join(names, ", ")))
+	}
+	if pydantic {
+		imports := "BaseModel"
+		if m.Kinds[TypeReference] || m.Kinds[TypeGeoPoint] {
+			imports += ", ConfigDict"
+		}
+		if strings.Contains(body.String(), "Field(") {
+			imports += ", Field"
+		}
+		b.WriteString(fmt.Sprintf("from pydantic import %s\n", imports))
+	}
+	b.WriteString(body.String())
+	return b.String()
+}
+
+func pyType(r genRef, typing map[string]bool) string {
+	switch r.Kind {
+	case kindObject:
+		return r.Object
+	case "union":
+		var parts []string
+		for _, u := range r.Union {
+			parts = append(parts, pyType(u, typing))
+		}
+		typing["Union"] = true
+		return "Union[" + strings.Join(parts, ", ") + "]"
+	case TypeArray:
+		typing["List"] = true
+		return "List[" + pyType(*r.Elem, typing) + "]"
+	case TypeMap:
+		typing["Any"] = true
+		typing["Dict"] = true
+		return "Dict[str, Any]"
+	case kindAny:
+		typing["Any"] = true
+		return "Any"
+	}
+	return map[string]string{
+		TypeString:    "str",
+		TypeInteger:   "int",
+		TypeDouble:    "float",
+		TypeBoolean:   "bool",
+		TypeTimestamp: "datetime",
+		TypeReference: "DocumentReference",
+		TypeGeoPoint:  "GeoPoint",
+		TypeBytes:     "bytes",
+	}[r.Kind]
+}
+
+// goInitialisms are words written in capitals in Go names.
+var goInitialisms = map[string]bool{"id": true, "url": true, "uri": true, "api": true, "http": true, "json": true, "uid": true}
+
+// exportedName converts a field name to an exported identifier: created_at -> CreatedAt, userId -> UserID.
+func exportedName(key string) string {
+	var words []string
+	var word []rune
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(word) > 0 {
+				words = append(words, string(word))
+			}
+			word = nil
+			continue
+		case unicode.IsUpper(r) && len(word) > 0 && i > 0 && unicode.IsLower(runes[i-1]):
+			words = append(words, string(word))
+			word = nil
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+
+	var b strings.Builder
+	for _, w := range words {
+		if goInitialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		r := []rune(w)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+
+	name := b.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "F" + name
+	}
+	return name
+}
+
+// singular drops a plural ending from a type name: Orders -> Order, Categories -> Category.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "ss"), strings.HasSuffix(name, "us"):
+		return name
+	case strings.HasSuffix(name, "s") && len(name) > 1:
+		return name[:len(name)-1]
+	}
+	return name
+}
+
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true,
+	"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true,
+	"else": true, "except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true,
+	"pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+var pythonInvalid = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// pythonName makes a field name a valid Python identifier.
+func pythonName(key string) string {
+	name := strings.Trim(pythonInvalid.ReplaceAllString(key, "_"), "_")
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "f_" + name
+	}
+	if pythonKeywords[name] {
+		name += "_"
+	}
+	return name
+}
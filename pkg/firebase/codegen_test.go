@@ -0,0 +1,224 @@
+package firebase
+
+import (
+	"strings"
+	"testing"
+)
+
+// codegenSchema samples shops/s1/orders, where only the first order has an
+// address and items, and note is null in the second.
+func codegenSchema() *Schema {
+	return InferSchema("shops/s1/orders", []Document{
+		schemaDoc(map[string]interface{}{
+			"createdAt": map[string]interface{}{"timestampValue": "2024-01-02T03:04:05Z"},
+			"customer":  map[string]interface{}{"referenceValue": "projects/demo/databases/(default)/documents/users/ana"},
+			"note":      schemaStr("leave at door"),
+			"address": map[string]interface{}{"mapValue": map[string]interface{}{"fields": map[string]interface{}{
+				"location": map[string]interface{}{"geoPointValue": map[string]interface{}{"latitude": 41.3, "longitude": 19.8}},
+			}}},
+			"items": map[string]interface{}{"arrayValue": map[string]interface{}{"values": []interface{}{
+				map[string]interface{}{"mapValue": map[string]interface{}{"fields": map[string]interface{}{"qty": schemaInt("2")}}},
+			}}},
+		}),
+		schemaDoc(map[string]interface{}{
+			"createdAt": map[string]interface{}{"timestampValue": "2024-01-03T03:04:05Z"},
+			"customer":  map[string]interface{}{"referenceValue": "projects/demo/databases/(default)/documents/users/bob"},
+			"note":      map[string]interface{}{"nullValue": nil},
+		}),
+	})
+}
+
+func TestGenerateTypes(t *testing.T) {
+	tests := []struct {
+		lang     string
+		expected []string
+	}{
+		{LangTypeScript, []string{
+			`import type { DocumentReference, GeoPoint, Timestamp } from "firebase/firestore";`,
+			"export interface OrderAddress {\n  location: GeoPoint;\n}",
+			"export interface OrderItem {\n  qty: number;\n}",
+			"  /** Present in 50% of documents */\n  address?: OrderAddress;",
+			"  createdAt: Timestamp;",
+			"  customer: DocumentReference;",
+			"  items?: OrderItem[];",
+			"  note: string | null;",
+		}},
+		{LangGo, []string{
+			"package models",
+			"\t\"time\"\n\n\t\"cloud.google.com/go/firestore\"\n\t\"google.golang.org/genproto/googleapis/type/latlng\"",
+			"Location *latlng.LatLng `firestore:\"location\"`",
+			"Address   *OrderAddress          `firestore:\"address,omitempty\"`",
+			"CreatedAt time.Time",
+			"Customer  *firestore.DocumentRef",
+			"Items []OrderItem `firestore:\"items,omitempty\"`",
+			"Note  *string",
+		}},
+		{LangDataclass, []string{
+			"from dataclasses import dataclass\nfrom datetime import datetime\nfrom typing import List, Optional",
+			"from google.cloud.firestore import DocumentReference, GeoPoint",
+			"@dataclass\nclass OrderAddress:\n    location: GeoPoint",
+			"    createdAt: datetime\n    customer: DocumentReference\n    note: Optional[str]\n    address: Optional[OrderAddress] = None  # present in 50% of documents",
+			"    items: Optional[List[OrderItem]] = None",
+		}},
+		{LangPydantic, []string{
+			"from pydantic import BaseModel, ConfigDict\n",
+			"class Order(BaseModel):\n    model_config = ConfigDict(arbitrary_types_allowed=True)",
+			"    note: Optional[str]\n",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			output, err := GenerateTypes(codegenSchema(), tt.lang)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(output, want) {
+					t.Errorf("GenerateTypes(%s) missing %q in:\n%s", tt.lang, want, output)
+				}
+			}
+		})
+	}
+
+	if _, err := GenerateTypes(codegenSchema(), "cobol"); err == nil {
+		t.Error("GenerateTypes with an unknown language should fail")
+	}
+}
+
+func TestGenerateTypesConflictsAndNames(t *testing.T) {
+	s := InferSchema("users", []Document{
+		schemaDoc(map[string]interface{}{"age": schemaInt("30"), "first name": schemaStr("Ana"), "class": schemaStr("a")}),
+		schemaDoc(map[string]interface{}{"age": schemaStr("41"), "first name": schemaStr("Bob"), "class": schemaStr("b")}),
+	})
+
+	ts, _ := GenerateTypes(s, LangTypeScript)
+	goSrc, _ := GenerateTypes(s, LangGo)
+	py, _ := GenerateTypes(s, LangPydantic)
+
+	for _, check := range []struct{ output, want string }{
+		{ts, `"first name": string;`},
+		{ts, "age: number | string;"},
+		{goSrc, "Age       interface{} `firestore:\"age\"`"},
+		{goSrc, "FirstName string      `firestore:\"first name\"`"},
+		{py, "age: Union[int, str]"},
+		{py, `first_name: str = Field(alias="first name")`},
+		{py, "class_: str = Field(alias=\"class\")"},
+	} {
+		if !strings.Contains(check.output, check.want) {
+			t.Errorf("missing %q in:\n%s", check.want, check.output)
+		}
+	}
+}
+
+func TestGenerateTypesArrayElements(t *testing.T) {
+	item := func(fields map[string]interface{}) interface{} {
+		return map[string]interface{}{"mapValue": map[string]interface{}{"fields": fields}}
+	}
+	items := func(values ...interface{}) interface{} {
+		return map[string]interface{}{"arrayValue": map[string]interface{}{"values": values}}
+	}
+	// Every document has both fields, but no element of the first one does
+	s := InferSchema("orders", []Document{
+		schemaDoc(map[string]interface{}{"items": items(
+			item(map[string]interface{}{"price": schemaStr("9.99")}),
+			item(map[string]interface{}{"sku": schemaStr("A1")}),
+		)}),
+		schemaDoc(map[string]interface{}{"items": items(
+			item(map[string]interface{}{"price": schemaStr("5"), "sku": schemaStr("B2")}),
+			item(map[string]interface{}{"price": schemaStr("7"), "sku": schemaStr("C3")}),
+		)}),
+	})
+
+	ts, _ := GenerateTypes(s, LangTypeScript)
+	for _, want := range []string{"price?: string;", "sku?: string;", "Present in 75% of elements"} {
+		if !strings.Contains(ts, want) {
+			t.Errorf("missing %q in:\n%s", want, ts)
+		}
+	}
+}
+
+func TestGenerateTypesNullableMap(t *testing.T) {
+	address := func(fields map[string]interface{}) interface{} {
+		return map[string]interface{}{"mapValue": map[string]interface{}{"fields": fields}}
+	}
+	// city is in every address, which is null in the last document
+	s := InferSchema("users", []Document{
+		schemaDoc(map[string]interface{}{"address": address(map[string]interface{}{"city": schemaStr("Tirana"), "zip": schemaStr("1001")})}),
+		schemaDoc(map[string]interface{}{"address": address(map[string]interface{}{"city": schemaStr("Durrës")})}),
+		schemaDoc(map[string]interface{}{"address": map[string]interface{}{"nullValue": nil}}),
+	})
+
+	ts, _ := GenerateTypes(s, LangTypeScript)
+	for _, want := range []string{
+		"  city: string;",
+		"  /** Present in 50% of address maps */\n  zip?: string;",
+		"  address: UserAddress | null;",
+	} {
+		if !strings.Contains(ts, want) {
+			t.Errorf("missing %q in:\n%s", want, ts)
+		}
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"name", "Name"},
+		{"created_at", "CreatedAt"},
+		{"userId", "UserID"},
+		{"first name", "FirstName"},
+		{"2fa", "F2fa"},
+		{"apiURL", "APIURL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := exportedName(tt.key); got != tt.expected {
+				t.Errorf("exportedName(%q) = %q, expected %q", tt.key, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTypeName(t *testing.T) {
+	tests := []struct {
+		collection string
+		expected   string
+	}{
+		{"users", "User"},
+		{"shops/s1/categories", "Category"},
+		{"address", "Address"},
+		{"order_items", "OrderItem"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.collection, func(t *testing.T) {
+			if got := TypeName(tt.collection); got != tt.expected {
+				t.Errorf("TypeName(%q) = %q, expected %q", tt.collection, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPythonName(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"name", "name"},
+		{"first name", "first_name"},
+		{"class", "class_"},
+		{"2fa", "f_2fa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := pythonName(tt.key); got != tt.expected {
+				t.Errorf("pythonName(%q) = %q, expected %q", tt.key, got, tt.expected)
+			}
+		})
+	}
+}
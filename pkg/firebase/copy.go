@@ -0,0 +1,329 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Reference handling when copying documents.
+const (
+	CopyRefsKeep     = "keep"     // Leave references pointing at the source documents
+	CopyRefsRetarget = "retarget" // Point references at the same paths in the destination database
+	CopyRefsRemap    = "remap"    // Like retarget, but references to copied documents follow them to their new path
+)
+
+// DefaultDatabase is the ID of a project's default Firestore database.
+const DefaultDatabase = "(default)"
+
+// CopyOptions configures PlanCopy.
+type CopyOptions struct {
+	Roots          []string // Collection or document paths to copy, all in the same collection
+	DestProject    string   // Destination project (default: current project)
+	DestDatabase   string   // Destination database (default: "(default)")
+	DestCollection string   // Collection the roots' collection maps to (default: same path)
+	References     string   // CopyRefsKeep, CopyRefsRetarget or CopyRefsRemap
+}
+
+// CopyWrite is one document write of a copy.
+type CopyWrite struct {
+	Source string                 // Source document path
+	Dest   string                 // Destination document path
+	Fields map[string]interface{} // Fields to write, with references already rewritten
+}
+
+// CopyPlan lists every write a copy will make, for review before committing.
+type CopyPlan struct {
+	Options    CopyOptions
+	Writes     []CopyWrite
+	References int // Reference values rewritten
+}
+
+// CopyFailure describes a document that could not be written.
+type CopyFailure struct {
+	Path  string // Destination document path
+	Error string
+}
+
+// CopySummary reports the outcome of a committed copy.
+type CopySummary struct {
+	Written []string
+	Failed  []CopyFailure
+}
+
+// PlanCopy reads the documents below opts.Roots (documents and subcollections,
+// recursively) and works out where each one is written. Nothing is written.
+func (c *Client) PlanCopy(ctx context.Context, opts CopyOptions) (*CopyPlan, error) {
+	if c.currentProject == "" {
+		return nil, fmt.Errorf("no project selected")
+	}
+	if len(opts.Roots) == 0 {
+		return nil, fmt.Errorf("nothing to copy")
+	}
+	if opts.DestProject == "" {
+		opts.DestProject = c.currentProject
+	}
+	if opts.DestDatabase == "" {
+		opts.DestDatabase = DefaultDatabase
+	}
+	switch opts.References {
+	case "":
+		opts.References = CopyRefsKeep
+	case CopyRefsKeep, CopyRefsRetarget, CopyRefsRemap:
+	default:
+		return nil, fmt.Errorf("unknown reference mode %q", opts.References)
+	}
+
+	sourceCollection := ""
+	roots := make([]string, 0, len(opts.Roots))
+	for _, root := range opts.Roots {
+		root = strings.Trim(root, "/")
+		roots = append(roots, root)
+		if root == "" {
+			return nil, fmt.Errorf("nothing to copy")
+		}
+		col := CopySourceCollection(root)
+		if sourceCollection != "" && col != sourceCollection {
+			return nil, fmt.Errorf("%s and %s are in different collections", sourceCollection, col)
+		}
+		sourceCollection = col
+	}
+	opts.Roots = roots
+
+	opts.DestCollection = strings.Trim(opts.DestCollection, "/")
+	if opts.DestCollection == "" {
+		opts.DestCollection = sourceCollection
+	}
+	if strings.Count(opts.DestCollection, "/")%2 != 0 {
+		return nil, fmt.Errorf("%q is not a collection", opts.DestCollection)
+	}
+	if opts.DestProject == c.currentProject && opts.DestDatabase == DefaultDatabase && opts.DestCollection == sourceCollection {
+		return nil, fmt.Errorf("source and destination are the same")
+	}
+
+	p := &copyPlanner{
+		client: c,
+		ctx:    ctx,
+		plan:   &CopyPlan{Options: opts},
+		seen:   make(map[string]bool),
+		from:   sourceCollection,
+		to:     opts.DestCollection,
+	}
+	for _, root := range opts.Roots {
+		if err := p.addRoot(root); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.References != CopyRefsKeep {
+		p.rewriteReferences()
+	}
+	return p.plan, nil
+}
+
+// CommitCopy writes a plan to its destination in batches of up to 500.
+// Existing destination documents are replaced.
+func (c *Client) CommitCopy(ctx context.Context, plan *CopyPlan, progress func(done, total int)) (*CopySummary, error) {
+	opts := plan.Options
+	summary := &CopySummary{}
+
+	for start := 0; start < len(plan.Writes); start += importBatchSize {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		batch := plan.Writes[start:min(start+importBatchSize, len(plan.Writes))]
+		writes := make([]map[string]interface{}, 0, len(batch))
+		for _, w := range batch {
+			writes = append(writes, map[string]interface{}{
+				"update": map[string]interface{}{
+					"name":   documentNameIn(opts.DestProject, opts.DestDatabase, w.Dest),
+					"fields": w.Fields,
+				},
+			})
+		}
+
+		statuses, err := c.postBatchWrite(opts.DestProject, opts.DestDatabase, writes)
+		if err != nil {
+			return summary, err
+		}
+		for i, w := range batch {
+			if statuses[i].Code == 0 {
+				summary.Written = append(summary.Written, w.Dest)
+			} else {
+				summary.Failed = append(summary.Failed, CopyFailure{Path: w.Dest, Error: statuses[i].Message})
+			}
+		}
+
+		if progress != nil {
+			progress(start+len(batch), len(plan.Writes))
+		}
+	}
+
+	return summary, nil
+}
+
+// documentNameIn returns the full resource name of a document in any project and database.
+func documentNameIn(project, database, docPath string) string {
+	return fmt.Sprintf("projects/%s/databases/%s/documents/%s", project, database, docPath)
+}
+
+// CopySourceCollection returns the collection a copy root belongs to:
+// the root itself for a collection, its parent for a document.
+func CopySourceCollection(root string) string {
+	if strings.Count(root, "/")%2 == 0 {
+		return root
+	}
+	return root[:strings.LastIndex(root, "/")]
+}
+
+// copyDestPath maps a path below the source collection to the destination collection.
+func copyDestPath(path, from, to string) string {
+	return to + strings.TrimPrefix(path, from)
+}
+
+// copyPlanner holds the state of PlanCopy's walk.
+type copyPlanner struct {
+	client *Client
+	ctx    context.Context
+	plan   *CopyPlan
+	seen   map[string]bool
+	from   string // Source collection
+	to     string // Destination collection
+}
+
+// addRoot adds a collection or document root and everything below it.
+func (p *copyPlanner) addRoot(root string) error {
+	if strings.Count(root, "/")%2 == 0 {
+		return p.addCollection(root)
+	}
+	doc, err := p.client.GetDocument(root)
+	if err != nil {
+		return err
+	}
+	return p.addDocument(*doc)
+}
+
+// addCollection adds every document of a collection, page by page.
+func (p *copyPlanner) addCollection(path string) error {
+	after := ""
+	for {
+		if err := p.ctx.Err(); err != nil {
+			return err
+		}
+		docs, err := p.client.ListDocumentsAfter(path, after, treeExportPageSize)
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			if err := p.addDocument(doc); err != nil {
+				return err
+			}
+			after = doc.Path
+		}
+		if len(docs) < treeExportPageSize {
+			return nil
+		}
+	}
+}
+
+// addDocument adds a document and its subcollections. Documents reached through
+// more than one root (e.g. a collection and one of its documents) are added once.
+func (p *copyPlanner) addDocument(doc Document) error {
+	if p.seen[doc.Path] {
+		return nil
+	}
+	p.seen[doc.Path] = true
+
+	fields := doc.Fields
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	p.plan.Writes = append(p.plan.Writes, CopyWrite{
+		Source: doc.Path,
+		Dest:   copyDestPath(doc.Path, p.from, p.to),
+		Fields: fields,
+	})
+
+	collections, err := p.client.ListSubcollections(doc.Path)
+	if err != nil {
+		return err
+	}
+	for _, col := range collections {
+		if err := p.addCollection(col.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteReferences points references to the source database at the destination.
+// Only references into the source project's default database are changed.
+func (p *copyPlanner) rewriteReferences() {
+	opts := p.plan.Options
+	sourceRoot := documentNameIn(p.client.currentProject, DefaultDatabase, "")
+
+	copied := make(map[string]string)
+	if opts.References == CopyRefsRemap {
+		for _, w := range p.plan.Writes {
+			copied[w.Source] = w.Dest
+		}
+	}
+
+	rewrite := func(ref string) (string, bool) {
+		if !strings.HasPrefix(ref, sourceRoot) {
+			return ref, false
+		}
+		path := strings.TrimPrefix(ref, sourceRoot)
+		if dest, ok := copied[path]; ok {
+			path = dest
+		}
+		newRef := documentNameIn(opts.DestProject, opts.DestDatabase, path)
+		return newRef, newRef != ref
+	}
+
+	for i, w := range p.plan.Writes {
+		fields := make(map[string]interface{}, len(w.Fields))
+		for name, value := range w.Fields {
+			fields[name] = rewriteReferenceValue(value, rewrite, &p.plan.References)
+		}
+		p.plan.Writes[i].Fields = fields
+	}
+}
+
+// rewriteReferenceValue returns a copy of a typed Firestore value with every
+// referenceValue passed through rewrite. count is incremented per changed reference.
+func rewriteReferenceValue(value interface{}, rewrite func(string) (string, bool), count *int) interface{} {
+	typed, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	if ref, ok := typed["referenceValue"].(string); ok {
+		newRef, changed := rewrite(ref)
+		if changed {
+			*count++
+		}
+		return map[string]interface{}{"referenceValue": newRef}
+	}
+
+	if m, ok := typed["mapValue"].(map[string]interface{}); ok {
+		fields, _ := m["fields"].(map[string]interface{})
+		newFields := make(map[string]interface{}, len(fields))
+		for name, v := range fields {
+			newFields[name] = rewriteReferenceValue(v, rewrite, count)
+		}
+		return map[string]interface{}{"mapValue": map[string]interface{}{"fields": newFields}}
+	}
+
+	if a, ok := typed["arrayValue"].(map[string]interface{}); ok {
+		values, _ := a["values"].([]interface{})
+		newValues := make([]interface{}, len(values))
+		for i, v := range values {
+			newValues[i] = rewriteReferenceValue(v, rewrite, count)
+		}
+		return map[string]interface{}{"arrayValue": map[string]interface{}{"values": newValues}}
+	}
+
+	return value
+}
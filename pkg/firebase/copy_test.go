@@ -0,0 +1,191 @@
+package firebase
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newCopyClient returns an offline client over users/{alice,bob} and
+// users/alice/orders/o1, where alice.friend references users/bob and
+// o1.owner references users/alice.
+func newCopyClient(t *testing.T) *Client {
+	t.Helper()
+	ref := func(id string) []byte {
+		return (&protoWriter{}).tag(valueReferenceGroup, wireStartGroup).
+			str(refValueAppField, "s~demo").
+			tag(refValueElementGroup, wireStartGroup).str(refValueTypeField, "users").str(refValueNameField, id).tag(refValueElementGroup, wireEndGroup).
+			tag(valueReferenceGroup, wireEndGroup).buf
+	}
+	dir := writeExport(t,
+		entityBytes(keyBytes("s~demo", "users", "alice"), property("friend", 0, false, ref("bob"))),
+		entityBytes(keyBytes("s~demo", "users", "bob"), property("name", 0, false, (&protoWriter{}).str(valueStringField, "Bob").buf)),
+		entityBytes(keyBytes("s~demo", "users", "alice", "orders", "o1"), property("owner", 0, false, ref("alice"))),
+	)
+	store, err := LoadExport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{currentProject: "demo", backup: store}
+}
+
+// copyDests returns the destination paths of a plan in order.
+func copyDests(plan *CopyPlan) []string {
+	var dests []string
+	for _, w := range plan.Writes {
+		dests = append(dests, w.Dest)
+	}
+	return dests
+}
+
+// copyRef returns the reference stored in a top-level field of a planned write.
+func copyRef(t *testing.T, plan *CopyPlan, dest, field string) string {
+	t.Helper()
+	for _, w := range plan.Writes {
+		if w.Dest == dest {
+			value, _ := w.Fields[field].(map[string]interface{})
+			ref, _ := value["referenceValue"].(string)
+			return ref
+		}
+	}
+	t.Fatalf("no write for %s", dest)
+	return ""
+}
+
+func TestPlanCopyPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     CopyOptions
+		expected []string
+	}{
+		{
+			name:     "collection to another collection",
+			opts:     CopyOptions{Roots: []string{"users"}, DestCollection: "people"},
+			expected: []string{"people/alice", "people/alice/orders/o1", "people/bob"},
+		},
+		{
+			name:     "document keeps its ID",
+			opts:     CopyOptions{Roots: []string{"users/alice"}, DestCollection: "archive/2024/users"},
+			expected: []string{"archive/2024/users/alice", "archive/2024/users/alice/orders/o1"},
+		},
+		{
+			name:     "multiple documents",
+			opts:     CopyOptions{Roots: []string{"users/bob", "users/alice"}, DestCollection: "people"},
+			expected: []string{"people/bob", "people/alice", "people/alice/orders/o1"},
+		},
+		{
+			name:     "overlapping roots are copied once",
+			opts:     CopyOptions{Roots: []string{"users", "users/alice"}, DestCollection: "people"},
+			expected: []string{"people/alice", "people/alice/orders/o1", "people/bob"},
+		},
+		{
+			name:     "same path in another project",
+			opts:     CopyOptions{Roots: []string{"users/bob"}, DestProject: "staging"},
+			expected: []string{"users/bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := newCopyClient(t).PlanCopy(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := copyDests(plan); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("destinations = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlanCopyReferences(t *testing.T) {
+	const (
+		source  = "projects/demo/databases/(default)/documents/"
+		staging = "projects/staging/databases/(default)/documents/"
+	)
+
+	tests := []struct {
+		mode       string
+		friend     string // people/alice.friend
+		owner      string // people/alice/orders/o1.owner
+		references int
+	}{
+		{CopyRefsKeep, source + "users/bob", source + "users/alice", 0},
+		{CopyRefsRetarget, staging + "users/bob", staging + "users/alice", 2},
+		{CopyRefsRemap, staging + "users/bob", staging + "people/alice", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			plan, err := newCopyClient(t).PlanCopy(context.Background(), CopyOptions{
+				Roots:          []string{"users/alice"},
+				DestProject:    "staging",
+				DestCollection: "people",
+				References:     tt.mode,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := copyRef(t, plan, "people/alice", "friend"); got != tt.friend {
+				t.Errorf("friend = %q, expected %q", got, tt.friend)
+			}
+			if got := copyRef(t, plan, "people/alice/orders/o1", "owner"); got != tt.owner {
+				t.Errorf("owner = %q, expected %q", got, tt.owner)
+			}
+			if plan.References != tt.references {
+				t.Errorf("References = %d, expected %d", plan.References, tt.references)
+			}
+		})
+	}
+}
+
+func TestPlanCopyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    CopyOptions
+		errPart string
+	}{
+		{"no roots", CopyOptions{}, "nothing to copy"},
+		{"same destination", CopyOptions{Roots: []string{"users"}}, "same"},
+		{"roots in different collections", CopyOptions{Roots: []string{"users/bob", "users/alice/orders/o1"}, DestProject: "x"}, "different collections"},
+		{"destination is a document", CopyOptions{Roots: []string{"users"}, DestCollection: "a/b"}, "not a collection"},
+		{"unknown reference mode", CopyOptions{Roots: []string{"users"}, DestProject: "x", References: "follow"}, "reference mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newCopyClient(t).PlanCopy(context.Background(), tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.errPart) {
+				t.Errorf("expected error containing %q, got %v", tt.errPart, err)
+			}
+		})
+	}
+}
+
+func TestRewriteReferenceValue(t *testing.T) {
+	rewrite := func(ref string) (string, bool) { return "new/" + ref, true }
+	value := map[string]interface{}{"mapValue": map[string]interface{}{"fields": map[string]interface{}{
+		"ref":  map[string]interface{}{"referenceValue": "a"},
+		"list": map[string]interface{}{"arrayValue": map[string]interface{}{"values": []interface{}{map[string]interface{}{"referenceValue": "b"}}}},
+		"name": map[string]interface{}{"stringValue": "x"},
+	}}}
+	expected := map[string]interface{}{"mapValue": map[string]interface{}{"fields": map[string]interface{}{
+		"ref":  map[string]interface{}{"referenceValue": "new/a"},
+		"list": map[string]interface{}{"arrayValue": map[string]interface{}{"values": []interface{}{map[string]interface{}{"referenceValue": "new/b"}}}},
+		"name": map[string]interface{}{"stringValue": "x"},
+	}}}
+
+	count := 0
+	result := rewriteReferenceValue(value, rewrite, &count)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("rewriteReferenceValue() = %#v, expected %#v", result, expected)
+	}
+	if count != 2 {
+		t.Errorf("count = %d, expected 2", count)
+	}
+	// The source value must be left untouched
+	if ref := value["mapValue"].(map[string]interface{})["fields"].(map[string]interface{})["ref"]; !reflect.DeepEqual(ref, map[string]interface{}{"referenceValue": "a"}) {
+		t.Errorf("source value was modified: %#v", ref)
+	}
+}
@@ -0,0 +1,603 @@
+package firebase
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Protobuf wire types.
+const (
+	wireVarint     = 0
+	wireFixed64    = 1
+	wireBytes      = 2
+	wireStartGroup = 3
+	wireEndGroup   = 4
+	wireFixed32    = 5
+)
+
+// EntityProto field numbers (storage_onestore_v3 entity.proto), the format
+// written by `gcloud firestore export`.
+const (
+	entityKeyField         = 13
+	entityPropertyField    = 14
+	entityRawPropertyField = 15
+
+	referenceAppField       = 13
+	referencePathField      = 14
+	referenceNamespaceField = 20
+	referenceDatabaseField  = 23
+
+	pathElementGroup     = 1
+	pathElementTypeField = 2
+	pathElementIDField   = 3
+	pathElementNameField = 4
+
+	propertyMeaningField  = 1
+	propertyNameField     = 3
+	propertyMultipleField = 4
+	propertyValueField    = 5
+
+	valueInt64Field     = 1
+	valueBooleanField   = 2
+	valueStringField    = 3
+	valueDoubleField    = 4
+	valuePointGroup     = 5
+	valuePointXField    = 6
+	valuePointYField    = 7
+	valueReferenceGroup = 12
+
+	refValueAppField       = 13
+	refValueElementGroup   = 14
+	refValueTypeField      = 15
+	refValueIDField        = 16
+	refValueNameField      = 17
+	refValueNamespaceField = 20
+	refValueDatabaseField  = 23
+)
+
+// Property meanings that change how a PropertyValue is interpreted.
+const (
+	meaningGDWhen      = 7  // int64Value holds microseconds since epoch
+	meaningBlob        = 14 // stringValue holds raw bytes
+	meaningByteString  = 16 // stringValue holds raw bytes (indexed)
+	meaningEntityProto = 19 // stringValue holds an embedded EntityProto (Firestore map)
+	meaningEmptyList   = 24 // Property is an empty array
+)
+
+// keyElement is one kind/name pair of a Datastore entity key.
+// In Firestore exports the kind is the collection ID and the name (or numeric ID)
+// is the document ID.
+type keyElement struct {
+	Kind string
+	Name string
+	ID   int64
+}
+
+// entityKey is a decoded Datastore key.
+type entityKey struct {
+	App       string
+	Namespace string
+	Database  string
+	Path      []keyElement
+}
+
+// DocumentPath converts the key path to a Firestore document path,
+// e.g. [(users, abc), (orders, o1)] -> "users/abc/orders/o1".
+func (k entityKey) DocumentPath() string {
+	segments := make([]string, 0, len(k.Path)*2)
+	for _, el := range k.Path {
+		segments = append(segments, el.Kind, el.docID())
+	}
+	return strings.Join(segments, "/")
+}
+
+// ProjectID returns the project the key belongs to.
+// Datastore app IDs carry a partition prefix such as "s~" which is stripped.
+func (k entityKey) ProjectID() string {
+	if i := strings.Index(k.App, "~"); i >= 0 {
+		return k.App[i+1:]
+	}
+	return k.App
+}
+
+// docID returns the element's name, falling back to its numeric ID.
+func (el keyElement) docID() string {
+	if el.Name != "" {
+		return el.Name
+	}
+	return strconv.FormatInt(el.ID, 10)
+}
+
+// exportEntity is a decoded entity: its key and its fields in Firestore's typed format.
+type exportEntity struct {
+	Key    entityKey
+	Fields map[string]interface{}
+}
+
+// protoReader walks a protobuf-encoded message.
+type protoReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *protoReader) done() bool {
+	return r.pos >= len(r.buf)
+}
+
+func (r *protoReader) varint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid varint at offset %d", r.pos)
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *protoReader) fixed64() (uint64, error) {
+	if r.pos+8 > len(r.buf) {
+		return 0, fmt.Errorf("truncated fixed64 at offset %d", r.pos)
+	}
+	v := binary.LittleEndian.Uint64(r.buf[r.pos:])
+	r.pos += 8
+	return v, nil
+}
+
+func (r *protoReader) bytes() ([]byte, error) {
+	n, err := r.varint()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(r.buf)-r.pos) < n {
+		return nil, fmt.Errorf("truncated bytes field at offset %d", r.pos)
+	}
+	b := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+// tag reads the next field number and wire type.
+func (r *protoReader) tag() (int, int, error) {
+	v, err := r.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(v >> 3), int(v & 7), nil
+}
+
+// skip discards a field of the given wire type, including nested groups.
+func (r *protoReader) skip(field, wire int) error {
+	var err error
+	switch wire {
+	case wireVarint:
+		_, err = r.varint()
+	case wireFixed64:
+		_, err = r.fixed64()
+	case wireBytes:
+		_, err = r.bytes()
+	case wireFixed32:
+		if r.pos+4 > len(r.buf) {
+			return fmt.Errorf("truncated fixed32 at offset %d", r.pos)
+		}
+		r.pos += 4
+	case wireStartGroup:
+		for {
+			f, w, err := r.tag()
+			if err != nil {
+				return err
+			}
+			if w == wireEndGroup {
+				if f != field {
+					return fmt.Errorf("mismatched end group %d (want %d)", f, field)
+				}
+				return nil
+			}
+			if err := r.skip(f, w); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported wire type %d", wire)
+	}
+	return err
+}
+
+// decodeEntity decodes a serialized EntityProto from an export file.
+func decodeEntity(data []byte) (*exportEntity, error) {
+	entity := &exportEntity{Fields: make(map[string]interface{})}
+	r := &protoReader{buf: data}
+
+	for !r.done() {
+		field, wire, err := r.tag()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == entityKeyField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			if entity.Key, err = decodeReference(b); err != nil {
+				return nil, fmt.Errorf("key: %v", err)
+			}
+		case (field == entityPropertyField || field == entityRawPropertyField) && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			if err := decodeProperty(b, entity.Fields); err != nil {
+				return nil, err
+			}
+		default:
+			if err := r.skip(field, wire); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return entity, nil
+}
+
+// decodeReference decodes a Reference message (an entity key).
+func decodeReference(data []byte) (entityKey, error) {
+	var key entityKey
+	r := &protoReader{buf: data}
+
+	for !r.done() {
+		field, wire, err := r.tag()
+		if err != nil {
+			return key, err
+		}
+		switch {
+		case field == referenceAppField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return key, err
+			}
+			key.App = string(b)
+		case field == referenceNamespaceField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return key, err
+			}
+			key.Namespace = string(b)
+		case field == referenceDatabaseField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return key, err
+			}
+			key.Database = string(b)
+		case field == referencePathField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return key, err
+			}
+			if key.Path, err = decodePath(b); err != nil {
+				return key, err
+			}
+		default:
+			if err := r.skip(field, wire); err != nil {
+				return key, err
+			}
+		}
+	}
+
+	return key, nil
+}
+
+// decodePath decodes a Path message: a repeated group of key elements.
+func decodePath(data []byte) ([]keyElement, error) {
+	var path []keyElement
+	r := &protoReader{buf: data}
+
+	for !r.done() {
+		field, wire, err := r.tag()
+		if err != nil {
+			return nil, err
+		}
+		if field != pathElementGroup || wire != wireStartGroup {
+			if err := r.skip(field, wire); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		el, err := decodeKeyElementGroup(r, pathElementGroup, pathElementTypeField, pathElementIDField, pathElementNameField)
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, el)
+	}
+
+	return path, nil
+}
+
+// decodeKeyElementGroup reads a key element group until its end tag.
+// Keys and reference values use the same layout with different field numbers.
+func decodeKeyElementGroup(r *protoReader, group, typeField, idField, nameField int) (keyElement, error) {
+	var el keyElement
+	for {
+		field, wire, err := r.tag()
+		if err != nil {
+			return el, err
+		}
+		if wire == wireEndGroup && field == group {
+			return el, nil
+		}
+		switch {
+		case field == typeField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return el, err
+			}
+			el.Kind = string(b)
+		case field == nameField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return el, err
+			}
+			el.Name = string(b)
+		case field == idField && wire == wireVarint:
+			v, err := r.varint()
+			if err != nil {
+				return el, err
+			}
+			el.ID = int64(v)
+		default:
+			if err := r.skip(field, wire); err != nil {
+				return el, err
+			}
+		}
+	}
+}
+
+// decodeProperty decodes a Property message and stores its value in fields.
+// Properties flagged as multiple are accumulated into an arrayValue.
+func decodeProperty(data []byte, fields map[string]interface{}) error {
+	var (
+		name     string
+		meaning  int
+		multiple bool
+		rawValue []byte
+		hasValue bool
+	)
+	r := &protoReader{buf: data}
+
+	for !r.done() {
+		field, wire, err := r.tag()
+		if err != nil {
+			return err
+		}
+		switch {
+		case field == propertyNameField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return err
+			}
+			name = string(b)
+		case field == propertyMeaningField && wire == wireVarint:
+			v, err := r.varint()
+			if err != nil {
+				return err
+			}
+			meaning = int(v)
+		case field == propertyMultipleField && wire == wireVarint:
+			v, err := r.varint()
+			if err != nil {
+				return err
+			}
+			multiple = v != 0
+		case field == propertyValueField && wire == wireBytes:
+			if rawValue, err = r.bytes(); err != nil {
+				return err
+			}
+			hasValue = true
+		default:
+			if err := r.skip(field, wire); err != nil {
+				return err
+			}
+		}
+	}
+
+	if name == "" {
+		return fmt.Errorf("property without name")
+	}
+
+	if meaning == meaningEmptyList {
+		fields[name] = map[string]interface{}{"arrayValue": map[string]interface{}{}}
+		return nil
+	}
+
+	value := map[string]interface{}{"nullValue": nil}
+	if hasValue {
+		var err error
+		if value, err = decodePropertyValue(rawValue, meaning); err != nil {
+			return fmt.Errorf("property %q: %v", name, err)
+		}
+	}
+
+	if !multiple {
+		fields[name] = value
+		return nil
+	}
+
+	// Repeated property: append to the array built so far
+	var values []interface{}
+	if existing, ok := fields[name].(map[string]interface{}); ok {
+		if arr, ok := existing["arrayValue"].(map[string]interface{}); ok {
+			values, _ = arr["values"].([]interface{})
+		}
+	}
+	fields[name] = map[string]interface{}{
+		"arrayValue": map[string]interface{}{"values": append(values, value)},
+	}
+	return nil
+}
+
+// decodePropertyValue converts a PropertyValue message to Firestore's typed value format,
+// the same shape the REST API returns (e.g. {"stringValue": "hello"}).
+func decodePropertyValue(data []byte, meaning int) (map[string]interface{}, error) {
+	r := &protoReader{buf: data}
+	var result map[string]interface{}
+
+	for !r.done() {
+		field, wire, err := r.tag()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == valueInt64Field && wire == wireVarint:
+			v, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			if meaning == meaningGDWhen {
+				ts := time.UnixMicro(int64(v)).UTC().Format(time.RFC3339Nano)
+				result = map[string]interface{}{"timestampValue": ts}
+			} else {
+				result = map[string]interface{}{"integerValue": strconv.FormatInt(int64(v), 10)}
+			}
+		case field == valueBooleanField && wire == wireVarint:
+			v, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			result = map[string]interface{}{"booleanValue": v != 0}
+		case field == valueStringField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			switch meaning {
+			case meaningEntityProto:
+				embedded, err := decodeEntity(b)
+				if err != nil {
+					return nil, fmt.Errorf("embedded entity: %v", err)
+				}
+				result = map[string]interface{}{
+					"mapValue": map[string]interface{}{"fields": embedded.Fields},
+				}
+			case meaningBlob, meaningByteString:
+				result = map[string]interface{}{"bytesValue": base64.StdEncoding.EncodeToString(b)}
+			default:
+				result = map[string]interface{}{"stringValue": string(b)}
+			}
+		case field == valueDoubleField && wire == wireFixed64:
+			v, err := r.fixed64()
+			if err != nil {
+				return nil, err
+			}
+			result = map[string]interface{}{"doubleValue": math.Float64frombits(v)}
+		case field == valuePointGroup && wire == wireStartGroup:
+			lat, lng, err := decodePointGroup(r)
+			if err != nil {
+				return nil, err
+			}
+			result = map[string]interface{}{
+				"geoPointValue": map[string]interface{}{"latitude": lat, "longitude": lng},
+			}
+		case field == valueReferenceGroup && wire == wireStartGroup:
+			name, err := decodeReferenceValueGroup(r)
+			if err != nil {
+				return nil, err
+			}
+			result = map[string]interface{}{"referenceValue": name}
+		default:
+			if err := r.skip(field, wire); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if result == nil {
+		return map[string]interface{}{"nullValue": nil}, nil
+	}
+	return result, nil
+}
+
+// decodePointGroup reads a PointValue group (x = latitude, y = longitude).
+func decodePointGroup(r *protoReader) (float64, float64, error) {
+	var x, y float64
+	for {
+		field, wire, err := r.tag()
+		if err != nil {
+			return 0, 0, err
+		}
+		if wire == wireEndGroup && field == valuePointGroup {
+			return x, y, nil
+		}
+		switch {
+		case field == valuePointXField && wire == wireFixed64:
+			v, err := r.fixed64()
+			if err != nil {
+				return 0, 0, err
+			}
+			x = math.Float64frombits(v)
+		case field == valuePointYField && wire == wireFixed64:
+			v, err := r.fixed64()
+			if err != nil {
+				return 0, 0, err
+			}
+			y = math.Float64frombits(v)
+		default:
+			if err := r.skip(field, wire); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+}
+
+// decodeReferenceValueGroup reads a ReferenceValue group and returns the
+// referenced document's full resource name.
+func decodeReferenceValueGroup(r *protoReader) (string, error) {
+	var key entityKey
+	for {
+		field, wire, err := r.tag()
+		if err != nil {
+			return "", err
+		}
+		if wire == wireEndGroup && field == valueReferenceGroup {
+			break
+		}
+		switch {
+		case field == refValueAppField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return "", err
+			}
+			key.App = string(b)
+		case field == refValueDatabaseField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return "", err
+			}
+			key.Database = string(b)
+		case field == refValueNamespaceField && wire == wireBytes:
+			b, err := r.bytes()
+			if err != nil {
+				return "", err
+			}
+			key.Namespace = string(b)
+		case field == refValueElementGroup && wire == wireStartGroup:
+			el, err := decodeKeyElementGroup(r, refValueElementGroup, refValueTypeField, refValueIDField, refValueNameField)
+			if err != nil {
+				return "", err
+			}
+			key.Path = append(key.Path, el)
+		default:
+			if err := r.skip(field, wire); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	database := key.Database
+	if database == "" {
+		database = "(default)"
+	}
+	return fmt.Sprintf("projects/%s/databases/%s/documents/%s", key.ProjectID(), database, key.DocumentPath()), nil
+}
@@ -19,9 +19,12 @@ type Collection struct {
 
 // Document represents a Firestore document.
 type Document struct {
-	ID   string                 // Document ID
-	Path string                 // Full path from root
-	Data map[string]interface{} // Document fields as a map
+	ID         string                 // Document ID
+	Path       string                 // Full path from root
+	Data       map[string]interface{} // Document fields as a map
+	Fields     map[string]interface{} // Fields in Firestore's typed format, as returned by the API
+	CreateTime string                 // RFC 3339 creation time, empty when read from a backup
+	UpdateTime string                 // RFC 3339 time of the last update, empty when read from a backup
 }
 
 // QueryFilter represents a where clause in a Firestore query.
@@ -29,15 +32,16 @@ type QueryFilter struct {
 	Field     string
 	Operator  string // EQUAL, NOT_EQUAL, LESS_THAN, LESS_THAN_OR_EQUAL, GREATER_THAN, GREATER_THAN_OR_EQUAL, ARRAY_CONTAINS, IN
 	Value     interface{}
-	ValueType string // string, integer, double, boolean, null (empty = auto-detect)
+	ValueType string // string, integer, double, boolean, null, array, timestamp, reference, geopoint, json (empty = auto-detect)
 }
 
 // QueryOptions contains all options for a Firestore query.
 type QueryOptions struct {
-	Filters  []QueryFilter
-	OrderBy  string
-	OrderDir string // ASCENDING or DESCENDING
-	Limit    int
+	Filters    []QueryFilter
+	OrderBy    string
+	OrderDir   string // ASCENDING or DESCENDING
+	Limit      int
+	StartAfter string // Document path to start after (requires OrderBy "__name__")
 }
 
 // getFirebaseToken retrieves the OAuth access token from Firebase CLI config.
@@ -114,12 +118,17 @@ func (c *Client) refreshAccessToken(refreshToken string) (string, error) {
 
 // firestoreRequest makes an authenticated request to the Firestore REST API.
 func (c *Client) firestoreRequest(method, path string) ([]byte, error) {
+	return c.firestoreRequestTo(c.currentProject, method, path)
+}
+
+// firestoreRequestTo makes an authenticated request below the documents root of any project.
+func (c *Client) firestoreRequestTo(project, method, path string) ([]byte, error) {
 	token, err := c.getFirebaseToken()
 	if err != nil {
 		return nil, err
 	}
 
-	url := fmt.Sprintf("https://firestore.googleapis.com/v1/projects/%s/databases/(default)/documents%s", c.currentProject, path)
+	url := fmt.Sprintf("https://firestore.googleapis.com/v1/projects/%s/databases/(default)/documents%s", project, path)
 
 	req, err := http.NewRequest(method, url, nil)
 	if err != nil {
@@ -146,12 +155,67 @@ func (c *Client) firestoreRequest(method, path string) ([]byte, error) {
 	return body, nil
 }
 
+// firestorePost makes an authenticated POST with a JSON body to the Firestore REST API.
+// path is appended to the documents root, e.g. ":batchWrite".
+func (c *Client) firestorePost(path string, payload interface{}) ([]byte, error) {
+	return c.firestorePostTo(c.currentProject, DefaultDatabase, path, payload)
+}
+
+// firestorePostTo sends a POST request below the documents root of any project and database.
+func (c *Client) firestorePostTo(project, database, path string, payload interface{}) ([]byte, error) {
+	token, err := c.getFirebaseToken()
+	if err != nil {
+		return nil, err
+	}
+
+	reqData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("https://firestore.googleapis.com/v1/projects/%s/databases/%s/documents%s", project, database, path)
+
+	req, err := http.NewRequest("POST", url, strings.NewReader(string(reqData)))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
+
+// documentName returns the full resource name of a document in the current project.
+func (c *Client) documentName(docPath string) string {
+	return documentNameIn(c.currentProject, DefaultDatabase, docPath)
+}
+
 // ListCollections returns all root-level collections in the current project.
 func (c *Client) ListCollections() ([]Collection, error) {
 	if c.currentProject == "" {
 		return nil, fmt.Errorf("no project selected")
 	}
 
+	if c.backup != nil {
+		return c.backup.ListCollections(""), nil
+	}
+
 	token, err := c.getFirebaseToken()
 	if err != nil {
 		return nil, err
@@ -226,6 +290,10 @@ func (c *Client) ListDocuments(collectionPath string, limit int) ([]Document, er
 		limit = 50
 	}
 
+	if c.backup != nil {
+		return c.backup.ListDocuments(collectionPath, limit), nil
+	}
+
 	body, err := c.firestoreRequest("GET", fmt.Sprintf("/%s?pageSize=%d", collectionPath, limit))
 	if err != nil {
 		return nil, err
@@ -233,8 +301,10 @@ func (c *Client) ListDocuments(collectionPath string, limit int) ([]Document, er
 
 	var result struct {
 		Documents []struct {
-			Name   string                 `json:"name"`
-			Fields map[string]interface{} `json:"fields"`
+			Name       string                 `json:"name"`
+			Fields     map[string]interface{} `json:"fields"`
+			CreateTime string                 `json:"createTime"`
+			UpdateTime string                 `json:"updateTime"`
 		} `json:"documents"`
 	}
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -248,9 +318,12 @@ func (c *Client) ListDocuments(collectionPath string, limit int) ([]Document, er
 		docID := parts[len(parts)-1]
 
 		documents = append(documents, Document{
-			ID:   docID,
-			Path: strings.Join(parts[5:], "/"), // Path after "documents/"
-			Data: parseFirestoreFields(doc.Fields),
+			ID:         docID,
+			Path:       strings.Join(parts[5:], "/"), // Path after "documents/"
+			Data:       parseFirestoreFields(doc.Fields),
+			Fields:     doc.Fields,
+			CreateTime: doc.CreateTime,
+			UpdateTime: doc.UpdateTime,
 		})
 	}
 
@@ -263,14 +336,34 @@ func (c *Client) GetDocument(docPath string) (*Document, error) {
 		return nil, fmt.Errorf("no project selected")
 	}
 
-	body, err := c.firestoreRequest("GET", "/"+docPath)
+	if c.backup != nil {
+		return c.backup.GetDocument(docPath)
+	}
+
+	return c.fetchDocument(c.currentProject, docPath)
+}
+
+// GetDocumentFrom retrieves a document from any project, e.g. to compare it
+// with the same path in the current one.
+func (c *Client) GetDocumentFrom(project, docPath string) (*Document, error) {
+	if project == c.currentProject {
+		return c.GetDocument(docPath)
+	}
+	return c.fetchDocument(project, docPath)
+}
+
+// fetchDocument reads a single document through the REST API.
+func (c *Client) fetchDocument(project, docPath string) (*Document, error) {
+	body, err := c.firestoreRequestTo(project, "GET", "/"+docPath)
 	if err != nil {
 		return nil, err
 	}
 
 	var result struct {
-		Name   string                 `json:"name"`
-		Fields map[string]interface{} `json:"fields"`
+		Name       string                 `json:"name"`
+		Fields     map[string]interface{} `json:"fields"`
+		CreateTime string                 `json:"createTime"`
+		UpdateTime string                 `json:"updateTime"`
 	}
 	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, err
@@ -280,9 +373,12 @@ func (c *Client) GetDocument(docPath string) (*Document, error) {
 	docID := parts[len(parts)-1]
 
 	return &Document{
-		ID:   docID,
-		Path: docPath,
-		Data: parseFirestoreFields(result.Fields),
+		ID:         docID,
+		Path:       docPath,
+		Data:       parseFirestoreFields(result.Fields),
+		Fields:     result.Fields,
+		CreateTime: result.CreateTime,
+		UpdateTime: result.UpdateTime,
 	}, nil
 }
 
@@ -292,6 +388,10 @@ func (c *Client) ListSubcollections(docPath string) ([]Collection, error) {
 		return nil, fmt.Errorf("no project selected")
 	}
 
+	if c.backup != nil {
+		return c.backup.ListCollections(docPath), nil
+	}
+
 	token, err := c.getFirebaseToken()
 	if err != nil {
 		return nil, err
@@ -409,6 +509,10 @@ func (c *Client) RunQuery(collectionPath string, opts QueryOptions) ([]Document,
 		return nil, fmt.Errorf("no project selected")
 	}
 
+	if c.backup != nil {
+		return nil, fmt.Errorf("queries are not supported when browsing an export")
+	}
+
 	token, err := c.getFirebaseToken()
 	if err != nil {
 		return nil, err
@@ -416,6 +520,15 @@ func (c *Client) RunQuery(collectionPath string, opts QueryOptions) ([]Document,
 
 	// Build the structured query
 	query := buildStructuredQuery(collectionPath, opts)
+	resolveQueryReferences(query, strings.TrimSuffix(c.documentName(""), "/"))
+	if opts.StartAfter != "" {
+		query["startAt"] = map[string]interface{}{
+			"values": []map[string]interface{}{
+				{"referenceValue": c.documentName(opts.StartAfter)},
+			},
+			"before": false,
+		}
+	}
 
 	reqData, err := json.Marshal(map[string]interface{}{
 		"structuredQuery": query,
@@ -424,7 +537,8 @@ func (c *Client) RunQuery(collectionPath string, opts QueryOptions) ([]Document,
 		return nil, err
 	}
 
-	url := fmt.Sprintf("https://firestore.googleapis.com/v1/projects/%s/databases/(default)/documents:runQuery", c.currentProject)
+	// Subcollection queries must run under their parent document
+	url := fmt.Sprintf("https://firestore.googleapis.com/v1/projects/%s/databases/(default)/documents%s:runQuery", c.currentProject, queryParent(collectionPath))
 
 	req, err := http.NewRequest("POST", url, strings.NewReader(string(reqData)))
 	if err != nil {
@@ -452,8 +566,10 @@ func (c *Client) RunQuery(collectionPath string, opts QueryOptions) ([]Document,
 	// Parse query results (array of objects with "document" field)
 	var results []struct {
 		Document struct {
-			Name   string                 `json:"name"`
-			Fields map[string]interface{} `json:"fields"`
+			Name       string                 `json:"name"`
+			Fields     map[string]interface{} `json:"fields"`
+			CreateTime string                 `json:"createTime"`
+			UpdateTime string                 `json:"updateTime"`
 		} `json:"document"`
 		ReadTime string `json:"readTime"`
 	}
@@ -471,15 +587,84 @@ func (c *Client) RunQuery(collectionPath string, opts QueryOptions) ([]Document,
 		docID := parts[len(parts)-1]
 
 		documents = append(documents, Document{
-			ID:   docID,
-			Path: strings.Join(parts[5:], "/"),
-			Data: parseFirestoreFields(result.Document.Fields),
+			ID:         docID,
+			Path:       strings.Join(parts[5:], "/"),
+			Data:       parseFirestoreFields(result.Document.Fields),
+			Fields:     result.Document.Fields,
+			CreateTime: result.Document.CreateTime,
+			UpdateTime: result.Document.UpdateTime,
 		})
 	}
 
 	return documents, nil
 }
 
+// queryParent returns the URL path segment of the document a collection belongs to,
+// e.g. "users/abc/orders" -> "/users/abc". Root collections return "".
+func queryParent(collectionPath string) string {
+	i := strings.LastIndex(collectionPath, "/")
+	if i < 0 {
+		return ""
+	}
+	return "/" + collectionPath[:i]
+}
+
+// ListDocumentsAfter returns up to limit documents of a collection ordered by
+// document name, starting after the given document path ("" to start at the beginning).
+// Used to page through large collections with a resumable cursor.
+func (c *Client) ListDocumentsAfter(collectionPath, startAfter string, limit int) ([]Document, error) {
+	if c.currentProject == "" {
+		return nil, fmt.Errorf("no project selected")
+	}
+
+	if c.backup != nil {
+		return c.backup.ListDocumentsAfter(collectionPath, startAfter, limit), nil
+	}
+
+	return c.RunQuery(collectionPath, QueryOptions{
+		OrderBy:    "__name__",
+		Limit:      limit,
+		StartAfter: startAfter,
+	})
+}
+
+// idPrefixEnd bounds a document ID prefix range: it sorts after the
+// characters used in document IDs.
+const idPrefixEnd = "\uf8ff"
+
+// IDPrefixQuery returns the query options matching the documents of a
+// collection whose ID starts with prefix, in ID order: a range on __name__
+// from the prefix to the prefix followed by idPrefixEnd.
+func IDPrefixQuery(collectionPath, prefix string, limit int) QueryOptions {
+	start := collectionPath + "/" + prefix
+	return QueryOptions{
+		Filters: []QueryFilter{
+			{Field: "__name__", Operator: ">=", Value: start, ValueType: "reference"},
+			{Field: "__name__", Operator: "<", Value: start + idPrefixEnd, ValueType: "reference"},
+		},
+		OrderBy: "__name__",
+		Limit:   limit,
+	}
+}
+
+// SearchDocumentIDs returns up to limit documents of a collection whose ID
+// starts with prefix, in ID order. Unlike filtering loaded documents, the
+// search runs on the server and finds documents in collections of any size.
+func (c *Client) SearchDocumentIDs(collectionPath, prefix string, limit int) ([]Document, error) {
+	if c.currentProject == "" {
+		return nil, fmt.Errorf("no project selected")
+	}
+	if prefix == "" {
+		return nil, fmt.Errorf("empty document ID prefix")
+	}
+
+	if c.backup != nil {
+		return c.backup.SearchDocumentIDs(collectionPath, prefix, limit), nil
+	}
+
+	return c.RunQuery(collectionPath, IDPrefixQuery(collectionPath, prefix, limit))
+}
+
 // buildStructuredQuery constructs a Firestore structured query from QueryOptions.
 func buildStructuredQuery(collectionPath string, opts QueryOptions) map[string]interface{} {
 	// Extract collection ID from path (last segment)
@@ -592,7 +777,13 @@ func toFirestoreValue(v interface{}, valueType string) map[string]interface{} {
 		case "null":
 			return map[string]interface{}{"nullValue": nil}
 		case "array":
-			return parseArrayValue(strVal)
+			return parseArrayValue(v)
+		case "timestamp", "reference", "geopoint", "json":
+			// Invalid values are sent as strings; ValidateQuery reports them first
+			if value, err := typedQueryValue(strVal, valueType, time.Now()); err == nil {
+				return value
+			}
+			return map[string]interface{}{"stringValue": strVal}
 		}
 	}
 
@@ -627,21 +818,15 @@ func toFirestoreValue(v interface{}, valueType string) map[string]interface{} {
 	return map[string]interface{}{"stringValue": strVal}
 }
 
-// parseArrayValue parses a comma-separated string into a Firestore arrayValue.
-// Each element is auto-typed (integers, booleans, etc. are detected).
+// parseArrayValue converts the value of an array filter into a Firestore
+// arrayValue. A list from ParseQuery keeps the types of its items; text is a
+// list like ["a,b", 1] or comma-separated values, each auto-typed.
 // Example: "a,b,c" -> arrayValue with 3 stringValues
 // Example: "1,2,3" -> arrayValue with 3 integerValues
-func parseArrayValue(s string) map[string]interface{} {
-	parts := strings.Split(s, ",")
+func parseArrayValue(v interface{}) map[string]interface{} {
 	var values []map[string]interface{}
-
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
-		}
-		// Auto-detect type for each element
-		values = append(values, toFirestoreValue(part, "auto"))
+	for _, item := range queryListItems(v) {
+		values = append(values, queryItemValue(item))
 	}
 
 	return map[string]interface{}{
@@ -650,3 +835,66 @@ func parseArrayValue(s string) map[string]interface{} {
 		},
 	}
 }
+
+// queryListItems returns the items of an array filter value.
+func queryListItems(v interface{}) []interface{} {
+	if items, ok := v.([]interface{}); ok {
+		return items
+	}
+
+	s := strings.TrimSpace(fmt.Sprintf("%v", v))
+	if strings.HasPrefix(s, "[") {
+		if tokens, err := tokenizeQuery(s); err == nil {
+			p := &queryParser{tokens: tokens}
+			if value, _, err := p.parseValue(); err == nil && p.peek().kind == tokenEOF {
+				items, _ := value.([]interface{})
+				return items
+			}
+		}
+	}
+
+	var items []interface{}
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, AutoQueryValue(part))
+		}
+	}
+	return items
+}
+
+// AutoQueryValue converts text to the value it is auto-detected as: null,
+// a boolean, an int64, a float64 or else a string.
+func AutoQueryValue(text string) interface{} {
+	text = strings.TrimSpace(text)
+	lower := strings.ToLower(text)
+	switch {
+	case text == "" || text == "null":
+		return nil
+	case lower == "true" || lower == "false":
+		return lower == "true"
+	}
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f
+	}
+	return text
+}
+
+// queryItemValue converts an item of a list filter value to a Firestore value.
+func queryItemValue(item interface{}) map[string]interface{} {
+	switch v := item.(type) {
+	case nil:
+		return map[string]interface{}{"nullValue": nil}
+	case bool:
+		return map[string]interface{}{"booleanValue": v}
+	case int64:
+		return map[string]interface{}{"integerValue": strconv.FormatInt(v, 10)}
+	case float64:
+		return map[string]interface{}{"doubleValue": v}
+	case string:
+		return map[string]interface{}{"stringValue": v}
+	}
+	return toFirestoreValue(item, "auto")
+}
@@ -603,3 +603,26 @@ func TestExtractFirestoreValue(t *testing.T) {
 		})
 	}
 }
+
+func TestIDPrefixQuery(t *testing.T) {
+	opts := IDPrefixQuery("users/a/orders", "order_2025_10_", 100)
+	if problems := ValidateQuery(opts); len(problems) > 0 {
+		t.Fatalf("ValidateQuery() = %+v, expected no problems", problems)
+	}
+
+	query := buildStructuredQuery("users/a/orders", opts)
+	resolveQueryReferences(query, "projects/p/databases/(default)/documents")
+	where := query["where"].(map[string]interface{})["compositeFilter"].(map[string]interface{})
+	filters := where["filters"].([]map[string]interface{})
+	expected := []string{
+		"projects/p/databases/(default)/documents/users/a/orders/order_2025_10_",
+		"projects/p/databases/(default)/documents/users/a/orders/order_2025_10_\uf8ff",
+	}
+	for i, f := range filters {
+		field := f["fieldFilter"].(map[string]interface{})
+		got := field["value"].(map[string]interface{})["referenceValue"]
+		if got != expected[i] {
+			t.Errorf("filter %d value = %v, expected %v", i, got, expected[i])
+		}
+	}
+}
@@ -0,0 +1,675 @@
+package firebase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Import modes.
+const (
+	ImportSkipExisting = "skip-existing" // Create missing documents, leave existing ones untouched
+	ImportOverwrite    = "overwrite"     // Replace existing documents
+)
+
+const (
+	importBatchSize = 500              // Maximum writes per batchWrite request
+	importMaxLine   = 16 * 1024 * 1024 // Longest NDJSON line accepted
+	autoIDAlphabet  = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	autoIDLength    = 20
+)
+
+// Status codes returned per write by batchWrite (google.rpc.Code).
+const (
+	codeAlreadyExists      = 6
+	codeFailedPrecondition = 9
+)
+
+// ImportRecord is one document read from an import file.
+type ImportRecord struct {
+	ID   string                 // Document ID ("" to generate one)
+	Data map[string]interface{} // Fields as decoded JSON (numbers as json.Number)
+}
+
+// ImportOptions configures ImportDocuments.
+type ImportOptions struct {
+	Collection string                // Collection path to import into
+	Mode       string                // ImportSkipExisting or ImportOverwrite
+	DryRun     bool                  // Report what Mode would do, write nothing
+	Progress   func(done, total int) // Called after each batch (optional)
+}
+
+// ImportFailure describes a document that could not be imported.
+type ImportFailure struct {
+	ID    string
+	Error string
+}
+
+// ImportSummary reports the outcome of an import.
+// In a dry run, the paths are those that would be created, replaced or skipped.
+type ImportSummary struct {
+	Mode        string
+	DryRun      bool
+	Created     []string // Document paths that didn't exist
+	Overwritten []string // Existing document paths replaced (ImportOverwrite)
+	Skipped     []string // Existing document paths left alone (ImportSkipExisting)
+	Failed      []ImportFailure
+}
+
+// importWrite is an encoded document ready to be written.
+type importWrite struct {
+	Path   string
+	Fields map[string]interface{}
+}
+
+// ParseImportFile reads documents from a JSON array, a JSON object keyed by
+// document ID, or NDJSON (one object per line).
+// If idField is set, that field supplies the document ID and is removed from the data.
+// NDJSON lines written by ExportTree ({"path", "data"}) keep their document ID.
+func ParseImportFile(data []byte, idField string) ([]ImportRecord, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	switch trimmed[0] {
+	case '[':
+		var items []interface{}
+		if err := decodeJSON(trimmed, &items); err != nil {
+			return nil, err
+		}
+		records := make([]ImportRecord, 0, len(items))
+		for i, item := range items {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("element %d is not an object", i+1)
+			}
+			record, err := recordFromObject(obj, idField)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %v", i+1, err)
+			}
+			records = append(records, record)
+		}
+		return records, nil
+
+	case '{':
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		dec.UseNumber()
+		var obj map[string]interface{}
+		if err := dec.Decode(&obj); err == nil && !dec.More() && isKeyedObject(obj) {
+			return recordsFromKeyedObject(obj)
+		}
+		return parseNDJSON(trimmed, idField)
+
+	default:
+		return nil, fmt.Errorf("unrecognized format: expected a JSON array, an object keyed by ID, or NDJSON")
+	}
+}
+
+// decodeJSON decodes data keeping numbers as json.Number so integers stay integers.
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// isKeyedObject reports whether every value of obj is itself an object,
+// i.e. obj maps document IDs to documents.
+func isKeyedObject(obj map[string]interface{}) bool {
+	if len(obj) == 0 {
+		return false
+	}
+	for _, v := range obj {
+		if _, ok := v.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// recordsFromKeyedObject converts {"id": {...}, ...} into records ordered by ID.
+func recordsFromKeyedObject(obj map[string]interface{}) ([]ImportRecord, error) {
+	ids := make([]string, 0, len(obj))
+	for id := range obj {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	records := make([]ImportRecord, 0, len(ids))
+	for _, id := range ids {
+		if err := validateDocumentID(id); err != nil {
+			return nil, err
+		}
+		records = append(records, ImportRecord{ID: id, Data: obj[id].(map[string]interface{})})
+	}
+	return records, nil
+}
+
+// parseNDJSON reads one object per non-empty line.
+func parseNDJSON(data []byte, idField string) ([]ImportRecord, error) {
+	var records []ImportRecord
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), importMaxLine)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var obj map[string]interface{}
+		if err := decodeJSON(line, &obj); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+
+		// Lines written by ExportTree carry the document path
+		if path, data, ok := exportLineParts(obj); ok {
+			id := path[strings.LastIndex(path, "/")+1:]
+			records = append(records, ImportRecord{ID: id, Data: data})
+			continue
+		}
+
+		record, err := recordFromObject(obj, idField)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// exportLineParts recognizes a {"path": "...", "data": {...}} NDJSON line.
+func exportLineParts(obj map[string]interface{}) (string, map[string]interface{}, bool) {
+	if len(obj) != 2 {
+		return "", nil, false
+	}
+	path, ok := obj["path"].(string)
+	if !ok || path == "" {
+		return "", nil, false
+	}
+	data, ok := obj["data"].(map[string]interface{})
+	if !ok {
+		return "", nil, false
+	}
+	return path, data, true
+}
+
+// recordFromObject builds a record, taking the ID from idField when present.
+func recordFromObject(obj map[string]interface{}, idField string) (ImportRecord, error) {
+	record := ImportRecord{Data: obj}
+	if idField == "" {
+		return record, nil
+	}
+
+	raw, ok := obj[idField]
+	if !ok {
+		return record, nil
+	}
+	switch v := raw.(type) {
+	case string:
+		record.ID = v
+	case json.Number:
+		record.ID = v.String()
+	default:
+		return record, fmt.Errorf("%s must be a string or number", idField)
+	}
+	if err := validateDocumentID(record.ID); err != nil {
+		return record, err
+	}
+
+	data := make(map[string]interface{}, len(obj)-1)
+	for k, v := range obj {
+		if k != idField {
+			data[k] = v
+		}
+	}
+	record.Data = data
+	return record, nil
+}
+
+// validateDocumentID checks the Firestore constraints on document IDs.
+func validateDocumentID(id string) error {
+	switch {
+	case id == "":
+		return fmt.Errorf("empty document ID")
+	case id == "." || id == "..":
+		return fmt.Errorf("invalid document ID %q", id)
+	case strings.Contains(id, "/"):
+		return fmt.Errorf("document ID %q contains '/'", id)
+	case strings.HasPrefix(id, "__") && strings.HasSuffix(id, "__"):
+		return fmt.Errorf("document ID %q is reserved", id)
+	}
+	return nil
+}
+
+// newDocumentID generates a random 20-character ID like Firestore's auto IDs.
+func newDocumentID() string {
+	id := make([]byte, autoIDLength)
+	max := big.NewInt(int64(len(autoIDAlphabet)))
+	for i := range id {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		id[i] = autoIDAlphabet[n.Int64()]
+	}
+	return string(id)
+}
+
+// encodeImportFields converts decoded JSON fields to Firestore's typed format.
+func encodeImportFields(data map[string]interface{}, docsRoot string) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, len(data))
+	for name, v := range data {
+		value, err := encodeImportValue(v, docsRoot)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// encodeImportValue converts a decoded JSON value to Firestore's typed value format.
+// Unlike toFirestoreValue it keeps JSON types and also recognizes:
+//   - RFC 3339 strings -> timestampValue
+//   - full document names (projects/.../documents/...) -> referenceValue
+//   - {"latitude": n, "longitude": n} -> geoPointValue
+//   - wrappers {"$timestamp": s}, {"$ref": path}, {"$geo": [lat, lng]},
+//     {"$bytes": base64} and {"$string": s} (a string that must not be converted)
+//
+// Relative $ref paths are resolved against docsRoot ("projects/p/databases/d/documents").
+func encodeImportValue(v interface{}, docsRoot string) (map[string]interface{}, error) {
+	switch val := v.(type) {
+	case nil:
+		return map[string]interface{}{"nullValue": nil}, nil
+	case bool:
+		return map[string]interface{}{"booleanValue": val}, nil
+	case json.Number:
+		if i, err := strconv.ParseInt(val.String(), 10, 64); err == nil {
+			return map[string]interface{}{"integerValue": strconv.FormatInt(i, 10)}, nil
+		}
+		f, err := val.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", val)
+		}
+		return map[string]interface{}{"doubleValue": f}, nil
+	case float64:
+		return map[string]interface{}{"doubleValue": val}, nil
+	case string:
+		if ts, ok := parseImportTimestamp(val); ok {
+			return map[string]interface{}{"timestampValue": ts}, nil
+		}
+		if isDocumentName(val) {
+			return map[string]interface{}{"referenceValue": val}, nil
+		}
+		return map[string]interface{}{"stringValue": val}, nil
+	case []interface{}:
+		values := make([]interface{}, 0, len(val))
+		for i, item := range val {
+			value, err := encodeImportValue(item, docsRoot)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %v", i, err)
+			}
+			values = append(values, value)
+		}
+		return map[string]interface{}{"arrayValue": map[string]interface{}{"values": values}}, nil
+	case map[string]interface{}:
+		if value, ok, err := encodeWrapper(val, docsRoot); ok || err != nil {
+			return value, err
+		}
+		if lat, lng, ok := geoPointParts(val); ok {
+			return map[string]interface{}{
+				"geoPointValue": map[string]interface{}{"latitude": lat, "longitude": lng},
+			}, nil
+		}
+		fields, err := encodeImportFields(val, docsRoot)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"mapValue": map[string]interface{}{"fields": fields}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v", v)
+	}
+}
+
+// encodeWrapper handles single-key {"$type": value} objects.
+func encodeWrapper(obj map[string]interface{}, docsRoot string) (map[string]interface{}, bool, error) {
+	if len(obj) != 1 {
+		return nil, false, nil
+	}
+	for key, raw := range obj {
+		switch key {
+		case "$timestamp":
+			s, _ := raw.(string)
+			ts, ok := parseImportTimestamp(s)
+			if !ok {
+				return nil, true, fmt.Errorf("$timestamp must be an RFC 3339 string")
+			}
+			return map[string]interface{}{"timestampValue": ts}, true, nil
+		case "$ref":
+			path, _ := raw.(string)
+			path = strings.Trim(path, "/")
+			if isDocumentName(path) {
+				return map[string]interface{}{"referenceValue": path}, true, nil
+			}
+			// Relative document paths have an even number of segments
+			if path == "" || strings.Count(path, "/")%2 == 0 {
+				return nil, true, fmt.Errorf("$ref must be a document path")
+			}
+			return map[string]interface{}{"referenceValue": docsRoot + "/" + path}, true, nil
+		case "$geo":
+			pair, _ := raw.([]interface{})
+			if len(pair) != 2 {
+				return nil, true, fmt.Errorf("$geo must be [latitude, longitude]")
+			}
+			lat, ok1 := numberValue(pair[0])
+			lng, ok2 := numberValue(pair[1])
+			if !ok1 || !ok2 {
+				return nil, true, fmt.Errorf("$geo must be [latitude, longitude]")
+			}
+			return map[string]interface{}{
+				"geoPointValue": map[string]interface{}{"latitude": lat, "longitude": lng},
+			}, true, nil
+		case "$bytes":
+			s, _ := raw.(string)
+			if _, err := base64.StdEncoding.DecodeString(s); err != nil {
+				return nil, true, fmt.Errorf("$bytes must be base64")
+			}
+			return map[string]interface{}{"bytesValue": s}, true, nil
+		case "$string":
+			s, ok := raw.(string)
+			if !ok {
+				return nil, true, fmt.Errorf("$string must be a string")
+			}
+			return map[string]interface{}{"stringValue": s}, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// parseImportTimestamp returns the normalized timestamp if s is an RFC 3339 date-time.
+func parseImportTimestamp(s string) (string, bool) {
+	if len(s) < len("2006-01-02T15:04:05Z") || s[4] != '-' || s[10] != 'T' {
+		return "", false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return "", false
+	}
+	return t.UTC().Format(time.RFC3339Nano), true
+}
+
+// isDocumentName reports whether s is a full document resource name.
+func isDocumentName(s string) bool {
+	return strings.HasPrefix(s, "projects/") && strings.Contains(s, "/databases/") && strings.Contains(s, "/documents/")
+}
+
+// geoPointParts recognizes {"latitude": n, "longitude": n}, the shape geopoints are displayed in.
+func geoPointParts(obj map[string]interface{}) (float64, float64, bool) {
+	if len(obj) != 2 {
+		return 0, 0, false
+	}
+	lat, ok1 := numberValue(obj["latitude"])
+	lng, ok2 := numberValue(obj["longitude"])
+	return lat, lng, ok1 && ok2
+}
+
+// numberValue converts a decoded JSON number to float64.
+func numberValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// ImportDocuments writes records into a collection in batches of up to 500.
+// Records that fail to encode are reported as failures and never sent.
+func (c *Client) ImportDocuments(ctx context.Context, records []ImportRecord, opts ImportOptions) (*ImportSummary, error) {
+	if c.currentProject == "" {
+		return nil, fmt.Errorf("no project selected")
+	}
+	if c.backup != nil {
+		return nil, fmt.Errorf("importing is not supported when browsing an export")
+	}
+	switch opts.Mode {
+	case ImportSkipExisting, ImportOverwrite:
+	default:
+		return nil, fmt.Errorf("unknown import mode %q", opts.Mode)
+	}
+
+	collection := strings.Trim(opts.Collection, "/")
+	if collection == "" || strings.Count(collection, "/")%2 != 0 {
+		return nil, fmt.Errorf("%q is not a collection", opts.Collection)
+	}
+
+	summary := &ImportSummary{Mode: opts.Mode, DryRun: opts.DryRun}
+	docsRoot := fmt.Sprintf("projects/%s/databases/(default)/documents", c.currentProject)
+
+	// Encode everything first so a dry run reports the same failures as a real import.
+	// Records sharing an ID are all left out: one batch can't write a document twice.
+	duplicates := duplicateRecords(records)
+	var writes []importWrite
+	for i, record := range records {
+		id := record.ID
+		if id == "" {
+			id = newDocumentID()
+		}
+		if others, ok := duplicates[i]; ok {
+			summary.Failed = append(summary.Failed, ImportFailure{ID: id, Error: fmt.Sprintf("record %d: duplicate ID, also in %s", i+1, others)})
+			continue
+		}
+		fields, err := encodeImportFields(record.Data, docsRoot)
+		if err != nil {
+			summary.Failed = append(summary.Failed, ImportFailure{ID: id, Error: err.Error()})
+			continue
+		}
+		writes = append(writes, importWrite{Path: collection + "/" + id, Fields: fields})
+	}
+
+	for start := 0; start < len(writes); start += importBatchSize {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		batch := writes[start:min(start+importBatchSize, len(writes))]
+		var err error
+		if opts.DryRun {
+			err = c.classifyExisting(batch, opts.Mode, summary)
+		} else {
+			err = c.batchWrite(batch, opts.Mode, summary)
+		}
+		if err != nil {
+			return summary, err
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(start+len(batch), len(writes))
+		}
+	}
+
+	return summary, nil
+}
+
+// duplicateRecords finds records whose ID another record also has. It maps
+// their index to the other records, e.g. "records 2 and 5".
+func duplicateRecords(records []ImportRecord) map[int]string {
+	byID := make(map[string][]int)
+	for i, record := range records {
+		if record.ID != "" {
+			byID[record.ID] = append(byID[record.ID], i)
+		}
+	}
+
+	duplicates := make(map[int]string)
+	for _, indexes := range byID {
+		if len(indexes) < 2 {
+			continue
+		}
+		for _, i := range indexes {
+			var others []string
+			for _, j := range indexes {
+				if j != i {
+					others = append(others, strconv.Itoa(j+1))
+				}
+			}
+			if len(others) == 1 {
+				duplicates[i] = "record " + others[0]
+			} else {
+				duplicates[i] = "records " + joinAnd(others)
+			}
+		}
+	}
+	return duplicates
+}
+
+// joinAnd joins items as "1", "1 and 2" or "1, 2 and 3".
+func joinAnd(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+// batchWrite sends one batch. Each write carries an exists=false precondition,
+// so existing documents are rejected individually: they are skipped, or with
+// ImportOverwrite written again without it and counted as overwritten.
+func (c *Client) batchWrite(batch []importWrite, mode string, summary *ImportSummary) error {
+	existing, err := c.writeDocuments(batch, true, &summary.Created, summary)
+	if err != nil || len(existing) == 0 {
+		return err
+	}
+	if mode == ImportSkipExisting {
+		for _, w := range existing {
+			summary.Skipped = append(summary.Skipped, w.Path)
+		}
+		return nil
+	}
+	_, err = c.writeDocuments(existing, false, &summary.Overwritten, summary)
+	return err
+}
+
+// writeDocuments writes documents, adding those written to done and those
+// that failed to the summary. With onlyNew, documents that already exist are
+// returned instead.
+func (c *Client) writeDocuments(batch []importWrite, onlyNew bool, done *[]string, summary *ImportSummary) ([]importWrite, error) {
+	writes := make([]map[string]interface{}, 0, len(batch))
+	for _, w := range batch {
+		write := map[string]interface{}{
+			"update": map[string]interface{}{
+				"name":   c.documentName(w.Path),
+				"fields": w.Fields,
+			},
+		}
+		if onlyNew {
+			write["currentDocument"] = map[string]interface{}{"exists": false}
+		}
+		writes = append(writes, write)
+	}
+
+	statuses, err := c.postBatchWrite(c.currentProject, DefaultDatabase, writes)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []importWrite
+	for i, w := range batch {
+		code, message := statuses[i].Code, statuses[i].Message
+		switch {
+		case code == 0:
+			*done = append(*done, w.Path)
+		case onlyNew && (code == codeAlreadyExists || code == codeFailedPrecondition):
+			existing = append(existing, w)
+		default:
+			id := w.Path[strings.LastIndex(w.Path, "/")+1:]
+			summary.Failed = append(summary.Failed, ImportFailure{ID: id, Error: message})
+		}
+	}
+	return existing, nil
+}
+
+// writeStatus is the outcome of one write in a batchWrite request.
+type writeStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// postBatchWrite sends writes to the batchWrite endpoint of a project and database
+// and returns one status per write, in order.
+func (c *Client) postBatchWrite(project, database string, writes []map[string]interface{}) ([]writeStatus, error) {
+	body, err := c.firestorePostTo(project, database, ":batchWrite", map[string]interface{}{"writes": writes})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Status []writeStatus `json:"status"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse batchWrite response: %v", err)
+	}
+
+	// A missing status entry means the write succeeded (code 0 is omitted)
+	statuses := make([]writeStatus, len(writes))
+	copy(statuses, result.Status)
+	return statuses, nil
+}
+
+// classifyExisting checks which documents of a batch already exist (dry run)
+// and counts them as mode would.
+func (c *Client) classifyExisting(batch []importWrite, mode string, summary *ImportSummary) error {
+	names := make([]string, 0, len(batch))
+	for _, w := range batch {
+		names = append(names, c.documentName(w.Path))
+	}
+
+	body, err := c.firestorePost(":batchGet", map[string]interface{}{"documents": names})
+	if err != nil {
+		return err
+	}
+
+	var results []struct {
+		Found *struct {
+			Name string `json:"name"`
+		} `json:"found"`
+	}
+	if err := json.Unmarshal(body, &results); err != nil {
+		return fmt.Errorf("failed to parse batchGet response: %v", err)
+	}
+
+	existing := make(map[string]bool)
+	for _, r := range results {
+		if r.Found != nil {
+			existing[r.Found.Name] = true
+		}
+	}
+
+	for _, w := range batch {
+		switch {
+		case !existing[c.documentName(w.Path)]:
+			summary.Created = append(summary.Created, w.Path)
+		case mode == ImportOverwrite:
+			summary.Overwritten = append(summary.Overwritten, w.Path)
+		default:
+			summary.Skipped = append(summary.Skipped, w.Path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package firebase
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseImportFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		idField  string
+		expected []ImportRecord
+	}{
+		{
+			name:    "array with ID field",
+			input:   `[{"id": "a", "n": 1}, {"id": 2, "n": 2}]`,
+			idField: "id",
+			expected: []ImportRecord{
+				{ID: "a", Data: map[string]interface{}{"n": json.Number("1")}},
+				{ID: "2", Data: map[string]interface{}{"n": json.Number("2")}},
+			},
+		},
+		{
+			name:    "array without ID field generates IDs later",
+			input:   `[{"n": 1}]`,
+			idField: "id",
+			expected: []ImportRecord{
+				{Data: map[string]interface{}{"n": json.Number("1")}},
+			},
+		},
+		{
+			name:  "object keyed by ID",
+			input: `{"b": {"n": 2}, "a": {"n": 1}}`,
+			expected: []ImportRecord{
+				{ID: "a", Data: map[string]interface{}{"n": json.Number("1")}},
+				{ID: "b", Data: map[string]interface{}{"n": json.Number("2")}},
+			},
+		},
+		{
+			name:    "NDJSON",
+			input:   "{\"id\": \"a\", \"n\": 1}\n\n{\"id\": \"b\", \"n\": 2}\n",
+			idField: "id",
+			expected: []ImportRecord{
+				{ID: "a", Data: map[string]interface{}{"n": json.Number("1")}},
+				{ID: "b", Data: map[string]interface{}{"n": json.Number("2")}},
+			},
+		},
+		{
+			name:  "single NDJSON line with scalar fields",
+			input: `{"name": "x"}`,
+			expected: []ImportRecord{
+				{Data: map[string]interface{}{"name": "x"}},
+			},
+		},
+		{
+			name:  "NDJSON written by tree export",
+			input: `{"path": "users/alice/orders/o1", "data": {"n": 1}}`,
+			expected: []ImportRecord{
+				{ID: "o1", Data: map[string]interface{}{"n": json.Number("1")}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := ParseImportFile([]byte(tt.input), tt.idField)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(records, tt.expected) {
+				t.Errorf("ParseImportFile() = %#v, expected %#v", records, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseImportFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		idField string
+		errPart string
+	}{
+		{"empty file", "  \n", "", "empty"},
+		{"not JSON", "id,name\n1,x", "", "unrecognized format"},
+		{"array of scalars", `[1, 2]`, "", "element 1"},
+		{"bad NDJSON line", "{\"a\": 1}\n{oops}\n", "", "line 2"},
+		{"ID with slash", `[{"id": "a/b"}]`, "id", "contains '/'"},
+		{"ID of wrong type", `[{"id": true}]`, "id", "string or number"},
+		{"reserved keyed ID", `{"__x__": {"a": 1}}`, "", "reserved"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseImportFile([]byte(tt.input), tt.idField)
+			if err == nil || !strings.Contains(err.Error(), tt.errPart) {
+				t.Errorf("expected error containing %q, got %v", tt.errPart, err)
+			}
+		})
+	}
+}
+
+func TestEncodeImportValue(t *testing.T) {
+	const root = "projects/p/databases/(default)/documents"
+
+	tests := []struct {
+		name     string
+		input    string // JSON
+		expected map[string]interface{}
+	}{
+		{"null", `null`, map[string]interface{}{"nullValue": nil}},
+		{"boolean", `true`, map[string]interface{}{"booleanValue": true}},
+		{"integer", `42`, map[string]interface{}{"integerValue": "42"}},
+		{"large integer keeps precision", `9007199254740993`, map[string]interface{}{"integerValue": "9007199254740993"}},
+		{"double", `1.5`, map[string]interface{}{"doubleValue": 1.5}},
+		{"plain string", `"hello"`, map[string]interface{}{"stringValue": "hello"}},
+		{"numeric string stays string", `"42"`, map[string]interface{}{"stringValue": "42"}},
+		{"date-only string stays string", `"2024-01-02"`, map[string]interface{}{"stringValue": "2024-01-02"}},
+		{"RFC 3339 timestamp", `"2024-01-02T03:04:05+02:00"`, map[string]interface{}{"timestampValue": "2024-01-02T01:04:05Z"}},
+		{"document name", `"projects/p/databases/(default)/documents/users/a"`, map[string]interface{}{"referenceValue": "projects/p/databases/(default)/documents/users/a"}},
+		{"$timestamp", `{"$timestamp": "2024-01-02T03:04:05.5Z"}`, map[string]interface{}{"timestampValue": "2024-01-02T03:04:05.5Z"}},
+		{"relative $ref", `{"$ref": "users/a"}`, map[string]interface{}{"referenceValue": root + "/users/a"}},
+		{"$geo", `{"$geo": [41.3, 19.8]}`, map[string]interface{}{"geoPointValue": map[string]interface{}{"latitude": 41.3, "longitude": 19.8}}},
+		{"geopoint object", `{"latitude": 1, "longitude": 2}`, map[string]interface{}{"geoPointValue": map[string]interface{}{"latitude": 1.0, "longitude": 2.0}}},
+		{"$bytes", `{"$bytes": "AQI="}`, map[string]interface{}{"bytesValue": "AQI="}},
+		{"$string keeps timestamp-like text", `{"$string": "2024-01-02T03:04:05Z"}`, map[string]interface{}{"stringValue": "2024-01-02T03:04:05Z"}},
+		{
+			"map",
+			`{"a": 1}`,
+			map[string]interface{}{"mapValue": map[string]interface{}{"fields": map[string]interface{}{
+				"a": map[string]interface{}{"integerValue": "1"},
+			}}},
+		},
+		{
+			"array",
+			`["x", 1]`,
+			map[string]interface{}{"arrayValue": map[string]interface{}{"values": []interface{}{
+				map[string]interface{}{"stringValue": "x"},
+				map[string]interface{}{"integerValue": "1"},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			if err := decodeJSON([]byte(tt.input), &v); err != nil {
+				t.Fatal(err)
+			}
+			result, err := encodeImportValue(v, root)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("encodeImportValue(%s) = %#v, expected %#v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncodeImportValueErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"bad $timestamp", `{"$timestamp": "yesterday"}`},
+		{"$ref to collection", `{"$ref": "users"}`},
+		{"$geo with one coordinate", `{"$geo": [1]}`},
+		{"$bytes not base64", `{"$bytes": "%%%"}`},
+		{"nested error", `{"a": [{"$ref": ""}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			if err := decodeJSON([]byte(tt.input), &v); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := encodeImportValue(v, "projects/p/databases/(default)/documents"); err == nil {
+				t.Errorf("expected error for %s, got nil", tt.input)
+			}
+		})
+	}
+}
+
+func TestNewDocumentID(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := newDocumentID()
+		if len(id) != autoIDLength {
+			t.Fatalf("newDocumentID() length = %d, expected %d", len(id), autoIDLength)
+		}
+		if strings.Trim(id, autoIDAlphabet) != "" {
+			t.Fatalf("newDocumentID() = %q contains characters outside the alphabet", id)
+		}
+		if seen[id] {
+			t.Fatalf("newDocumentID() returned duplicate %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestDuplicateRecords(t *testing.T) {
+	records := []ImportRecord{{ID: "a"}, {ID: "b"}, {ID: "a"}, {ID: ""}, {ID: ""}, {ID: "c"}, {ID: "a"}}
+	expected := map[int]string{
+		0: "records 3 and 7",
+		2: "records 1 and 7",
+		6: "records 1 and 3",
+	}
+	if got := duplicateRecords(records); !reflect.DeepEqual(got, expected) {
+		t.Errorf("duplicateRecords() = %v, expected %v", got, expected)
+	}
+
+	if got := duplicateRecords([]ImportRecord{{ID: "x"}, {ID: "x"}}); got[1] != "record 1" {
+		t.Errorf("duplicateRecords()[1] = %q, expected record 1", got[1])
+	}
+}
@@ -0,0 +1,136 @@
+package firebase
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// LevelDB log format constants.
+// Managed exports write each entity as one logical record in this format:
+// https://github.com/google/leveldb/blob/main/doc/log_format.md
+const (
+	logBlockSize  = 32 * 1024 // Records never straddle a 32 KiB block boundary without fragmenting
+	logHeaderSize = 7         // checksum (4) + length (2) + type (1)
+
+	logRecordZero   = 0 // Preallocated/zeroed space, skip the rest of the block
+	logRecordFull   = 1
+	logRecordFirst  = 2
+	logRecordMiddle = 3
+	logRecordLast   = 4
+
+	logCRCMaskDelta = 0xa282ead8
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// logReader reads logical records from a LevelDB log file,
+// reassembling fragmented records and verifying checksums.
+type logReader struct {
+	r     io.Reader
+	block []byte // Current block contents
+	pos   int    // Read position within block
+	eof   bool
+}
+
+// newLogReader creates a reader over a LevelDB log stream.
+func newLogReader(r io.Reader) *logReader {
+	return &logReader{r: r}
+}
+
+// Next returns the next logical record, or io.EOF when the log is exhausted.
+func (lr *logReader) Next() ([]byte, error) {
+	var record []byte
+	inFragment := false
+
+	for {
+		// Not enough room left for a header: the rest of the block is a trailer
+		if len(lr.block)-lr.pos < logHeaderSize {
+			if err := lr.readBlock(); err != nil {
+				if err == io.EOF && inFragment {
+					return nil, fmt.Errorf("truncated record at end of log")
+				}
+				return nil, err
+			}
+			continue
+		}
+
+		header := lr.block[lr.pos : lr.pos+logHeaderSize]
+		checksum := binary.LittleEndian.Uint32(header[0:4])
+		length := int(binary.LittleEndian.Uint16(header[4:6]))
+		recordType := header[6]
+
+		if recordType == logRecordZero && length == 0 {
+			lr.pos = len(lr.block)
+			continue
+		}
+
+		start := lr.pos + logHeaderSize
+		if start+length > len(lr.block) {
+			return nil, fmt.Errorf("record length %d exceeds block", length)
+		}
+		payload := lr.block[start : start+length]
+		lr.pos = start + length
+
+		if unmaskCRC(checksum) != crc32.Update(crc32.Checksum([]byte{recordType}, crc32cTable), crc32cTable, payload) {
+			return nil, fmt.Errorf("checksum mismatch in log record")
+		}
+
+		switch recordType {
+		case logRecordFull:
+			if inFragment {
+				return nil, fmt.Errorf("unexpected full record inside fragment")
+			}
+			return append([]byte(nil), payload...), nil
+		case logRecordFirst:
+			if inFragment {
+				return nil, fmt.Errorf("unexpected first fragment inside fragment")
+			}
+			record = append([]byte(nil), payload...)
+			inFragment = true
+		case logRecordMiddle:
+			if !inFragment {
+				return nil, fmt.Errorf("unexpected middle fragment")
+			}
+			record = append(record, payload...)
+		case logRecordLast:
+			if !inFragment {
+				return nil, fmt.Errorf("unexpected last fragment")
+			}
+			return append(record, payload...), nil
+		default:
+			return nil, fmt.Errorf("unknown log record type %d", recordType)
+		}
+	}
+}
+
+// readBlock loads the next block from the underlying reader.
+// The final block of a file may be shorter than logBlockSize.
+func (lr *logReader) readBlock() error {
+	if lr.eof {
+		return io.EOF
+	}
+	if lr.block == nil {
+		lr.block = make([]byte, logBlockSize)
+	}
+	n, err := io.ReadFull(lr.r, lr.block[:logBlockSize])
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		lr.eof = true
+		if n == 0 {
+			return io.EOF
+		}
+	} else if err != nil {
+		return err
+	}
+	lr.block = lr.block[:n]
+	lr.pos = 0
+	return nil
+}
+
+// unmaskCRC reverses the rotation LevelDB applies to stored checksums.
+func unmaskCRC(masked uint32) uint32 {
+	rot := masked - logCRCMaskDelta
+	return rot>>17 | rot<<15
+}
@@ -0,0 +1,191 @@
+package firebase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parts of a query a QueryProblem points at.
+const (
+	QueryPartField    = "field"
+	QueryPartOperator = "operator"
+	QueryPartValue    = "value"
+	QueryPartOrderBy  = "orderBy"
+	QueryPartLimit    = "limit"
+)
+
+// maxDisjunctions is Firestore's limit on the values of in, not-in and
+// array-contains-any filters, and on the disjunctions they expand to.
+const maxDisjunctions = 30
+
+// QueryProblem is a query shape Firestore would reject.
+type QueryProblem struct {
+	Filter  int    // Index of the offending filter, -1 for order and limit problems
+	Part    string // One of the QueryPart constants
+	Message string
+}
+
+// listOperators take a list of values.
+var listOperators = map[string]bool{"IN": true, "NOT_IN": true, "ARRAY_CONTAINS_ANY": true}
+
+// inequalityOperators constrain the first order by of a query.
+var inequalityOperators = map[string]bool{
+	"LESS_THAN": true, "LESS_THAN_OR_EQUAL": true, "GREATER_THAN": true, "GREATER_THAN_OR_EQUAL": true,
+	"NOT_EQUAL": true, "NOT_IN": true,
+}
+
+// ValidateQuery checks query options against Firestore's documented query
+// limits without sending them. Values that contain parameters like $userId
+// are only checked once bound. Problems are in filter order, then order by
+// and limit.
+func ValidateQuery(opts QueryOptions) []QueryProblem {
+	var problems []QueryProblem
+	add := func(filter int, part, format string, args ...interface{}) {
+		problems = append(problems, QueryProblem{Filter: filter, Part: part, Message: fmt.Sprintf(format, args...)})
+	}
+
+	seen := make(map[string]int) // API operator -> index of the first filter using it
+	disjunctions := 1
+	inequalityField := ""
+	for i, f := range opts.Filters {
+		if msg := checkFieldPath(f.Field); msg != "" {
+			add(i, QueryPartField, "%s", msg)
+		}
+
+		if !isQueryOperator(f.Operator) && sdkOperators[f.Operator] == "" {
+			add(i, QueryPartOperator, "unknown operator %q", f.Operator)
+			continue
+		}
+		op := convertOperator(f.Operator)
+		name := sdkOperators[op]
+
+		// Combinations, reported on the later filter
+		if first, ok := seen[op]; ok && (op == "ARRAY_CONTAINS" || op == "ARRAY_CONTAINS_ANY" || op == "NOT_IN" || op == "NOT_EQUAL") {
+			add(i, QueryPartOperator, "only one %s filter is allowed per query (filter %d has one)", name, first+1)
+		}
+		for _, other := range conflictingOperators[op] {
+			if first, ok := seen[other]; ok {
+				add(i, QueryPartOperator, "%s can't be combined with %s (filter %d)", name, sdkOperators[other], first+1)
+			}
+		}
+		if _, ok := seen[op]; !ok {
+			seen[op] = i
+		}
+
+		if inequalityOperators[op] && inequalityField == "" {
+			inequalityField = f.Field
+		}
+
+		value := QueryValueText(f)
+		if QueryParamPattern.MatchString(value) {
+			continue
+		}
+		if msg := checkTypedValue(value, f.ValueType); msg != "" {
+			add(i, QueryPartValue, "%s", msg)
+			continue
+		}
+
+		fv := toFirestoreValue(f.Value, f.ValueType)
+		arr, isList := fv["arrayValue"].(map[string]interface{})
+		_, isNull := fv["nullValue"]
+		switch {
+		case listOperators[op] && !isList:
+			add(i, QueryPartValue, "%s needs a list of values: set the type to array", name)
+		case listOperators[op]:
+			n := arrayLength(arr)
+			switch {
+			case n == 0:
+				add(i, QueryPartValue, "%s needs at least one value", name)
+			case n > maxDisjunctions:
+				add(i, QueryPartValue, "%s allows at most %d values, found %d", name, maxDisjunctions, n)
+			case op != "NOT_IN":
+				disjunctions *= n
+				if disjunctions > maxDisjunctions {
+					add(i, QueryPartValue, "in and array-contains-any filters expand to %d combinations, at most %d are allowed", disjunctions, maxDisjunctions)
+				}
+			}
+		case isNull && op != "EQUAL" && op != "NOT_EQUAL":
+			add(i, QueryPartOperator, "null can only be compared with == or !=")
+		}
+
+		if f.Field == "__name__" && fv["referenceValue"] == nil && !listOperators[op] {
+			add(i, QueryPartValue, "__name__ filters compare document references")
+		}
+	}
+
+	if opts.OrderBy != "" {
+		if msg := checkFieldPath(opts.OrderBy); msg != "" {
+			add(-1, QueryPartOrderBy, "%s", msg)
+		} else if inequalityField != "" && opts.OrderBy != inequalityField {
+			add(-1, QueryPartOrderBy, "the first order by must be on %q, the field of the inequality filter", inequalityField)
+		}
+	}
+	if opts.Limit < 0 {
+		add(-1, QueryPartLimit, "limit can't be negative")
+	}
+	return problems
+}
+
+// conflictingOperators lists, for each operator, the operators it can't be
+// used with in the same query.
+var conflictingOperators = map[string][]string{
+	"NOT_IN":             {"NOT_EQUAL", "IN", "ARRAY_CONTAINS_ANY"},
+	"NOT_EQUAL":          {"NOT_IN"},
+	"IN":                 {"NOT_IN"},
+	"ARRAY_CONTAINS_ANY": {"NOT_IN", "ARRAY_CONTAINS"},
+	"ARRAY_CONTAINS":     {"ARRAY_CONTAINS_ANY"},
+}
+
+// checkFieldPath describes what is wrong with a dotted field path, or returns "".
+func checkFieldPath(path string) string {
+	if strings.TrimSpace(path) == "" {
+		return "field name is required"
+	}
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" {
+			return fmt.Sprintf("invalid field path %q: empty segment", path)
+		}
+	}
+	return ""
+}
+
+// arrayLength counts the values of an arrayValue built from the query builder
+// or from JSON.
+func arrayLength(arr map[string]interface{}) int {
+	switch values := arr["values"].(type) {
+	case []map[string]interface{}:
+		return len(values)
+	case []interface{}:
+		return len(values)
+	}
+	return 0
+}
+
+// checkTypedValue describes why value can't be sent as valueType, or returns "".
+func checkTypedValue(value, valueType string) string {
+	if isTypedQueryValue(valueType) {
+		if _, err := typedQueryValue(value, valueType, time.Now()); err != nil {
+			return err.Error()
+		}
+		return ""
+	}
+	switch valueType {
+	case "integer":
+		if _, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err != nil {
+			return fmt.Sprintf("%q is not an integer", value)
+		}
+	case "double":
+		if _, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+			return fmt.Sprintf("%q is not a number", value)
+		}
+	case "boolean":
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "true", "false", "1", "0":
+		default:
+			return fmt.Sprintf("%q is not true or false", value)
+		}
+	}
+	return ""
+}
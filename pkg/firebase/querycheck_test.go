@@ -0,0 +1,79 @@
+package firebase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateQuery(t *testing.T) {
+	filter := func(field, op, value, valueType string) QueryFilter {
+		return QueryFilter{Field: field, Operator: op, Value: value, ValueType: valueType}
+	}
+	many := strings.TrimSuffix(strings.Repeat("x,", 31), ",")
+
+	tests := []struct {
+		name     string
+		opts     QueryOptions
+		expected []QueryProblem
+	}{
+		{"valid", QueryOptions{
+			Filters: []QueryFilter{filter("age", ">=", "18", "integer"), filter("tags", "array-contains", "a", "auto")},
+			OrderBy: "age", Limit: 10,
+		}, nil},
+		{"inequality with another first order by", QueryOptions{
+			Filters: []QueryFilter{filter("status", "==", "active", "auto"), filter("age", ">", "18", "auto")},
+			OrderBy: "created",
+		}, []QueryProblem{{-1, QueryPartOrderBy, `the first order by must be on "age", the field of the inequality filter`}}},
+		{"too many in values", QueryOptions{
+			Filters: []QueryFilter{filter("id", "in", many, "array")},
+		}, []QueryProblem{{0, QueryPartValue, "in allows at most 30 values, found 31"}}},
+		{"in without a list", QueryOptions{
+			Filters: []QueryFilter{filter("id", "in", "a,b", "string")},
+		}, []QueryProblem{{0, QueryPartValue, "in needs a list of values: set the type to array"}}},
+		{"not-in with !=", QueryOptions{
+			Filters: []QueryFilter{filter("a", "!=", "1", "auto"), filter("b", "not-in", "1,2", "array")},
+			OrderBy: "a",
+		}, []QueryProblem{{1, QueryPartOperator, "not-in can't be combined with != (filter 1)"}}},
+		{"two array-contains", QueryOptions{
+			Filters: []QueryFilter{filter("a", "array-contains", "1", "auto"), filter("b", "array-contains", "2", "auto")},
+		}, []QueryProblem{{1, QueryPartOperator, "only one array-contains filter is allowed per query (filter 1 has one)"}}},
+		{"array-contains with array-contains-any", QueryOptions{
+			Filters: []QueryFilter{filter("a", "array-contains", "1", "auto"), filter("b", "array-contains-any", "1,2", "array")},
+		}, []QueryProblem{{1, QueryPartOperator, "array-contains-any can't be combined with array-contains (filter 1)"}}},
+		{"too many disjunctions", QueryOptions{
+			Filters: []QueryFilter{filter("a", "in", "1,2,3,4,5,6", "array"), filter("b", "array-contains-any", "1,2,3,4,5,6", "array")},
+		}, []QueryProblem{{1, QueryPartValue, "in and array-contains-any filters expand to 36 combinations, at most 30 are allowed"}}},
+		{"field, type and null problems", QueryOptions{
+			Filters: []QueryFilter{
+				filter("", "==", "1", "auto"),
+				filter("a..b", "==", "x", "integer"),
+				filter("c", ">", "null", "auto"),
+				filter("d", "like", "x", "auto"),
+			},
+			OrderBy: "c", Limit: -1,
+		}, []QueryProblem{
+			{0, QueryPartField, "field name is required"},
+			{1, QueryPartField, `invalid field path "a..b": empty segment`},
+			{1, QueryPartValue, `"x" is not an integer`},
+			{2, QueryPartOperator, "null can only be compared with == or !="},
+			{3, QueryPartOperator, `unknown operator "like"`},
+			{-1, QueryPartLimit, "limit can't be negative"},
+		}},
+		{"parameters are checked once bound", QueryOptions{
+			Filters: []QueryFilter{filter("age", ">", "$minAge", "integer"), filter("id", "in", "$ids", "auto")},
+		}, nil},
+		{"document name needs a reference", QueryOptions{
+			Filters: []QueryFilter{filter("__name__", "==", "users/a", "auto")},
+		}, []QueryProblem{{0, QueryPartValue, "__name__ filters compare document references"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateQuery(tt.opts)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ValidateQuery() = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
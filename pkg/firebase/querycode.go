@@ -0,0 +1,369 @@
+package firebase
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Snippet languages supported by GenerateQueryCode.
+const (
+	SnippetJS     = "js"     // Web SDK v9 modular
+	SnippetNode   = "node"   // Admin SDK for Node.js
+	SnippetGo     = "go"     // cloud.google.com/go/firestore
+	SnippetPython = "python" // google-cloud-firestore
+	SnippetREST   = "rest"   // runQuery request body
+)
+
+// sdkOperators maps the REST operators of buildStructuredQuery back to the
+// operators the client SDKs take.
+var sdkOperators = map[string]string{
+	"EQUAL":                 "==",
+	"NOT_EQUAL":             "!=",
+	"LESS_THAN":             "<",
+	"LESS_THAN_OR_EQUAL":    "<=",
+	"GREATER_THAN":          ">",
+	"GREATER_THAN_OR_EQUAL": ">=",
+	"IN":                    "in",
+	"NOT_IN":                "not-in",
+	"ARRAY_CONTAINS":        "array-contains",
+	"ARRAY_CONTAINS_ANY":    "array-contains-any",
+}
+
+// GenerateQueryCode writes the query on collectionPath as code for lang.
+// Values are typed the way RunQuery sends them. A value that is a single
+// parameter like $userId becomes a variable named userId.
+func GenerateQueryCode(collectionPath string, opts QueryOptions, lang string) (string, error) {
+	q := snippetQuery{collection: collectionPath, opts: opts, uses: make(map[string]bool)}
+	switch lang {
+	case SnippetJS:
+		return q.js(), nil
+	case SnippetNode:
+		return q.node(), nil
+	case SnippetGo:
+		return q.goCode(), nil
+	case SnippetPython:
+		return q.python(), nil
+	case SnippetREST:
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]interface{}{"structuredQuery": buildStructuredQuery(collectionPath, opts)}); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+	return "", fmt.Errorf("unknown language %q", lang)
+}
+
+type snippetQuery struct {
+	collection string
+	opts       QueryOptions
+	uses       map[string]bool // SDK names the literals need imported
+}
+
+// descending reports whether the query orders by its field descending.
+func (q snippetQuery) descending() bool {
+	return strings.HasPrefix(strings.ToUpper(q.opts.OrderDir), "DESC")
+}
+
+// filterArgs returns the field, operator and value of a filter, with the
+// value written in syntax.
+func filterArgs(f QueryFilter, syntax literalSyntax) (string, string, string) {
+	op := sdkOperators[convertOperator(f.Operator)]
+	if s, ok := f.Value.(string); ok && queryParamToken(strings.TrimSpace(s)) && (f.ValueType == "" || f.ValueType == "auto" || f.ValueType == "string") {
+		return f.Field, op, strings.TrimSpace(s)[1:]
+	}
+	return f.Field, op, syntax.literal(toFirestoreValue(f.Value, f.ValueType))
+}
+
+// usedNames returns the names of uses that are in names, in the order of names.
+func (q snippetQuery) usedNames(names ...string) []string {
+	var used []string
+	for _, name := range names {
+		if q.uses[name] {
+			used = append(used, name)
+		}
+	}
+	return used
+}
+
+func (q snippetQuery) js() string {
+	syntax := q.jsSyntax(func(path string) string {
+		q.uses["doc"] = true
+		return fmt.Sprintf("doc(db, %s)", jsString(path))
+	})
+	args := []string{fmt.Sprintf("collection(db, %s)", jsString(q.collection))}
+	if len(q.opts.Filters) > 0 {
+		q.uses["where"] = true
+	}
+	for _, f := range q.opts.Filters {
+		field, op, value := filterArgs(f, syntax)
+		args = append(args, fmt.Sprintf("where(%s, %s, %s)", jsString(field), jsString(op), value))
+	}
+	if q.opts.OrderBy != "" {
+		q.uses["orderBy"] = true
+		args = append(args, "orderBy("+jsString(q.opts.OrderBy)+q.jsDirection()+")")
+	}
+	if q.opts.Limit > 0 {
+		q.uses["limit"] = true
+		args = append(args, fmt.Sprintf("limit(%d)", q.opts.Limit))
+	}
+	q.uses["collection"], q.uses["query"], q.uses["getDocs"] = true, true, true
+	imports := q.usedNames("collection", "doc", "query", "where", "orderBy", "limit", "getDocs", "GeoPoint", "Timestamp")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "import { %s } from \"firebase/firestore\";\n\n", strings.Join(imports, ", "))
+	fmt.Fprintf(&b, "const q = query(\n  %s\n);\n", strings.Join(args, ",\n  "))
+	b.WriteString("const snapshot = await getDocs(q);\n")
+	return b.String()
+}
+
+func (q snippetQuery) node() string {
+	syntax := q.jsSyntax(func(path string) string {
+		return fmt.Sprintf("db.doc(%s)", jsString(path))
+	})
+	var body strings.Builder
+	fmt.Fprintf(&body, "const snapshot = await db.collection(%s)\n", jsString(q.collection))
+	for _, f := range q.opts.Filters {
+		field, op, value := filterArgs(f, syntax)
+		fmt.Fprintf(&body, "  .where(%s, %s, %s)\n", jsString(field), jsString(op), value)
+	}
+	if q.opts.OrderBy != "" {
+		fmt.Fprintf(&body, "  .orderBy(%s%s)\n", jsString(q.opts.OrderBy), q.jsDirection())
+	}
+	if q.opts.Limit > 0 {
+		fmt.Fprintf(&body, "  .limit(%d)\n", q.opts.Limit)
+	}
+	body.WriteString("  .get();\n")
+
+	if imports := q.usedNames("GeoPoint", "Timestamp"); len(imports) > 0 {
+		return fmt.Sprintf("const { %s } = require(\"firebase-admin/firestore\");\n\n", strings.Join(imports, ", ")) + body.String()
+	}
+	return body.String()
+}
+
+func (q snippetQuery) jsDirection() string {
+	if q.descending() {
+		return `, "desc"`
+	}
+	return ""
+}
+
+// jsSyntax writes JavaScript literals; reference writes document references.
+func (q snippetQuery) jsSyntax(reference func(string) string) literalSyntax {
+	return literalSyntax{
+		str: jsString, null: "null", yes: "true", no: "false",
+		list: func(items []string) string { return "[" + strings.Join(items, ", ") + "]" },
+		object: func(keys, values []string) string {
+			var entries []string
+			for i, key := range keys {
+				entries = append(entries, jsString(key)+": "+values[i])
+			}
+			return "{ " + strings.Join(entries, ", ") + " }"
+		},
+		timestamp: func(t time.Time) string {
+			q.uses["Timestamp"] = true
+			return fmt.Sprintf("Timestamp.fromDate(new Date(%s))", jsString(t.Format(time.RFC3339Nano)))
+		},
+		reference: reference,
+		geoPoint: func(lat, lng float64) string {
+			q.uses["GeoPoint"] = true
+			return fmt.Sprintf("new GeoPoint(%s, %s)", doubleLiteral(lat), doubleLiteral(lng))
+		},
+	}
+}
+
+func (q snippetQuery) goCode() string {
+	syntax := literalSyntax{
+		str: strconv.Quote, null: "nil", yes: "true", no: "false",
+		list: func(items []string) string { return "[]interface{}{" + strings.Join(items, ", ") + "}" },
+		object: func(keys, values []string) string {
+			var entries []string
+			for i, key := range keys {
+				entries = append(entries, strconv.Quote(key)+": "+values[i])
+			}
+			return "map[string]interface{}{" + strings.Join(entries, ", ") + "}"
+		},
+		timestamp: func(t time.Time) string {
+			return fmt.Sprintf("time.Date(%d, time.%s, %d, %d, %d, %d, %d, time.UTC)",
+				t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+		},
+		reference: func(path string) string { return fmt.Sprintf("client.Doc(%s)", strconv.Quote(path)) },
+		geoPoint: func(lat, lng float64) string {
+			return fmt.Sprintf("&latlng.LatLng{Latitude: %s, Longitude: %s}", doubleLiteral(lat), doubleLiteral(lng))
+		},
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "iter := client.Collection(%s).\n", strconv.Quote(q.collection))
+	for _, f := range q.opts.Filters {
+		field, op, value := filterArgs(f, syntax)
+		fmt.Fprintf(&b, "\tWhere(%s, %s, %s).\n", strconv.Quote(field), strconv.Quote(op), value)
+	}
+	if q.opts.OrderBy != "" {
+		dir := "firestore.Asc"
+		if q.descending() {
+			dir = "firestore.Desc"
+		}
+		fmt.Fprintf(&b, "\tOrderBy(%s, %s).\n", strconv.Quote(q.opts.OrderBy), dir)
+	}
+	if q.opts.Limit > 0 {
+		fmt.Fprintf(&b, "\tLimit(%d).\n", q.opts.Limit)
+	}
+	b.WriteString("\tDocuments(ctx)\n")
+	b.WriteString("docs, err := iter.GetAll()\n")
+	return b.String()
+}
+
+func (q snippetQuery) python() string {
+	syntax := literalSyntax{
+		str: jsString, null: "None", yes: "True", no: "False",
+		list: func(items []string) string { return "[" + strings.Join(items, ", ") + "]" },
+		object: func(keys, values []string) string {
+			var entries []string
+			for i, key := range keys {
+				entries = append(entries, jsString(key)+": "+values[i])
+			}
+			return "{" + strings.Join(entries, ", ") + "}"
+		},
+		timestamp: func(t time.Time) string {
+			q.uses["datetime"] = true
+			return fmt.Sprintf("datetime(%d, %d, %d, %d, %d, %d, %d, tzinfo=timezone.utc)",
+				t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1000)
+		},
+		reference: func(path string) string { return fmt.Sprintf("db.document(%s)", jsString(path)) },
+		geoPoint: func(lat, lng float64) string {
+			return fmt.Sprintf("firestore.GeoPoint(%s, %s)", doubleLiteral(lat), doubleLiteral(lng))
+		},
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "\ndocs = (\n    db.collection(%s)\n", jsString(q.collection))
+	for _, f := range q.opts.Filters {
+		field, op, value := filterArgs(f, syntax)
+		fmt.Fprintf(&body, "    .where(filter=FieldFilter(%s, %s, %s))\n", jsString(field), jsString(op), value)
+	}
+	if q.opts.OrderBy != "" {
+		dir := "ASCENDING"
+		if q.descending() {
+			dir = "DESCENDING"
+		}
+		fmt.Fprintf(&body, "    .order_by(%s, direction=firestore.Query.%s)\n", jsString(q.opts.OrderBy), dir)
+	}
+	if q.opts.Limit > 0 {
+		fmt.Fprintf(&body, "    .limit(%d)\n", q.opts.Limit)
+	}
+	body.WriteString("    .stream()\n)\n")
+
+	var b strings.Builder
+	if q.uses["datetime"] {
+		b.WriteString("from datetime import datetime, timezone\n\n")
+	}
+	b.WriteString("from google.cloud import firestore\n")
+	if len(q.opts.Filters) > 0 {
+		b.WriteString("from google.cloud.firestore_v1.base_query import FieldFilter\n")
+	}
+	return b.String() + body.String()
+}
+
+// jsString writes s as a double-quoted JavaScript or Python string.
+func jsString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// literalSyntax writes Firestore values as literals of one language.
+type literalSyntax struct {
+	str           func(string) string
+	null, yes, no string
+	list          func(items []string) string
+	object        func(keys, values []string) string // Keys are sorted
+	timestamp     func(time.Time) string
+	reference     func(path string) string // Path relative to the database
+	geoPoint      func(lat, lng float64) string
+}
+
+// literal writes a Firestore value produced by toFirestoreValue.
+func (s literalSyntax) literal(value map[string]interface{}) string {
+	switch {
+	case value["stringValue"] != nil:
+		return s.str(fmt.Sprintf("%v", value["stringValue"]))
+	case value["integerValue"] != nil:
+		return fmt.Sprintf("%v", value["integerValue"])
+	case value["doubleValue"] != nil:
+		return doubleLiteral(value["doubleValue"])
+	case value["booleanValue"] != nil:
+		if value["booleanValue"] == true {
+			return s.yes
+		}
+		return s.no
+	case value["timestampValue"] != nil:
+		t, err := time.Parse(time.RFC3339Nano, fmt.Sprintf("%v", value["timestampValue"]))
+		if err != nil {
+			return s.str(fmt.Sprintf("%v", value["timestampValue"]))
+		}
+		return s.timestamp(t.UTC())
+	case value["referenceValue"] != nil:
+		path := strings.Trim(fmt.Sprintf("%v", value["referenceValue"]), "/")
+		if i := strings.Index(path, "/documents/"); i >= 0 && isDocumentName(path) {
+			path = path[i+len("/documents/"):]
+		}
+		return s.reference(path)
+	case value["geoPointValue"] != nil:
+		geo, _ := value["geoPointValue"].(map[string]interface{})
+		lat, _ := geo["latitude"].(float64)
+		lng, _ := geo["longitude"].(float64)
+		return s.geoPoint(lat, lng)
+	case value["arrayValue"] != nil:
+		var items []string
+		arr, _ := value["arrayValue"].(map[string]interface{})
+		switch values := arr["values"].(type) {
+		case []map[string]interface{}:
+			for _, v := range values {
+				items = append(items, s.literal(v))
+			}
+		case []interface{}:
+			for _, v := range values {
+				item, _ := v.(map[string]interface{})
+				items = append(items, s.literal(item))
+			}
+		}
+		return s.list(items)
+	case value["mapValue"] != nil:
+		m, _ := value["mapValue"].(map[string]interface{})
+		fields, _ := m["fields"].(map[string]interface{})
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]string, len(keys))
+		for i, key := range keys {
+			field, _ := fields[key].(map[string]interface{})
+			values[i] = s.literal(field)
+		}
+		return s.object(keys, values)
+	}
+	return s.null
+}
+
+// doubleLiteral writes a double so that it reads back as a floating point number.
+func doubleLiteral(v interface{}) string {
+	f, err := strconv.ParseFloat(fmt.Sprintf("%v", v), 64)
+	if err != nil {
+		return strconv.Quote(fmt.Sprintf("%v", v))
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
@@ -0,0 +1,165 @@
+package firebase
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerateQueryCode(t *testing.T) {
+	opts := QueryOptions{
+		Filters: []QueryFilter{
+			{Field: "status", Operator: "==", Value: "active", ValueType: "auto"},
+			{Field: "owner", Operator: "==", Value: "$userId", ValueType: "auto"},
+			{Field: "tags", Operator: "array-contains-any", Value: "a, 2, true", ValueType: "array"},
+			{Field: "score", Operator: ">=", Value: "3", ValueType: "double"},
+			{Field: "deletedAt", Operator: "==", Value: "", ValueType: "null"},
+		},
+		OrderBy:  "created",
+		OrderDir: "DESC",
+		Limit:    100,
+	}
+
+	tests := []struct {
+		lang     string
+		expected []string
+	}{
+		{SnippetJS, []string{
+			`import { collection, query, where, orderBy, limit, getDocs } from "firebase/firestore";`,
+			"const q = query(\n  collection(db, \"users/u1/orders\"),\n  where(\"status\", \"==\", \"active\"),",
+			`where("owner", "==", userId),`,
+			`where("tags", "array-contains-any", ["a", 2, true]),`,
+			`where("score", ">=", 3.0),`,
+			`where("deletedAt", "==", null),`,
+			"orderBy(\"created\", \"desc\"),\n  limit(100)\n);",
+		}},
+		{SnippetNode, []string{
+			"const snapshot = await db.collection(\"users/u1/orders\")\n  .where(\"status\", \"==\", \"active\")",
+			`.where("tags", "array-contains-any", ["a", 2, true])`,
+			".orderBy(\"created\", \"desc\")\n  .limit(100)\n  .get();",
+		}},
+		{SnippetGo, []string{
+			"iter := client.Collection(\"users/u1/orders\").\n\tWhere(\"status\", \"==\", \"active\").",
+			`Where("owner", "==", userId).`,
+			`Where("tags", "array-contains-any", []interface{}{"a", 2, true}).`,
+			`Where("deletedAt", "==", nil).`,
+			"\tOrderBy(\"created\", firestore.Desc).\n\tLimit(100).\n\tDocuments(ctx)",
+		}},
+		{SnippetPython, []string{
+			"from google.cloud.firestore_v1.base_query import FieldFilter",
+			`.where(filter=FieldFilter("tags", "array-contains-any", ["a", 2, True]))`,
+			`.where(filter=FieldFilter("deletedAt", "==", None))`,
+			`.order_by("created", direction=firestore.Query.DESCENDING)`,
+			".limit(100)\n    .stream()\n)",
+		}},
+		{SnippetREST, []string{
+			`"structuredQuery": {`,
+			`"op": "ARRAY_CONTAINS_ANY"`,
+			`"direction": "DESCENDING"`,
+			`"limit": 100`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			code, err := GenerateQueryCode("users/u1/orders", opts, tt.lang)
+			if err != nil {
+				t.Fatalf("GenerateQueryCode() error: %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(code, want) {
+					t.Errorf("GenerateQueryCode(%s) missing %q in:\n%s", tt.lang, want, code)
+				}
+			}
+		})
+	}
+
+	if _, err := GenerateQueryCode("users", opts, "cobol"); err == nil {
+		t.Error("GenerateQueryCode() should fail for an unknown language")
+	}
+}
+
+func TestGenerateQueryCodeREST(t *testing.T) {
+	opts := QueryOptions{Filters: []QueryFilter{{Field: "age", Operator: ">", Value: "18"}}}
+	code, err := GenerateQueryCode("users", opts, SnippetREST)
+	if err != nil {
+		t.Fatalf("GenerateQueryCode() error: %v", err)
+	}
+
+	var body struct {
+		StructuredQuery map[string]interface{} `json:"structuredQuery"`
+	}
+	if err := json.Unmarshal([]byte(code), &body); err != nil {
+		t.Fatalf("REST snippet is not JSON: %v", err)
+	}
+	expected, _ := json.Marshal(buildStructuredQuery("users", opts))
+	got, _ := json.Marshal(body.StructuredQuery)
+	if string(got) != string(expected) {
+		t.Errorf("structuredQuery = %s, expected %s", got, expected)
+	}
+}
+
+func TestGenerateQueryCodeMinimal(t *testing.T) {
+	code, _ := GenerateQueryCode("users", QueryOptions{}, SnippetJS)
+	expected := "import { collection, query, getDocs } from \"firebase/firestore\";\n\n" +
+		"const q = query(\n  collection(db, \"users\")\n);\nconst snapshot = await getDocs(q);\n"
+	if code != expected {
+		t.Errorf("GenerateQueryCode() = %q, expected %q", code, expected)
+	}
+
+	code, _ = GenerateQueryCode("users", QueryOptions{}, SnippetPython)
+	if strings.Contains(code, "FieldFilter") {
+		t.Errorf("GenerateQueryCode() imports FieldFilter without filters:\n%s", code)
+	}
+}
+
+func TestGenerateQueryCodeTypedValues(t *testing.T) {
+	opts := QueryOptions{Filters: []QueryFilter{
+		{Field: "created", Operator: ">", Value: "2025-01-02T03:04:05Z", ValueType: "timestamp"},
+		{Field: "author", Operator: "==", Value: "users/ana", ValueType: "reference"},
+		{Field: "at", Operator: "==", Value: "48.5, 2", ValueType: "geopoint"},
+		{Field: "meta", Operator: "==", Value: `{"b": [1], "a": "x"}`, ValueType: "json"},
+	}}
+
+	tests := []struct {
+		lang     string
+		expected []string
+	}{
+		{SnippetJS, []string{
+			`import { collection, doc, query, where, getDocs, GeoPoint, Timestamp } from "firebase/firestore";`,
+			`where("created", ">", Timestamp.fromDate(new Date("2025-01-02T03:04:05Z"))),`,
+			`where("author", "==", doc(db, "users/ana")),`,
+			`where("at", "==", new GeoPoint(48.5, 2.0)),`,
+			`where("meta", "==", { "a": "x", "b": [1] })`,
+		}},
+		{SnippetNode, []string{
+			`const { GeoPoint, Timestamp } = require("firebase-admin/firestore");`,
+			`.where("author", "==", db.doc("users/ana"))`,
+		}},
+		{SnippetGo, []string{
+			`Where("created", ">", time.Date(2025, time.January, 2, 3, 4, 5, 0, time.UTC)).`,
+			`Where("author", "==", client.Doc("users/ana")).`,
+			`Where("at", "==", &latlng.LatLng{Latitude: 48.5, Longitude: 2.0}).`,
+			`Where("meta", "==", map[string]interface{}{"a": "x", "b": []interface{}{1}}).`,
+		}},
+		{SnippetPython, []string{
+			"from datetime import datetime, timezone\n\nfrom google.cloud import firestore",
+			`FieldFilter("created", ">", datetime(2025, 1, 2, 3, 4, 5, 0, tzinfo=timezone.utc))`,
+			`FieldFilter("author", "==", db.document("users/ana"))`,
+			`FieldFilter("at", "==", firestore.GeoPoint(48.5, 2.0))`,
+			`FieldFilter("meta", "==", {"a": "x", "b": [1]})`,
+		}},
+	}
+
+	for _, tt := range tests {
+		code, err := GenerateQueryCode("posts", opts, tt.lang)
+		if err != nil {
+			t.Fatalf("GenerateQueryCode(%s) error: %v", tt.lang, err)
+		}
+		for _, want := range tt.expected {
+			if !strings.Contains(code, want) {
+				t.Errorf("GenerateQueryCode(%s) missing %q in:\n%s", tt.lang, want, code)
+			}
+		}
+	}
+}
@@ -0,0 +1,742 @@
+package firebase
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query text accepted by ParseQuery, in either of two forms:
+//
+//	where status == "active" and age >= 18 order by created desc limit 100
+//	.where('status', '==', 'active').where('age', '>=', 18).orderBy('created', 'desc').limit(100)
+//
+// Values are quoted strings, numbers, true, false, null, [lists], parameters
+// like $userId or typed values: timestamp("now-7d"), ref("users/abc"),
+// geopoint(48.85, 2.35) and json('{"a": 1}'). Dates like 2025-01-01 and
+// relative times like now-7d are timestamps without the call. Field names that
+// aren't plain identifiers are written in backquotes: `first name`.
+
+// QueryOperators are the where operators of the query builder and query text.
+var QueryOperators = []string{"==", "!=", "<", "<=", ">", ">=", "in", "not-in", "array-contains", "array-contains-any"}
+
+// QueryParamPattern matches parameters like $userId in filter values.
+var QueryParamPattern = regexp.MustCompile(`\$[A-Za-z_][A-Za-z0-9_]*`)
+
+// QuerySyntaxError is an error in query text at a 1-based column.
+type QuerySyntaxError struct {
+	Column  int
+	Message string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+type queryTokenKind int
+
+const (
+	tokenEOF queryTokenKind = iota
+	tokenIdent
+	tokenField // `backquoted field`
+	tokenString
+	tokenNumber
+	tokenDate // 2025-01-01 or 2025-01-01T10:00:00Z
+	tokenOperator
+	tokenPunct
+)
+
+type queryToken struct {
+	kind  queryTokenKind
+	text  string // Source text, or the unquoted value of strings and fields
+	col   int
+	start int // Rune offset, to tell adjacent tokens apart
+}
+
+// describe names a token in error messages.
+func (t queryToken) describe() string {
+	if t.kind == tokenEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || r == '$'
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r) || r == '.' || r == '-'
+}
+
+// queryDate matches a bare date or RFC 3339 time at the start of text.
+var queryDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:\d{2})?)?`)
+
+// queryRelativeTime matches offsets with a plus after now or today, which
+// aren't part of an identifier: now+1d, today+1w-12h.
+var queryRelativeTime = regexp.MustCompile(`^([+-]\d+[smhdw])+`)
+
+// tokenizeQuery splits query text into tokens.
+func tokenizeQuery(text string) ([]queryToken, error) {
+	runes := []rune(text)
+	var tokens []queryToken
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case isIdentStart(r):
+			for i < len(runes) && isIdentPart(runes[i]) {
+				i++
+			}
+			if isRelativeTime(strings.ToLower(string(runes[start:i]))) {
+				i += len(queryRelativeTime.FindString(string(runes[i:])))
+			}
+			tokens = append(tokens, queryToken{kind: tokenIdent, text: string(runes[start:i])})
+		case queryDate.MatchString(string(runes[i:])):
+			i += len([]rune(queryDate.FindString(string(runes[i:]))))
+			tokens = append(tokens, queryToken{kind: tokenDate, text: string(runes[start:i])})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune(".eE", runes[i]) ||
+				(strings.ContainsRune("+-", runes[i]) && strings.ContainsRune("eE", runes[i-1]))) {
+				i++
+			}
+			number := string(runes[start:i])
+			if _, err := strconv.ParseFloat(number, 64); err != nil {
+				return nil, &QuerySyntaxError{start + 1, fmt.Sprintf("invalid number %q", number)}
+			}
+			tokens = append(tokens, queryToken{kind: tokenNumber, text: number})
+		case r == '"' || r == '\'' || r == '`':
+			value, end, err := scanQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			kind := tokenString
+			if r == '`' {
+				kind = tokenField
+			}
+			tokens = append(tokens, queryToken{kind: kind, text: value})
+		case strings.ContainsRune("=!<>", r):
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+			op := string(runes[start:i])
+			switch op {
+			case "=":
+				op = "=="
+			case "!":
+				return nil, &QuerySyntaxError{start + 1, `unexpected "!", expected "!="`}
+			}
+			tokens = append(tokens, queryToken{kind: tokenOperator, text: op})
+		case strings.ContainsRune("()[],.", r):
+			i++
+			tokens = append(tokens, queryToken{kind: tokenPunct, text: string(r)})
+		default:
+			return nil, &QuerySyntaxError{start + 1, fmt.Sprintf("unexpected %q", r)}
+		}
+		tokens[len(tokens)-1].col = start + 1
+		tokens[len(tokens)-1].start = start
+	}
+	return append(tokens, queryToken{kind: tokenEOF, col: len(runes) + 1, start: len(runes)}), nil
+}
+
+// scanQuoted reads the quoted string starting at runes[start], handling
+// backslash escapes, and returns its value and the offset after it.
+func scanQuoted(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var b strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == quote:
+			return b.String(), i + 1, nil
+		case r == '\\' && i+1 < len(runes):
+			i++
+			switch runes[i] {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			default:
+				b.WriteRune(runes[i])
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", 0, &QuerySyntaxError{start + 1, "unterminated string"}
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) errorf(t queryToken, format string, args ...interface{}) error {
+	return &QuerySyntaxError{t.col, fmt.Sprintf(format, args...)}
+}
+
+// keyword reports whether the next token is the keyword word, ignoring case.
+func (p *queryParser) keyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && strings.EqualFold(t.text, word)
+}
+
+func (p *queryParser) expectKeyword(word string) error {
+	if !p.keyword(word) {
+		return p.errorf(p.peek(), "expected %q, found %s", word, p.peek().describe())
+	}
+	p.next()
+	return nil
+}
+
+func (p *queryParser) expectPunct(punct string) error {
+	t := p.next()
+	if t.kind != tokenPunct || t.text != punct {
+		return p.errorf(t, "expected %q, found %s", punct, t.describe())
+	}
+	return nil
+}
+
+// compactKeywords can't be used as bare field names in the compact form.
+var compactKeywords = map[string]bool{"where": true, "and": true, "order": true, "by": true, "limit": true, "asc": true, "desc": true}
+
+// ParseQuery parses query text into query options. Directions are "ASC" or
+// "DESC" and values are kept as text with a value type, as in the query builder.
+func ParseQuery(text string) (QueryOptions, error) {
+	tokens, err := tokenizeQuery(text)
+	if err != nil {
+		return QueryOptions{}, err
+	}
+	p := &queryParser{tokens: tokens}
+
+	first := p.peek()
+	if (first.kind == tokenPunct && first.text == ".") ||
+		(first.kind == tokenIdent && len(tokens) > 1 && tokens[1].kind == tokenPunct && tokens[1].text == "(") {
+		return p.parseChain()
+	}
+	return p.parseCompact()
+}
+
+// parseCompact parses: [where cond {and cond}] [order by field [asc|desc]] [limit n]
+func (p *queryParser) parseCompact() (QueryOptions, error) {
+	var opts QueryOptions
+	if p.keyword("where") {
+		p.next()
+		for {
+			f, err := p.parseCondition()
+			if err != nil {
+				return opts, err
+			}
+			opts.Filters = append(opts.Filters, f)
+			if !p.keyword("and") {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if p.keyword("order") {
+		p.next()
+		if err := p.expectKeyword("by"); err != nil {
+			return opts, err
+		}
+		field, err := p.parseField()
+		if err != nil {
+			return opts, err
+		}
+		opts.OrderBy = field
+		opts.OrderDir = "ASC"
+		if p.keyword("asc") || p.keyword("desc") {
+			opts.OrderDir = strings.ToUpper(p.next().text)
+		}
+	}
+
+	if p.keyword("limit") {
+		p.next()
+		limit, err := p.parseLimit()
+		if err != nil {
+			return opts, err
+		}
+		opts.Limit = limit
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return opts, p.errorf(t, "unexpected %s, expected where, and, order by or limit", t.describe())
+	}
+	return opts, nil
+}
+
+// parseField reads a bare or backquoted field name.
+func (p *queryParser) parseField() (string, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenField && t.text != "":
+		return t.text, nil
+	case t.kind == tokenIdent && !compactKeywords[strings.ToLower(t.text)] && !strings.HasPrefix(t.text, "$"):
+		return t.text, nil
+	}
+	return "", p.errorf(t, "expected a field name, found %s", t.describe())
+}
+
+// parseCondition reads: field operator value
+func (p *queryParser) parseCondition() (QueryFilter, error) {
+	field, err := p.parseField()
+	if err != nil {
+		return QueryFilter{}, err
+	}
+
+	t := p.next()
+	op := ""
+	switch {
+	case t.kind == tokenOperator:
+		op = t.text
+	case t.kind == tokenIdent && strings.EqualFold(t.text, "not") && p.keyword("in"):
+		p.next()
+		op = "not-in"
+	case t.kind == tokenIdent && isQueryOperator(strings.ToLower(t.text)):
+		op = strings.ToLower(t.text)
+	default:
+		return QueryFilter{}, p.errorf(t, "expected an operator after %q, found %s", field, t.describe())
+	}
+
+	value, valueType, err := p.parseValue()
+	if err != nil {
+		return QueryFilter{}, err
+	}
+	return QueryFilter{Field: field, Operator: op, Value: value, ValueType: valueType}, nil
+}
+
+func isQueryOperator(op string) bool {
+	for _, o := range QueryOperators {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseValue reads a value and returns it with its value type. Lists are
+// returned as []interface{} with the "array" type, their items typed as in
+// queryListItem.
+func (p *queryParser) parseValue() (interface{}, string, error) {
+	t := p.peek()
+	if t.kind == tokenPunct && t.text == "[" {
+		p.next()
+		items := []interface{}{}
+		for {
+			if end := p.peek(); end.kind == tokenPunct && end.text == "]" && len(items) == 0 {
+				p.next()
+				break
+			}
+			itemToken := p.peek()
+			value, valueType, err := p.parseScalar()
+			if err != nil {
+				return nil, "", err
+			}
+			item, ok := queryListItem(value, valueType)
+			if !ok {
+				return nil, "", p.errorf(itemToken, "%s values can't be list items", valueType)
+			}
+			items = append(items, item)
+			sep := p.next()
+			if sep.kind == tokenPunct && sep.text == "]" {
+				break
+			}
+			if sep.kind != tokenPunct || sep.text != "," {
+				return nil, "", p.errorf(sep, "expected \",\" or \"]\", found %s", sep.describe())
+			}
+		}
+		return items, "array", nil
+	}
+	return p.parseScalar()
+}
+
+// queryListItem converts a scalar read by parseScalar to a list item: a
+// string, int64, float64, bool or nil. Parameters stay strings until bound.
+func queryListItem(value, valueType string) (interface{}, bool) {
+	switch valueType {
+	case "string", "auto":
+		return value, true
+	case "integer":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i, true
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		return f, err == nil
+	case "double":
+		f, err := strconv.ParseFloat(value, 64)
+		return f, err == nil
+	case "boolean":
+		return value == "true", true
+	case "null":
+		return nil, true
+	}
+	return nil, false
+}
+
+// typedValueFuncs maps the functions of typed values to their value type.
+var typedValueFuncs = map[string]string{
+	"timestamp": "timestamp",
+	"ref":       "reference",
+	"reference": "reference",
+	"geopoint":  "geopoint",
+	"json":      "json",
+}
+
+// parseScalar reads a string, number, boolean, null, parameter or typed value.
+func (p *queryParser) parseScalar() (string, string, error) {
+	t := p.next()
+	if open := p.peek(); t.kind == tokenIdent && open.kind == tokenPunct && open.text == "(" {
+		return p.parseTypedValue(t)
+	}
+	switch t.kind {
+	case tokenDate:
+		if msg := checkTypedValue(t.text, "timestamp"); msg != "" {
+			return "", "", p.errorf(t, "%s", msg)
+		}
+		return t.text, "timestamp", nil
+	case tokenString:
+		return t.text, "string", nil
+	case tokenNumber:
+		if strings.ContainsAny(t.text, ".eE") {
+			return t.text, "double", nil
+		}
+		return t.text, "integer", nil
+	case tokenIdent:
+		switch lower := strings.ToLower(t.text); {
+		case lower == "true" || lower == "false":
+			return lower, "boolean", nil
+		case lower == "null":
+			return "", "null", nil
+		case strings.HasPrefix(t.text, "$") && len(t.text) > 1:
+			return t.text, "auto", nil
+		case isRelativeTime(lower):
+			if msg := checkTypedValue(t.text, "timestamp"); msg != "" {
+				return "", "", p.errorf(t, "%s, or quote it as a string", msg)
+			}
+			return t.text, "timestamp", nil
+		}
+	}
+	return "", "", p.errorf(t, "expected a value, found %s", t.describe())
+}
+
+// isRelativeTime reports whether a word is now or today, with or without
+// offsets.
+func isRelativeTime(word string) bool {
+	for _, base := range []string{"now", "today"} {
+		if rest, ok := strings.CutPrefix(word, base); ok && (rest == "" || rest[0] == '+' || rest[0] == '-') {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTypedValue reads the arguments of a typed value like timestamp("now-7d")
+// after its name. Geopoints take two numbers, the others a string.
+func (p *queryParser) parseTypedValue(name queryToken) (string, string, error) {
+	valueType, ok := typedValueFuncs[strings.ToLower(name.text)]
+	if !ok {
+		return "", "", p.errorf(name, "unknown value type %q, expected timestamp, ref, geopoint or json", name.text)
+	}
+	p.next() // (
+
+	var value string
+	if valueType == "geopoint" {
+		var coords []string
+		for i := 0; i < 2; i++ {
+			if i > 0 {
+				if err := p.expectPunct(","); err != nil {
+					return "", "", err
+				}
+			}
+			t := p.next()
+			if t.kind != tokenNumber {
+				return "", "", p.errorf(t, "expected a number, found %s", t.describe())
+			}
+			coords = append(coords, t.text)
+		}
+		value = strings.Join(coords, ", ")
+	} else {
+		t := p.next()
+		if t.kind != tokenString {
+			return "", "", p.errorf(t, "expected a string in quotes, found %s", t.describe())
+		}
+		value = t.text
+	}
+
+	if err := p.expectPunct(")"); err != nil {
+		return "", "", err
+	}
+	// Parameters are checked once bound
+	if msg := checkTypedValue(value, valueType); msg != "" && !QueryParamPattern.MatchString(value) {
+		return "", "", p.errorf(name, "%s", msg)
+	}
+	return value, valueType, nil
+}
+
+func (p *queryParser) parseLimit() (int, error) {
+	t := p.next()
+	limit, err := strconv.Atoi(t.text)
+	if t.kind != tokenNumber || err != nil || limit <= 0 {
+		return 0, p.errorf(t, "expected a positive whole number, found %s", t.describe())
+	}
+	return limit, nil
+}
+
+// parseChain parses JS SDK calls: .where(field, op, value) .orderBy(field[, dir]) .limit(n)
+func (p *queryParser) parseChain() (QueryOptions, error) {
+	var opts QueryOptions
+	for i := 0; p.peek().kind != tokenEOF; i++ {
+		if dot := p.peek(); dot.kind == tokenPunct && dot.text == "." {
+			p.next()
+		} else if i > 0 {
+			return opts, p.errorf(dot, "expected \".\", found %s", dot.describe())
+		}
+
+		method := p.next()
+		if method.kind != tokenIdent {
+			return opts, p.errorf(method, "expected where, orderBy or limit, found %s", method.describe())
+		}
+		if err := p.expectPunct("("); err != nil {
+			return opts, err
+		}
+
+		switch method.text {
+		case "where":
+			field, err := p.parseFieldArg()
+			if err != nil {
+				return opts, err
+			}
+			if err := p.expectPunct(","); err != nil {
+				return opts, err
+			}
+			opToken := p.peek()
+			op, err := p.parseStringArg("an operator")
+			if err != nil {
+				return opts, err
+			}
+			if op == "=" {
+				op = "=="
+			}
+			if !isQueryOperator(op) {
+				return opts, p.errorf(opToken, "unknown operator %q", op)
+			}
+			if err := p.expectPunct(","); err != nil {
+				return opts, err
+			}
+			value, valueType, err := p.parseValue()
+			if err != nil {
+				return opts, err
+			}
+			opts.Filters = append(opts.Filters, QueryFilter{Field: field, Operator: op, Value: value, ValueType: valueType})
+		case "orderBy":
+			field, err := p.parseFieldArg()
+			if err != nil {
+				return opts, err
+			}
+			opts.OrderBy = field
+			opts.OrderDir = "ASC"
+			if comma := p.peek(); comma.kind == tokenPunct && comma.text == "," {
+				p.next()
+				dirToken := p.peek()
+				dir, err := p.parseStringArg(`"asc" or "desc"`)
+				if err != nil {
+					return opts, err
+				}
+				if !strings.EqualFold(dir, "asc") && !strings.EqualFold(dir, "desc") {
+					return opts, p.errorf(dirToken, "expected \"asc\" or \"desc\", found %q", dir)
+				}
+				opts.OrderDir = strings.ToUpper(dir)
+			}
+		case "limit":
+			limit, err := p.parseLimit()
+			if err != nil {
+				return opts, err
+			}
+			opts.Limit = limit
+		default:
+			return opts, p.errorf(method, "unknown method %q, expected where, orderBy or limit", method.text)
+		}
+
+		if err := p.expectPunct(")"); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+// parseFieldArg reads a field name in quotes or, as a JS template literal,
+// in backquotes.
+func (p *queryParser) parseFieldArg() (string, error) {
+	if t := p.peek(); t.kind == tokenField && t.text != "" {
+		p.next()
+		return t.text, nil
+	}
+	return p.parseStringArg("a field name")
+}
+
+func (p *queryParser) parseStringArg(what string) (string, error) {
+	t := p.next()
+	if t.kind != tokenString || t.text == "" {
+		return "", p.errorf(t, "expected %s in quotes, found %s", what, t.describe())
+	}
+	return t.text, nil
+}
+
+// FormatQuery writes query options in the compact form of ParseQuery.
+func FormatQuery(opts QueryOptions) string {
+	var parts []string
+	for i, f := range opts.Filters {
+		keyword := "and"
+		if i == 0 {
+			keyword = "where"
+		}
+		parts = append(parts, keyword, formatQueryField(f.Field), f.Operator, formatQueryValue(f))
+	}
+	if opts.OrderBy != "" {
+		parts = append(parts, "order by", formatQueryField(opts.OrderBy))
+		if strings.HasPrefix(strings.ToUpper(opts.OrderDir), "DESC") {
+			parts = append(parts, "desc")
+		}
+	}
+	if opts.Limit > 0 {
+		parts = append(parts, "limit", strconv.Itoa(opts.Limit))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatQueryField writes a field bare when ParseQuery would read it back as a field.
+func formatQueryField(field string) string {
+	runes := []rune(field)
+	plain := len(runes) > 0 && isIdentStart(runes[0]) && runes[0] != '$' && !compactKeywords[strings.ToLower(field)]
+	for _, r := range runes {
+		plain = plain && isIdentPart(r)
+	}
+	if plain {
+		return field
+	}
+	return "`" + strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(field) + "`"
+}
+
+// formatQueryValue writes a filter value so that it parses back to the same
+// Firestore value.
+func formatQueryValue(f QueryFilter) string {
+	value := fmt.Sprintf("%v", f.Value)
+	switch f.ValueType {
+	case "string":
+		return strconv.Quote(value)
+	case "integer", "double":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return value
+		}
+		return strconv.Quote(value)
+	case "boolean":
+		return strconv.FormatBool(strings.ToLower(value) == "true" || value == "1")
+	case "null":
+		return "null"
+	case "timestamp", "json":
+		return f.ValueType + "(" + strconv.Quote(value) + ")"
+	case "reference":
+		return "ref(" + strconv.Quote(value) + ")"
+	case "geopoint":
+		if _, _, err := parseQueryGeoPoint(value); err == nil {
+			return "geopoint(" + value + ")"
+		}
+		return strconv.Quote(value)
+	case "array":
+		var items []string
+		for _, item := range queryListItems(f.Value) {
+			items = append(items, formatListItem(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return formatAutoValue(strings.TrimSpace(value))
+}
+
+// formatListItem writes an item of a list value so that it parses back to
+// the same item.
+func formatListItem(item interface{}) string {
+	switch v := item.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		text := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".eEnN") {
+			text += ".0" // Read back as a double, not an integer
+		}
+		return text
+	case string:
+		if queryParamToken(v) {
+			return v
+		}
+		return strconv.Quote(v)
+	}
+	return formatAutoValue(fmt.Sprintf("%v", item))
+}
+
+// QueryValueText is a filter value as text, as shown and edited in the query
+// builder. Lists are written like ["a,b", 1].
+func QueryValueText(f QueryFilter) string {
+	if _, ok := f.Value.([]interface{}); ok {
+		return formatQueryValue(f)
+	}
+	return fmt.Sprintf("%v", f.Value)
+}
+
+// formatAutoValue writes an auto-typed value: as a literal when it would be
+// detected as a number, boolean or null, otherwise as a string.
+func formatAutoValue(value string) string {
+	lower := strings.ToLower(value)
+	switch {
+	case value == "" || lower == "null":
+		return "null"
+	case lower == "true" || lower == "false":
+		return lower
+	case queryParamToken(value):
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		if tokens, err := tokenizeQuery(value); err == nil && len(tokens) == 2 && tokens[0].kind == tokenNumber {
+			return value
+		}
+	}
+	return strconv.Quote(value)
+}
+
+// queryParamToken reports whether value is a single parameter like $userId.
+func queryParamToken(value string) bool {
+	runes := []rune(value)
+	if len(runes) < 2 || runes[0] != '$' {
+		return false
+	}
+	for _, r := range runes[1:] {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return false
+		}
+	}
+	return true
+}
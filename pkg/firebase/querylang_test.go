@@ -0,0 +1,200 @@
+package firebase
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	active := QueryFilter{Field: "status", Operator: "==", Value: "active", ValueType: "string"}
+	adult := QueryFilter{Field: "age", Operator: ">=", Value: "18", ValueType: "integer"}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected QueryOptions
+	}{
+		{"empty", "", QueryOptions{}},
+		{"compact", `where status == "active" and age >= 18 order by created desc limit 100`,
+			QueryOptions{Filters: []QueryFilter{active, adult}, OrderBy: "created", OrderDir: "DESC", Limit: 100}},
+		{"chain", `.where('status', '==', 'active').where('age', '>=', 18).orderBy('created', 'desc').limit(100)`,
+			QueryOptions{Filters: []QueryFilter{active, adult}, OrderBy: "created", OrderDir: "DESC", Limit: 100}},
+		{"chain without leading dot", `where("status", "=", "active")`,
+			QueryOptions{Filters: []QueryFilter{active}}},
+		{"keywords ignore case", `WHERE status = 'active' ORDER BY name`,
+			QueryOptions{Filters: []QueryFilter{active}, OrderBy: "name", OrderDir: "ASC"}},
+		{"word operators and lists", `where tags array-contains-any ["a", "b"] and role not in [1, 2.5] and ok in [true, null]`,
+			QueryOptions{Filters: []QueryFilter{
+				{Field: "tags", Operator: "array-contains-any", Value: []interface{}{"a", "b"}, ValueType: "array"},
+				{Field: "role", Operator: "not-in", Value: []interface{}{int64(1), 2.5}, ValueType: "array"},
+				{Field: "ok", Operator: "in", Value: []interface{}{true, nil}, ValueType: "array"},
+			}}},
+		{"list items with commas", `where a in ["x,y", "z"]`,
+			QueryOptions{Filters: []QueryFilter{{Field: "a", Operator: "in", Value: []interface{}{"x,y", "z"}, ValueType: "array"}}}},
+		{"chain with odd field names", ".where('first name', 'in', ['x,y', $z]).where(`a.b-c`, '==', 1).orderBy(`last name`, 'desc')",
+			QueryOptions{Filters: []QueryFilter{
+				{Field: "first name", Operator: "in", Value: []interface{}{"x,y", "$z"}, ValueType: "array"},
+				{Field: "a.b-c", Operator: "==", Value: "1", ValueType: "integer"},
+			}, OrderBy: "last name", OrderDir: "DESC"}},
+		{"backquoted field, nested field and parameter", "where `first name` != $name and address.city < -1.5",
+			QueryOptions{Filters: []QueryFilter{
+				{Field: "first name", Operator: "!=", Value: "$name", ValueType: "auto"},
+				{Field: "address.city", Operator: "<", Value: "-1.5", ValueType: "double"},
+			}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQuery(tt.input)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseQuery(%q) = %+v, expected %+v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		column  int
+		message string
+	}{
+		{`where status active`, 14, `expected an operator after "status", found "active"`},
+		{`where status == "active`, 17, "unterminated string"},
+		{`where age > 18 limit 0`, 22, `expected a positive whole number, found "0"`},
+		{`where age ! 18`, 11, `unexpected "!", expected "!="`},
+		{`where age > 18 sort by age`, 16, `unexpected "sort", expected where, and, order by or limit`},
+		{`where order == 1`, 7, `expected a field name, found "order"`},
+		{`order age`, 7, `expected "by", found "age"`},
+		{`where tags in ["a", "b"`, 24, `expected "," or "]", found end of query`},
+		{`where age ~ 1`, 11, `unexpected '~'`},
+		{`.where('age', 'like', 1)`, 15, `unknown operator "like"`},
+		{`.where('age', '>', 1).select('a')`, 23, `unknown method "select", expected where, orderBy or limit`},
+		{`.orderBy('age', 'up')`, 17, `expected "asc" or "desc", found "up"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseQuery(tt.input)
+			var syntaxErr *QuerySyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("ParseQuery(%q) error = %v, expected a syntax error", tt.input, err)
+			}
+			if syntaxErr.Column != tt.column || syntaxErr.Message != tt.message {
+				t.Errorf("ParseQuery(%q) error = %q at column %d, expected %q at column %d",
+					tt.input, syntaxErr.Message, syntaxErr.Column, tt.message, tt.column)
+			}
+		})
+	}
+}
+
+func TestFormatQueryRoundTrip(t *testing.T) {
+	opts := QueryOptions{
+		Filters: []QueryFilter{
+			{Field: "status", Operator: "==", Value: `say "hi"`, ValueType: "string"},
+			{Field: "first name", Operator: "!=", Value: "$name", ValueType: "auto"},
+			{Field: "where", Operator: "in", Value: "1, two", ValueType: "array"},
+			{Field: "score", Operator: ">", Value: "2.5", ValueType: "double"},
+			{Field: "deleted", Operator: "==", Value: "", ValueType: "null"},
+		},
+		OrderBy:  "created",
+		OrderDir: "DESC",
+		Limit:    20,
+	}
+
+	text := FormatQuery(opts)
+	expected := "where status == \"say \\\"hi\\\"\" and `first name` != $name and `where` in [1, \"two\"] " +
+		"and score > 2.5 and deleted == null order by created desc limit 20"
+	if text != expected {
+		t.Fatalf("FormatQuery() = %q, expected %q", text, expected)
+	}
+
+	got, err := ParseQuery(text)
+	if err != nil {
+		t.Fatalf("ParseQuery(FormatQuery()) error: %v", err)
+	}
+	if got.Filters[0].Value != `say "hi"` || got.Filters[1].Field != "first name" || got.Filters[2].Field != "where" {
+		t.Errorf("ParseQuery(FormatQuery()) = %+v, expected the same filters", got.Filters)
+	}
+	if !reflect.DeepEqual(got.Filters[2].Value, []interface{}{int64(1), "two"}) || got.OrderDir != "DESC" || got.Limit != 20 {
+		t.Errorf("ParseQuery(FormatQuery()) = %+v, expected the same query", got)
+	}
+}
+
+func TestQueryListValues(t *testing.T) {
+	got, err := ParseQuery(`where a in ["x,y", 2.0, $b]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := got.Filters[0]
+	if text := QueryValueText(f); text != `["x,y", 2.0, $b]` {
+		t.Errorf("QueryValueText() = %q, expected the list as written", text)
+	}
+
+	// The builder keeps edited lists as text, which must mean the same
+	for _, value := range []interface{}{f.Value, QueryValueText(f)} {
+		expected := map[string]interface{}{"arrayValue": map[string]interface{}{"values": []map[string]interface{}{
+			{"stringValue": "x,y"}, {"doubleValue": 2.0}, {"stringValue": "$b"},
+		}}}
+		if fv := toFirestoreValue(value, "array"); !reflect.DeepEqual(fv, expected) {
+			t.Errorf("toFirestoreValue(%#v) = %v, expected %v", value, fv, expected)
+		}
+	}
+}
+
+func TestParseQueryTypedValues(t *testing.T) {
+	text := `where created > timestamp("now-7d") and author == ref("users/ana") and at == geopoint(48.85, -2.5) and meta == json('{"a": 1}')`
+	got, err := ParseQuery(text)
+	if err != nil {
+		t.Fatalf("ParseQuery() error: %v", err)
+	}
+	expected := []QueryFilter{
+		{Field: "created", Operator: ">", Value: "now-7d", ValueType: "timestamp"},
+		{Field: "author", Operator: "==", Value: "users/ana", ValueType: "reference"},
+		{Field: "at", Operator: "==", Value: "48.85, -2.5", ValueType: "geopoint"},
+		{Field: "meta", Operator: "==", Value: `{"a": 1}`, ValueType: "json"},
+	}
+	if !reflect.DeepEqual(got.Filters, expected) {
+		t.Errorf("ParseQuery() filters = %+v, expected %+v", got.Filters, expected)
+	}
+
+	// Dates and relative times don't need timestamp()
+	bare, err := ParseQuery(`where created > 2025-01-01 and created < now-7d and updated >= today+1w-12h and at == 2025-01-01T10:00:00Z and now == 1`)
+	if err != nil {
+		t.Fatalf("ParseQuery() error: %v", err)
+	}
+	for i, value := range []string{"2025-01-01", "now-7d", "today+1w-12h", "2025-01-01T10:00:00Z"} {
+		if f := bare.Filters[i]; f.Value != value || f.ValueType != "timestamp" {
+			t.Errorf("filter %d = %+v, expected the timestamp %s", i, f, value)
+		}
+	}
+	if f := bare.Filters[4]; f.Field != "now" {
+		t.Errorf("filter 4 = %+v, expected now as a field name", f)
+	}
+
+	formatted := FormatQuery(got)
+	if again, err := ParseQuery(formatted); err != nil || !reflect.DeepEqual(again.Filters, expected) {
+		t.Errorf("ParseQuery(%q) = %+v, %v, expected the same filters", formatted, again.Filters, err)
+	}
+
+	for _, tt := range []struct {
+		input   string
+		message string
+	}{
+		{`where a == timestamp("soon")`, `"soon" is not a date, an RFC 3339 time or now-7d`},
+		{`where a == point(1, 2)`, `unknown value type "point", expected timestamp, ref, geopoint or json`},
+		{`where a == geopoint(1)`, `expected ",", found ")"`},
+		{`where a in [timestamp("now")]`, `timestamp values can't be list items`},
+		{`where a > 2025-02-30`, `"2025-02-30" is not a date`},
+		{`where a > now-7x`, `invalid offset "-7x" in "now-7x", expected e.g. -7d or +2h, or quote it as a string`},
+	} {
+		if _, err := ParseQuery(tt.input); err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("ParseQuery(%q) error = %v, expected %q", tt.input, err, tt.message)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package firebase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryTimeLayouts are the absolute timestamps ParseQueryTimestamp accepts.
+// Layouts without a zone are read as UTC.
+var queryTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// queryTimeOffset matches one offset of a relative timestamp, like -7d.
+var queryTimeOffset = regexp.MustCompile(`^([+-])(\d+)([smhdw])`)
+
+var queryTimeUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// ParseQueryTimestamp reads a timestamp filter value: an RFC 3339 date-time,
+// a date or date-time without a zone (UTC), or "now" or "today" (midnight UTC)
+// followed by offsets like now-7d or today+1w-12h. Units are s, m, h, d and w.
+func ParseQueryTimestamp(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range queryTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	lower := strings.ToLower(s)
+	var t time.Time
+	switch {
+	case strings.HasPrefix(lower, "now"):
+		t, lower = now.UTC(), lower[len("now"):]
+	case strings.HasPrefix(lower, "today"):
+		t, lower = now.UTC().Truncate(24*time.Hour), lower[len("today"):]
+	default:
+		return time.Time{}, fmt.Errorf("%q is not a date, an RFC 3339 time or now-7d", s)
+	}
+	for lower != "" {
+		m := queryTimeOffset.FindStringSubmatch(lower)
+		if m == nil {
+			return time.Time{}, fmt.Errorf("invalid offset %q in %q, expected e.g. -7d or +2h", lower, s)
+		}
+		n, _ := strconv.Atoi(m[2])
+		d := time.Duration(n) * queryTimeUnits[m[3]]
+		if m[1] == "-" {
+			d = -d
+		}
+		t = t.Add(d)
+		lower = lower[len(m[0]):]
+	}
+	return t, nil
+}
+
+// parseQueryReference reads a reference filter value: a document path or a
+// full document name. Paths are returned without surrounding slashes.
+func parseQueryReference(s string) (string, error) {
+	path := strings.Trim(strings.TrimSpace(s), "/")
+	if isDocumentName(path) {
+		return path, nil
+	}
+	if path == "" || strings.Count(path, "/")%2 == 0 || strings.Contains(path, "//") {
+		return "", fmt.Errorf("%q is not a document path like users/abc", s)
+	}
+	return path, nil
+}
+
+// parseQueryGeoPoint reads a geopoint filter value: "latitude, longitude".
+func parseQueryGeoPoint(s string) (float64, float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) == 2 {
+		lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		lng, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err1 == nil && err2 == nil {
+			if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+				return 0, 0, fmt.Errorf("%q is out of range: latitude -90..90, longitude -180..180", s)
+			}
+			return lat, lng, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("%q is not a geopoint like 48.8566, 2.3522", s)
+}
+
+// parseQueryJSON reads a JSON literal filter value. Objects become maps and
+// the wrappers of ImportDocuments ({"$ref": path}, {"$timestamp": s}, ...)
+// are recognized.
+func parseQueryJSON(s string) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid JSON: unexpected text after the value")
+	}
+	value, err := encodeImportValue(v, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON value: %v", err)
+	}
+	return value, nil
+}
+
+// typedQueryValue converts a value of one of the timestamp, reference,
+// geopoint and json types to Firestore's typed value format. References are
+// kept relative until RunQuery resolves them in the current project.
+func typedQueryValue(s, valueType string, now time.Time) (map[string]interface{}, error) {
+	switch valueType {
+	case "timestamp":
+		t, err := ParseQueryTimestamp(s, now)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"timestampValue": t.Format(time.RFC3339Nano)}, nil
+	case "reference":
+		path, err := parseQueryReference(s)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"referenceValue": path}, nil
+	case "geopoint":
+		lat, lng, err := parseQueryGeoPoint(s)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"geoPointValue": map[string]interface{}{"latitude": lat, "longitude": lng},
+		}, nil
+	case "json":
+		return parseQueryJSON(s)
+	}
+	return nil, fmt.Errorf("unknown value type %q", valueType)
+}
+
+// isTypedQueryValue reports whether valueType is handled by typedQueryValue.
+func isTypedQueryValue(valueType string) bool {
+	switch valueType {
+	case "timestamp", "reference", "geopoint", "json":
+		return true
+	}
+	return false
+}
+
+// QueryValuePreview describes how a filter value of the timestamp,
+// reference, geopoint or json type will be sent, e.g. the time now-7d
+// stands for. Other types have no preview and return "".
+func QueryValuePreview(value, valueType string, now time.Time) (string, error) {
+	if !isTypedQueryValue(valueType) {
+		return "", nil
+	}
+	fv, err := typedQueryValue(value, valueType, now)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case fv["timestampValue"] != nil:
+		return fmt.Sprintf("%v", fv["timestampValue"]), nil
+	case fv["referenceValue"] != nil:
+		return fmt.Sprintf("→ %v", fv["referenceValue"]), nil
+	case fv["geoPointValue"] != nil:
+		geo := fv["geoPointValue"].(map[string]interface{})
+		return formatGeoPoint(geo["latitude"].(float64), geo["longitude"].(float64)), nil
+	}
+	return describeFirestoreValue(fv), nil
+}
+
+// formatGeoPoint writes a geopoint with hemispheres, e.g. 48.8566°N, 2.3522°E.
+func formatGeoPoint(lat, lng float64) string {
+	ns, ew := "N", "E"
+	if lat < 0 {
+		ns, lat = "S", -lat
+	}
+	if lng < 0 {
+		ew, lng = "W", -lng
+	}
+	return fmt.Sprintf("%s°%s, %s°%s", strconv.FormatFloat(lat, 'f', -1, 64), ns, strconv.FormatFloat(lng, 'f', -1, 64), ew)
+}
+
+// describeFirestoreValue names the type and size of a typed value.
+func describeFirestoreValue(fv map[string]interface{}) string {
+	if m, ok := fv["mapValue"].(map[string]interface{}); ok {
+		fields, _ := m["fields"].(map[string]interface{})
+		return fmt.Sprintf("map of %d field(s)", len(fields))
+	}
+	if a, ok := fv["arrayValue"].(map[string]interface{}); ok {
+		values, _ := a["values"].([]interface{})
+		return fmt.Sprintf("array of %d value(s)", len(values))
+	}
+	for key := range fv {
+		return strings.TrimSuffix(key, "Value")
+	}
+	return ""
+}
+
+// resolveQueryReferences turns the relative reference values of a structured
+// query into document names under docsRoot ("projects/p/databases/d/documents").
+func resolveQueryReferences(v interface{}, docsRoot string) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			if ref, ok := item.(string); ok && key == "referenceValue" && !isDocumentName(ref) {
+				val[key] = docsRoot + "/" + strings.Trim(ref, "/")
+				continue
+			}
+			resolveQueryReferences(item, docsRoot)
+		}
+	case []interface{}:
+		for _, item := range val {
+			resolveQueryReferences(item, docsRoot)
+		}
+	case []map[string]interface{}:
+		for _, item := range val {
+			resolveQueryReferences(item, docsRoot)
+		}
+	}
+}
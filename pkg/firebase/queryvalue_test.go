@@ -0,0 +1,124 @@
+package firebase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQueryTimestamp(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		input    string
+		expected string
+		err      string
+	}{
+		{"2025-01-01", "2025-01-01T00:00:00Z", ""},
+		{"2025-01-01T10:20:30+02:00", "2025-01-01T08:20:30Z", ""},
+		{"2025-01-01 10:20", "2025-01-01T10:20:00Z", ""},
+		{"now", "2026-10-18T15:30:00Z", ""},
+		{"now-7d", "2026-10-11T15:30:00Z", ""},
+		{"NOW+2h-30m", "2026-10-18T17:00:00Z", ""},
+		{"today-1w", "2026-10-11T00:00:00Z", ""},
+		{"yesterday", "", "is not a date"},
+		{"now-7y", "", `invalid offset "-7y"`},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseQueryTimestamp(tt.input, now)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseQueryTimestamp(%q) error = %v, expected %q", tt.input, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got.Format(time.RFC3339) != tt.expected {
+			t.Errorf("ParseQueryTimestamp(%q) = %v, %v, expected %s", tt.input, got, err, tt.expected)
+		}
+	}
+}
+
+func TestTypedQueryValue(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value     string
+		valueType string
+		expected  map[string]interface{}
+		err       string
+	}{
+		{"now-1d", "timestamp", map[string]interface{}{"timestampValue": "2026-10-17T00:00:00Z"}, ""},
+		{"/users/abc/", "reference", map[string]interface{}{"referenceValue": "users/abc"}, ""},
+		{"users", "reference", nil, "is not a document path"},
+		{"48.85, -2.5", "geopoint", map[string]interface{}{"geoPointValue": map[string]interface{}{"latitude": 48.85, "longitude": -2.5}}, ""},
+		{"91, 0", "geopoint", nil, "out of range"},
+		{"north", "geopoint", nil, "is not a geopoint"},
+		{`{"a": 1, "b": [true]}`, "json", map[string]interface{}{"mapValue": map[string]interface{}{"fields": map[string]interface{}{
+			"a": map[string]interface{}{"integerValue": "1"},
+			"b": map[string]interface{}{"arrayValue": map[string]interface{}{"values": []interface{}{map[string]interface{}{"booleanValue": true}}}},
+		}}}, ""},
+		{`{"a": 1} x`, "json", nil, "unexpected text"},
+		{`{"a": }`, "json", nil, "invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		got, err := typedQueryValue(tt.value, tt.valueType, now)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("typedQueryValue(%q, %s) error = %v, expected %q", tt.value, tt.valueType, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("typedQueryValue(%q, %s) = %v, %v, expected %v", tt.value, tt.valueType, got, err, tt.expected)
+		}
+	}
+
+	// Invalid typed values fall back to strings, which ValidateQuery reports
+	if got := toFirestoreValue("soon", "timestamp"); got["stringValue"] != "soon" {
+		t.Errorf("toFirestoreValue(soon, timestamp) = %v, expected a string", got)
+	}
+}
+
+func TestQueryValuePreview(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value, valueType, expected string
+	}{
+		{"now-7d", "timestamp", "2026-10-11T12:00:00Z"},
+		{"users/abc", "reference", "→ users/abc"},
+		{"-33.86, 151.2", "geopoint", "33.86°S, 151.2°E"},
+		{`{"a": 1, "b": 2}`, "json", "map of 2 field(s)"},
+		{`[1, 2, 3]`, "json", "array of 3 value(s)"},
+		{"18", "integer", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := QueryValuePreview(tt.value, tt.valueType, now)
+		if err != nil || got != tt.expected {
+			t.Errorf("QueryValuePreview(%q, %s) = %q, %v, expected %q", tt.value, tt.valueType, got, err, tt.expected)
+		}
+	}
+}
+
+func TestResolveQueryReferences(t *testing.T) {
+	query := buildStructuredQuery("posts", QueryOptions{Filters: []QueryFilter{
+		{Field: "author", Operator: "==", Value: "users/ana", ValueType: "reference"},
+		{Field: "tags", Operator: "in", Value: `[{"$ref": "tags/go"}, "projects/p/databases/(default)/documents/tags/js"]`, ValueType: "json"},
+	}})
+	resolveQueryReferences(query, "projects/demo/databases/(default)/documents")
+
+	filters := query["where"].(map[string]interface{})["compositeFilter"].(map[string]interface{})["filters"].([]map[string]interface{})
+	author := filters[0]["fieldFilter"].(map[string]interface{})["value"]
+	if !reflect.DeepEqual(author, map[string]interface{}{"referenceValue": "projects/demo/databases/(default)/documents/users/ana"}) {
+		t.Errorf("author value = %v", author)
+	}
+	tags := filters[1]["fieldFilter"].(map[string]interface{})["value"].(map[string]interface{})["arrayValue"].(map[string]interface{})["values"].([]interface{})
+	expected := []interface{}{
+		map[string]interface{}{"referenceValue": "projects/demo/databases/(default)/documents/tags/go"},
+		map[string]interface{}{"referenceValue": "projects/p/databases/(default)/documents/tags/js"},
+	}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("tags values = %v, expected %v", tags, expected)
+	}
+}
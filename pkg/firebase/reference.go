@@ -0,0 +1,77 @@
+package firebase
+
+import (
+	"sort"
+	"strings"
+)
+
+// Reference is a Firestore document reference.
+type Reference struct {
+	Project  string
+	Database string
+	Path     string // Document path, e.g. users/abc
+}
+
+// ParseReference parses a referenceValue of the form
+// projects/<project>/databases/<database>/documents/<path>.
+func ParseReference(name string) (Reference, bool) {
+	parts := strings.SplitN(name, "/", 6)
+	if len(parts) != 6 || parts[0] != "projects" || parts[2] != "databases" || parts[4] != "documents" {
+		return Reference{}, false
+	}
+	if parts[1] == "" || parts[3] == "" || strings.Count(parts[5], "/")%2 != 1 {
+		return Reference{}, false // Not a document path
+	}
+	return Reference{Project: parts[1], Database: parts[3], Path: parts[5]}, true
+}
+
+// String returns the full resource name of the reference.
+func (r Reference) String() string {
+	return documentNameIn(r.Project, r.Database, r.Path)
+}
+
+// FieldReference is a reference held by a document field.
+type FieldReference struct {
+	Field string // jq-style path of the field, e.g. .author or .items[0].product
+	Reference
+}
+
+// DocumentReferences returns the references held by a document's typed fields,
+// including those nested in maps and arrays, sorted by field path.
+func DocumentReferences(fields map[string]interface{}) []FieldReference {
+	var refs []FieldReference
+	for name, value := range fields {
+		collectReferences(value, jqKeyPath(".", name), &refs)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Field < refs[j].Field })
+	return refs
+}
+
+func collectReferences(value interface{}, path string, refs *[]FieldReference) {
+	typed, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if name, ok := typed["referenceValue"].(string); ok {
+		if ref, ok := ParseReference(name); ok {
+			*refs = append(*refs, FieldReference{Field: path, Reference: ref})
+		}
+		return
+	}
+
+	if m, ok := typed["mapValue"].(map[string]interface{}); ok {
+		fields, _ := m["fields"].(map[string]interface{})
+		for name, v := range fields {
+			collectReferences(v, jqKeyPath(path, name), refs)
+		}
+		return
+	}
+
+	if a, ok := typed["arrayValue"].(map[string]interface{}); ok {
+		values, _ := a["values"].([]interface{})
+		for i, v := range values {
+			collectReferences(v, jqIndexPath(path, i), refs)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package firebase
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Reference
+		ok       bool
+	}{
+		{"document", "projects/demo/databases/(default)/documents/users/abc", Reference{"demo", "(default)", "users/abc"}, true},
+		{"subcollection document", "projects/demo/databases/eu/documents/users/abc/orders/o1", Reference{"demo", "eu", "users/abc/orders/o1"}, true},
+		{"collection path", "projects/demo/databases/(default)/documents/users", Reference{}, false},
+		{"no documents segment", "projects/demo/databases/(default)/users/abc", Reference{}, false},
+		{"plain path", "users/abc", Reference{}, false},
+		{"empty project", "projects//databases/(default)/documents/users/abc", Reference{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, ok := ParseReference(tt.input)
+			if ok != tt.ok || ref != tt.expected {
+				t.Errorf("ParseReference(%q) = %+v, %v, expected %+v, %v", tt.input, ref, ok, tt.expected, tt.ok)
+			}
+			if ok && ref.String() != tt.input {
+				t.Errorf("Reference.String() = %q, expected %q", ref.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestDocumentReferences(t *testing.T) {
+	ref := func(path string) map[string]interface{} {
+		return map[string]interface{}{"referenceValue": "projects/demo/databases/(default)/documents/" + path}
+	}
+	fields := map[string]interface{}{
+		"author": ref("users/ana"),
+		"title":  schemaStr("Hello"),
+		"items": map[string]interface{}{"arrayValue": map[string]interface{}{"values": []interface{}{
+			map[string]interface{}{"mapValue": map[string]interface{}{"fields": map[string]interface{}{"product": ref("products/p1")}}},
+			ref("products/p2"),
+		}}},
+		"first reviewer": ref("users/bob"),
+		"broken":         map[string]interface{}{"referenceValue": "users/ana"},
+	}
+
+	got := DocumentReferences(fields)
+	expected := []FieldReference{
+		{Field: `.author`, Reference: Reference{"demo", "(default)", "users/ana"}},
+		{Field: `.items[0].product`, Reference: Reference{"demo", "(default)", "products/p1"}},
+		{Field: `.items[1]`, Reference: Reference{"demo", "(default)", "products/p2"}},
+		{Field: `["first reviewer"]`, Reference: Reference{"demo", "(default)", "users/bob"}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("DocumentReferences() = %+v, expected %+v", got, expected)
+	}
+}
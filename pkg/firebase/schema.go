@@ -0,0 +1,270 @@
+package firebase
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Firestore value types as reported by schema inference.
+const (
+	TypeString    = "string"
+	TypeInteger   = "integer"
+	TypeDouble    = "double"
+	TypeBoolean   = "boolean"
+	TypeNull      = "null"
+	TypeTimestamp = "timestamp"
+	TypeMap       = "map"
+	TypeArray     = "array"
+	TypeReference = "reference"
+	TypeGeoPoint  = "geopoint"
+	TypeBytes     = "bytes"
+)
+
+// firestoreValueTypes maps the keys of Firestore's typed value format to type names.
+var firestoreValueTypes = map[string]string{
+	"stringValue":    TypeString,
+	"integerValue":   TypeInteger,
+	"doubleValue":    TypeDouble,
+	"booleanValue":   TypeBoolean,
+	"nullValue":      TypeNull,
+	"timestampValue": TypeTimestamp,
+	"mapValue":       TypeMap,
+	"arrayValue":     TypeArray,
+	"referenceValue": TypeReference,
+	"geoPointValue":  TypeGeoPoint,
+	"bytesValue":     TypeBytes,
+}
+
+// maxSchemaExamples is how many distinct example values are kept per field.
+const maxSchemaExamples = 3
+
+// SchemaField describes one field path observed in a sample of documents.
+type SchemaField struct {
+	Path     string         // Field path; array elements are path[], e.g. items[].price
+	Name     string         // Field name within its parent map ("" for array elements)
+	Parent   string         // Path of the enclosing map or array ("" at the top level)
+	Types    map[string]int // Values seen per Firestore type
+	Present  int            // Documents containing the path
+	Values   int            // Values seen (more than Present for array elements)
+	Nulls    int            // Null values seen
+	Examples []string       // Up to maxSchemaExamples distinct scalar values
+}
+
+// Schema is the structure inferred from a sample of a collection.
+type Schema struct {
+	Collection string
+	Documents  int            // Documents sampled
+	Fields     []*SchemaField // Sorted by path
+}
+
+// SampleSchema infers the schema of a collection from up to limit of its documents.
+func (c *Client) SampleSchema(collectionPath string, limit int) (*Schema, error) {
+	docs, err := c.sampleDocuments(collectionPath, limit, treeExportPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return InferSchema(collectionPath, docs), nil
+}
+
+// sampleDocuments reads the first limit documents of a collection, pageSize
+// at a time, since one request returns at most a page.
+func (c *Client) sampleDocuments(collectionPath string, limit, pageSize int) ([]Document, error) {
+	var docs []Document
+	after := ""
+	for len(docs) < limit {
+		want := min(pageSize, limit-len(docs))
+		page, err := c.ListDocumentsAfter(collectionPath, after, want)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, page...)
+		if len(page) < want {
+			break
+		}
+		after = page[len(page)-1].Path
+	}
+	return docs, nil
+}
+
+// InferSchema walks every field of the documents, including nested maps and
+// array elements, and records the types, presence and examples of each path.
+func InferSchema(collection string, docs []Document) *Schema {
+	s := &Schema{Collection: collection, Documents: len(docs)}
+	fields := make(map[string]*SchemaField)
+
+	for _, doc := range docs {
+		seen := make(map[string]bool)
+		inferFields(fields, seen, "", doc.Fields)
+	}
+
+	for _, f := range fields {
+		s.Fields = append(s.Fields, f)
+	}
+	sort.Slice(s.Fields, func(i, j int) bool { return s.Fields[i].Path < s.Fields[j].Path })
+	return s
+}
+
+// inferFields records each field of a typed map under prefix.
+func inferFields(fields map[string]*SchemaField, seen map[string]bool, prefix string, typed map[string]interface{}) {
+	for key, value := range typed {
+		path := SchemaPathKey(key)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		if v, ok := value.(map[string]interface{}); ok {
+			inferValue(fields, seen, prefix, key, path, v)
+		}
+	}
+}
+
+// inferValue records one typed value at path and descends into maps and arrays.
+func inferValue(fields map[string]*SchemaField, seen map[string]bool, parent, name, path string, value map[string]interface{}) {
+	f := fields[path]
+	if f == nil {
+		f = &SchemaField{Path: path, Name: name, Parent: parent, Types: make(map[string]int)}
+		fields[path] = f
+	}
+	if !seen[path] {
+		seen[path] = true
+		f.Present++
+	}
+	f.Values++
+
+	typ, raw := firestoreValueType(value)
+	f.Types[typ]++
+
+	switch typ {
+	case TypeNull:
+		f.Nulls++
+	case TypeMap:
+		m, _ := raw.(map[string]interface{})
+		children, _ := m["fields"].(map[string]interface{})
+		inferFields(fields, seen, path, children)
+	case TypeArray:
+		m, _ := raw.(map[string]interface{})
+		values, _ := m["values"].([]interface{})
+		for _, item := range values {
+			if v, ok := item.(map[string]interface{}); ok {
+				inferValue(fields, seen, path, "", path+"[]", v)
+			}
+		}
+	default:
+		f.addExample(formatSchemaExample(typ, raw))
+	}
+}
+
+// firestoreValueType returns the type name and raw value of a typed Firestore value.
+func firestoreValueType(value map[string]interface{}) (string, interface{}) {
+	for key, raw := range value {
+		if typ, ok := firestoreValueTypes[key]; ok {
+			return typ, raw
+		}
+	}
+	return "unknown", nil
+}
+
+// addExample keeps the first distinct example values.
+func (f *SchemaField) addExample(example string) {
+	if len(f.Examples) >= maxSchemaExamples {
+		return
+	}
+	for _, e := range f.Examples {
+		if e == example {
+			return
+		}
+	}
+	f.Examples = append(f.Examples, example)
+}
+
+// formatSchemaExample shows a raw value compactly: strings quoted, references as paths.
+func formatSchemaExample(typ string, raw interface{}) string {
+	var s string
+	switch typ {
+	case TypeString:
+		b, _ := json.Marshal(raw)
+		s = string(b)
+	case TypeReference:
+		s = fmt.Sprint(raw)
+		if i := strings.Index(s, "/documents/"); i >= 0 {
+			s = s[i+len("/documents/"):]
+		}
+	case TypeGeoPoint:
+		m, _ := raw.(map[string]interface{})
+		s = fmt.Sprintf("%v,%v", m["latitude"], m["longitude"])
+	default:
+		s = fmt.Sprint(raw)
+	}
+
+	if runes := []rune(s); len(runes) > 40 {
+		s = string(runes[:39]) + "…"
+	}
+	return s
+}
+
+// simpleFieldName matches field names Firestore accepts in a path without quoting.
+var simpleFieldName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SchemaPathKey quotes a field name with backticks when a Firestore field path requires it.
+func SchemaPathKey(key string) string {
+	if simpleFieldName.MatchString(key) {
+		return key
+	}
+	return "`" + strings.ReplaceAll(key, "`", "\\`") + "`"
+}
+
+// TypeNames returns the non-null types seen, most frequent first.
+func (f *SchemaField) TypeNames() []string {
+	var names []string
+	for typ := range f.Types {
+		if typ != TypeNull {
+			names = append(names, typ)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if f.Types[names[i]] != f.Types[names[j]] {
+			return f.Types[names[i]] > f.Types[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Conflict reports whether the path holds values of more than one non-null type.
+func (f *SchemaField) Conflict() bool {
+	return len(f.TypeNames()) > 1
+}
+
+// Field returns the field at path, or nil if it was not seen.
+func (s *Schema) Field(path string) *SchemaField {
+	for _, f := range s.Fields {
+		if f.Path == path {
+			return f
+		}
+	}
+	return nil
+}
+
+// Children returns the fields of the map at path ("" for top-level fields).
+func (s *Schema) Children(path string) []*SchemaField {
+	var children []*SchemaField
+	for _, f := range s.Fields {
+		if f.Parent == path && f.Name != "" {
+			children = append(children, f)
+		}
+	}
+	return children
+}
+
+// Conflicts returns how many fields have conflicting types.
+func (s *Schema) Conflicts() int {
+	n := 0
+	for _, f := range s.Fields {
+		if f.Conflict() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,113 @@
+package firebase
+
+import (
+	"reflect"
+	"testing"
+)
+
+func schemaDoc(fields map[string]interface{}) Document {
+	return Document{Fields: fields}
+}
+
+func schemaStr(s string) map[string]interface{} { return map[string]interface{}{"stringValue": s} }
+func schemaInt(s string) map[string]interface{} { return map[string]interface{}{"integerValue": s} }
+
+func TestInferSchema(t *testing.T) {
+	null := map[string]interface{}{"nullValue": nil}
+	docs := []Document{
+		schemaDoc(map[string]interface{}{
+			"age":  schemaInt("30"),
+			"name": schemaStr("Ana"),
+			"address": map[string]interface{}{"mapValue": map[string]interface{}{
+				"fields": map[string]interface{}{"city": schemaStr("Tirana")},
+			}},
+			"tags": map[string]interface{}{"arrayValue": map[string]interface{}{
+				"values": []interface{}{schemaStr("a"), schemaStr("b")},
+			}},
+		}),
+		schemaDoc(map[string]interface{}{
+			"age":        schemaStr("41"),
+			"name":       null,
+			"first name": schemaStr("Bob"),
+			"owner":      map[string]interface{}{"referenceValue": "projects/demo/databases/(default)/documents/users/ana"},
+		}),
+	}
+
+	s := InferSchema("users", docs)
+	if s.Documents != 2 {
+		t.Errorf("Documents = %d, expected 2", s.Documents)
+	}
+
+	fields := make(map[string]*SchemaField)
+	var paths []string
+	for _, f := range s.Fields {
+		fields[f.Path] = f
+		paths = append(paths, f.Path)
+	}
+	expected := []string{"`first name`", "address", "address.city", "age", "name", "owner", "tags", "tags[]"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("paths = %q, expected %q", paths, expected)
+	}
+
+	if f := fields["age"]; !f.Conflict() || f.Present != 2 || !reflect.DeepEqual(f.Examples, []string{"30", `"41"`}) {
+		t.Errorf("age = %+v, expected a conflict with both examples", f)
+	}
+	if f := fields["name"]; f.Conflict() || f.Nulls != 1 || !reflect.DeepEqual(f.TypeNames(), []string{TypeString}) {
+		t.Errorf("name = %+v, expected string with one null", f)
+	}
+	if f := fields["tags[]"]; f.Present != 1 || f.Values != 2 {
+		t.Errorf("tags[] present %d values %d, expected 1 and 2", f.Present, f.Values)
+	}
+	if f := fields["owner"]; !reflect.DeepEqual(f.Examples, []string{"users/ana"}) || f.Types[TypeReference] != 1 {
+		t.Errorf("owner = %+v, expected a reference to users/ana", f)
+	}
+	if s.Conflicts() != 1 {
+		t.Errorf("Conflicts() = %d, expected 1", s.Conflicts())
+	}
+}
+
+func TestSchemaPathKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"name", "name"},
+		{"_id2", "_id2"},
+		{"first name", "`first name`"},
+		{"2fa", "`2fa`"},
+		{"a`b", "`a\\`b`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := SchemaPathKey(tt.key); got != tt.expected {
+				t.Errorf("SchemaPathKey(%q) = %q, expected %q", tt.key, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSampleDocumentsPages(t *testing.T) {
+	c := newCopyClient(t)
+	for _, tc := range []struct {
+		limit, pageSize int
+		want            []string
+	}{
+		{limit: 2, pageSize: 1, want: []string{"users/alice", "users/bob"}},
+		{limit: 1, pageSize: 1, want: []string{"users/alice"}},
+		{limit: 5, pageSize: 1, want: []string{"users/alice", "users/bob"}},
+		{limit: 5, pageSize: 300, want: []string{"users/alice", "users/bob"}},
+	} {
+		docs, err := c.sampleDocuments("users", tc.limit, tc.pageSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, d := range docs {
+			got = append(got, d.Path)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("limit %d, page %d: got %v, want %v", tc.limit, tc.pageSize, got, tc.want)
+		}
+	}
+}
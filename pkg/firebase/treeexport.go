@@ -0,0 +1,391 @@
+package firebase
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tree export output formats.
+const (
+	TreeExportNDJSON = "ndjson" // One {"path", "data"} object per line
+	TreeExportDir    = "dir"    // One JSON file per document, mirroring document paths
+)
+
+const (
+	treeExportPageSize        = 300
+	treeExportCheckpointEvery = time.Second
+	treeExportManifestName    = ".lazyfire-export.json"
+)
+
+// TreeExportOptions configures ExportTree.
+type TreeExportOptions struct {
+	Root     string                   // Collection or document path to export
+	Target   string                   // Output file (*.ndjson) or directory
+	PageSize int                      // Documents fetched per request (default 300)
+	Progress func(TreeExportProgress) // Called at every checkpoint (optional)
+}
+
+// TreeExportProgress reports how far an export has got.
+type TreeExportProgress struct {
+	Format    string // TreeExportNDJSON or TreeExportDir
+	Documents int    // Documents written, including those from interrupted runs
+	Path      string // Document most recently written
+	Resumed   bool   // True when continuing from a manifest
+}
+
+// treeExportManifest records export progress so an interrupted export can resume.
+// It is a consistent snapshot: every collection is either done, in progress
+// (with the last written document as cursor), or not started.
+type treeExportManifest struct {
+	Root      string            `json:"root"`
+	Format    string            `json:"format"`
+	Documents int               `json:"documents"`
+	Bytes     int64             `json:"bytes,omitempty"` // NDJSON output size at this checkpoint
+	Cursors   map[string]string `json:"cursors"`         // Last written document per collection in progress
+	Done      []string          `json:"done"`            // Collections fully exported
+	UpdatedAt string            `json:"updatedAt"`
+}
+
+// treeExportLine is one NDJSON record.
+type treeExportLine struct {
+	Path string                 `json:"path"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// TreeExportFormat returns the output format implied by a target path.
+func TreeExportFormat(target string) string {
+	if strings.HasSuffix(strings.ToLower(target), ".ndjson") {
+		return TreeExportNDJSON
+	}
+	return TreeExportDir
+}
+
+// treeExportManifestPath returns where the manifest for a target is kept.
+func treeExportManifestPath(target, format string) string {
+	if format == TreeExportNDJSON {
+		return target + ".manifest.json"
+	}
+	return filepath.Join(target, treeExportManifestName)
+}
+
+// treeExporter holds the state of a running export.
+type treeExporter struct {
+	client       *Client
+	ctx          context.Context
+	opts         TreeExportOptions
+	manifest     *treeExportManifest
+	manifestPath string
+	done         map[string]bool
+	progress     TreeExportProgress
+
+	file     *os.File // NDJSON output
+	w        *bufio.Writer
+	written  int64
+	lastSave time.Time
+}
+
+// ExportTree writes a collection or document and everything below it
+// (documents and subcollections, recursively) to opts.Target.
+// Progress is checkpointed to a manifest next to the output; running the same
+// export again after a failure or cancellation continues where it stopped.
+// The manifest is removed once the export completes.
+func (c *Client) ExportTree(ctx context.Context, opts TreeExportOptions) (TreeExportProgress, error) {
+	if opts.Root == "" {
+		return TreeExportProgress{}, fmt.Errorf("nothing to export")
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = treeExportPageSize
+	}
+
+	format := TreeExportFormat(opts.Target)
+	e := &treeExporter{
+		client:       c,
+		ctx:          ctx,
+		opts:         opts,
+		manifestPath: treeExportManifestPath(opts.Target, format),
+		done:         make(map[string]bool),
+		progress:     TreeExportProgress{Format: format},
+	}
+
+	if err := e.open(); err != nil {
+		return e.progress, err
+	}
+
+	err := e.run()
+	if err == nil {
+		err = e.finish()
+	} else {
+		// State is consistent at any point, keep it for the next run
+		if saveErr := e.checkpoint(true); saveErr != nil {
+			err = fmt.Errorf("%v (saving progress failed: %v)", err, saveErr)
+		}
+	}
+	if e.file != nil {
+		e.file.Close()
+	}
+	return e.progress, err
+}
+
+// open loads an existing manifest (resume) or starts a new one, and prepares the output.
+func (e *treeExporter) open() error {
+	format := e.progress.Format
+	e.manifest = &treeExportManifest{
+		Root:    e.opts.Root,
+		Format:  format,
+		Cursors: make(map[string]string),
+	}
+
+	data, err := os.ReadFile(e.manifestPath)
+	switch {
+	case err == nil:
+		var m treeExportManifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("invalid export manifest %s: %v", e.manifestPath, err)
+		}
+		if m.Root != e.opts.Root || m.Format != format {
+			return fmt.Errorf("%s holds an unfinished export of %s", e.opts.Target, m.Root)
+		}
+		if m.Cursors == nil {
+			m.Cursors = make(map[string]string)
+		}
+		e.manifest = &m
+		for _, path := range m.Done {
+			e.done[path] = true
+		}
+		e.progress.Documents = m.Documents
+		e.progress.Resumed = true
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	if format == TreeExportDir {
+		return os.MkdirAll(e.opts.Target, 0755)
+	}
+
+	if dir := filepath.Dir(e.opts.Target); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(e.opts.Target, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	// Drop anything written after the last checkpoint so records are not duplicated
+	if err := f.Truncate(e.manifest.Bytes); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Seek(e.manifest.Bytes, 0); err != nil {
+		f.Close()
+		return err
+	}
+	e.file = f
+	e.w = bufio.NewWriter(f)
+	e.written = e.manifest.Bytes
+	return nil
+}
+
+// run walks the export root.
+func (e *treeExporter) run() error {
+	root := strings.Trim(e.opts.Root, "/")
+	if strings.Count(root, "/")%2 == 0 {
+		return e.exportCollection(root)
+	}
+
+	// Document root: the document itself, then its subcollections
+	if e.manifest.Documents == 0 {
+		doc, err := e.client.GetDocument(root)
+		if err != nil {
+			return err
+		}
+		if err := e.writeDocument(*doc); err != nil {
+			return err
+		}
+		if err := e.checkpoint(true); err != nil {
+			return err
+		}
+	}
+	return e.exportSubcollections(root)
+}
+
+// exportCollection exports all documents of a collection after its cursor.
+func (e *treeExporter) exportCollection(path string) error {
+	if e.done[path] {
+		return nil
+	}
+
+	after := e.manifest.Cursors[path]
+	if after != "" {
+		// Finish the subtree of the document that was being exported when we stopped
+		if err := e.exportSubcollections(after); err != nil {
+			return err
+		}
+	}
+
+	for {
+		if err := e.ctx.Err(); err != nil {
+			return err
+		}
+
+		docs, err := e.client.ListDocumentsAfter(path, after, e.opts.PageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, doc := range docs {
+			if err := e.ctx.Err(); err != nil {
+				return err
+			}
+			if err := e.writeDocument(doc); err != nil {
+				return err
+			}
+			e.manifest.Cursors[path] = doc.Path
+			after = doc.Path
+
+			if err := e.exportSubcollections(doc.Path); err != nil {
+				return err
+			}
+		}
+
+		if err := e.checkpoint(false); err != nil {
+			return err
+		}
+		if len(docs) < e.opts.PageSize {
+			break
+		}
+	}
+
+	e.markDone(path)
+	return e.checkpoint(false)
+}
+
+// exportSubcollections exports every subcollection of a document.
+func (e *treeExporter) exportSubcollections(docPath string) error {
+	collections, err := e.client.ListSubcollections(docPath)
+	if err != nil {
+		return err
+	}
+	for _, col := range collections {
+		if err := e.exportCollection(col.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markDone records a finished collection. Entries below it are no longer needed.
+func (e *treeExporter) markDone(path string) {
+	prefix := path + "/"
+	for p := range e.done {
+		if strings.HasPrefix(p, prefix) {
+			delete(e.done, p)
+		}
+	}
+	for p := range e.manifest.Cursors {
+		if p == path || strings.HasPrefix(p, prefix) {
+			delete(e.manifest.Cursors, p)
+		}
+	}
+	e.done[path] = true
+}
+
+// writeDocument writes one document in the export's format.
+func (e *treeExporter) writeDocument(doc Document) error {
+	if doc.Data == nil {
+		doc.Data = map[string]interface{}{}
+	}
+
+	if e.progress.Format == TreeExportNDJSON {
+		line, err := json.Marshal(treeExportLine{Path: doc.Path, Data: doc.Data})
+		if err != nil {
+			return fmt.Errorf("%s: %v", doc.Path, err)
+		}
+		// Only count complete records; a partial write is truncated on resume
+		if _, err := e.w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		e.written += int64(len(line) + 1)
+	} else {
+		data, err := json.MarshalIndent(doc.Data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("%s: %v", doc.Path, err)
+		}
+		file := filepath.Join(e.opts.Target, filepath.FromSlash(doc.Path)+".json")
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	e.progress.Documents++
+	e.progress.Path = doc.Path
+	return nil
+}
+
+// checkpoint flushes output and saves the manifest. Unless forced, it is
+// throttled so small subcollections don't cause a manifest write each.
+func (e *treeExporter) checkpoint(force bool) error {
+	if !force && time.Since(e.lastSave) < treeExportCheckpointEvery {
+		return nil
+	}
+	e.lastSave = time.Now()
+
+	if e.w != nil {
+		if err := e.w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	e.manifest.Documents = e.progress.Documents
+	e.manifest.Bytes = e.written
+	e.manifest.Done = e.manifest.Done[:0]
+	for path := range e.done {
+		e.manifest.Done = append(e.manifest.Done, path)
+	}
+	sort.Strings(e.manifest.Done)
+	e.manifest.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	data, err := json.MarshalIndent(e.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := e.manifestPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, e.manifestPath); err != nil {
+		return err
+	}
+
+	if e.opts.Progress != nil {
+		e.opts.Progress(e.progress)
+	}
+	return nil
+}
+
+// finish flushes the output and removes the manifest of a completed export.
+func (e *treeExporter) finish() error {
+	if e.w != nil {
+		if err := e.w.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(e.manifestPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if e.opts.Progress != nil {
+		e.opts.Progress(e.progress)
+	}
+	return nil
+}
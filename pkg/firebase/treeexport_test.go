@@ -0,0 +1,215 @@
+package firebase
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTreeExportClient returns an offline client over a small export:
+// users/{alice,bob}, users/alice/orders/{o1,o2} and cities/tirana.
+func newTreeExportClient(t *testing.T) *Client {
+	t.Helper()
+	name := func(v string) []byte {
+		return property("name", 0, false, (&protoWriter{}).str(valueStringField, v).buf)
+	}
+	dir := writeExport(t,
+		entityBytes(keyBytes("s~demo", "users", "alice"), name("Alice")),
+		entityBytes(keyBytes("s~demo", "users", "bob"), name("Bob")),
+		entityBytes(keyBytes("s~demo", "users", "alice", "orders", "o1"), name("Order 1")),
+		entityBytes(keyBytes("s~demo", "users", "alice", "orders", "o2"), name("Order 2")),
+		entityBytes(keyBytes("s~demo", "cities", "tirana"), name("Tirana")),
+	)
+	store, err := LoadExport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{currentProject: "demo", backup: store}
+}
+
+// readNDJSONPaths returns the document paths of an NDJSON export in order.
+func readNDJSONPaths(t *testing.T, file string) []string {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line treeExportLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		paths = append(paths, line.Path)
+	}
+	return paths
+}
+
+func TestTreeExportFormat(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{"/tmp/users.ndjson", TreeExportNDJSON},
+		{"/tmp/USERS.NDJSON", TreeExportNDJSON},
+		{"/tmp/users", TreeExportDir},
+		{"/tmp/users.json", TreeExportDir},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			if got := TreeExportFormat(tt.target); got != tt.expected {
+				t.Errorf("TreeExportFormat(%q) = %q, expected %q", tt.target, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExportTreeNDJSON(t *testing.T) {
+	client := newTreeExportClient(t)
+	target := filepath.Join(t.TempDir(), "users.ndjson")
+
+	progress, err := client.ExportTree(context.Background(), TreeExportOptions{Root: "users", Target: target, PageSize: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"users/alice", "users/alice/orders/o1", "users/alice/orders/o2", "users/bob"}
+	if got := readNDJSONPaths(t, target); !reflect.DeepEqual(got, expected) {
+		t.Errorf("exported paths = %v, expected %v", got, expected)
+	}
+	if progress.Documents != len(expected) {
+		t.Errorf("Documents = %d, expected %d", progress.Documents, len(expected))
+	}
+	if _, err := os.Stat(target + ".manifest.json"); !os.IsNotExist(err) {
+		t.Error("manifest should be removed after a completed export")
+	}
+}
+
+func TestExportTreeDirectory(t *testing.T) {
+	client := newTreeExportClient(t)
+	target := t.TempDir()
+
+	if _, err := client.ExportTree(context.Background(), TreeExportOptions{Root: "users/alice", Target: target}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, path := range []string{"users/alice", "users/alice/orders/o1", "users/alice/orders/o2"} {
+		data, err := os.ReadFile(filepath.Join(target, filepath.FromSlash(path)+".json"))
+		if err != nil {
+			t.Errorf("missing file for %s: %v", path, err)
+			continue
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Errorf("%s: invalid JSON: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(target, "users", "bob.json")); !os.IsNotExist(err) {
+		t.Error("sibling document users/bob should not be exported")
+	}
+}
+
+func TestExportTreeResume(t *testing.T) {
+	client := newTreeExportClient(t)
+	target := filepath.Join(t.TempDir(), "users.ndjson")
+
+	// Simulate a run interrupted inside users/alice/orders: the checkpoint covers
+	// users/alice and o1, and a partial record was written after it.
+	written := `{"path":"users/alice","data":{"name":"Alice"}}` + "\n" +
+		`{"path":"users/alice/orders/o1","data":{"name":"Order 1"}}` + "\n"
+	if err := os.WriteFile(target, []byte(written+`{"path":"users/alice/ord`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, _ := json.Marshal(treeExportManifest{
+		Root:      "users",
+		Format:    TreeExportNDJSON,
+		Documents: 2,
+		Bytes:     int64(len(written)),
+		Cursors:   map[string]string{"users": "users/alice", "users/alice/orders": "users/alice/orders/o1"},
+	})
+	if err := os.WriteFile(target+".manifest.json", manifest, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	progress, err := client.ExportTree(context.Background(), TreeExportOptions{Root: "users", Target: target})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"users/alice", "users/alice/orders/o1", "users/alice/orders/o2", "users/bob"}
+	if got := readNDJSONPaths(t, target); !reflect.DeepEqual(got, expected) {
+		t.Errorf("exported paths = %v, expected %v", got, expected)
+	}
+	if !progress.Resumed {
+		t.Error("expected export to report it resumed")
+	}
+	if progress.Documents != len(expected) {
+		t.Errorf("Documents = %d, expected %d", progress.Documents, len(expected))
+	}
+}
+
+func TestExportTreeCancelKeepsManifest(t *testing.T) {
+	client := newTreeExportClient(t)
+	target := filepath.Join(t.TempDir(), "users.ndjson")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.ExportTree(ctx, TreeExportOptions{Root: "users", Target: target}); err == nil {
+		t.Fatal("expected cancellation error, got nil")
+	}
+	if _, err := os.Stat(target + ".manifest.json"); err != nil {
+		t.Errorf("manifest should be kept after cancellation: %v", err)
+	}
+
+	// Resuming the same root finishes the export
+	if _, err := client.ExportTree(context.Background(), TreeExportOptions{Root: "users", Target: target}); err != nil {
+		t.Fatalf("resume: unexpected error: %v", err)
+	}
+	if got := readNDJSONPaths(t, target); len(got) != 4 {
+		t.Errorf("resumed export wrote %d documents, expected 4", len(got))
+	}
+}
+
+func TestExportTreeRejectsOtherRoot(t *testing.T) {
+	client := newTreeExportClient(t)
+	target := filepath.Join(t.TempDir(), "out.ndjson")
+
+	manifest, _ := json.Marshal(treeExportManifest{Root: "cities", Format: TreeExportNDJSON})
+	if err := os.WriteFile(target+".manifest.json", manifest, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.ExportTree(context.Background(), TreeExportOptions{Root: "users", Target: target})
+	if err == nil || !strings.Contains(err.Error(), "cities") {
+		t.Errorf("expected error mentioning the unfinished export, got %v", err)
+	}
+}
+
+func TestQueryParent(t *testing.T) {
+	tests := []struct {
+		collectionPath string
+		expected       string
+	}{
+		{"users", ""},
+		{"users/abc/orders", "/users/abc"},
+		{"a/b/c/d/e", "/a/b/c/d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.collectionPath, func(t *testing.T) {
+			if got := queryParent(tt.collectionPath); got != tt.expected {
+				t.Errorf("queryParent(%q) = %q, expected %q", tt.collectionPath, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,610 @@
+package firebase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError is one JSON Schema violation in a document.
+type ValidationError struct {
+	Path    string // jq-style path of the offending value, "." for the document
+	Message string
+}
+
+// JSONSchema is a JSON Schema used to validate documents. It supports the
+// keywords that describe document shapes: type, enum, const, properties,
+// required, additionalProperties, items, the length, size and range limits,
+// pattern, format (date-time, date, email, uri, byte), allOf, anyOf, oneOf, not and
+// local $ref. Other keywords are ignored.
+type JSONSchema struct {
+	root     interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// LoadJSONSchema reads a JSON Schema file.
+func LoadJSONSchema(path string) (*JSONSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJSONSchema(data)
+}
+
+// ParseJSONSchema parses a JSON Schema document.
+func ParseJSONSchema(data []byte) (*JSONSchema, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema: %v", err)
+	}
+	switch root.(type) {
+	case map[string]interface{}, bool:
+	default:
+		return nil, fmt.Errorf("invalid JSON Schema: expected an object")
+	}
+	s := &JSONSchema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := s.checkRefCycles(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// checkRefCycles rejects $refs that lead back to themselves while validating
+// the same value, like a → b → a, which would recurse forever. Recursion
+// through properties or items is fine since it descends into the value.
+func (s *JSONSchema) checkRefCycles() error {
+	const (
+		resolving = 1
+		done      = 2
+	)
+	state := make(map[string]int)
+	var visit func(ref string) error
+	visit = func(ref string) error {
+		switch state[ref] {
+		case resolving:
+			return fmt.Errorf("invalid JSON Schema: $ref %q refers back to itself", ref)
+		case done:
+			return nil
+		}
+		state[ref] = resolving
+		if target, err := s.resolve(ref); err == nil { // Unresolved refs are reported when validating
+			for _, next := range sameValueRefs(target, nil) {
+				if err := visit(next); err != nil {
+					return err
+				}
+			}
+		}
+		state[ref] = done
+		return nil
+	}
+
+	for _, ref := range allRefs(s.root, nil) {
+		if err := visit(ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameValueRefs appends the $refs a schema follows without descending into
+// the value: its own and those of its allOf, anyOf, oneOf and not subschemas.
+func sameValueRefs(schema interface{}, refs []string) []string {
+	sch, ok := schema.(map[string]interface{})
+	if !ok {
+		return refs
+	}
+	if ref, ok := sch["$ref"].(string); ok {
+		refs = append(refs, ref)
+	}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		subs, _ := sch[keyword].([]interface{})
+		for _, sub := range subs {
+			refs = sameValueRefs(sub, refs)
+		}
+	}
+	return sameValueRefs(sch["not"], refs)
+}
+
+// allRefs appends every $ref in a schema document.
+func allRefs(node interface{}, refs []string) []string {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := n["$ref"].(string); ok {
+			refs = append(refs, ref)
+		}
+		keys := make([]string, 0, len(n))
+		for key := range n {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // Report the same cycle on every load
+		for _, key := range keys {
+			refs = allRefs(n[key], refs)
+		}
+	case []interface{}:
+		for _, item := range n {
+			refs = allRefs(item, refs)
+		}
+	}
+	return refs
+}
+
+// ValidateFields validates a document given in Firestore's typed field format.
+func (s *JSONSchema) ValidateFields(fields map[string]interface{}) []ValidationError {
+	return s.Validate(FirestoreJSON(fields))
+}
+
+// Validate checks a JSON value against the schema. Errors are sorted by path.
+func (s *JSONSchema) Validate(value interface{}) []ValidationError {
+	var errs []ValidationError
+	s.validate(s.root, value, ".", &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs
+}
+
+// FirestoreJSON converts typed Firestore fields to the JSON a schema describes:
+// integers and doubles become numbers, timestamps RFC 3339 strings, references
+// document paths, geopoints {latitude, longitude} objects and bytes base64 strings.
+func FirestoreJSON(fields map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if v, ok := value.(map[string]interface{}); ok {
+			result[key] = firestoreJSONValue(v)
+		}
+	}
+	return result
+}
+
+func firestoreJSONValue(value map[string]interface{}) interface{} {
+	typ, raw := firestoreValueType(value)
+	switch typ {
+	case TypeInteger:
+		if n, err := strconv.ParseInt(fmt.Sprint(raw), 10, 64); err == nil {
+			return n
+		}
+	case TypeDouble:
+		switch n := raw.(type) {
+		case float64:
+			return n
+		case string: // NaN and Infinity arrive as strings
+			f, _ := strconv.ParseFloat(n, 64)
+			return f
+		}
+	case TypeMap:
+		m, _ := raw.(map[string]interface{})
+		fields, _ := m["fields"].(map[string]interface{})
+		return FirestoreJSON(fields)
+	case TypeArray:
+		m, _ := raw.(map[string]interface{})
+		values, _ := m["values"].([]interface{})
+		arr := make([]interface{}, 0, len(values))
+		for _, item := range values {
+			if v, ok := item.(map[string]interface{}); ok {
+				arr = append(arr, firestoreJSONValue(v))
+			}
+		}
+		return arr
+	case TypeReference:
+		ref := fmt.Sprint(raw)
+		if i := strings.Index(ref, "/documents/"); i >= 0 {
+			return ref[i+len("/documents/"):]
+		}
+		return ref
+	}
+	return raw
+}
+
+// validate appends the violations of value against schema, located at path.
+func (s *JSONSchema) validate(schema interface{}, value interface{}, path string, errs *[]ValidationError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if allowed, ok := schema.(bool); ok {
+		if !allowed {
+			fail("not allowed")
+		}
+		return
+	}
+	sch, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if ref, ok := sch["$ref"].(string); ok {
+		target, err := s.resolve(ref)
+		if err != nil {
+			fail("%v", err)
+			return
+		}
+		s.validate(target, value, path, errs)
+	}
+
+	if t, ok := sch["type"]; ok && !matchesType(t, value) {
+		fail("expected %s, got %s", describeType(t), jsonTypeName(value))
+		return // Other keywords would only repeat the mismatch
+	}
+
+	if enum, ok := sch["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %s", compactJSON(enum))
+		}
+	}
+	if c, ok := sch["const"]; ok && !jsonEqual(c, value) {
+		fail("must be %s", compactJSON(c))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.validateObject(sch, v, path, errs)
+	case []interface{}:
+		s.validateArray(sch, v, path, errs)
+	case string:
+		s.validateString(sch, v, fail)
+	}
+	if n, ok := jsonNumber(value); ok {
+		validateNumber(sch, n, fail)
+	}
+
+	if all, ok := sch["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			s.validate(sub, value, path, errs)
+		}
+	}
+	if anyOf, ok := sch["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if s.matches(sub, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("does not match any of the allowed schemas")
+		}
+	}
+	if oneOf, ok := sch["oneOf"].([]interface{}); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			if s.matches(sub, value) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("must match exactly one schema, matches %d", matched)
+		}
+	}
+	if not, ok := sch["not"]; ok && s.matches(not, value) {
+		fail("must not match the schema in \"not\"")
+	}
+}
+
+// matches reports whether value is valid against schema.
+func (s *JSONSchema) matches(schema interface{}, value interface{}) bool {
+	var errs []ValidationError
+	s.validate(schema, value, ".", &errs)
+	return len(errs) == 0
+}
+
+func (s *JSONSchema) validateObject(sch map[string]interface{}, obj map[string]interface{}, path string, errs *[]ValidationError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if required, ok := sch["required"].([]interface{}); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, present := obj[name]; !present {
+					fail("missing required field %q", name)
+				}
+			}
+		}
+	}
+	if n, ok := jsonNumber(sch["minProperties"]); ok && float64(len(obj)) < n {
+		fail("must have at least %v fields", n)
+	}
+	if n, ok := jsonNumber(sch["maxProperties"]); ok && float64(len(obj)) > n {
+		fail("must have at most %v fields", n)
+	}
+
+	props, _ := sch["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := jqKeyPath(path, key)
+		if prop, ok := props[key]; ok {
+			s.validate(prop, obj[key], childPath, errs)
+			continue
+		}
+		switch additional := sch["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, ValidationError{Path: childPath, Message: "field is not allowed"})
+			}
+		case map[string]interface{}:
+			s.validate(additional, obj[key], childPath, errs)
+		}
+	}
+}
+
+func (s *JSONSchema) validateArray(sch map[string]interface{}, arr []interface{}, path string, errs *[]ValidationError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n, ok := jsonNumber(sch["minItems"]); ok && float64(len(arr)) < n {
+		fail("must have at least %v items, has %d", n, len(arr))
+	}
+	if n, ok := jsonNumber(sch["maxItems"]); ok && float64(len(arr)) > n {
+		fail("must have at most %v items, has %d", n, len(arr))
+	}
+	if unique, _ := sch["uniqueItems"].(bool); unique {
+	outer:
+		for i := range arr {
+			for j := 0; j < i; j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					fail("items %d and %d are equal", j, i)
+					break outer
+				}
+			}
+		}
+	}
+	if items, ok := sch["items"]; ok {
+		for i, item := range arr {
+			s.validate(items, item, jqIndexPath(path, i), errs)
+		}
+	}
+}
+
+func (s *JSONSchema) validateString(sch map[string]interface{}, str string, fail func(string, ...interface{})) {
+	length := float64(len([]rune(str)))
+	if n, ok := jsonNumber(sch["minLength"]); ok && length < n {
+		fail("must be at least %v characters", n)
+	}
+	if n, ok := jsonNumber(sch["maxLength"]); ok && length > n {
+		fail("must be at most %v characters", n)
+	}
+	if pattern, ok := sch["pattern"].(string); ok {
+		re, err := s.pattern(pattern)
+		if err != nil {
+			fail("invalid pattern %q in schema", pattern)
+		} else if !re.MatchString(str) {
+			fail("must match %s", pattern)
+		}
+	}
+	if format, ok := sch["format"].(string); ok && !matchesFormat(format, str) {
+		fail("must be a valid %s", format)
+	}
+}
+
+func validateNumber(sch map[string]interface{}, n float64, fail func(string, ...interface{})) {
+	if min, ok := jsonNumber(sch["minimum"]); ok && n < min {
+		fail("must be >= %v", min)
+	}
+	if max, ok := jsonNumber(sch["maximum"]); ok && n > max {
+		fail("must be <= %v", max)
+	}
+	if min, ok := jsonNumber(sch["exclusiveMinimum"]); ok && n <= min {
+		fail("must be > %v", min)
+	}
+	if max, ok := jsonNumber(sch["exclusiveMaximum"]); ok && n >= max {
+		fail("must be < %v", max)
+	}
+	if m, ok := jsonNumber(sch["multipleOf"]); ok && m > 0 {
+		if q := n / m; math.Abs(q-math.Round(q)) > 1e-9 {
+			fail("must be a multiple of %v", m)
+		}
+	}
+}
+
+// pattern compiles a schema regular expression once.
+func (s *JSONSchema) pattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := s.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	s.patterns[pattern] = re
+	return re, nil
+}
+
+// resolve follows a local reference such as #/$defs/address.
+func (s *JSONSchema) resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q, only local references are supported", ref)
+	}
+	node := s.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+		if node, ok = m[part]; !ok {
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+	}
+	return node, nil
+}
+
+// matchesType checks the "type" keyword, a name or a list of names.
+func matchesType(t interface{}, value interface{}) bool {
+	switch t := t.(type) {
+	case string:
+		return matchesTypeName(t, value)
+	case []interface{}:
+		for _, name := range t {
+			if s, ok := name.(string); ok && matchesTypeName(s, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesTypeName(name string, value interface{}) bool {
+	actual := jsonTypeName(value)
+	switch name {
+	case "number":
+		return actual == "integer" || actual == "number"
+	case "integer":
+		if actual == "number" {
+			n, _ := jsonNumber(value)
+			return n == math.Trunc(n)
+		}
+	}
+	return actual == name
+}
+
+func describeType(t interface{}) string {
+	if list, ok := t.([]interface{}); ok {
+		var names []string
+		for _, name := range list {
+			names = append(names, fmt.Sprint(name))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// jsonTypeName returns the JSON Schema type of a value.
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case int64, int:
+		return "integer"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonNumber returns value as a float64 if it is a number.
+func jsonNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// jsonEqual compares two JSON values, treating equal numbers of different Go types as equal.
+func jsonEqual(a, b interface{}) bool {
+	if x, ok := jsonNumber(a); ok {
+		y, ok := jsonNumber(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// matchesFormat checks the common string formats; unknown formats always match.
+func matchesFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uri":
+		return uriScheme.MatchString(s)
+	case "byte":
+		_, err := base64.StdEncoding.DecodeString(s)
+		return err == nil
+	}
+	return true
+}
+
+var uriScheme = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:\S+$`)
+
+func compactJSON(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// jqKeyPath appends a field name to a jq-style path: .address.city, .["first name"].
+func jqKeyPath(path, key string) string {
+	if path == "." {
+		path = ""
+	}
+	if simpleFieldName.MatchString(key) {
+		return path + "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return path + "[" + string(quoted) + "]"
+}
+
+// jqIndexPath appends an array index to a jq-style path.
+func jqIndexPath(path string, i int) string {
+	if path == "." {
+		path = ""
+	}
+	return fmt.Sprintf("%s[%d]", path, i)
+}
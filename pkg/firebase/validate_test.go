@@ -0,0 +1,154 @@
+package firebase
+
+import (
+	"reflect"
+	"testing"
+)
+
+const orderSchema = `{
+	"type": "object",
+	"required": ["status", "total"],
+	"additionalProperties": false,
+	"properties": {
+		"status": {"enum": ["open", "paid"]},
+		"total": {"type": "number", "minimum": 0},
+		"qty": {"type": "integer"},
+		"email": {"type": "string", "format": "email"},
+		"createdAt": {"type": "string", "format": "date-time"},
+		"code": {"type": "string", "pattern": "^[A-Z]{3}$"},
+		"tags": {"type": "array", "maxItems": 2, "uniqueItems": true, "items": {"type": "string"}},
+		"address": {"$ref": "#/$defs/address"},
+		"note": {"type": ["string", "null"]}
+	},
+	"$defs": {
+		"address": {"type": "object", "required": ["city"], "properties": {"city": {"type": "string", "minLength": 2}}}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	schema, err := ParseJSONSchema([]byte(orderSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		value    map[string]interface{}
+		expected []string // "path: message"
+	}{
+		{"valid", map[string]interface{}{"status": "paid", "total": 9.5, "qty": int64(2), "note": nil, "tags": []interface{}{"a"}}, nil},
+		{"missing required", map[string]interface{}{"status": "open"}, []string{`.: missing required field "total"`}},
+		{"wrong type", map[string]interface{}{"status": "open", "total": "9"}, []string{".total: expected number, got string"}},
+		{"enum and minimum", map[string]interface{}{"status": "void", "total": -1.0}, []string{`.status: must be one of ["open","paid"]`, ".total: must be >= 0"}},
+		{"integer", map[string]interface{}{"status": "open", "total": 1.0, "qty": 1.5}, []string{".qty: expected integer, got number"}},
+		{"additional field", map[string]interface{}{"status": "open", "total": 1.0, "first name": "x"}, []string{`["first name"]: field is not allowed`}},
+		{"formats", map[string]interface{}{"status": "open", "total": 1.0, "email": "nope", "createdAt": "yesterday", "code": "ab"}, []string{
+			".code: must match ^[A-Z]{3}$", ".createdAt: must be a valid date-time", ".email: must be a valid email",
+		}},
+		{"array", map[string]interface{}{"status": "open", "total": 1.0, "tags": []interface{}{"a", "a", int64(3)}}, []string{
+			".tags: must have at most 2 items, has 3", ".tags: items 0 and 1 are equal", ".tags[2]: expected string, got integer",
+		}},
+		{"ref", map[string]interface{}{"status": "open", "total": 1.0, "address": map[string]interface{}{"city": "T"}}, []string{".address.city: must be at least 2 characters"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result []string
+			for _, e := range schema.Validate(tt.value) {
+				result = append(result, e.Path+": "+e.Message)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Validate() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateCombinators(t *testing.T) {
+	schema, err := ParseJSONSchema([]byte(`{
+		"properties": {
+			"id": {"anyOf": [{"type": "string"}, {"type": "integer"}]},
+			"kind": {"oneOf": [{"const": "a"}, {"enum": ["a", "b"]}]},
+			"x": {"not": {"type": "null"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := schema.Validate(map[string]interface{}{"id": true, "kind": "a", "x": nil})
+	var result []string
+	for _, e := range errs {
+		result = append(result, e.Path+": "+e.Message)
+	}
+	expected := []string{
+		".id: does not match any of the allowed schemas",
+		".kind: must match exactly one schema, matches 2",
+		`.x: must not match the schema in "not"`,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Validate() = %q, expected %q", result, expected)
+	}
+}
+
+func TestFirestoreJSON(t *testing.T) {
+	fields := map[string]interface{}{
+		"n":   schemaInt("42"),
+		"d":   map[string]interface{}{"doubleValue": 1.5},
+		"ref": map[string]interface{}{"referenceValue": "projects/p/databases/(default)/documents/users/a"},
+		"m": map[string]interface{}{"mapValue": map[string]interface{}{"fields": map[string]interface{}{
+			"tags": map[string]interface{}{"arrayValue": map[string]interface{}{"values": []interface{}{schemaStr("x")}}},
+		}}},
+		"empty": map[string]interface{}{"arrayValue": map[string]interface{}{}},
+		"null":  map[string]interface{}{"nullValue": nil},
+	}
+	expected := map[string]interface{}{
+		"n":     int64(42),
+		"d":     1.5,
+		"ref":   "users/a",
+		"m":     map[string]interface{}{"tags": []interface{}{"x"}},
+		"empty": []interface{}{},
+		"null":  nil,
+	}
+	if got := FirestoreJSON(fields); !reflect.DeepEqual(got, expected) {
+		t.Errorf("FirestoreJSON() = %#v, expected %#v", got, expected)
+	}
+}
+
+func TestParseJSONSchemaErrors(t *testing.T) {
+	for _, input := range []string{`not json`, `[1, 2]`} {
+		if _, err := ParseJSONSchema([]byte(input)); err == nil {
+			t.Errorf("ParseJSONSchema(%q) should fail", input)
+		}
+	}
+	schema, _ := ParseJSONSchema([]byte(`{"properties": {"a": {"$ref": "#/missing"}}}`))
+	if errs := schema.Validate(map[string]interface{}{"a": 1.0}); len(errs) != 1 {
+		t.Errorf("unresolved $ref should be reported, got %v", errs)
+	}
+}
+
+func TestParseJSONSchemaRefCycles(t *testing.T) {
+	cycles := []string{
+		`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`,
+		`{"properties": {"x": {"$ref": "#/$defs/a"}}, "$defs": {"a": {"allOf": [{"$ref": "#/$defs/a"}]}}}`,
+		`{"anyOf": [{"not": {"$ref": "#"}}]}`,
+	}
+	for _, input := range cycles {
+		if _, err := ParseJSONSchema([]byte(input)); err == nil {
+			t.Errorf("ParseJSONSchema(%s) should reject the $ref cycle", input)
+		}
+	}
+
+	// Recursion through properties descends into the value and ends with it
+	schema, err := ParseJSONSchema([]byte(`{
+		"$defs": {"node": {"type": "object", "properties": {"child": {"$ref": "#/$defs/node"}}}},
+		"$ref": "#/$defs/node"
+	}`))
+	if err != nil {
+		t.Fatalf("recursive schema: %v", err)
+	}
+	value := map[string]interface{}{"child": map[string]interface{}{"child": "leaf"}}
+	if errs := schema.Validate(value); len(errs) != 1 || errs[0].Path != ".child.child" {
+		t.Errorf("Validate() = %v, expected one error at .child.child", errs)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
 )
 
 // Actions - clean handler functions without state checks.
@@ -22,8 +23,7 @@ func (g *Gui) doQuit() error {
 func (g *Gui) doEscape() error {
 	// Priority: help popup > command modal > details panel > select mode (only in tree) > filter input > committed filter
 	if g.helpOpen {
-		g.helpOpen = false
-		g.helpPopup = nil
+		g.closeHelpPopup()
 		return g.Layout(g.g)
 	}
 	if g.modalOpen {
@@ -54,8 +54,7 @@ func (g *Gui) doEscape() error {
 // doToggleHelp toggles the help popup
 func (g *Gui) doToggleHelp() error {
 	if g.helpOpen {
-		g.helpOpen = false
-		g.helpPopup = nil
+		g.closeHelpPopup()
 	} else {
 		g.buildHelpPopup()
 		g.helpOpen = true
@@ -84,6 +83,24 @@ func (g *Gui) helpMoveDown() error {
 	return g.Layout(g.g)
 }
 
+// helpKey runs the selected popup item's action for a key, if it has one.
+// The popup is closed first, like on Enter.
+func (g *Gui) helpKey(ch rune) func() error {
+	return func() error {
+		if g.helpPopup == nil {
+			return nil
+		}
+		item := g.helpPopup.GetSelectedItem()
+		if item == nil || item.Keys[ch] == nil {
+			return nil
+		}
+		action := item.Keys[ch]
+		g.helpOpen = false
+		g.helpPopup = nil
+		return action()
+	}
+}
+
 func (g *Gui) helpClose() error {
 	// Get selected item before closing
 	var action func() error
@@ -94,17 +111,26 @@ func (g *Gui) helpClose() error {
 		}
 	}
 
-	// Close popup
-	g.helpOpen = false
-	g.helpPopup = nil
-
 	// Execute action if any
 	if action != nil {
+		g.helpOpen = false
+		g.helpPopup = nil
 		return action()
 	}
+	g.closeHelpPopup()
 	return g.Layout(g.g)
 }
 
+// closeHelpPopup closes the help popup or menu without running an action.
+func (g *Gui) closeHelpPopup() {
+	popup := g.helpPopup
+	g.helpOpen = false
+	g.helpPopup = nil
+	if popup != nil && popup.OnCancel != nil {
+		popup.OnCancel()
+	}
+}
+
 // Context-specific handlers for filter mode
 func (g *Gui) filterCursorLeft() error {
 	if g.filterCursorPos > 0 {
@@ -125,22 +151,6 @@ func (g *Gui) blockAction() error {
 	return nil
 }
 
-// Filter char inserters for keys that have other bindings
-func (g *Gui) filterInsertJ() error         { return g.insertFilterChar(g.g, 'j') }
-func (g *Gui) filterInsertK() error         { return g.insertFilterChar(g.g, 'k') }
-func (g *Gui) filterInsertH() error         { return g.insertFilterChar(g.g, 'h') }
-func (g *Gui) filterInsertL() error         { return g.insertFilterChar(g.g, 'l') }
-func (g *Gui) filterInsertQuestion() error  { return g.insertFilterChar(g.g, '?') }
-func (g *Gui) filterInsertAt() error        { return g.insertFilterChar(g.g, '@') }
-func (g *Gui) filterInsertC() error         { return g.insertFilterChar(g.g, 'c') }
-func (g *Gui) filterInsertS() error         { return g.insertFilterChar(g.g, 's') }
-func (g *Gui) filterInsertR() error         { return g.insertFilterChar(g.g, 'r') }
-func (g *Gui) filterInsertQ() error      { return g.insertFilterChar(g.g, 'q') }
-func (g *Gui) filterInsertUpperF() error { return g.insertFilterChar(g.g, 'F') }
-func (g *Gui) filterInsertV() error         { return g.insertFilterChar(g.g, 'v') }
-func (g *Gui) filterInsertE() error         { return g.insertFilterChar(g.g, 'e') }
-func (g *Gui) filterInsertSlash() error     { return g.insertFilterChar(g.g, '/') }
-
 // doColumnLeft switches to the panel on the left (skips details)
 func (g *Gui) doColumnLeft() error {
 	if g.currentColumn == "details" {
@@ -227,7 +237,7 @@ func (g *Gui) doCursorDown() error {
 // doNextColumn - Tab goes to details panel (keeps existing content)
 func (g *Gui) doNextColumn() error {
 	if g.currentColumn == "details" {
-		return nil // Already in details, do nothing
+		return nil
 	}
 	g.previousColumn = g.currentColumn
 	return g.setFocus(g.g, "details")
@@ -247,11 +257,6 @@ func (g *Gui) doSpace() error {
 	return nil
 }
 
-// filterInsertSpace inserts space in filter
-func (g *Gui) filterInsertSpace() error {
-	return g.insertFilterChar(g.g, ' ')
-}
-
 // doEnter - normal mode enter handler
 func (g *Gui) doEnter() error {
 	switch g.currentColumn {
@@ -269,6 +274,8 @@ func (g *Gui) doEnter() error {
 		}
 		g.previousColumn = g.currentColumn
 		return g.setFocus(g.g, "details")
+	case "details":
+		return g.followReferenceAction()
 	}
 	return nil
 }
@@ -294,6 +301,12 @@ func (g *Gui) doStartFilter() error {
 	case "details":
 		g.detailsFilter = ""
 	}
+	if g.currentColumn == "tree" {
+		g.treeFilterCollection = g.getSelectedCollection()
+		if g.treeFilterCollection == "" {
+			g.treeFilterCollection = g.currentCollection
+		}
+	}
 	g.filterInputActive = true
 	g.filterInputPanel = g.currentColumn
 	g.filterInputText = ""
@@ -306,6 +319,7 @@ func (g *Gui) doFilterBackspace() error {
 	if !g.filterInputActive {
 		return nil
 	}
+	g.closeQuerySelect()
 	if g.filterCursorPos > 0 && len(g.filterInputText) > 0 {
 		g.filterInputText = g.filterInputText[:g.filterCursorPos-1] + g.filterInputText[g.filterCursorPos:]
 		g.filterCursorPos--
@@ -333,6 +347,120 @@ func (g *Gui) doSaveJSON() error {
 	return g.saveJSONAction()
 }
 
+// doExportTree exports the selected collection or document subtree to disk
+func (g *Gui) doExportTree() error {
+	return g.exportTreeAction()
+}
+
+// doImport imports a JSON or NDJSON file into the selected collection
+func (g *Gui) doImport() error {
+	return g.importAction()
+}
+
+// doCopyTo copies the selected subtree or documents to another project or collection
+func (g *Gui) doCopyTo() error {
+	return g.copyToAction()
+}
+
+// doMarkDiff marks the selected document as the left side of a diff
+func (g *Gui) doMarkDiff() error {
+	return g.markDiffAction()
+}
+
+// doDiff compares the marked document with another one
+func (g *Gui) doDiff() error {
+	return g.diffAction()
+}
+
+// doToggleDiffIgnoreOrder toggles ignoring array order in the shown diff
+func (g *Gui) doToggleDiffIgnoreOrder() error {
+	return g.toggleDiffIgnoreOrderAction()
+}
+
+// doOpenTable shows the loaded documents of a collection as a table
+func (g *Gui) doOpenTable() error {
+	return g.openTableAction()
+}
+
+// doSchema infers the schema of a collection from a sample of its documents
+func (g *Gui) doSchema() error {
+	return g.schemaAction()
+}
+
+// doGenerateTypes emits TypeScript, Go or Python models for a collection
+func (g *Gui) doGenerateTypes() error {
+	return g.generateTypesAction()
+}
+
+// doValidateCollection checks loaded documents against the collection's JSON Schema
+func (g *Gui) doValidateCollection() error {
+	return g.validateCollectionAction()
+}
+
+// doOpenExplorer browses the open document as a collapsible tree
+func (g *Gui) doOpenExplorer() error {
+	return g.openExplorerAction()
+}
+
+// doGoToPath opens a path or console URL in the tree
+func (g *Gui) doGoToPath() error {
+	return g.goToPathAction()
+}
+
+// doSearch searches the values of loaded documents
+func (g *Gui) doSearch() error {
+	return g.searchAction()
+}
+
+// doNextMatch jumps to the next match of the details search
+func (g *Gui) doNextMatch() error {
+	return g.nextDetailsMatchAction()
+}
+
+// doPrevMatch jumps to the previous match of the details search
+func (g *Gui) doPrevMatch() error {
+	return g.prevDetailsMatchAction()
+}
+
+// doJqRun runs a jq program across the selected or loaded documents
+func (g *Gui) doJqRun() error {
+	return g.jqRunAction()
+}
+
+// doJumpBack returns to the previously visited document
+func (g *Gui) doJumpBack() error {
+	return g.jumpBackAction()
+}
+
+// doJumpForward goes forward again in the jumplist
+func (g *Gui) doJumpForward() error {
+	return g.jumpForwardAction()
+}
+
+// doAddBookmark bookmarks the selected document or collection
+func (g *Gui) doAddBookmark() error {
+	return g.addBookmarkAction()
+}
+
+// doOpenBookmarks lists saved bookmarks
+func (g *Gui) doOpenBookmarks() error {
+	return g.openBookmarksAction("")
+}
+
+func (g *Gui) doOpenHistory() error {
+	return g.openHistoryAction("")
+}
+
+// doCancelExport stops a running subtree export
+func (g *Gui) doCancelExport() error {
+	if g.exportCancel == nil {
+		return nil
+	}
+	g.exportCancel()
+	g.updateCommand("export", "Cancelling...", "running")
+	return nil
+}
+
 // doEditInEditor opens current document in external editor
 func (g *Gui) doEditInEditor() error {
 	if g.currentColumn != "details" {
@@ -414,6 +542,7 @@ func (g *Gui) doRefresh() error {
 		return g.Layout(g.g)
 	}
 
+	g.clearProjectCaches()
 	g.collections = nil
 	g.treeNodes = nil
 	g.currentDocData = nil
@@ -452,8 +581,7 @@ func (g *Gui) doHelpClick() error {
 
 func (g *Gui) doProjectsClick() error {
 	if g.helpOpen {
-		g.helpOpen = false
-		g.helpPopup = nil
+		g.closeHelpPopup()
 		return g.Layout(g.g)
 	}
 	g.currentColumn = "projects"
@@ -475,8 +603,7 @@ func (g *Gui) doProjectsClick() error {
 
 func (g *Gui) doCollectionsClick() error {
 	if g.helpOpen {
-		g.helpOpen = false
-		g.helpPopup = nil
+		g.closeHelpPopup()
 		return g.Layout(g.g)
 	}
 	g.currentColumn = "collections"
@@ -497,8 +624,7 @@ func (g *Gui) doCollectionsClick() error {
 
 func (g *Gui) doTreeClick() error {
 	if g.helpOpen {
-		g.helpOpen = false
-		g.helpPopup = nil
+		g.closeHelpPopup()
 		return g.Layout(g.g)
 	}
 	g.currentColumn = "tree"
@@ -519,8 +645,7 @@ func (g *Gui) doTreeClick() error {
 
 func (g *Gui) doDetailsClick() error {
 	if g.helpOpen {
-		g.helpOpen = false
-		g.helpPopup = nil
+		g.closeHelpPopup()
 		return g.Layout(g.g)
 	}
 	g.currentColumn = "details"
@@ -529,8 +654,7 @@ func (g *Gui) doDetailsClick() error {
 
 func (g *Gui) doOutsideClick() error {
 	if g.helpOpen {
-		g.helpOpen = false
-		g.helpPopup = nil
+		g.closeHelpPopup()
 		return g.Layout(g.g)
 	}
 	return nil
@@ -648,7 +772,7 @@ func (g *Gui) doFetchSelectedDocs() error {
 	// Fetch uncached documents in parallel
 	type result struct {
 		path string
-		data map[string]any
+		doc  *firebase.Document
 		err  error
 	}
 
@@ -663,7 +787,7 @@ func (g *Gui) doFetchSelectedDocs() error {
 			if err != nil {
 				results[idx] = result{path: docPath, err: err}
 			} else {
-				results[idx] = result{path: docPath, data: doc.Data}
+				results[idx] = result{path: docPath, doc: doc}
 			}
 		}(i, path)
 	}
@@ -675,8 +799,8 @@ func (g *Gui) doFetchSelectedDocs() error {
 		if r.err != nil {
 			g.logCommand("api", fmt.Sprintf("Error fetching %s: %v", r.path, r.err), "error")
 		} else {
-			combined[r.path] = r.data
-			g.docCache[r.path] = r.data
+			combined[r.path] = r.doc.Data
+			g.cacheDocument(*r.doc)
 		}
 	}
 
@@ -700,11 +824,17 @@ func (g *Gui) doOpenQuery() error {
 
 // queryClose closes the query modal
 func (g *Gui) queryClose() error {
+	if g.queryCodeOpen {
+		return g.closeQueryCode()
+	}
 	return g.closeQueryModal()
 }
 
 // queryMoveUp moves up in the query modal
 func (g *Gui) queryMoveUp() error {
+	if g.queryCodeOpen {
+		return g.scrollQueryCode(-1)
+	}
 	if g.queryActiveRow == queryRowFilters && len(g.queryFilters) > 0 {
 		// In filters: move to previous filter, or wrap to buttons if at first
 		filterIdx := g.queryActiveCol / 4
@@ -731,6 +861,9 @@ func (g *Gui) queryMoveUp() error {
 
 // queryMoveDown moves down in the query modal
 func (g *Gui) queryMoveDown() error {
+	if g.queryCodeOpen {
+		return g.scrollQueryCode(1)
+	}
 	if g.queryActiveRow == queryRowFilters && len(g.queryFilters) > 0 {
 		// In filters: move to next filter, or to orderBy if at last
 		filterIdx := g.queryActiveCol / 4
@@ -744,7 +877,7 @@ func (g *Gui) queryMoveDown() error {
 	// Move to next row
 	g.queryActiveRow++
 	if g.queryActiveRow > queryRowButtons {
-		g.queryActiveRow = queryRowFilters
+		g.queryActiveRow = queryRowText
 	}
 	g.queryActiveCol = 0
 	return g.Layout(g.g)
@@ -752,6 +885,9 @@ func (g *Gui) queryMoveDown() error {
 
 // queryMoveLeft moves left in the query modal
 func (g *Gui) queryMoveLeft() error {
+	if g.queryCodeOpen {
+		return g.switchQueryCodeLang(-1)
+	}
 	g.queryActiveCol--
 	if g.queryActiveCol < 0 {
 		g.queryActiveCol = g.getMaxColForRow()
@@ -761,6 +897,9 @@ func (g *Gui) queryMoveLeft() error {
 
 // queryMoveRight moves right in the query modal
 func (g *Gui) queryMoveRight() error {
+	if g.queryCodeOpen {
+		return g.switchQueryCodeLang(1)
+	}
 	g.queryActiveCol++
 	if g.queryActiveCol > g.getMaxColForRow() {
 		g.queryActiveCol = 0
@@ -790,6 +929,9 @@ func (g *Gui) queryKeyL() error {
 
 // queryNextField moves to the next field, wrapping to next row at end
 func (g *Gui) queryNextField() error {
+	if g.queryCodeOpen {
+		return g.switchQueryCodeLang(1)
+	}
 	maxCol := g.getMaxColForRow()
 
 	if g.queryActiveCol < maxCol {
@@ -800,7 +942,7 @@ func (g *Gui) queryNextField() error {
 		g.queryActiveCol = 0
 		g.queryActiveRow++
 		if g.queryActiveRow > queryRowButtons {
-			g.queryActiveRow = queryRowFilters
+			g.queryActiveRow = queryRowText
 		}
 	}
 
@@ -809,6 +951,9 @@ func (g *Gui) queryNextField() error {
 
 // queryEnter handles enter key in query modal
 func (g *Gui) queryEnter() error {
+	if g.queryCodeOpen {
+		return g.copyQueryCode()
+	}
 	return g.handleQueryEnter()
 }
 
@@ -821,6 +966,12 @@ func (g *Gui) queryBackspace() error {
 // Only handles special action keys when not in edit mode
 func (g *Gui) queryInsertChar(ch rune) func() error {
 	return func() error {
+		if g.queryCodeOpen {
+			if ch == 'c' || ch == 'y' {
+				return g.copyQueryCode()
+			}
+			return nil
+		}
 		switch ch {
 		case 'a':
 			g.addQueryFilter()
@@ -830,6 +981,17 @@ func (g *Gui) queryInsertChar(ch rune) func() error {
 				g.removeQueryFilter()
 			}
 			return g.Layout(g.g)
+		case 'b':
+			return g.bookmarkQueryAction()
+		case ':':
+			g.editQueryText()
+			return g.Layout(g.g)
+		case 'H':
+			// The history is listed in the help popup, which the query modal would cover
+			g.queryModalOpen = false
+			err := g.openHistoryAction("")
+			g.queryModalOpen = !g.helpOpen
+			return err
 		}
 		return nil
 	}
@@ -1,6 +1,13 @@
 package gui
 
-import "github.com/jesseduffield/gocui"
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+)
 
 // Context represents the current UI context/mode
 type Context string
@@ -13,11 +20,17 @@ const (
 	ContextSelect      Context = "select"      // Visual selection mode
 	ContextQuery       Context = "query"       // Query builder modal
 	ContextQuerySelect Context = "querySelect" // Query select popup
+	ContextPrompt      Context = "prompt"      // Text prompt (input handled by its editor)
+	ContextTable       Context = "table"       // Table view (keys bound to the table view)
+	ContextExplorer    Context = "explorer"    // JSON explorer (keys bound to the explorer view)
 )
 
 // Binding represents a keybinding with context-aware handling
 type Binding struct {
-	Key         interface{} // gocui.Key or rune
+	// ID names the action in the keybinding config, e.g. "copyJSON". Bindings
+	// without one are fixed.
+	ID          string
+	Key         interface{} // gocui.Key or rune, nil when unbound
 	Modifier    gocui.Modifier
 	ViewName    string // Empty for global, specific view name otherwise
 	Handler     func() error
@@ -27,6 +40,10 @@ type Binding struct {
 	// Contexts maps specific contexts to different handlers (optional)
 	// If current context has a handler here, it's used instead of Handler
 	Contexts map[Context]func() error
+	// BlockWhileTyping makes a key other than a character do nothing in the
+	// filter, help and query builder unless Contexts says otherwise. Characters
+	// are always typed in the filter and passed to the help and query builder.
+	BlockWhileTyping bool
 }
 
 // BindingGroup represents a set of related keybindings
@@ -105,6 +122,15 @@ func (g *Gui) newDisabledReasons() DisabledReasons {
 
 // getContext returns the current UI context
 func (g *Gui) getContext() Context {
+	if g.promptOpen {
+		return ContextPrompt
+	}
+	if g.table != nil && !g.helpOpen && !g.modalOpen {
+		return ContextTable
+	}
+	if g.explorer != nil && !g.helpOpen && !g.modalOpen {
+		return ContextExplorer
+	}
 	if g.querySelectOpen {
 		return ContextQuerySelect
 	}
@@ -129,7 +155,7 @@ func (g *Gui) getContext() Context {
 // KeybindingManager handles registration and execution of keybindings
 type KeybindingManager struct {
 	gui      *Gui
-	bindings []*Binding
+	bindings []*Binding // Effective bindings once resolved
 	guards   Guards
 	disabled DisabledReasons
 }
@@ -154,9 +180,199 @@ func (km *KeybindingManager) RegisterAll(bindings []*Binding) {
 	km.bindings = append(km.bindings, bindings...)
 }
 
+// Resolve applies the keybinding config to the registered bindings and binds
+// every character no action uses to typing. It returns the problems found:
+// unknown actions or keys, and keys bound to several actions.
+func (km *KeybindingManager) Resolve(overrides map[string][]string) []string {
+	bindings, problems := resolveBindings(km.bindings, overrides)
+
+	bound := make(map[rune]bool)
+	for _, b := range bindings {
+		if r, ok := b.Key.(rune); ok && b.ViewName == "" && b.Modifier == gocui.ModNone {
+			bound[r] = true
+		}
+	}
+	for r := ' '; r <= '~'; r++ {
+		if !bound[r] {
+			bindings = append(bindings, &Binding{Key: r, Handler: km.gui.makeFilterCharAction(r)})
+		}
+	}
+
+	km.bindings = bindings
+	return problems
+}
+
+// resolveBindings sets the keys of bindings from the config, which maps action
+// IDs to keys in lazygit's notation; IDs ignore case since viper lowercases
+// them. An action registered with several keys takes the configured keys in
+// order, extra keys working like its last one. When two global bindings, or two
+// of the same view, share a key, a fixed binding beats a configured one, which
+// beats a default; otherwise the first one registered keeps the key. A view
+// binding shadowing a global key is kept, but reported if configured. Unbound
+// bindings are left out of the result.
+func resolveBindings(bindings []*Binding, overrides map[string][]string) ([]*Binding, []string) {
+	var problems []string
+
+	ids := make([]string, 0, len(overrides))
+	for id := range overrides {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	configured := make(map[*Binding]bool)
+	for _, id := range ids {
+		group := findBindings(bindings, id)
+		if len(group) == 0 {
+			problems = append(problems, fmt.Sprintf("Unknown action %q", id))
+			continue
+		}
+		names := overrides[id]
+		if len(names) == 1 && strings.EqualFold(names[0], disabledKey) {
+			names = nil
+		}
+		keys := make([]interface{}, 0, len(names))
+		for _, name := range names {
+			key, err := parseKey(name)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", group[0].ID, err))
+				break
+			}
+			keys = append(keys, key)
+		}
+		if len(keys) < len(names) {
+			continue
+		}
+
+		defaults := make([]interface{}, len(group))
+		for i, b := range group {
+			defaults[i], b.Key = b.Key, nil
+		}
+		// A default key keeps its binding, the others take one for the same
+		// kind of key: an arrow key for nextItem works like ↓, not like j.
+		var others []interface{}
+		for _, key := range keys {
+			if i := slices.Index(defaults, key); i >= 0 && group[i].Key == nil {
+				group[i].Key = key
+				configured[group[i]] = true
+			} else {
+				others = append(others, key)
+			}
+		}
+		for _, key := range others {
+			b, added := keySlot(group, defaults, key)
+			b.Key = key
+			configured[b] = true
+			if added {
+				bindings = append(bindings, b)
+			}
+		}
+	}
+
+	type keyMod struct {
+		view string
+		key  interface{}
+		mod  gocui.Modifier
+	}
+	owners := make(map[keyMod]*Binding)
+	for _, b := range bindings {
+		if b.Key == nil {
+			continue
+		}
+		k := keyMod{b.ViewName, b.Key, b.Modifier}
+		owner, taken := owners[k]
+		if !taken {
+			owners[k] = b
+			continue
+		}
+
+		loser := b
+		if owner.ID != "" && (b.ID == "" || configured[b] && !configured[owner]) {
+			owners[k], loser = b, owner
+		}
+		problems = append(problems, fmt.Sprintf("%s is bound to both %s and %s, %s is unbound",
+			keyScope(b), bindingName(owner), bindingName(b), bindingName(loser)))
+		loser.Key = nil
+	}
+
+	// A key of a view runs instead of the same global key there. Defaults do
+	// that on purpose, so only report it when the config caused it.
+	for _, b := range bindings {
+		if b.Key == nil || b.ViewName == "" {
+			continue
+		}
+		global, taken := owners[keyMod{"", b.Key, b.Modifier}]
+		if taken && (configured[b] || configured[global]) {
+			problems = append(problems, fmt.Sprintf("%s is bound to both %s and %s, %s does nothing there",
+				keyScope(b), bindingName(global), bindingName(b), bindingName(global)))
+		}
+	}
+
+	resolved := make([]*Binding, 0, len(bindings))
+	for _, b := range bindings {
+		if b.Key != nil {
+			resolved = append(resolved, b)
+		}
+	}
+	return resolved, problems
+}
+
+// keyScope names the key of a binding in problems, with its view unless
+// global: "c" or "Tab in details".
+func keyScope(b *Binding) string {
+	if b.ViewName == "" {
+		return keyLabel(b.Key)
+	}
+	return keyLabel(b.Key) + " in " + b.ViewName
+}
+
+// findBindings returns the bindings of an action, one per key.
+func findBindings(bindings []*Binding, id string) []*Binding {
+	var group []*Binding
+	for _, b := range bindings {
+		if b.ID != "" && strings.EqualFold(b.ID, id) {
+			group = append(group, b)
+		}
+	}
+	return group
+}
+
+// bindingName names a binding in problems: its action ID, or its description
+// when fixed.
+func bindingName(b *Binding) string {
+	if b.ID != "" {
+		return b.ID
+	}
+	return b.Description
+}
+
+// KeyLabels shows the first effective key of actions, e.g. "j/k"; unbound
+// actions are left out.
+func (km *KeybindingManager) KeyLabels(ids ...string) string {
+	return km.keyLabelsAt(0, ids)
+}
+
+// AltKeyLabels shows the second key of actions that have one, e.g. "↓/↑".
+func (km *KeybindingManager) AltKeyLabels(ids ...string) string {
+	return km.keyLabelsAt(1, ids)
+}
+
+// keyLabelsAt shows the nth effective key of actions.
+func (km *KeybindingManager) keyLabelsAt(n int, ids []string) string {
+	var labels []string
+	for _, id := range ids {
+		if group := findBindings(km.bindings, id); n < len(group) {
+			labels = append(labels, keyLabel(group[n].Key))
+		}
+	}
+	return strings.Join(labels, "/")
+}
+
 // Apply registers all bindings with gocui
 func (km *KeybindingManager) Apply() error {
 	for _, b := range km.bindings {
+		if b.ViewName == "" {
+			b.Contexts = km.typingContexts(b)
+		}
 		handler := km.wrapHandler(b)
 
 		var err error
@@ -173,6 +389,66 @@ func (km *KeybindingManager) Apply() error {
 	return nil
 }
 
+// keySlot returns the binding a configured key that isn't a default takes:
+// an unbound one for the same kind of key, or else a copy, which is added.
+// Whether it is blocked while typing follows from the key: characters are
+// typed anyway, other keys keep working.
+func keySlot(group []*Binding, defaults []interface{}, key interface{}) (*Binding, bool) {
+	var like *Binding
+	for i, b := range group {
+		if isCharKey(defaults[i]) != isCharKey(key) {
+			continue
+		}
+		if b.Key == nil {
+			b.BlockWhileTyping = isCharKey(key)
+			return b, false
+		}
+		if like == nil {
+			like = b
+		}
+	}
+	if like == nil {
+		like = group[0]
+	}
+	extra := *like
+	extra.BlockWhileTyping = isCharKey(key)
+	return &extra, true
+}
+
+// isCharKey reports whether key types a character, which Space does too.
+func isCharKey(key interface{}) bool {
+	_, isRune := key.(rune)
+	return isRune || key == gocui.KeySpace
+}
+
+// typingContexts adds what a global key does while text is typed. A character
+// (or Space) is typed into the filter, and passed to the help popup and query
+// builder unless Contexts handles it there. Other keys do nothing there if the
+// binding is BlockWhileTyping.
+func (km *KeybindingManager) typingContexts(b *Binding) map[Context]func() error {
+	contexts := make(map[Context]func() error, len(b.Contexts)+3)
+	r, isRune := b.Key.(rune)
+	if b.Key == gocui.KeySpace {
+		r, isRune = ' ', true
+	}
+	switch {
+	case isRune:
+		contexts[ContextHelp] = km.gui.helpKey(r)
+		contexts[ContextQuery] = km.gui.queryInsertChar(r)
+	case b.BlockWhileTyping:
+		contexts[ContextFilter] = km.gui.blockAction
+		contexts[ContextHelp] = km.gui.blockAction
+		contexts[ContextQuery] = km.gui.blockAction
+	}
+	for ctx, handler := range b.Contexts {
+		contexts[ctx] = handler
+	}
+	if isRune {
+		contexts[ContextFilter] = km.gui.makeFilterCharAction(r)
+	}
+	return contexts
+}
+
 // wrapHandler creates a gocui-compatible handler that checks context and disabled state
 func (km *KeybindingManager) wrapHandler(b *Binding) func(*gocui.Gui, *gocui.View) error {
 	return func(gui *gocui.Gui, v *gocui.View) error {
@@ -184,6 +460,11 @@ func (km *KeybindingManager) wrapHandler(b *Binding) func(*gocui.Gui, *gocui.Vie
 			}
 		}
 
+		// The table view and explorer only handle their own keys
+		if (ctx == ContextTable || ctx == ContextExplorer) && b.ViewName == "" {
+			return nil
+		}
+
 		// Check if binding is disabled for this context
 		if b.GetDisabledReason != nil {
 			if reason := b.GetDisabledReason(); reason != "" {
@@ -194,3 +475,47 @@ func (km *KeybindingManager) wrapHandler(b *Binding) func(*gocui.Gui, *gocui.Vie
 		return b.Handler()
 	}
 }
+
+// keyLabels shows the first effective key of actions in help, e.g. "j/k";
+// unbound actions are left out.
+func (g *Gui) keyLabels(ids ...string) string {
+	if g.keybindings == nil {
+		return ""
+	}
+	return g.keybindings.KeyLabels(ids...)
+}
+
+// altKeyLabels shows the second key of actions in help, e.g. "↓/↑".
+func (g *Gui) altKeyLabels(ids ...string) string {
+	if g.keybindings == nil {
+		return ""
+	}
+	return g.keybindings.AltKeyLabels(ids...)
+}
+
+// keyHint is a key and what it does, for a footer.
+type keyHint struct {
+	keys  string
+	label string
+}
+
+// withKeys appends hint, formatted with the keys of the actions, to text. The
+// hint is left out when the actions are unbound.
+func (g *Gui) withKeys(text, hint string, ids ...string) string {
+	if keys := g.keyLabels(ids...); keys != "" {
+		return text + fmt.Sprintf(hint, keys)
+	}
+	return text
+}
+
+// footerHints joins hints like "s sort · Esc close", leaving out those
+// without keys.
+func (g *Gui) footerHints(hints []keyHint) string {
+	var parts []string
+	for _, h := range hints {
+		if h.keys != "" {
+			parts = append(parts, h.keys+" "+h.label)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
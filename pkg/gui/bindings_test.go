@@ -0,0 +1,268 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jesseduffield/gocui"
+)
+
+func testBindings() []*Binding {
+	return []*Binding{
+		{ID: "quit", Key: 'q'},
+		{ID: "copyJSON", Key: 'c'},
+		{ID: "saveJSON", Key: 's'},
+		{ID: "goToPath", Key: gocui.KeyCtrlG},
+		{Key: gocui.KeyBackspace2, Description: "Backspace"},
+		{Key: gocui.MouseLeft, ViewName: "tree"},
+	}
+}
+
+func resolvedKeys(bindings []*Binding) map[string]interface{} {
+	keys := make(map[string]interface{})
+	for _, b := range bindings {
+		if b.ID != "" {
+			keys[b.ID] = b.Key
+		}
+	}
+	return keys
+}
+
+func TestResolveBindings(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		keys      map[string]interface{}
+		problems  []string
+	}{
+		{
+			name:     "defaults",
+			keys:     map[string]interface{}{"quit": 'q', "copyJSON": 'c', "saveJSON": 's', "goToPath": gocui.KeyCtrlG},
+			problems: nil,
+		},
+		{
+			// Viper lowercases the action IDs
+			name:      "remap and unbind",
+			overrides: map[string][]string{"copyjson": {"y"}, "gotopath": {"<c-p>"}, "quit": {"<disabled>"}},
+			keys:      map[string]interface{}{"copyJSON": 'y', "saveJSON": 's', "goToPath": gocui.KeyCtrlP},
+		},
+		{
+			name:      "unknown action and key keep the defaults",
+			overrides: map[string][]string{"fly": {"f"}, "saveJSON": {"<nope>"}},
+			keys:      map[string]interface{}{"quit": 'q', "copyJSON": 'c', "saveJSON": 's', "goToPath": gocui.KeyCtrlG},
+			problems:  []string{`Unknown action "fly"`, `saveJSON: unknown key "<nope>"`},
+		},
+		{
+			name:      "configured key wins a conflict",
+			overrides: map[string][]string{"saveJSON": {"c"}},
+			keys:      map[string]interface{}{"quit": 'q', "saveJSON": 'c', "goToPath": gocui.KeyCtrlG},
+			problems:  []string{"c is bound to both copyJSON and saveJSON, copyJSON is unbound"},
+		},
+		{
+			name:      "first default wins when both are configured",
+			overrides: map[string][]string{"copyJSON": {"x"}, "saveJSON": {"x"}},
+			keys:      map[string]interface{}{"quit": 'q', "copyJSON": 'x', "goToPath": gocui.KeyCtrlG},
+			problems:  []string{"x is bound to both copyJSON and saveJSON, saveJSON is unbound"},
+		},
+		{
+			name:      "fixed keys cannot be taken",
+			overrides: map[string][]string{"goToPath": {"<backspace>"}},
+			keys:      map[string]interface{}{"quit": 'q', "copyJSON": 'c', "saveJSON": 's'},
+			problems:  []string{"Backspace is bound to both goToPath and Backspace, goToPath is unbound"},
+		},
+	}
+	for _, tt := range tests {
+		bindings, problems := resolveBindings(testBindings(), tt.overrides)
+		if got := resolvedKeys(bindings); !reflect.DeepEqual(got, tt.keys) {
+			t.Errorf("%s: keys = %v, expected %v", tt.name, got, tt.keys)
+		}
+		if !reflect.DeepEqual(problems, tt.problems) {
+			t.Errorf("%s: problems = %q, expected %q", tt.name, problems, tt.problems)
+		}
+		if len(bindings) != len(tt.keys)+2 {
+			t.Errorf("%s: %d bindings, expected the fixed ones too", tt.name, len(bindings))
+		}
+	}
+}
+
+func TestResolveViewBindings(t *testing.T) {
+	viewBindings := func() []*Binding {
+		return []*Binding{
+			{ID: "copyJSON", Key: 'c'},
+			{ID: "togglePanel", Key: gocui.KeyTab},
+			{ID: "jumpForward", Key: gocui.KeyTab, ViewName: "details"},
+			{Key: gocui.KeyEnter, ViewName: "details", Description: "Follow reference"},
+			{Key: 'y', ViewName: "explorer", Description: "Copy path"},
+		}
+	}
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		keys      map[string]interface{}
+		problems  []string
+	}{
+		{
+			// jumpForward shadows togglePanel in details on purpose
+			name: "defaults",
+			keys: map[string]interface{}{"copyJSON": 'c', "togglePanel": gocui.KeyTab, "jumpForward": gocui.KeyTab},
+		},
+		{
+			name:      "configured view key shadows a global one",
+			overrides: map[string][]string{"jumpForward": {"c"}},
+			keys:      map[string]interface{}{"copyJSON": 'c', "togglePanel": gocui.KeyTab, "jumpForward": 'c'},
+			problems:  []string{"c in details is bound to both copyJSON and jumpForward, copyJSON does nothing there"},
+		},
+		{
+			name:      "configured global key shadowed by a view",
+			overrides: map[string][]string{"copyJSON": {"y"}},
+			keys:      map[string]interface{}{"copyJSON": 'y', "togglePanel": gocui.KeyTab, "jumpForward": gocui.KeyTab},
+			problems:  []string{"y in explorer is bound to both copyJSON and Copy path, copyJSON does nothing there"},
+		},
+		{
+			name:      "fixed view key wins a conflict in its view",
+			overrides: map[string][]string{"jumpForward": {"<enter>"}},
+			keys:      map[string]interface{}{"copyJSON": 'c', "togglePanel": gocui.KeyTab},
+			problems:  []string{"Enter in details is bound to both jumpForward and Follow reference, jumpForward is unbound"},
+		},
+		{
+			name:      "remapped global key still shadowed by the default view key",
+			overrides: map[string][]string{"togglePanel": {"<tab>"}},
+			keys:      map[string]interface{}{"copyJSON": 'c', "togglePanel": gocui.KeyTab, "jumpForward": gocui.KeyTab},
+			problems:  []string{"Tab in details is bound to both togglePanel and jumpForward, togglePanel does nothing there"},
+		},
+	}
+	for _, tt := range tests {
+		bindings, problems := resolveBindings(viewBindings(), tt.overrides)
+		if got := resolvedKeys(bindings); !reflect.DeepEqual(got, tt.keys) {
+			t.Errorf("%s: keys = %v, expected %v", tt.name, got, tt.keys)
+		}
+		if !reflect.DeepEqual(problems, tt.problems) {
+			t.Errorf("%s: problems = %q, expected %q", tt.name, problems, tt.problems)
+		}
+	}
+}
+
+func TestResolveSeveralKeys(t *testing.T) {
+	keyBindings := func() []*Binding {
+		return []*Binding{
+			{ID: "nextItem", Key: 'j', BlockWhileTyping: true},
+			{ID: "nextItem", Key: gocui.KeyArrowDown},
+		}
+	}
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		keys      []interface{}
+		problems  []string
+	}{
+		{"defaults", nil, []interface{}{'j', gocui.KeyArrowDown}, nil},
+		{"one key replaces both", map[string][]string{"nextItem": {"n"}}, []interface{}{'n'}, nil},
+		{"extra keys", map[string][]string{"nextItem": {"j", "<down>", "<c-n>"}}, []interface{}{'j', gocui.KeyArrowDown, gocui.KeyCtrlN}, nil},
+		{"arrow key takes the arrow binding", map[string][]string{"nextItem": {"<down>"}}, []interface{}{gocui.KeyArrowDown}, nil},
+		{"default keys keep their binding", map[string][]string{"nextItem": {"<down>", "j"}}, []interface{}{'j', gocui.KeyArrowDown}, nil},
+		{"unbind all", map[string][]string{"nextItem": {"<disabled>"}}, nil, nil},
+		{"bad key keeps the defaults", map[string][]string{"nextItem": {"n", "<nope>"}}, []interface{}{'j', gocui.KeyArrowDown}, []string{`nextItem: unknown key "<nope>"`}},
+	}
+	for _, tt := range tests {
+		bindings, problems := resolveBindings(keyBindings(), tt.overrides)
+		var keys []interface{}
+		for _, b := range bindings {
+			keys = append(keys, b.Key)
+		}
+		if !reflect.DeepEqual(keys, tt.keys) {
+			t.Errorf("%s: keys = %v, expected %v", tt.name, keys, tt.keys)
+		}
+		if !reflect.DeepEqual(problems, tt.problems) {
+			t.Errorf("%s: problems = %q, expected %q", tt.name, problems, tt.problems)
+		}
+	}
+
+	// Whether a new key is blocked while typing follows from the key
+	blocked := func(overrides map[string][]string) []bool {
+		bindings, _ := resolveBindings(keyBindings(), overrides)
+		var blocked []bool
+		for _, b := range bindings {
+			blocked = append(blocked, b.BlockWhileTyping)
+		}
+		return blocked
+	}
+	if got := blocked(map[string][]string{"nextItem": {"<c-n>", "n"}}); !reflect.DeepEqual(got, []bool{true, false}) {
+		t.Errorf("<c-n>, n: blocked = %v, expected n blocked and <c-n> not", got)
+	}
+	onlyJ := func() []*Binding { return []*Binding{{ID: "nextItem", Key: 'j', BlockWhileTyping: true}} }
+	bindings, _ := resolveBindings(onlyJ(), map[string][]string{"nextItem": {"j", "<down>"}})
+	if len(bindings) != 2 || bindings[1].BlockWhileTyping {
+		t.Error("<down> added to j should keep working while typing")
+	}
+}
+
+func TestResolveTableAndExplorerBindings(t *testing.T) {
+	g := &Gui{}
+	g.views.table = "table"
+	g.views.explorer = "explorer"
+	bindings := append(g.tableBindings(), g.explorerBindings()...)
+
+	resolved, problems := resolveBindings(bindings, map[string][]string{
+		"tableSort":        {"o"},
+		"explorerCopyPath": {"Y"},
+		"tableClose":       {"<esc>"},
+	})
+	if len(problems) > 0 {
+		t.Errorf("problems = %q", problems)
+	}
+
+	km := &KeybindingManager{bindings: resolved}
+	if got := km.KeyLabels("tableSort", "explorerCopyPath", "tableClose"); got != "o/Y/Esc" {
+		t.Errorf("KeyLabels = %q, expected o/Y/Esc", got)
+	}
+	for _, b := range resolved {
+		if b.ViewName == "table" && b.Key == 'q' {
+			t.Error("q should no longer close the table")
+		}
+	}
+}
+
+func TestKeyLabels(t *testing.T) {
+	bindings, _ := resolveBindings(testBindings(), map[string][]string{"saveJSON": {"<disabled>"}})
+	km := &KeybindingManager{bindings: bindings}
+	if got := km.KeyLabels("copyJSON", "saveJSON", "goToPath"); got != "c/Ctrl-g" {
+		t.Errorf("KeyLabels = %q, expected c/Ctrl-g", got)
+	}
+
+	km.bindings = []*Binding{{ID: "prevItem", Key: 'k'}, {ID: "nextItem", Key: 'j'}, {ID: "nextItem", Key: gocui.KeyArrowDown}}
+	if got := km.AltKeyLabels("prevItem", "nextItem"); got != "↓" {
+		t.Errorf("AltKeyLabels = %q, expected ↓", got)
+	}
+}
+
+func TestDefaultBindingsHaveNoProblems(t *testing.T) {
+	g := &Gui{}
+	g.views.details = "details"
+	g.views.table = "table"
+	g.views.explorer = "explorer"
+	km := g.newKeybindingManager()
+	km.RegisterAll(g.globalBindings(km))
+	km.RegisterAll(g.navigationBindings(km))
+	km.RegisterAll(g.filterBindings(km))
+	km.RegisterAll(g.actionBindings(km))
+	km.RegisterAll(g.mouseBindings())
+	km.RegisterAll(g.tableBindings())
+	km.RegisterAll(g.explorerBindings())
+
+	if problems := km.Resolve(nil); len(problems) > 0 {
+		t.Errorf("default bindings have problems: %q", problems)
+	}
+
+	// Every printable character is bound, to an action or to typing
+	bound := make(map[rune]bool)
+	for _, b := range km.bindings {
+		if r, ok := b.Key.(rune); ok && b.ViewName == "" {
+			bound[r] = true
+		}
+	}
+	for r := ' '; r <= '~'; r++ {
+		if !bound[r] {
+			t.Errorf("%q is not bound", r)
+		}
+	}
+}
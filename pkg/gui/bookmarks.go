@@ -0,0 +1,200 @@
+package gui
+
+import (
+	"fmt"
+
+	"github.com/marjoballabani/lazyfire/pkg/config"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// bookmarkTarget returns the document or collection b bookmarks in the current panel.
+func (g *Gui) bookmarkTarget() string {
+	switch g.currentColumn {
+	case "collections":
+		filtered := g.getFilteredCollections()
+		if g.selectedCollectionIdx < len(filtered) {
+			return filtered[g.selectedCollectionIdx].Path
+		}
+	case "tree":
+		filtered := g.getFilteredTreeNodes()
+		if g.selectedTreeIdx < len(filtered) {
+			return filtered[g.selectedTreeIdx].Path
+		}
+	case "details":
+		if isDiffDocumentPath(g.currentDocPath) {
+			return g.currentDocPath
+		}
+	}
+	return ""
+}
+
+// addBookmarkAction asks for a name and bookmarks the selected document or collection.
+func (g *Gui) addBookmarkAction() error {
+	path := g.bookmarkTarget()
+	if path == "" || g.currentProject == "" {
+		g.logCommand("bookmark", "Select a collection or document to bookmark", "error")
+		return nil
+	}
+	return g.openPrompt("Bookmark "+path+" as", path, func(name string) {
+		g.saveBookmark(config.Bookmark{Name: name, Project: g.currentProject, Database: firebase.DefaultDatabase, Path: path})
+	})
+}
+
+// bookmarkQueryAction asks for a name and bookmarks the query builder's state.
+func (g *Gui) bookmarkQueryAction() error {
+	if g.queryCollection == "" {
+		return nil
+	}
+	query := &config.BookmarkQuery{Filters: queryFilterConfigs(g.queryFilters), OrderBy: g.queryOrderBy, OrderDir: g.queryOrderDir, Limit: g.queryLimit}
+	collection := g.queryCollection
+	return g.openPrompt("Bookmark query on "+collection+" as", "Query "+collection, func(name string) {
+		g.saveBookmark(config.Bookmark{Name: name, Project: g.currentProject, Database: firebase.DefaultDatabase, Path: collection, Query: query})
+	})
+}
+
+// saveBookmark adds a bookmark to the bookmarks file.
+func (g *Gui) saveBookmark(b config.Bookmark) {
+	if b.Name == "" {
+		b.Name = b.Path
+	}
+	path := config.BookmarksPath()
+	bookmarks, err := config.LoadBookmarks(path)
+	if err == nil {
+		err = config.SaveBookmarks(path, config.AddBookmark(bookmarks, b))
+	}
+	if err != nil {
+		g.logCommand("bookmark", fmt.Sprintf("Failed to save bookmark: %v", err), "error")
+		return
+	}
+	g.logCommand("bookmark", fmt.Sprintf("Bookmarked %s as %q", b.Path, b.Name), "success")
+}
+
+// updateBookmarks rewrites the bookmarks file with f applied to its list.
+func (g *Gui) updateBookmarks(f func([]config.Bookmark) []config.Bookmark) error {
+	path := config.BookmarksPath()
+	bookmarks, err := config.LoadBookmarks(path)
+	if err == nil {
+		err = config.SaveBookmarks(path, f(bookmarks))
+	}
+	return err
+}
+
+// bookmarkKind is the popup's key column for a bookmark.
+func bookmarkKind(b config.Bookmark) string {
+	switch {
+	case b.Query != nil:
+		return "query"
+	case isDiffDocumentPath(b.Path):
+		return "document"
+	}
+	return "collection"
+}
+
+// openBookmarksAction lists the bookmarks matching filter. Enter opens one,
+// r renames it, d deletes it and / filters the list.
+func (g *Gui) openBookmarksAction(filter string) error {
+	bookmarks, err := config.LoadBookmarks(config.BookmarksPath())
+	if err != nil {
+		g.logCommand("bookmark", err.Error(), "error")
+		return nil
+	}
+	if len(bookmarks) == 0 {
+		g.logCommand("bookmark", g.withKeys("No bookmarks yet", ", press %s on a document or collection", "addBookmark"), "error")
+		return nil
+	}
+
+	filterKeys := map[rune]func() error{
+		'/': func() error {
+			return g.openPrompt("Filter bookmarks", filter, func(text string) {
+				_ = g.openBookmarksAction(text)
+			})
+		},
+	}
+
+	title := "Bookmarks"
+	if filter != "" {
+		title = fmt.Sprintf("Bookmarks matching %q", filter)
+	}
+	items := []PopupItem{{Label: title, IsHeader: true}}
+	for _, b := range bookmarks {
+		b := b
+		if filter != "" && !FuzzyMatch(b.Name+" "+b.Project+" "+b.Path, filter) {
+			continue
+		}
+		items = append(items, PopupItem{
+			Key:   bookmarkKind(b),
+			Label: fmt.Sprintf("%s \033[90m%s:%s\033[0m", b.Name, b.Project, b.Path),
+			Action: func() error {
+				return g.openBookmark(b)
+			},
+			Keys: map[rune]func() error{
+				'/': filterKeys['/'],
+				'r': func() error {
+					return g.openPrompt("Rename bookmark", b.Name, func(name string) {
+						// Find it again: the file may have changed since the list was shown
+						if err := g.updateBookmarks(func(list []config.Bookmark) []config.Bookmark {
+							if i := config.FindBookmark(list, b); i >= 0 && name != "" {
+								list[i].Name = name
+							}
+							return list
+						}); err != nil {
+							g.logCommand("bookmark", fmt.Sprintf("Failed to rename bookmark: %v", err), "error")
+						}
+						_ = g.openBookmarksAction(filter)
+					})
+				},
+				'd': func() error {
+					if err := g.updateBookmarks(func(list []config.Bookmark) []config.Bookmark {
+						if i := config.FindBookmark(list, b); i >= 0 {
+							list = append(list[:i], list[i+1:]...)
+						}
+						return list
+					}); err != nil {
+						g.logCommand("bookmark", fmt.Sprintf("Failed to delete bookmark: %v", err), "error")
+						return nil
+					}
+					g.logCommand("bookmark", fmt.Sprintf("Deleted bookmark %q", b.Name), "success")
+					return g.openBookmarksAction(filter)
+				},
+			},
+		})
+	}
+	if filter != "" {
+		items = append(items, PopupItem{
+			Key:    "/",
+			Label:  "Show all bookmarks",
+			Action: func() error { return g.openBookmarksAction("") },
+			Keys:   filterKeys,
+		})
+	}
+
+	if err := g.openMenu("Bookmarks", items); err != nil {
+		return err
+	}
+	g.helpPopup.Footer = g.footerHints([]keyHint{
+		{g.keyLabels("confirm"), "open"}, {"/", "filter"}, {"r", "rename"}, {"d", "delete"}, {g.keyLabels("return"), "close"},
+	})
+	return nil
+}
+
+// openBookmark switches to the bookmark's project if needed and opens it in
+// the tree; a query bookmark restores the query builder state and runs it.
+func (g *Gui) openBookmark(b config.Bookmark) error {
+	if b.Database != "" && b.Database != firebase.DefaultDatabase {
+		g.logCommand("bookmark", fmt.Sprintf("Can't open %s: only the %s database is browsable", b.Path, firebase.DefaultDatabase), "error")
+		return nil
+	}
+
+	switch bookmarkKind(b) {
+	case "document":
+		return g.jumpTo(b.Project, b.Path)
+	case "collection":
+		return g.navigateTo(b.Project, b.Path, func() {
+			_ = g.setFocus(g.g, "tree")
+		})
+	}
+
+	return g.openQueryAt(b.Project, b.Path, func() {
+		g.restoreQueryState(b.Query.Filters, b.Query.OrderBy, b.Query.OrderDir, b.Query.Limit)
+	}, true)
+}
@@ -0,0 +1,83 @@
+package gui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// typeLanguages lists the generators offered by the model menu, in order.
+var typeLanguages = []struct {
+	Key   string // Shown as the menu key
+	Lang  string
+	Label string
+}{
+	{"TypeScript", firebase.LangTypeScript, "Interfaces with Firestore web SDK types"},
+	{"Go", firebase.LangGo, "Structs with firestore tags"},
+	{"Dataclass", firebase.LangDataclass, "Python dataclasses"},
+	{"Pydantic", firebase.LangPydantic, "Python pydantic models"},
+}
+
+// generateTypesAction emits model definitions for the selected collection. The
+// schema report shown in details is reused; otherwise documents are sampled.
+func (g *Gui) generateTypesAction() error {
+	if g.isSchemaShown() {
+		return g.openTypeLanguageMenu(g.schemaView)
+	}
+
+	collection := g.getSelectedCollection()
+	if collection == "" {
+		g.logCommand("models", "Select a collection or document to generate models for", "error")
+		return nil
+	}
+	return g.promptSchemaSample("models", collection, g.openTypeLanguageMenu)
+}
+
+// openTypeLanguageMenu asks which language to generate.
+func (g *Gui) openTypeLanguageMenu(s *firebase.Schema) error {
+	items := []PopupItem{
+		{Label: fmt.Sprintf("%s from %d sampled documents", firebase.TypeName(s.Collection), s.Documents), IsHeader: true},
+	}
+	for _, l := range typeLanguages {
+		lang := l.Lang
+		items = append(items, PopupItem{Key: l.Key, Label: l.Label, Action: func() error {
+			return g.openTypeOutputMenu(s, lang)
+		}})
+	}
+	return g.openMenu("Generate models", items)
+}
+
+// openTypeOutputMenu generates the models and asks where they go.
+func (g *Gui) openTypeOutputMenu(s *firebase.Schema, lang string) error {
+	code, err := firebase.GenerateTypes(s, lang)
+	if err != nil {
+		g.logCommand("models", err.Error(), "error")
+		return nil
+	}
+
+	name := firebase.TypeName(s.Collection)
+	return g.openMenu("Generated "+name, []PopupItem{
+		{Key: "Copy", Label: "Copy to clipboard", Action: func() error {
+			if err := copyToClipboard(code); err != nil {
+				g.logCommand("models", fmt.Sprintf("Failed to copy: %v", err), "error")
+				return nil
+			}
+			g.logCommand("models", fmt.Sprintf("Copied %s models to clipboard", name), "success")
+			return nil
+		}},
+		{Key: "Save", Label: "Save to file", Action: func() error {
+			target := filepath.Join("~", "Downloads", strings.ToLower(name)+firebase.TypeExtensions[lang])
+			return g.openPrompt("Save models to", target, func(path string) {
+				path = expandHome(strings.TrimSpace(path))
+				if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+					g.logCommand("models", fmt.Sprintf("Failed to save: %v", err), "error")
+					return
+				}
+				g.logCommand("models", fmt.Sprintf("Saved to %s", path), "success")
+			})
+		}},
+	})
+}
@@ -0,0 +1,212 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// maxCopyPreviewItems limits how many planned writes the preview lists.
+const maxCopyPreviewItems = 200
+
+// copyToAction copies the selected collection or document subtree (or every
+// document picked in select mode) to another project, database or collection.
+// It asks for the destination and how to handle references, then shows the
+// planned writes for confirmation before anything is written.
+func (g *Gui) copyToAction() error {
+	roots := g.getCopyRoots()
+	if len(roots) == 0 {
+		g.logCommand("copy", "Select a collection or documents to copy", "error")
+		return nil
+	}
+	if len(g.projects) == 0 {
+		g.logCommand("copy", "No projects available", "error")
+		return nil
+	}
+
+	what := roots[0]
+	if len(roots) > 1 {
+		what = fmt.Sprintf("%d documents", len(roots))
+	}
+
+	items := []PopupItem{{Label: "Destination project", IsHeader: true}}
+	for _, p := range g.projects {
+		project := p.ID
+		label := p.DisplayName
+		if project == g.currentProject {
+			label += " (current)"
+		}
+		items = append(items, PopupItem{Key: project, Label: label, Action: func() error {
+			return g.promptCopyDestination(roots, project)
+		}})
+	}
+	return g.openMenu("Copy "+what+" to", items)
+}
+
+// getCopyRoots returns the paths to copy: the documents picked in select mode,
+// or the collection or document selected in the current panel.
+func (g *Gui) getCopyRoots() []string {
+	if g.currentColumn == "tree" && g.selectMode && len(g.selectedDocs) > 0 {
+		filtered := g.getFilteredTreeNodes()
+		indices := make([]int, 0, len(g.selectedDocs))
+		for idx := range g.selectedDocs {
+			indices = append(indices, idx)
+		}
+		sort.Ints(indices)
+
+		var roots []string
+		for _, idx := range indices {
+			if idx < len(filtered) && filtered[idx].Type == "document" {
+				roots = append(roots, filtered[idx].Path)
+			}
+		}
+		return roots
+	}
+
+	if root := g.getExportRoot(); root != "" {
+		return []string{root}
+	}
+	return nil
+}
+
+// promptCopyDestination asks for the destination database and collection.
+func (g *Gui) promptCopyDestination(roots []string, project string) error {
+	return g.openPrompt("Database in "+project, firebase.DefaultDatabase, func(database string) {
+		if database == "" {
+			return
+		}
+		source := firebase.CopySourceCollection(roots[0])
+		_ = g.openPrompt("Copy "+source+" to collection", source, func(collection string) {
+			if collection == "" {
+				return
+			}
+			_ = g.openCopyReferencesMenu(firebase.CopyOptions{
+				Roots:          roots,
+				DestProject:    project,
+				DestDatabase:   database,
+				DestCollection: collection,
+			})
+		})
+	})
+}
+
+// openCopyReferencesMenu lets the user choose how reference values are rewritten.
+func (g *Gui) openCopyReferencesMenu(opts firebase.CopyOptions) error {
+	plan := func(mode string) func() error {
+		return func() error {
+			opts.References = mode
+			g.startCopyPlan(opts)
+			return g.Layout(g.g)
+		}
+	}
+
+	items := []PopupItem{
+		{Label: "References to " + g.currentProject, IsHeader: true},
+		{Key: "keep", Label: "Leave pointing at the source", Action: plan(firebase.CopyRefsKeep)},
+		{Key: "retarget", Label: "Point at the destination database", Action: plan(firebase.CopyRefsRetarget)},
+		{Key: "remap", Label: "Follow copied documents to their new path", Action: plan(firebase.CopyRefsRemap)},
+	}
+	return g.openMenu("Copy references", items)
+}
+
+// startCopyPlan reads the source documents in the background and opens the preview.
+func (g *Gui) startCopyPlan(opts firebase.CopyOptions) {
+	g.logCommand("copy", fmt.Sprintf("Reading %d root(s) to copy...", len(opts.Roots)), "running")
+
+	go func() {
+		plan, err := g.firebaseClient.PlanCopy(context.Background(), opts)
+
+		g.g.Update(func(gui *gocui.Gui) error {
+			if err != nil {
+				g.updateCommand("copy", fmt.Sprintf("Failed: %v", err), "error")
+				return nil
+			}
+			if len(plan.Writes) == 0 {
+				g.updateCommand("copy", "Nothing to copy", "error")
+				return nil
+			}
+			g.updateCommand("copy", fmt.Sprintf("%d documents ready to copy, review and commit", len(plan.Writes)), "success")
+			return g.openCopyPreview(plan)
+		})
+	}()
+}
+
+// openCopyPreview lists the planned writes. Nothing is written until Commit is chosen.
+func (g *Gui) openCopyPreview(plan *firebase.CopyPlan) error {
+	opts := plan.Options
+	dest := opts.DestProject
+	if opts.DestDatabase != firebase.DefaultDatabase {
+		dest += "/" + opts.DestDatabase
+	}
+
+	summary := fmt.Sprintf("%d writes to %s, existing documents are replaced", len(plan.Writes), dest)
+	if plan.References > 0 {
+		summary += fmt.Sprintf(", %d references rewritten", plan.References)
+	}
+
+	items := []PopupItem{
+		{Label: summary, IsHeader: true},
+		{Key: "Commit", Label: fmt.Sprintf("Write %d documents", len(plan.Writes)), Action: func() error {
+			g.startCopyCommit(plan)
+			return g.Layout(g.g)
+		}},
+		{Key: "Cancel", Label: "Close without writing"},
+		{Label: "Writes", IsHeader: true},
+	}
+	for i, w := range plan.Writes {
+		if i == maxCopyPreviewItems {
+			items = append(items, PopupItem{Label: fmt.Sprintf("...and %d more", len(plan.Writes)-i), IsHeader: true})
+			break
+		}
+		items = append(items, PopupItem{Label: w.Source + " → " + w.Dest})
+	}
+	return g.openMenu("Copy preview", items)
+}
+
+// startCopyCommit writes a reviewed plan in the background.
+func (g *Gui) startCopyCommit(plan *firebase.CopyPlan) {
+	opts := plan.Options
+	g.logCommand("copy", fmt.Sprintf("Writing %d documents to %s...", len(plan.Writes), opts.DestProject), "running")
+
+	go func() {
+		summary, err := g.firebaseClient.CommitCopy(context.Background(), plan, func(done, total int) {
+			g.g.Update(func(gui *gocui.Gui) error {
+				g.updateCommand("copy", fmt.Sprintf("%d/%d documents written", done, total), "running")
+				return nil
+			})
+		})
+
+		g.g.Update(func(gui *gocui.Gui) error {
+			if summary != nil {
+				for i, f := range summary.Failed {
+					if i == maxImportFailuresLogged {
+						g.logCommand("copy", fmt.Sprintf("...and %d more failures", len(summary.Failed)-i), "error")
+						break
+					}
+					g.logCommand("copy", fmt.Sprintf("%s: %s", f.Path, f.Error), "error")
+				}
+			}
+
+			if err != nil {
+				g.logCommand("copy", fmt.Sprintf("Failed: %v", err), "error")
+				return nil
+			}
+
+			status := "success"
+			if len(summary.Failed) > 0 {
+				status = "error"
+			}
+			g.logCommand("copy", fmt.Sprintf("Copied %d documents to %s/%s, %d failed",
+				len(summary.Written), opts.DestProject, opts.DestCollection, len(summary.Failed)), status)
+
+			// Re-read the destination next time it is expanded
+			if opts.DestProject == g.currentProject {
+				delete(g.collectionCache, opts.DestCollection)
+			}
+			return nil
+		})
+	}()
+}
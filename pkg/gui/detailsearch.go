@@ -0,0 +1,261 @@
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+)
+
+// grepPrefix starts a details filter that shows only the matching lines, like
+// vim's :g/pattern/. Other filters not starting with "." search the document.
+const grepPrefix = "g/"
+
+const (
+	searchMatchBg   = "\033[43m" // Background of matches
+	searchCurrentBg = "\033[45m" // Background of the current match
+	searchBgReset   = "\033[49m"
+)
+
+// detailsMatch is a match of a details search in the document JSON.
+type detailsMatch struct {
+	Line  int // Line of the JSON as json.MarshalIndent writes it
+	Start int // Byte offsets of the match within the line
+	End   int
+}
+
+// detailsSearch is a vim-style search of the document shown in details: all
+// matches are highlighted in the full JSON and n/N move between them.
+type detailsSearch struct {
+	Pattern         string
+	Path            string // Document searched
+	Literal         bool   // Pattern is an invalid regexp, searched for literally
+	Matches         []detailsMatch
+	Current         int      // Selected match
+	jsonStart       int      // Line of the rendered content where the JSON starts
+	lines           []string // Rendered content without highlights
+	rendered        string   // Content with the matches highlighted, for renderedCurrent
+	renderedCurrent int      // Selected match when rendered was made
+}
+
+// grepFilter returns the text of a details filter like g/email, and whether
+// the filter is a grep.
+func grepFilter(filter string) (string, bool) {
+	if !strings.HasPrefix(filter, grepPrefix) {
+		return "", false
+	}
+	return filter[len(grepPrefix):], true
+}
+
+// isDetailsSearch reports whether a details filter is a search rather than a
+// jq query or a grep.
+func isDetailsSearch(filter string) bool {
+	_, grep := grepFilter(filter)
+	return filter != "" && !grep && !strings.HasPrefix(filter, ".")
+}
+
+// compileDetailsSearch turns a search into a regexp. It ignores case unless the
+// pattern has an uppercase letter (vim's smartcase). An invalid regexp is
+// searched for literally, which the second result reports.
+func compileDetailsSearch(pattern string) (*regexp.Regexp, bool) {
+	flags := ""
+	if strings.ToLower(pattern) == pattern {
+		flags = "(?i)"
+	}
+	if re, err := regexp.Compile(flags + pattern); err == nil {
+		return re, false
+	}
+	return regexp.MustCompile(flags + regexp.QuoteMeta(pattern)), true
+}
+
+// findDetailsMatches returns the non-empty matches of re in every line.
+func findDetailsMatches(lines []string, re *regexp.Regexp) []detailsMatch {
+	var matches []detailsMatch
+	for i, line := range lines {
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			if loc[1] > loc[0] {
+				matches = append(matches, detailsMatch{Line: i, Start: loc[0], End: loc[1]})
+			}
+		}
+	}
+	return matches
+}
+
+// highlightSpan is a background for a byte range of the visible text of a line.
+type highlightSpan struct {
+	Start int
+	End   int
+	Bg    string
+}
+
+// highlightSpans gives ranges of a colorized line a background. Offsets count
+// the visible text only, skipping the escape sequences of the colors; spans
+// must be sorted and not overlap.
+func highlightSpans(line string, spans []highlightSpan) string {
+	var b strings.Builder
+	visible, next, open := 0, 0, false
+	for i := 0; i < len(line); {
+		if line[i] == '\033' {
+			end := i + 1
+			if end < len(line) && line[end] == '[' {
+				end++
+				for end < len(line) && (line[end] < 0x40 || line[end] > 0x7e) {
+					end++
+				}
+				end++
+			}
+			end = min(end, len(line))
+			b.WriteString(line[i:end])
+			if open {
+				// A reset within the match would end its background
+				b.WriteString(spans[next].Bg)
+			}
+			i = end
+			continue
+		}
+
+		if open && visible == spans[next].End {
+			b.WriteString(searchBgReset)
+			open = false
+			next++
+		}
+		if !open && next < len(spans) && visible == spans[next].Start {
+			b.WriteString(spans[next].Bg)
+			open = true
+		}
+		b.WriteByte(line[i])
+		i++
+		visible++
+	}
+	if open {
+		b.WriteString(searchBgReset)
+	}
+	return b.String()
+}
+
+// render highlights every match, the current one differently.
+func (s *detailsSearch) render() string {
+	spans := make(map[int][]highlightSpan)
+	for i, m := range s.Matches {
+		bg := searchMatchBg
+		if i == s.Current {
+			bg = searchCurrentBg
+		}
+		spans[m.Line] = append(spans[m.Line], highlightSpan{Start: m.Start, End: m.End, Bg: bg})
+	}
+
+	lines := make([]string, len(s.lines))
+	copy(lines, s.lines)
+	for line, lineSpans := range spans {
+		if i := s.jsonStart + line; i < len(lines) {
+			lines[i] = highlightSpans(lines[i], lineSpans)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// footer counts the matches for the details panel, e.g. match 3/17, and
+// tells when an invalid regexp was searched for literally.
+func (s *detailsSearch) footer() string {
+	count := "no matches"
+	if len(s.Matches) > 0 {
+		count = fmt.Sprintf("match %d/%d", s.Current+1, len(s.Matches))
+	}
+	if s.Literal {
+		return "invalid regexp, searched literally: " + count
+	}
+	return count
+}
+
+// renderSearchDetails shows the full document with the matches of the search
+// highlighted. A new search starts at the first match from the top of the view.
+func (g *Gui) renderSearchDetails(v *gocui.View, pattern string) {
+	s := g.detailsSearch
+	if s == nil || s.Pattern != pattern || s.Path != g.currentDocPath {
+		data, err := json.MarshalIndent(g.currentDocData, "", "  ")
+		if err != nil {
+			v.SetContent(fmt.Sprintf("Error formatting data: %v\n", err))
+			return
+		}
+		content, jsonStart := g.documentContent(data)
+		re, literal := compileDetailsSearch(pattern)
+		s = &detailsSearch{
+			Pattern:         pattern,
+			Path:            g.currentDocPath,
+			Literal:         literal,
+			Matches:         findDetailsMatches(strings.Split(string(data), "\n"), re),
+			jsonStart:       jsonStart,
+			lines:           strings.Split(content, "\n"),
+			renderedCurrent: -1,
+		}
+		for i, m := range s.Matches {
+			if jsonStart+m.Line >= g.detailsScrollPos {
+				s.Current = i
+				break
+			}
+		}
+		g.detailsSearch = s
+		g.scrollToDetailsMatch()
+	}
+
+	if s.renderedCurrent != s.Current {
+		s.rendered = s.render()
+		s.renderedCurrent = s.Current
+	}
+	v.SetContent(s.rendered)
+}
+
+// activeDetailsSearch returns the committed search of the shown document, or nil.
+func (g *Gui) activeDetailsSearch() *detailsSearch {
+	s := g.detailsSearch
+	if s == nil || g.isFilteringPanel("details") || !isDetailsSearch(g.detailsFilter) ||
+		s.Pattern != g.detailsFilter || s.Path != g.currentDocPath {
+		return nil
+	}
+	return s
+}
+
+// detailsSearchFooter is the match count of the search typed or committed in
+// details, or "" when there is none.
+func (g *Gui) detailsSearchFooter() string {
+	filter := g.getDetailsFilter()
+	s := g.detailsSearch
+	if g.currentDocData == nil || !isDetailsSearch(filter) || s == nil || s.Pattern != filter || s.Path != g.currentDocPath {
+		return ""
+	}
+	return s.footer()
+}
+
+// scrollToDetailsMatch scrolls details to show the current match a few lines
+// from the top.
+func (g *Gui) scrollToDetailsMatch() {
+	s := g.detailsSearch
+	if s == nil || len(s.Matches) == 0 {
+		return
+	}
+	g.detailsScrollPos = max(0, s.jsonStart+s.Matches[s.Current].Line-3)
+}
+
+// moveDetailsMatch moves to the match delta away from the current one,
+// wrapping around at either end.
+func (g *Gui) moveDetailsMatch(delta int) error {
+	s := g.activeDetailsSearch()
+	if g.currentColumn != "details" || s == nil || len(s.Matches) == 0 {
+		return nil
+	}
+	s.Current = (s.Current + delta + len(s.Matches)) % len(s.Matches)
+	g.scrollToDetailsMatch()
+	return g.Layout(g.g)
+}
+
+// nextDetailsMatchAction jumps to the next match of the details search.
+func (g *Gui) nextDetailsMatchAction() error {
+	return g.moveDetailsMatch(1)
+}
+
+// prevDetailsMatchAction jumps to the previous match of the details search.
+func (g *Gui) prevDetailsMatchAction() error {
+	return g.moveDetailsMatch(-1)
+}
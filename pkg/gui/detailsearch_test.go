@@ -0,0 +1,109 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetailsFilterModes(t *testing.T) {
+	tests := []struct {
+		filter string
+		search bool
+		grep   string
+		isGrep bool
+	}{
+		{"email", true, "", false},
+		{`\d{4}`, true, "", false},
+		{"g/email", false, "email", true},
+		{".address.city", false, "", false},
+		{"", false, "", false},
+	}
+	for _, tt := range tests {
+		if got := isDetailsSearch(tt.filter); got != tt.search {
+			t.Errorf("isDetailsSearch(%q) = %v, expected %v", tt.filter, got, tt.search)
+		}
+		if text, ok := grepFilter(tt.filter); text != tt.grep || ok != tt.isGrep {
+			t.Errorf("grepFilter(%q) = %q, %v, expected %q, %v", tt.filter, text, ok, tt.grep, tt.isGrep)
+		}
+	}
+}
+
+func TestFindDetailsMatches(t *testing.T) {
+	lines := []string{
+		`{`,
+		`  "email": "ada@example.com",`,
+		`  "Name": "Ada",`,
+		`  "zip": "1010"`,
+		`}`,
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []detailsMatch
+		literal  bool
+	}{
+		// Lowercase ignores case
+		{"ada", []detailsMatch{{Line: 1, Start: 12, End: 15}, {Line: 2, Start: 11, End: 14}}, false},
+		// An uppercase letter makes it case-sensitive
+		{"Name", []detailsMatch{{Line: 2, Start: 3, End: 7}}, false},
+		// Regexes, skipping empty matches
+		{`\d+`, []detailsMatch{{Line: 3, Start: 10, End: 14}}, false},
+		{`x*`, []detailsMatch{{Line: 1, Start: 17, End: 18}}, false},
+		// Invalid regexes are searched for literally
+		{"(ada", nil, true},
+		{"com\",", []detailsMatch{{Line: 1, Start: 24, End: 29}}, false},
+	}
+	for _, tt := range tests {
+		re, literal := compileDetailsSearch(tt.pattern)
+		got := findDetailsMatches(lines, re)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("findDetailsMatches(%q) = %v, expected %v", tt.pattern, got, tt.expected)
+		}
+		if literal != tt.literal {
+			t.Errorf("compileDetailsSearch(%q) literal = %v, expected %v", tt.pattern, literal, tt.literal)
+		}
+	}
+}
+
+func TestHighlightSpans(t *testing.T) {
+	line := "  \033[36m\"email\"\033[0m\033[90m:\033[0m \033[32m\"ada@example.com\"\033[0m"
+	spans := []highlightSpan{
+		{Start: 5, End: 8, Bg: searchMatchBg},    // "ail" inside the key
+		{Start: 8, End: 13, Bg: searchCurrentBg}, // across the colors of `": "a`
+		{Start: 24, End: 27, Bg: searchMatchBg},  // "com" at the end
+	}
+
+	expected := "  \033[36m\"em" +
+		searchMatchBg + "ail" + searchBgReset +
+		searchCurrentBg + "\"\033[0m" + searchCurrentBg + "\033[90m" + searchCurrentBg + ":\033[0m" + searchCurrentBg + " \033[32m" + searchCurrentBg + "\"a" + searchBgReset +
+		"da@example." + searchMatchBg + "com" + searchBgReset + "\"\033[0m"
+	if got := highlightSpans(line, spans); got != expected {
+		t.Errorf("highlightSpans =\n%q\nexpected\n%q", got, expected)
+	}
+
+	if got := highlightSpans(line, nil); got != line {
+		t.Errorf("highlightSpans without spans = %q, expected the line unchanged", got)
+	}
+}
+
+func TestDetailsSearchRender(t *testing.T) {
+	s := &detailsSearch{
+		Matches:   []detailsMatch{{Line: 0, Start: 0, End: 1}, {Line: 1, Start: 2, End: 3}},
+		Current:   1,
+		jsonStart: 1,
+		lines:     []string{"header", "{", "  x", "}"},
+	}
+	expected := "header\n" + searchMatchBg + "{" + searchBgReset + "\n  " + searchCurrentBg + "x" + searchBgReset + "\n}"
+	if got := s.render(); got != expected {
+		t.Errorf("render = %q, expected %q", got, expected)
+	}
+	if got := s.footer(); got != "match 2/2" {
+		t.Errorf("footer = %q, expected match 2/2", got)
+	}
+	if got := (&detailsSearch{}).footer(); got != "no matches" {
+		t.Errorf("footer without matches = %q, expected no matches", got)
+	}
+	if got := (&detailsSearch{Literal: true}).footer(); got != "invalid regexp, searched literally: no matches" {
+		t.Errorf("footer of an invalid regexp = %q", got)
+	}
+}
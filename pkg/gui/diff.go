@@ -0,0 +1,482 @@
+package gui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+)
+
+// diffKind tells how a value differs between the two sides of a diff.
+type diffKind int
+
+const (
+	diffAdded   diffKind = iota // Only on the right
+	diffRemoved                 // Only on the left
+	diffChanged                 // On both sides with different values
+)
+
+// diffEntry is one difference, located by a jq-style path such as .address.city or .tags[2].
+type diffEntry struct {
+	Path string
+	Kind diffKind
+	Old  interface{} // Left value (nil for diffAdded)
+	New  interface{} // Right value (nil for diffRemoved)
+}
+
+// diffSide is one of the two documents being compared.
+type diffSide struct {
+	Label string      // Document path, project:path or file name
+	Data  interface{} // Document data
+}
+
+// docDiff is a comparison shown in the details panel.
+type docDiff struct {
+	Left, Right diffSide
+	IgnoreOrder bool // Match array elements regardless of position (opt-in)
+	Entries     []diffEntry
+}
+
+// newDocDiff compares two documents.
+func newDocDiff(left, right diffSide, ignoreOrder bool) *docDiff {
+	d := &docDiff{Left: left, Right: right, IgnoreOrder: ignoreOrder}
+	d.Entries = diffValues(normalizeJSON(left.Data), normalizeJSON(right.Data), ignoreOrder)
+	return d
+}
+
+// Title identifies the diff; it is shown as the details path.
+func (d *docDiff) Title() string {
+	return fmt.Sprintf("diff: %s ↔ %s", d.Left.Label, d.Right.Label)
+}
+
+// Data returns the differences as a JSON-friendly map (path -> {"-": old, "+": new})
+// so copy and save work on a diff like on a document.
+func (d *docDiff) Data() map[string]any {
+	data := make(map[string]any, len(d.Entries))
+	for _, e := range d.Entries {
+		change := make(map[string]any)
+		if e.Kind != diffAdded {
+			change["-"] = e.Old
+		}
+		if e.Kind != diffRemoved {
+			change["+"] = e.New
+		}
+		data[e.Path] = change
+	}
+	return data
+}
+
+// Counts returns how many values were changed, added and removed.
+func (d *docDiff) Counts() (changed, added, removed int) {
+	for _, e := range d.Entries {
+		switch e.Kind {
+		case diffChanged:
+			changed++
+		case diffAdded:
+			added++
+		case diffRemoved:
+			removed++
+		}
+	}
+	return changed, added, removed
+}
+
+// normalizeJSON round-trips a value through JSON so documents from Firestore,
+// files and jq results compare alike (numbers become json.Number).
+func normalizeJSON(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return v
+	}
+	return out
+}
+
+// diffValues returns the differences between two normalized JSON values.
+// Object keys are always matched by name, so key order never matters.
+func diffValues(a, b interface{}, ignoreOrder bool) []diffEntry {
+	var entries []diffEntry
+	diffValue(".", a, b, ignoreOrder, &entries)
+	return entries
+}
+
+func diffValue(path string, a, b interface{}, ignoreOrder bool, entries *[]diffEntry) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			diffObjects(path, av, bv, ignoreOrder, entries)
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			if ignoreOrder {
+				diffArraysUnordered(path, av, bv, entries)
+			} else {
+				diffArrays(path, av, bv, ignoreOrder, entries)
+			}
+			return
+		}
+	}
+
+	if !diffEqual(a, b, ignoreOrder) {
+		*entries = append(*entries, diffEntry{Path: path, Kind: diffChanged, Old: a, New: b})
+	}
+}
+
+func diffObjects(path string, a, b map[string]interface{}, ignoreOrder bool, entries *[]diffEntry) {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		av, inA := a[k]
+		bv, inB := b[k]
+		keyPath := diffKeyPath(path, k)
+		switch {
+		case !inB:
+			*entries = append(*entries, diffEntry{Path: keyPath, Kind: diffRemoved, Old: av})
+		case !inA:
+			*entries = append(*entries, diffEntry{Path: keyPath, Kind: diffAdded, New: bv})
+		default:
+			diffValue(keyPath, av, bv, ignoreOrder, entries)
+		}
+	}
+}
+
+// diffArrays compares arrays element by element.
+func diffArrays(path string, a, b []interface{}, ignoreOrder bool, entries *[]diffEntry) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		indexPath := diffIndexPath(path, i)
+		switch {
+		case i >= len(b):
+			*entries = append(*entries, diffEntry{Path: indexPath, Kind: diffRemoved, Old: a[i]})
+		case i >= len(a):
+			*entries = append(*entries, diffEntry{Path: indexPath, Kind: diffAdded, New: b[i]})
+		default:
+			diffValue(indexPath, a[i], b[i], ignoreOrder, entries)
+		}
+	}
+}
+
+// diffArraysUnordered compares arrays as multisets: an element only differs
+// when it has no equal counterpart anywhere in the other array.
+func diffArraysUnordered(path string, a, b []interface{}, entries *[]diffEntry) {
+	unmatched := make(map[string]int)
+	for _, v := range b {
+		unmatched[diffCanonical(v, true)]++
+	}
+
+	for i, v := range a {
+		key := diffCanonical(v, true)
+		if unmatched[key] > 0 {
+			unmatched[key]--
+			continue
+		}
+		*entries = append(*entries, diffEntry{Path: diffIndexPath(path, i), Kind: diffRemoved, Old: v})
+	}
+
+	matched := make(map[string]int)
+	for _, v := range a {
+		matched[diffCanonical(v, true)]++
+	}
+	for i, v := range b {
+		key := diffCanonical(v, true)
+		if matched[key] > 0 {
+			matched[key]--
+			continue
+		}
+		*entries = append(*entries, diffEntry{Path: diffIndexPath(path, i), Kind: diffAdded, New: v})
+	}
+}
+
+// diffEqual reports whether two values are equal. With ignoreOrder, nested
+// array order is ignored; strings are always compared exactly.
+func diffEqual(a, b interface{}, ignoreOrder bool) bool {
+	if ignoreOrder {
+		return diffCanonical(a, true) == diffCanonical(b, true)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// diffCanonical returns a stable string form of a value for comparison: keys
+// sorted and no formatting whitespace. With sortArrays, arrays are sorted too.
+func diffCanonical(v interface{}, sortArrays bool) string {
+	if sortArrays {
+		v = diffSortArrays(v)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// diffSortArrays sorts arrays by their canonical form, recursively.
+func diffSortArrays(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = diffSortArrays(item)
+		}
+		return out
+	case []interface{}:
+		keys := make([]string, len(val))
+		for i, item := range val {
+			keys[i] = diffCanonical(item, true)
+		}
+		sort.Strings(keys)
+		out := make([]interface{}, len(keys))
+		for i, k := range keys {
+			out[i] = json.RawMessage(k)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+var diffIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// diffKeyPath appends an object key to a jq-style path.
+func diffKeyPath(path, key string) string {
+	if path == "." {
+		path = ""
+	}
+	if diffIdentifier.MatchString(key) {
+		return path + "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return path + "[" + string(quoted) + "]"
+}
+
+// diffIndexPath appends an array index to a jq-style path.
+func diffIndexPath(path string, i int) string {
+	if path == "." {
+		path = ""
+	}
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+// renderDiff formats a diff for the details panel: a header naming both sides,
+// a summary, then one block per difference with the old value in red and the
+// new value in green. toggleKeys are the keys switching how arrays and
+// whitespace are compared.
+func renderDiff(d *docDiff, toggleKeys string) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("\033[36m─── %s ───\033[0m\n", d.Title()))
+	content.WriteString(fmt.Sprintf("\033[31m-\033[0m %s\n", d.Left.Label))
+	content.WriteString(fmt.Sprintf("\033[32m+\033[0m %s\n", d.Right.Label))
+
+	mode, toggle := "compared", "ignore"
+	if d.IgnoreOrder {
+		mode, toggle = "ignored", "compare"
+	}
+	if toggleKeys != "" {
+		mode += fmt.Sprintf(" (%s to %s)", toggleKeys, toggle)
+	}
+	content.WriteString(fmt.Sprintf("\033[90mKey order and formatting: ignored · Array order: %s\033[0m\n\n", mode))
+
+	if len(d.Entries) == 0 {
+		content.WriteString("\033[32mDocuments are identical\033[0m\n")
+		return content.String()
+	}
+
+	changed, added, removed := d.Counts()
+	content.WriteString(fmt.Sprintf("\033[33m%d changed\033[0m, \033[32m%d added\033[0m, \033[31m%d removed\033[0m\n", changed, added, removed))
+
+	for _, e := range d.Entries {
+		content.WriteString("\n")
+		switch e.Kind {
+		case diffChanged:
+			content.WriteString(fmt.Sprintf("\033[33m~ %s\033[0m\n", e.Path))
+			writeDiffValue(&content, "\033[31m-\033[0m", e.Old)
+			writeDiffValue(&content, "\033[32m+\033[0m", e.New)
+		case diffAdded:
+			content.WriteString(fmt.Sprintf("\033[32m+ %s\033[0m\n", e.Path))
+			writeDiffValue(&content, "\033[32m+\033[0m", e.New)
+		case diffRemoved:
+			content.WriteString(fmt.Sprintf("\033[31m- %s\033[0m\n", e.Path))
+			writeDiffValue(&content, "\033[31m-\033[0m", e.Old)
+		}
+	}
+	return content.String()
+}
+
+// writeDiffValue writes a value as indented, colorized JSON with a +/- marker on every line.
+func writeDiffValue(content *strings.Builder, marker string, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		data = []byte(fmt.Sprint(v))
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		content.WriteString(fmt.Sprintf("  %s %s\n", marker, colorizeLine(line)))
+	}
+}
+
+// markDiffAction remembers the selected document (or the document shown in
+// details, after any jq filter) as the left side of the next diff.
+func (g *Gui) markDiffAction() error {
+	data, path, err := g.getDocumentToCopy()
+	if err != nil {
+		g.logCommand("diff", err.Error(), "error")
+		return nil
+	}
+	g.diffMark = &diffSide{Label: path, Data: data}
+	g.logCommand("diff", g.withKeys("Marked "+path, ", press %s on another document to compare", "diffWithMarked"), "success")
+	return nil
+}
+
+// diffAction compares the marked document with the current selection, the same
+// path in another project, or a local JSON file.
+func (g *Gui) diffAction() error {
+	if g.diffMark == nil {
+		g.logCommand("diff", g.withKeys("Mark a document", " with %s", "markForDiff")+" first", "error")
+		return nil
+	}
+	mark := *g.diffMark
+
+	items := []PopupItem{
+		{Label: "Compare " + mark.Label + " with", IsHeader: true},
+		{Key: "selection", Label: "Selected document", Action: func() error {
+			data, path, err := g.getDocumentToCopy()
+			if err != nil {
+				g.logCommand("diff", err.Error(), "error")
+				return nil
+			}
+			return g.showDiff(newDocDiff(mark, diffSide{Label: path, Data: data}, false))
+		}},
+		{Key: "file", Label: "Local JSON file", Action: func() error {
+			return g.openPrompt("Compare "+mark.Label+" with JSON file", "", func(path string) {
+				g.diffWithFile(mark, path)
+			})
+		}},
+	}
+
+	// A plain document path can be looked up in the other projects
+	if isDiffDocumentPath(mark.Label) {
+		for _, p := range g.projects {
+			if p.ID == g.currentProject {
+				continue
+			}
+			project := p.ID
+			items = append(items, PopupItem{Key: project, Label: "Same path in " + p.DisplayName, Action: func() error {
+				g.diffWithProject(mark, project)
+				return g.Layout(g.g)
+			}})
+		}
+	}
+	return g.openMenu("Diff", items)
+}
+
+// isDiffDocumentPath reports whether a mark label is a document path
+// (and not a jq result, a multi-selection or a file).
+func isDiffDocumentPath(label string) bool {
+	return label != "" && !strings.Contains(label, " ") && strings.Count(label, "/")%2 == 1
+}
+
+// diffWithProject fetches the marked path from another project and compares it.
+func (g *Gui) diffWithProject(mark diffSide, project string) {
+	g.logCommand("diff", fmt.Sprintf("Fetching %s from %s...", mark.Label, project), "running")
+
+	go func() {
+		doc, err := g.firebaseClient.GetDocumentFrom(project, mark.Label)
+
+		g.g.Update(func(gui *gocui.Gui) error {
+			if err != nil {
+				g.updateCommand("diff", fmt.Sprintf("Failed: %v", err), "error")
+				return nil
+			}
+			g.updateCommand("diff", fmt.Sprintf("Fetched %s from %s", mark.Label, project), "success")
+			return g.showDiff(newDocDiff(mark, diffSide{Label: project + ":" + mark.Label, Data: doc.Data}, false))
+		})
+	}()
+}
+
+// diffWithFile compares the marked document with a JSON file.
+func (g *Gui) diffWithFile(mark diffSide, path string) {
+	if path == "" {
+		return
+	}
+	path = expandHome(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		g.logCommand("diff", fmt.Sprintf("Failed to read file: %v", err), "error")
+		return
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		g.logCommand("diff", fmt.Sprintf("%s: invalid JSON: %v", filepath.Base(path), err), "error")
+		return
+	}
+	_ = g.showDiff(newDocDiff(mark, diffSide{Label: filepath.Base(path), Data: v}, false))
+}
+
+// showDiff renders a diff in the details panel and focuses it.
+// The differences also become the current document data, so c and s copy or save them.
+func (g *Gui) showDiff(d *docDiff) error {
+	g.diffView = d
+	g.currentDocData = d.Data()
+	g.currentDocPath = d.Title()
+	g.clearDetailsCache()
+
+	changed, added, removed := d.Counts()
+	g.logCommand("diff", fmt.Sprintf("%d changed, %d added, %d removed", changed, added, removed), "success")
+
+	if g.currentColumn != "details" {
+		g.previousColumn = g.currentColumn
+	}
+	if err := g.setFocus(g.g, "details"); err != nil {
+		return err
+	}
+	return g.Layout(g.g)
+}
+
+// isDiffShown reports whether the details panel is showing the current diff.
+func (g *Gui) isDiffShown() bool {
+	return g.diffView != nil && g.currentDocData != nil && g.currentDocPath == g.diffView.Title()
+}
+
+// toggleDiffIgnoreOrderAction switches between comparing arrays by position and
+// ignoring their order.
+func (g *Gui) toggleDiffIgnoreOrderAction() error {
+	if !g.isDiffShown() {
+		return nil
+	}
+	d := g.diffView
+	return g.showDiff(newDocDiff(d.Left, d.Right, !d.IgnoreOrder))
+}
+
+// renderDiffDetails draws the current diff in the details view.
+func (g *Gui) renderDiffDetails(v *gocui.View) {
+	if g.cachedDetailsDocPath != g.currentDocPath || g.cachedDetailsContent == "" {
+		g.cachedDetailsContent = renderDiff(g.diffView, g.keyLabels("toggleDiffIgnoreOrder"))
+		g.cachedDetailsLines = strings.Split(g.cachedDetailsContent, "\n")
+		g.cachedDetailsHeader = ""
+		g.cachedDetailsDocPath = g.currentDocPath
+		g.detailsViewDirty = true
+	}
+	if g.detailsViewDirty {
+		v.SetContent(g.cachedDetailsContent)
+		g.detailsViewDirty = false
+	}
+}
@@ -0,0 +1,112 @@
+package gui
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffValues(t *testing.T) {
+	tests := []struct {
+		name        string
+		left        string // JSON
+		right       string // JSON
+		ignoreOrder bool
+		expected    []string // "kind path"
+	}{
+		{"identical", `{"a": 1, "b": [1, 2]}`, `{"b": [1, 2], "a": 1}`, false, nil},
+		{"changed scalar", `{"a": 1}`, `{"a": 2}`, false, []string{"~ .a"}},
+		{"added and removed", `{"a": 1}`, `{"b": 1}`, false, []string{"- .a", "+ .b"}},
+		{"nested path", `{"addr": {"city": "Tirana"}}`, `{"addr": {"city": "Durres"}}`, false, []string{"~ .addr.city"}},
+		{"key needing quotes", `{"first name": "a"}`, `{"first name": "b"}`, false, []string{`~ ["first name"]`}},
+		{"type change", `{"a": 1}`, `{"a": "1"}`, false, []string{"~ .a"}},
+		{"array element added", `{"t": ["x"]}`, `{"t": ["x", "y"]}`, false, []string{"+ .t[1]"}},
+		{"array reordered", `{"t": ["x", "y"]}`, `{"t": ["y", "x"]}`, false, []string{"~ .t[0]", "~ .t[1]"}},
+		{"array reordered, order ignored", `{"t": ["x", "y"]}`, `{"t": ["y", "x"]}`, true, nil},
+		{"array element replaced, order ignored", `{"t": ["x", "y"]}`, `{"t": ["y", "z"]}`, true, []string{"- .t[0]", "+ .t[1]"}},
+		{"whitespace", `{"s": "a  b\n"}`, `{"s": "a b"}`, false, []string{"~ .s"}},
+		{"whitespace kept when order is ignored", `{"s": "a  b\n"}`, `{"s": "a b"}`, true, []string{"~ .s"}},
+		{"nested array reordered, order ignored", `{"t": [{"a": [1, 2]}]}`, `{"t": [{"a": [2, 1]}]}`, true, nil},
+		{"root type change", `{"a": 1}`, `[1]`, false, []string{"~ ."}},
+	}
+
+	kinds := map[diffKind]string{diffAdded: "+", diffRemoved: "-", diffChanged: "~"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var left, right interface{}
+			if err := json.Unmarshal([]byte(tt.left), &left); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.right), &right); err != nil {
+				t.Fatal(err)
+			}
+
+			var result []string
+			for _, e := range newDocDiff(diffSide{Data: left}, diffSide{Data: right}, tt.ignoreOrder).Entries {
+				result = append(result, kinds[e.Kind]+" "+e.Path)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("diff(%s, %s) = %q, expected %q", tt.left, tt.right, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiffNormalizesNumbers(t *testing.T) {
+	// Firestore integers arrive as int64, file values as json.Number
+	left := diffSide{Data: map[string]any{"n": int64(30)}}
+	right := diffSide{Data: map[string]any{"n": json.Number("30")}}
+	if entries := newDocDiff(left, right, false).Entries; len(entries) != 0 {
+		t.Errorf("expected no differences, got %v", entries)
+	}
+}
+
+func TestRenderDiff(t *testing.T) {
+	d := newDocDiff(
+		diffSide{Label: "users/a", Data: map[string]any{"age": 30, "name": "Ana"}},
+		diffSide{Label: "staging:users/a", Data: map[string]any{"age": 31, "city": "Tirana"}},
+		false,
+	)
+	output := stripANSI(renderDiff(d, "w"))
+
+	for _, want := range []string{
+		"diff: users/a ↔ staging:users/a",
+		"1 changed, 1 added, 1 removed",
+		"~ .age",
+		"+ .city",
+		"- .name",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("renderDiff() missing %q in:\n%s", want, output)
+		}
+	}
+
+	identical := newDocDiff(diffSide{Label: "a", Data: map[string]any{}}, diffSide{Label: "b", Data: map[string]any{}}, false)
+	if output := stripANSI(renderDiff(identical, "w")); !strings.Contains(output, "identical") {
+		t.Errorf("renderDiff() of equal documents = %q, expected it to say identical", output)
+	}
+}
+
+func TestIsDiffDocumentPath(t *testing.T) {
+	tests := []struct {
+		label    string
+		expected bool
+	}{
+		{"users/a", true},
+		{"users/a/orders/o1", true},
+		{"users", false},
+		{"users/a (jq: .name)", false},
+		{"3 documents selected", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			if got := isDiffDocumentPath(tt.label); got != tt.expected {
+				t.Errorf("isDiffDocumentPath(%q) = %v, expected %v", tt.label, got, tt.expected)
+			}
+		})
+	}
+}
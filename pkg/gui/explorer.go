@@ -0,0 +1,486 @@
+package gui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+)
+
+// explorerLine is one visible node of the JSON explorer.
+type explorerLine struct {
+	Path   string // jq-style path, "." for the document
+	Label  string // Field name, [index], or the document path for the root
+	Value  any
+	Depth  int
+	Parent int // Index of the parent line, -1 for the root
+}
+
+// explorerState is the collapsible JSON explorer shown over the details panel.
+type explorerState struct {
+	title     string
+	root      any
+	collapsed map[string]bool // Paths of folded maps and arrays
+	links     map[string]bool // Paths of reference fields
+	lines     []explorerLine
+	cursor    int
+	offset    int  // First line shown
+	pendingZ  bool // "z" was pressed, waiting for a/o/c/R/M
+}
+
+// newExplorerState opens the explorer on a document with nested maps and
+// arrays folded, so the top-level fields are visible at a glance.
+func newExplorerState(title string, data any) *explorerState {
+	e := &explorerState{title: title, root: normalizeJSON(data), collapsed: make(map[string]bool)}
+	e.foldFrom(e.root, ".", 0, 1)
+	e.rebuild()
+	return e
+}
+
+// foldFrom folds every container at depth >= minDepth under value.
+func (e *explorerState) foldFrom(value any, path string, depth, minDepth int) {
+	if !isExplorerContainer(value) {
+		return
+	}
+	if depth >= minDepth {
+		e.collapsed[path] = true
+	}
+	forEachChild(value, path, func(_ string, childPath string, child any) {
+		e.foldFrom(child, childPath, depth+1, minDepth)
+	})
+}
+
+// isExplorerContainer reports whether a value is a non-empty map or array.
+func isExplorerContainer(value any) bool {
+	switch v := value.(type) {
+	case map[string]any:
+		return len(v) > 0
+	case []any:
+		return len(v) > 0
+	}
+	return false
+}
+
+// forEachChild calls f for each member of a map (sorted by key) or array.
+func forEachChild(value any, path string, f func(label, childPath string, child any)) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			f(k, diffKeyPath(path, k), v[k])
+		}
+	case []any:
+		for i, item := range v {
+			f(fmt.Sprintf("[%d]", i), diffIndexPath(path, i), item)
+		}
+	}
+}
+
+// rebuild recomputes the visible lines, keeping the cursor on the same node
+// or, when that node was folded away, on its closest visible ancestor.
+func (e *explorerState) rebuild() {
+	current := "."
+	if e.cursor < len(e.lines) {
+		current = e.lines[e.cursor].Path
+	}
+
+	e.lines = e.lines[:0]
+	var walk func(label, path string, value any, depth, parent int)
+	walk = func(label, path string, value any, depth, parent int) {
+		index := len(e.lines)
+		e.lines = append(e.lines, explorerLine{Path: path, Label: label, Value: value, Depth: depth, Parent: parent})
+		if e.collapsed[path] {
+			return
+		}
+		forEachChild(value, path, func(childLabel, childPath string, child any) {
+			walk(childLabel, childPath, child, depth+1, index)
+		})
+	}
+	walk(e.title, ".", e.root, 0, -1)
+
+	for p := current; ; p = parentJQPath(p) {
+		for i, line := range e.lines {
+			if line.Path == p {
+				e.cursor = i
+				return
+			}
+		}
+		if p == "." {
+			e.cursor = 0
+			return
+		}
+	}
+}
+
+// current returns the node under the cursor.
+func (e *explorerState) current() explorerLine {
+	return e.lines[e.cursor]
+}
+
+func (e *explorerState) move(delta int) {
+	e.cursor = max(0, min(len(e.lines)-1, e.cursor+delta))
+}
+
+// setFold folds or unfolds the container under the cursor. Folding a scalar
+// folds its parent instead, like zc inside a vim fold.
+func (e *explorerState) setFold(folded bool) {
+	line := e.current()
+	if !isExplorerContainer(line.Value) {
+		if !folded || line.Parent < 0 {
+			return
+		}
+		e.cursor = line.Parent
+		line = e.lines[line.Parent]
+	}
+	if line.Path == "." && folded {
+		return // Keep the document itself open
+	}
+	e.collapsed[line.Path] = folded
+	e.rebuild()
+}
+
+func (e *explorerState) toggleFold() {
+	line := e.current()
+	if isExplorerContainer(line.Value) {
+		e.setFold(!e.collapsed[line.Path])
+	}
+}
+
+// openAll unfolds everything (zR).
+func (e *explorerState) openAll() {
+	e.collapsed = make(map[string]bool)
+	e.rebuild()
+}
+
+// closeAll folds everything below the top level (zM).
+func (e *explorerState) closeAll() {
+	e.collapsed = make(map[string]bool)
+	e.foldFrom(e.root, ".", 0, 1)
+	e.rebuild()
+}
+
+// left folds an open container, or moves to the parent.
+func (e *explorerState) left() {
+	line := e.current()
+	if isExplorerContainer(line.Value) && !e.collapsed[line.Path] && line.Path != "." {
+		e.setFold(true)
+		return
+	}
+	e.parent()
+}
+
+// right unfolds a folded container, or moves into an open one.
+func (e *explorerState) right() {
+	line := e.current()
+	if !isExplorerContainer(line.Value) {
+		return
+	}
+	if e.collapsed[line.Path] {
+		e.setFold(false)
+		return
+	}
+	e.move(1)
+}
+
+// parent moves the cursor to the enclosing map or array.
+func (e *explorerState) parent() {
+	if p := e.current().Parent; p >= 0 {
+		e.cursor = p
+	}
+}
+
+// jqFilterPath makes a path usable as a jq filter: a quoted top-level key
+// ["first name"] needs a leading dot.
+func jqFilterPath(path string) string {
+	if strings.HasPrefix(path, "[") {
+		return "." + path
+	}
+	return path
+}
+
+// explorerSummary describes a map or array: {3 keys}, [12 items].
+func explorerSummary(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 1 {
+			return "{1 key}"
+		}
+		return fmt.Sprintf("{%d keys}", len(v))
+	case []any:
+		if len(v) == 1 {
+			return "[1 item]"
+		}
+		return fmt.Sprintf("[%d items]", len(v))
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// render draws the lines that fit in height, scrolling to keep the cursor visible.
+func (e *explorerState) render(height int) string {
+	if height < 1 {
+		height = 1
+	}
+	if e.cursor < e.offset {
+		e.offset = e.cursor
+	}
+	if e.cursor >= e.offset+height {
+		e.offset = e.cursor - height + 1
+	}
+
+	var b strings.Builder
+	end := min(len(e.lines), e.offset+height)
+	for i := e.offset; i < end; i++ {
+		line := e.lines[i]
+
+		arrow := "  "
+		if isExplorerContainer(line.Value) {
+			arrow = "▼ "
+			if e.collapsed[line.Path] {
+				arrow = "▶ "
+			}
+		}
+
+		summary := explorerSummary(line.Value)
+		indent := strings.Repeat("  ", line.Depth) + arrow
+		if i == e.cursor {
+			b.WriteString("\033[7m" + indent + line.Label + ": " + summary + "\033[0m\n")
+			continue
+		}
+
+		label := "\033[34m" + line.Label + "\033[0m"
+		if line.Parent < 0 {
+			label = "\033[36m" + line.Label + "\033[0m"
+		}
+		value := "\033[90m" + summary + "\033[0m"
+		switch line.Value.(type) {
+		case map[string]any, []any:
+		default:
+			value = colorizeLine(summary)
+		}
+		if e.links[line.Path] {
+			value = "\033[4;36m" + summary + "\033[0m"
+		}
+		text := indent + label + ": " + value
+		b.WriteString(text + "\n")
+	}
+	return b.String()
+}
+
+// openExplorerAction opens the document in details in the JSON explorer.
+func (g *Gui) openExplorerAction() error {
+	if g.currentDocData == nil {
+		g.logCommand("explorer", "Open a document in details first", "error")
+		return nil
+	}
+	g.explorer = newExplorerState(g.currentDocPath, g.currentDocData)
+	g.explorer.links = make(map[string]bool)
+	for _, ref := range g.documentReferences(g.currentDocPath) {
+		g.explorer.links[ref.Field] = true
+	}
+	return g.Layout(g.g)
+}
+
+// closeExplorer returns to the details panel.
+func (g *Gui) closeExplorer() error {
+	g.explorer = nil
+	if err := g.setFocus(g.g, g.currentColumn); err != nil {
+		return err
+	}
+	return g.Layout(g.g)
+}
+
+// explorerCopyPath copies the jq path of the current node.
+func (g *Gui) explorerCopyPath() error {
+	if g.explorer == nil {
+		return nil
+	}
+	path := jqFilterPath(g.explorer.current().Path)
+	if err := copyToClipboard(path); err != nil {
+		g.logCommand("copy", fmt.Sprintf("Failed to copy: %v", err), "error")
+		return nil
+	}
+	g.logCommand("copy", fmt.Sprintf("Copied %s to clipboard", path), "success")
+	return nil
+}
+
+// explorerFilter closes the explorer and starts a jq filter on details with the
+// current node's path, ready to be refined or committed with Enter.
+func (g *Gui) explorerFilter() error {
+	if g.explorer == nil {
+		return nil
+	}
+	path := jqFilterPath(g.explorer.current().Path)
+	g.explorer = nil
+
+	if g.currentColumn != "details" {
+		g.previousColumn = g.currentColumn
+	}
+	if err := g.setFocus(g.g, "details"); err != nil {
+		return err
+	}
+	g.detailsFilter = ""
+	g.filterInputActive = true
+	g.filterInputPanel = "details"
+	g.filterInputText = path
+	g.filterCursorPos = len(path)
+	return g.Layout(g.g)
+}
+
+// explorerEnter follows the reference under the cursor, or toggles a fold.
+func (g *Gui) explorerEnter() error {
+	if g.explorer == nil {
+		return nil
+	}
+	if ref, ok := g.referenceAt(g.explorer.current().Path); ok {
+		g.explorer = nil
+		return g.followReference(ref)
+	}
+	return g.explorerAction((*explorerState).toggleFold)()
+}
+
+// explorerAction wraps an explorer state change as a key handler. Any key
+// other than z ends a pending z prefix.
+func (g *Gui) explorerAction(f func(e *explorerState)) func() error {
+	return func() error {
+		if g.explorer != nil {
+			f(g.explorer)
+			g.explorer.pendingZ = false
+		}
+		return g.Layout(g.g)
+	}
+}
+
+// explorerFoldKey handles the second key of za, zo, zc, zR and zM.
+func (g *Gui) explorerFoldKey(key rune) func() error {
+	return g.explorerAction(func(e *explorerState) {
+		if !e.pendingZ {
+			return
+		}
+		switch key {
+		case 'a':
+			e.toggleFold()
+		case 'o':
+			e.setFold(false)
+		case 'c':
+			e.setFold(true)
+		case 'R':
+			e.openAll()
+		case 'M':
+			e.closeAll()
+		}
+	})
+}
+
+// explorerBindings are active while the explorer has focus.
+func (g *Gui) explorerBindings() []*Binding {
+	view := g.views.explorer
+	down := g.explorerAction(func(e *explorerState) { e.move(1) })
+	up := g.explorerAction(func(e *explorerState) { e.move(-1) })
+	left := g.explorerAction((*explorerState).left)
+	right := g.explorerAction((*explorerState).right)
+	toggle := g.explorerAction((*explorerState).toggleFold)
+	pendingZ := func() error {
+		if g.explorer != nil {
+			g.explorer.pendingZ = true
+		}
+		return nil
+	}
+
+	bindings := []*Binding{
+		{ID: "explorerNextNode", Key: 'j', ViewName: view, Handler: down, Description: "Next node"},
+		{ID: "explorerNextNode", Key: gocui.KeyArrowDown, ViewName: view, Handler: down, Description: "Next node"},
+		{ID: "explorerPrevNode", Key: 'k', ViewName: view, Handler: up, Description: "Previous node"},
+		{ID: "explorerPrevNode", Key: gocui.KeyArrowUp, ViewName: view, Handler: up, Description: "Previous node"},
+		{ID: "explorerFoldOrParent", Key: 'h', ViewName: view, Handler: left, Description: "Fold or go to parent"},
+		{ID: "explorerFoldOrParent", Key: gocui.KeyArrowLeft, ViewName: view, Handler: left, Description: "Fold or go to parent"},
+		{ID: "explorerUnfoldOrChild", Key: 'l', ViewName: view, Handler: right, Description: "Unfold or go to first child"},
+		{ID: "explorerUnfoldOrChild", Key: gocui.KeyArrowRight, ViewName: view, Handler: right, Description: "Unfold or go to first child"},
+		{ID: "explorerFirstNode", Key: 'g', ViewName: view, Handler: g.explorerAction(func(e *explorerState) { e.cursor = 0 }), Description: "First node"},
+		{ID: "explorerLastNode", Key: 'G', ViewName: view, Handler: g.explorerAction(func(e *explorerState) { e.move(len(e.lines)) }), Description: "Last node"},
+		{ID: "explorerParent", Key: 'p', ViewName: view, Handler: g.explorerAction((*explorerState).parent), Description: "Go to parent"},
+		{ID: "explorerEnter", Key: gocui.KeyEnter, ViewName: view, Handler: g.explorerEnter, Description: "Toggle fold or follow reference"},
+		{ID: "explorerToggleFold", Key: gocui.KeySpace, ViewName: view, Handler: toggle, Description: "Toggle fold"},
+		{ID: "explorerFoldPrefix", Key: 'z', ViewName: view, Handler: pendingZ, Description: "Fold prefix"},
+		{ID: "explorerCopyPath", Key: 'y', ViewName: view, Handler: g.explorerCopyPath, Description: "Copy path"},
+		{ID: "explorerFilter", Key: '/', ViewName: view, Handler: g.explorerFilter, Description: "Filter details by path (jq)"},
+		{ID: "explorerClose", Key: gocui.KeyEsc, ViewName: view, Handler: g.closeExplorer, Description: "Close explorer"},
+		{ID: "explorerClose", Key: 'x', ViewName: view, Handler: g.closeExplorer, Description: "Close explorer"},
+		{ID: "explorerClose", Key: 'q', ViewName: view, Handler: g.closeExplorer, Description: "Close explorer"},
+	}
+	for _, fold := range explorerFoldKeys {
+		bindings = append(bindings, &Binding{ID: fold.id, Key: fold.key, ViewName: view, Handler: g.explorerFoldKey(fold.key), Description: "Fold (after z)"})
+	}
+	return bindings
+}
+
+// explorerFoldKeys are the keys typed after the fold prefix, as in vim's za.
+var explorerFoldKeys = []struct {
+	id  string
+	key rune
+}{
+	{"explorerToggleFoldAfterZ", 'a'},
+	{"explorerOpenFoldAfterZ", 'o'},
+	{"explorerCloseFoldAfterZ", 'c'},
+	{"explorerOpenAllAfterZ", 'R'},
+	{"explorerCloseAllAfterZ", 'M'},
+}
+
+// layoutExplorer draws the explorer over the details panel.
+func (g *Gui) layoutExplorer(gui *gocui.Gui) error {
+	e := g.explorer
+
+	details, err := gui.View(g.views.details)
+	if err != nil {
+		return err
+	}
+	x0, y0, x1, y1 := details.Dimensions()
+
+	v, err := gui.SetView(g.views.explorer, x0, y0, x1, y1, 0)
+	if err != nil {
+		if !errors.Is(err, gocui.ErrUnknownView) {
+			return err
+		}
+		v.TitleColor = g.theme.ActiveBorderColor
+		v.FrameColor = g.theme.ActiveBorderColor
+		v.FrameRunes = g.roundedFrameRunes
+	}
+
+	v.Title = fmt.Sprintf(" Explorer: %s ", e.current().Path)
+
+	// Fold keys are shown after the prefix, e.g. za/zo/zc
+	z := g.keyLabels("explorerFoldPrefix")
+	afterZ := func(ids ...string) string {
+		var keys []string
+		for _, id := range ids {
+			if key := g.keyLabels(id); z != "" && key != "" {
+				keys = append(keys, z+key)
+			}
+		}
+		return strings.Join(keys, "/")
+	}
+	v.Footer = g.footerHints([]keyHint{
+		{afterZ("explorerToggleFoldAfterZ", "explorerOpenFoldAfterZ", "explorerCloseFoldAfterZ"), "fold"},
+		{afterZ("explorerOpenAllAfterZ", "explorerCloseAllAfterZ"), "all"},
+		{g.keyLabels("explorerEnter"), "follow ref"},
+		{g.keyLabels("explorerParent"), "parent"},
+		{g.keyLabels("explorerCopyPath"), "copy path"},
+		{g.keyLabels("explorerFilter"), "jq"},
+		{g.keyLabels("explorerClose"), "close"},
+	})
+
+	_, height := v.InnerSize()
+	v.SetContent(e.render(height))
+
+	if _, err := gui.SetCurrentView(g.views.explorer); err != nil {
+		return fmt.Errorf("failed to set explorer view: %w", err)
+	}
+	return nil
+}
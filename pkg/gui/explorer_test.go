@@ -0,0 +1,160 @@
+package gui
+
+import (
+	"strings"
+	"testing"
+)
+
+func explorerDoc() map[string]interface{} {
+	return map[string]interface{}{
+		"name": "Ana",
+		"address": map[string]interface{}{
+			"city": "Tirana",
+			"geo":  map[string]interface{}{"lat": 41.3},
+		},
+		"tags":       []interface{}{"a", "b", map[string]interface{}{"first name": "x"}},
+		"empty":      map[string]interface{}{},
+		"created_at": "2024-01-01",
+	}
+}
+
+// explorerPaths lists the paths of the visible lines.
+func explorerPaths(e *explorerState) string {
+	paths := make([]string, len(e.lines))
+	for i, line := range e.lines {
+		paths[i] = line.Path
+	}
+	return strings.Join(paths, " ")
+}
+
+// explorerSelect moves the cursor to a visible path.
+func explorerSelect(t *testing.T, e *explorerState, path string) {
+	t.Helper()
+	for i, line := range e.lines {
+		if line.Path == path {
+			e.cursor = i
+			return
+		}
+	}
+	t.Fatalf("%s is not visible in %s", path, explorerPaths(e))
+}
+
+func TestExplorerFolding(t *testing.T) {
+	e := newExplorerState("users/ana", explorerDoc())
+
+	if got, expected := explorerPaths(e), ". .address .created_at .empty .name .tags"; got != expected {
+		t.Fatalf("initial lines = %q, expected %q", got, expected)
+	}
+
+	explorerSelect(t, e, ".address")
+	e.toggleFold()
+	if got, expected := explorerPaths(e), ". .address .address.city .address.geo .created_at .empty .name .tags"; got != expected {
+		t.Errorf("after za = %q, expected %q", got, expected)
+	}
+
+	// zc on a scalar folds the enclosing map and moves there
+	explorerSelect(t, e, ".address.city")
+	e.setFold(true)
+	if got := e.current().Path; got != ".address" {
+		t.Errorf("cursor after zc on a field = %q, expected .address", got)
+	}
+	if got, expected := explorerPaths(e), ". .address .created_at .empty .name .tags"; got != expected {
+		t.Errorf("after zc = %q, expected %q", got, expected)
+	}
+
+	e.openAll()
+	if got, expected := explorerPaths(e), `. .address .address.city .address.geo .address.geo.lat .created_at .empty .name .tags .tags[0] .tags[1] .tags[2] .tags[2]["first name"]`; got != expected {
+		t.Errorf("after zR = %q, expected %q", got, expected)
+	}
+
+	// Folding keeps the cursor on the closest visible ancestor
+	explorerSelect(t, e, `.tags[2]["first name"]`)
+	e.closeAll()
+	if got := e.current().Path; got != ".tags" {
+		t.Errorf("cursor after zM = %q, expected .tags", got)
+	}
+}
+
+func TestExplorerNavigation(t *testing.T) {
+	e := newExplorerState("users/ana", explorerDoc())
+
+	explorerSelect(t, e, ".tags")
+	e.right() // Unfold
+	e.right() // Into the first item
+	if got := e.current().Path; got != ".tags[0]" {
+		t.Errorf("cursor after l l = %q, expected .tags[0]", got)
+	}
+
+	e.left() // Scalar: go to parent
+	if got := e.current().Path; got != ".tags" {
+		t.Errorf("cursor after h on a scalar = %q, expected .tags", got)
+	}
+	e.left() // Open container: fold it
+	if !e.collapsed[".tags"] {
+		t.Error("h on an open container should fold it")
+	}
+	e.left()
+	if got := e.current().Path; got != "." {
+		t.Errorf("cursor after h on a folded container = %q, expected .", got)
+	}
+
+	e.setFold(true)
+	if e.collapsed["."] {
+		t.Error("the document itself should not fold")
+	}
+}
+
+func TestExplorerSummary(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{map[string]interface{}{"a": 1.0}, "{1 key}"},
+		{map[string]interface{}{"a": 1.0, "b": 2.0}, "{2 keys}"},
+		{map[string]interface{}{}, "{0 keys}"},
+		{[]interface{}{1.0}, "[1 item]"},
+		{[]interface{}{1.0, 2.0, 3.0}, "[3 items]"},
+		{"Ana", `"Ana"`},
+		{nil, "null"},
+	}
+
+	for _, tt := range tests {
+		if got := explorerSummary(tt.value); got != tt.expected {
+			t.Errorf("explorerSummary(%v) = %q, expected %q", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestJQFilterPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{".", "."},
+		{".address.city", ".address.city"},
+		{`["first name"]`, `.["first name"]`},
+		{`["first name"].x`, `.["first name"].x`},
+	}
+
+	for _, tt := range tests {
+		if got := jqFilterPath(tt.path); got != tt.expected {
+			t.Errorf("jqFilterPath(%q) = %q, expected %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestExplorerRender(t *testing.T) {
+	e := newExplorerState("users/ana", explorerDoc())
+	out := stripANSI(e.render(3))
+
+	expected := "▼ users/ana: {5 keys}\n  ▶ address: {2 keys}\n    created_at: \"2024-01-01\"\n"
+	if out != expected {
+		t.Errorf("render = %q, expected %q", out, expected)
+	}
+
+	// Scrolls to keep the cursor visible
+	e.move(len(e.lines))
+	if out := stripANSI(e.render(3)); !strings.HasSuffix(out, "  ▶ tags: [3 items]\n") {
+		t.Errorf("render at the end = %q, expected tags last", out)
+	}
+}
@@ -1,7 +1,9 @@
 package gui
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,10 +12,15 @@ import (
 	"strings"
 
 	"github.com/itchyny/gojq"
+	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
 )
 
 // copyJSONAction copies current document to clipboard
 func (g *Gui) copyJSONAction() error {
+	if g.currentColumn == "details" && g.isJqRunShown() {
+		return g.copyJqRun()
+	}
 	docData, docPath, err := g.getDocumentToCopy()
 	if err != nil {
 		g.logCommand("copy", err.Error(), "error")
@@ -26,7 +33,17 @@ func (g *Gui) copyJSONAction() error {
 		return nil
 	}
 
-	// Copy to clipboard using platform-specific command
+	if err := copyToClipboard(string(data)); err != nil {
+		g.logCommand("copy", fmt.Sprintf("Failed to copy: %v", err), "error")
+		return nil
+	}
+
+	g.logCommand("copy", fmt.Sprintf("Copied %s to clipboard", docPath), "success")
+	return nil
+}
+
+// copyToClipboard puts text on the clipboard using the platform-specific command
+func copyToClipboard(text string) error {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
 	case "darwin":
@@ -34,22 +51,21 @@ func (g *Gui) copyJSONAction() error {
 	case "linux":
 		cmd = exec.Command("xclip", "-selection", "clipboard")
 	default:
-		g.logCommand("copy", "Clipboard not supported on this platform", "error")
-		return nil
+		return errors.New("clipboard not supported on this platform")
 	}
 
-	cmd.Stdin = strings.NewReader(string(data))
+	cmd.Stdin = strings.NewReader(text)
 	if err := cmd.Run(); err != nil {
-		g.logCommand("copy", fmt.Sprintf("Failed to copy: %v", err), "error")
-		return nil
+		return fmt.Errorf("running %s: %w", cmd.Args[0], err)
 	}
-
-	g.logCommand("copy", fmt.Sprintf("Copied %s to clipboard", docPath), "success")
 	return nil
 }
 
 // saveJSONAction saves current document to file
 func (g *Gui) saveJSONAction() error {
+	if g.currentColumn == "details" && g.isJqRunShown() {
+		return g.saveJqRun()
+	}
 	docData, docPath, err := g.getDocumentToCopy()
 	if err != nil {
 		g.logCommand("save", err.Error(), "error")
@@ -80,6 +96,101 @@ func (g *Gui) saveJSONAction() error {
 	return nil
 }
 
+// exportTreeAction asks for a target path and exports the selected collection or
+// document, including all subcollections. A path ending in .ndjson writes NDJSON,
+// anything else a directory of JSON files.
+func (g *Gui) exportTreeAction() error {
+	if g.exportCancel != nil {
+		g.logCommand("export", g.withKeys("An export is already running", " (%s to cancel)", "cancelExport"), "error")
+		return nil
+	}
+
+	root := g.getExportRoot()
+	if root == "" {
+		g.logCommand("export", "Select a collection or document to export", "error")
+		return nil
+	}
+
+	home, _ := os.UserHomeDir()
+	target := filepath.Join(home, "Downloads", strings.ReplaceAll(root, "/", "_"))
+
+	return g.openPrompt(fmt.Sprintf("Export %s to (dir, or file.ndjson)", root), target, func(target string) {
+		g.startTreeExport(root, target)
+	})
+}
+
+// getExportRoot returns the collection or document path selected in the current panel.
+func (g *Gui) getExportRoot() string {
+	switch g.currentColumn {
+	case "collections":
+		filtered := g.getFilteredCollections()
+		if g.selectedCollectionIdx < len(filtered) {
+			return filtered[g.selectedCollectionIdx].Path
+		}
+	case "tree":
+		filtered := g.getFilteredTreeNodes()
+		if g.selectedTreeIdx < len(filtered) {
+			return filtered[g.selectedTreeIdx].Path
+		}
+	case "details":
+		return g.currentDocPath
+	}
+	return ""
+}
+
+// startTreeExport runs the export in the background, reporting progress in the
+// commands panel. An existing manifest at the target resumes that export.
+func (g *Gui) startTreeExport(root, target string) {
+	if target == "" {
+		return
+	}
+	target = expandHome(target)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g.exportCancel = cancel
+	g.logCommand("export", fmt.Sprintf("Exporting %s to %s...", root, target), "running")
+
+	go func() {
+		progress, err := g.firebaseClient.ExportTree(ctx, firebase.TreeExportOptions{
+			Root:   root,
+			Target: target,
+			Progress: func(p firebase.TreeExportProgress) {
+				resumed := ""
+				if p.Resumed {
+					resumed = " (resumed)"
+				}
+				g.g.Update(func(gui *gocui.Gui) error {
+					g.updateCommand("export", g.withKeys(fmt.Sprintf("%d documents%s, at %s", p.Documents, resumed, p.Path), "  (%s to cancel)", "cancelExport"), "running")
+					return nil
+				})
+			},
+		})
+		cancel()
+
+		g.g.Update(func(gui *gocui.Gui) error {
+			g.exportCancel = nil
+			switch {
+			case errors.Is(err, context.Canceled):
+				g.updateCommand("export", fmt.Sprintf("Cancelled after %d documents, export again to the same path to resume", progress.Documents), "error")
+			case err != nil:
+				g.updateCommand("export", fmt.Sprintf("Failed after %d documents: %v", progress.Documents, err), "error")
+			default:
+				g.updateCommand("export", fmt.Sprintf("Exported %d documents to %s", progress.Documents, target), "success")
+			}
+			return nil
+		})
+	}()
+}
+
+// expandHome replaces a leading "~/" with the user's home directory.
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[2:])
+	}
+	return path
+}
+
 // getDocumentToCopy returns the document data to copy/save.
 // If a jq filter is active on details, returns the filtered result.
 func (g *Gui) getDocumentToCopy() (map[string]any, string, error) {
@@ -123,23 +234,36 @@ func (g *Gui) getJqFilteredResult() (map[string]any, string, bool) {
 		return nil, "", false
 	}
 
+	// Collect every result, keeping those before an error
 	iter := jqQuery.Run(g.currentDocData)
-	result, ok := iter.Next()
-	if !ok {
-		return nil, "", false
+	var results []any
+	for {
+		result, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if _, isErr := result.(error); isErr {
+			break
+		}
+		results = append(results, result)
 	}
-
-	if _, isErr := result.(error); isErr {
+	if len(results) == 0 {
 		return nil, "", false
 	}
 
-	// Convert result to map if possible
-	if resultMap, ok := result.(map[string]any); ok {
-		path := fmt.Sprintf("%s (jq: %s)", g.currentDocPath, filter)
-		return resultMap, path, true
-	}
-
-	// Wrap non-map results
 	path := fmt.Sprintf("%s (jq: %s)", g.currentDocPath, filter)
-	return map[string]any{"result": result}, path, true
+	return jqResultDocument(results), path, true
+}
+
+// jqResultDocument turns the results of a jq filter into a document to copy or
+// save: a single object as is, a single other value as {"result": x} and
+// several values as {"results": [...]}.
+func jqResultDocument(results []any) map[string]any {
+	if len(results) > 1 {
+		return map[string]any{"results": results}
+	}
+	if resultMap, ok := results[0].(map[string]any); ok {
+		return resultMap
+	}
+	return map[string]any{"result": results[0]}
 }
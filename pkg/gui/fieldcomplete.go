@@ -0,0 +1,237 @@
+package gui
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// maxFieldCandidates caps the completion popup of field names.
+const maxFieldCandidates = 10
+
+// fieldCompletion is a field path offered for completion with its types.
+type fieldCompletion struct {
+	Path string
+	Hint string // Types seen, e.g. "string" or "integer|double"
+}
+
+// collectionFields indexes the field paths of a collection from its documents
+// in the cache and its last sampled schema, sorted by path.
+func (g *Gui) collectionFields(collection string) []*firebase.SchemaField {
+	var docs []firebase.Document
+	for path, fields := range g.fieldsCache {
+		if i := strings.LastIndex(path, "/"); i > 0 && path[:i] == collection {
+			docs = append(docs, firebase.Document{Path: path, Fields: fields})
+		}
+	}
+	return mergeSchemaFields(firebase.InferSchema(collection, docs), g.schemaSamples[collection])
+}
+
+// mergeSchemaFields combines the fields of several schemas, adding up the
+// types seen per path. Nil schemas are skipped.
+func mergeSchemaFields(schemas ...*firebase.Schema) []*firebase.SchemaField {
+	byPath := make(map[string]*firebase.SchemaField)
+	for _, s := range schemas {
+		if s == nil {
+			continue
+		}
+		for _, f := range s.Fields {
+			merged := byPath[f.Path]
+			if merged == nil {
+				merged = &firebase.SchemaField{Path: f.Path, Name: f.Name, Parent: f.Parent, Types: make(map[string]int)}
+				byPath[f.Path] = merged
+			}
+			for typ, n := range f.Types {
+				merged.Types[typ] += n
+			}
+		}
+	}
+
+	fields := make([]*firebase.SchemaField, 0, len(byPath))
+	for _, f := range byPath {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+	return fields
+}
+
+// fieldTypeHint names the types of a field, most frequent first.
+func fieldTypeHint(f *firebase.SchemaField) string {
+	if names := f.TypeNames(); len(names) > 0 {
+		return strings.Join(names, "|")
+	}
+	return firebase.TypeNull
+}
+
+// queryFieldCompletions lists the paths a query can filter or order on.
+// Array elements can't be queried, so their paths are left out.
+func queryFieldCompletions(fields []*firebase.SchemaField) []fieldCompletion {
+	var completions []fieldCompletion
+	for _, f := range fields {
+		if !strings.Contains(f.Path, "[]") {
+			completions = append(completions, fieldCompletion{Path: f.Path, Hint: fieldTypeHint(f)})
+		}
+	}
+	return completions
+}
+
+// jqFieldCompletions lists the fields as jq paths, e.g. .items[].price.
+func jqFieldCompletions(fields []*firebase.SchemaField) []fieldCompletion {
+	completions := make([]fieldCompletion, 0, len(fields))
+	for _, f := range fields {
+		completions = append(completions, fieldCompletion{Path: jqFieldPath(f.Path), Hint: fieldTypeHint(f)})
+	}
+	return completions
+}
+
+// jqFieldPath turns a schema field path into a jq path: address.city becomes
+// .address.city, items[].price .items[].price and `first name` ."first name".
+func jqFieldPath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); {
+		switch {
+		case path[i] == '.':
+			i++
+		case strings.HasPrefix(path[i:], "[]"):
+			b.WriteString("[]")
+			i += 2
+		case path[i] == '`':
+			var name strings.Builder
+			for i++; i < len(path) && path[i] != '`'; i++ {
+				if path[i] == '\\' && i+1 < len(path) {
+					i++
+				}
+				name.WriteByte(path[i])
+			}
+			i++ // closing backquote
+			b.WriteString("." + strconv.Quote(name.String()))
+		default:
+			end := i
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+			b.WriteString("." + path[i:end])
+			i = end
+		}
+	}
+	return b.String()
+}
+
+// matchFieldCompletions returns the completions whose path starts with prefix,
+// or when none does those containing it (ignoring case), at most limit. The
+// second result is the longest common prefix of the matches, or prefix itself
+// when they don't share it.
+func matchFieldCompletions(all []fieldCompletion, prefix string, limit int) ([]fieldCompletion, string) {
+	var matches []fieldCompletion
+	for _, c := range all {
+		if strings.HasPrefix(c.Path, prefix) && c.Path != prefix {
+			matches = append(matches, c)
+		}
+	}
+	common := prefix
+	if len(matches) > 0 {
+		common = matches[0].Path
+		for _, m := range matches[1:] {
+			for !strings.HasPrefix(m.Path, common) {
+				common = common[:len(common)-1]
+			}
+		}
+	} else {
+		lower := strings.ToLower(prefix)
+		for _, c := range all {
+			if strings.Contains(strings.ToLower(c.Path), lower) && c.Path != prefix {
+				matches = append(matches, c)
+			}
+		}
+	}
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, common
+}
+
+// editingQueryField reports whether the query input edits a field name of a
+// filter or the ORDER BY.
+func (g *Gui) editingQueryField() bool {
+	switch g.queryActiveRow {
+	case queryRowFilters:
+		return g.queryActiveCol%4 == 0 && g.queryActiveCol/4 < len(g.queryFilters)
+	case queryRowOrderBy:
+		return g.queryActiveCol == 0
+	}
+	return false
+}
+
+// completeQueryField completes the field name typed in the query input against
+// the fields known in the queried collection.
+func (g *Gui) completeQueryField(v *gocui.View) {
+	all := queryFieldCompletions(g.collectionFields(g.queryCollection))
+	if len(all) == 0 {
+		g.logCommand("fields", "No fields known for "+g.queryCollection+": open some documents or infer its schema", "error")
+		return
+	}
+	matches, common := matchFieldCompletions(all, v.TextArea.GetContent(), maxFieldCandidates)
+	g.offerCompletions(matches, common, func(text string) {
+		v.TextArea.Clear()
+		v.TextArea.TypeString(text)
+		v.RenderTextArea()
+	})
+}
+
+// jqPathToken matches the path being typed at the end of a jq filter.
+var jqPathToken = regexp.MustCompile(`\.[A-Za-z0-9_.\[\]]*$`)
+
+// completeFilterField completes the jq path before the cursor in the details
+// filter against the fields known in the collection of the shown document.
+func (g *Gui) completeFilterField() error {
+	if g.filterInputPanel != "details" || !strings.HasPrefix(g.filterInputText, ".") {
+		return nil
+	}
+	before := g.filterInputText[:g.filterCursorPos]
+	token := jqPathToken.FindString(before)
+	if token == "" {
+		return nil
+	}
+
+	collection := ""
+	if i := strings.LastIndex(g.currentDocPath, "/"); i > 0 {
+		collection = g.currentDocPath[:i]
+	}
+	all := jqFieldCompletions(g.collectionFields(collection))
+	if len(all) == 0 {
+		g.logCommand("fields", "No fields known for this document", "error")
+		return nil
+	}
+	matches, common := matchFieldCompletions(all, token, maxFieldCandidates)
+	start := len(before) - len(token)
+	g.offerCompletions(matches, common, func(text string) {
+		g.filterInputText = g.filterInputText[:start] + text + g.filterInputText[g.filterCursorPos:]
+		g.filterCursorPos = start + len(text)
+	})
+	return g.Layout(g.g)
+}
+
+// offerCompletions fills in a single match, or the common prefix of several
+// matches and offers them in the select popup with their type hints.
+func (g *Gui) offerCompletions(matches []fieldCompletion, common string, set func(string)) {
+	switch len(matches) {
+	case 0:
+		g.logCommand("fields", "No known field matches", "error")
+	case 1:
+		set(matches[0].Path)
+	default:
+		set(common)
+		items := make([]string, len(matches))
+		hints := make([]string, len(matches))
+		for i, m := range matches {
+			items[i], hints[i] = m.Path, m.Hint
+		}
+		g.openQuerySelect(items, "", set)
+		g.querySelectHints = hints
+	}
+}
@@ -0,0 +1,98 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+func TestCollectionFields(t *testing.T) {
+	g := &Gui{
+		fieldsCache: map[string]map[string]any{
+			"users/a": {
+				"name": map[string]any{"stringValue": "Ana"},
+				"address": map[string]any{"mapValue": map[string]any{"fields": map[string]any{
+					"city": map[string]any{"stringValue": "Paris"},
+				}}},
+			},
+			"users/b": {
+				"age": map[string]any{"integerValue": "31"},
+			},
+			"users/a/posts/p1": {
+				"title": map[string]any{"stringValue": "Hi"},
+			},
+		},
+		schemaSamples: map[string]*firebase.Schema{
+			"users": {Collection: "users", Fields: []*firebase.SchemaField{
+				{Path: "age", Name: "age", Types: map[string]int{firebase.TypeDouble: 3, firebase.TypeInteger: 1}},
+				{Path: "tags", Name: "tags", Types: map[string]int{firebase.TypeArray: 2}},
+				{Path: "tags[]", Parent: "tags", Types: map[string]int{firebase.TypeString: 5}},
+			}},
+		},
+	}
+
+	got := queryFieldCompletions(g.collectionFields("users"))
+	expected := []fieldCompletion{
+		{Path: "address", Hint: "map"},
+		{Path: "address.city", Hint: "string"},
+		{Path: "age", Hint: "double|integer"},
+		{Path: "name", Hint: "string"},
+		{Path: "tags", Hint: "array"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("queryFieldCompletions() = %+v, expected %+v", got, expected)
+	}
+}
+
+func TestJQFieldPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"name", ".name"},
+		{"address.city", ".address.city"},
+		{"items[].price", ".items[].price"},
+		{"matrix[][]", ".matrix[][]"},
+		{"`first name`", `."first name"`},
+		{"meta.`a.b`.c", `.meta."a.b".c`},
+		{"`say \\`hi\\``", ".\"say `hi`\""},
+	}
+
+	for _, tt := range tests {
+		if got := jqFieldPath(tt.path); got != tt.expected {
+			t.Errorf("jqFieldPath(%q) = %q, expected %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestMatchFieldCompletions(t *testing.T) {
+	all := []fieldCompletion{
+		{Path: "address"}, {Path: "address.city"}, {Path: "address.zip"}, {Path: "age"}, {Path: "createdAt"},
+	}
+	tests := []struct {
+		prefix   string
+		limit    int
+		expected []string
+		common   string
+	}{
+		{"a", 10, []string{"address", "address.city", "address.zip", "age"}, "a"},
+		{"address.", 10, []string{"address.city", "address.zip"}, "address."},
+		{"addr", 10, []string{"address", "address.city", "address.zip"}, "address"},
+		{"age", 10, nil, "age"},
+		{"city", 10, []string{"address.city"}, "city"},
+		{"AT", 10, []string{"createdAt"}, "AT"},
+		{"", 2, []string{"address", "address.city"}, ""},
+	}
+
+	for _, tt := range tests {
+		matches, common := matchFieldCompletions(all, tt.prefix, tt.limit)
+		var paths []string
+		for _, m := range matches {
+			paths = append(paths, m.Path)
+		}
+		if !reflect.DeepEqual(paths, tt.expected) || common != tt.common {
+			t.Errorf("matchFieldCompletions(%q, %d) = %v, %q, expected %v, %q", tt.prefix, tt.limit, paths, common, tt.expected, tt.common)
+		}
+	}
+}
@@ -25,9 +25,14 @@ func (g *Gui) commitFilter(gui *gocui.Gui) error {
 	case "tree":
 		g.treeFilter = filterText
 		g.selectedTreeIdx = 0
+		if prefix, ok := idSearchFilter(filterText); ok {
+			g.searchDocumentIDs(g.treeFilterCollection, prefix)
+		}
 	case "details":
 		g.detailsFilter = filterText
-		g.detailsScrollPos = 0
+		if !isDetailsSearch(filterText) {
+			g.detailsScrollPos = 0
+		}
 	}
 
 	// Exit input mode but keep filter active
@@ -73,8 +78,11 @@ func (g *Gui) clearCurrentFilter(gui *gocui.Gui) error {
 		g.treeFilter = ""
 		g.selectedTreeIdx = 0
 	case "details":
+		// Stay at the match a search went to
+		if !isDetailsSearch(g.detailsFilter) {
+			g.detailsScrollPos = 0
+		}
 		g.detailsFilter = ""
-		g.detailsScrollPos = 0
 	}
 	return g.Layout(gui)
 }
@@ -89,6 +97,8 @@ func (g *Gui) cancelFilterInput(gui *gocui.Gui) error {
 
 // insertFilterChar inserts a character at the cursor position
 func (g *Gui) insertFilterChar(gui *gocui.Gui, ch rune) error {
+	// Typing dismisses the completion popup
+	g.closeQuerySelect()
 	// Insert character at cursor position
 	g.filterInputText = g.filterInputText[:g.filterCursorPos] + string(ch) + g.filterInputText[g.filterCursorPos:]
 	g.filterCursorPos++
@@ -108,6 +118,26 @@ func MatchesFilter(text, filter string) bool {
 	return strings.Contains(strings.ToLower(text), strings.ToLower(filter))
 }
 
+// FuzzyMatch checks if the characters of filter appear in text in order,
+// not necessarily next to each other (case-insensitive). Spaces in filter are ignored.
+func FuzzyMatch(text, filter string) bool {
+	rest := []rune(strings.ToLower(text))
+	for _, ch := range strings.ToLower(filter) {
+		if ch == ' ' {
+			continue
+		}
+		i := 0
+		for i < len(rest) && rest[i] != ch {
+			i++
+		}
+		if i == len(rest) {
+			return false
+		}
+		rest = rest[i+1:]
+	}
+	return true
+}
+
 // getFilteredProjects returns projects matching the current filter
 func (g *Gui) getFilteredProjects() []firebase.Project {
 	// Use input text while typing, otherwise use committed filter
@@ -155,6 +185,15 @@ func (g *Gui) getFilteredTreeNodes() []TreeNode {
 		return g.treeNodes
 	}
 	var filtered []TreeNode
+	if prefix, ok := idSearchFilter(filter); ok {
+		// ID prefix search: documents whose ID starts with the prefix
+		for _, n := range g.treeNodes {
+			if n.Type == "document" && strings.HasPrefix(n.Name, prefix) {
+				filtered = append(filtered, n)
+			}
+		}
+		return filtered
+	}
 	for _, n := range g.treeNodes {
 		if g.matchesFilter(n.Name, filter) || g.matchesFilter(n.Path, filter) {
 			filtered = append(filtered, n)
@@ -186,10 +225,13 @@ func (g *Gui) getOriginalTreeNodeIndex(filteredIdx int) int {
 	return -1
 }
 
-// renderFilteredDetails shows only JSON lines that match the filter
-// If filter starts with "." it's treated as a jq query
+// renderFilteredDetails applies the details filter: a filter starting with "."
+// is a jq query, one starting with g/ shows only the matching JSON lines and
+// anything else searches the document with the matches highlighted
 func (g *Gui) renderFilteredDetails(v *gocui.View) {
 	filter := g.getDetailsFilter()
+	// The view no longer shows the cached document
+	g.detailsViewDirty = true
 
 	// If filter starts with ".", treat as jq query
 	if strings.HasPrefix(filter, ".") {
@@ -197,7 +239,13 @@ func (g *Gui) renderFilteredDetails(v *gocui.View) {
 		return
 	}
 
-	// Otherwise, do line-based string matching
+	text, ok := grepFilter(filter)
+	if !ok {
+		g.renderSearchDetails(v, filter)
+		return
+	}
+
+	// Grep: line-based string matching
 	data, err := json.MarshalIndent(g.currentDocData, "", "  ")
 	if err != nil {
 		v.SetContent(fmt.Sprintf("Error formatting data: %v\n", err))
@@ -205,12 +253,12 @@ func (g *Gui) renderFilteredDetails(v *gocui.View) {
 	}
 
 	var content strings.Builder
-	content.WriteString(fmt.Sprintf("\033[36m─── %s (filtered) ───\033[0m\n\n", g.currentDocPath))
+	content.WriteString(fmt.Sprintf("\033[36m─── %s (grep: %s) ───\033[0m\n\n", g.currentDocPath, text))
 
 	lines := strings.Split(string(data), "\n")
 	matchCount := 0
 	for _, line := range lines {
-		if g.matchesFilter(line, filter) {
+		if g.matchesFilter(line, text) {
 			content.WriteString(colorizeLine(line))
 			content.WriteString("\n")
 			matchCount++
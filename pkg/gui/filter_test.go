@@ -81,3 +81,24 @@ func TestMatchesFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		text     string
+		filter   string
+		expected bool
+	}{
+		{"anything", "", true},
+		{"users/abc/orders", "uord", true},
+		{"Big orders prod:orders", "big prod", true},
+		{"users", "USR", true},
+		{"users", "usu", false},
+		{"orders", "ordersx", false},
+	}
+
+	for _, tt := range tests {
+		if got := FuzzyMatch(tt.text, tt.filter); got != tt.expected {
+			t.Errorf("FuzzyMatch(%q, %q) = %v, expected %v", tt.text, tt.filter, got, tt.expected)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package gui
 
 import (
+	"context"
 	"fmt"
 	"sync/atomic"
 	"time"
@@ -37,6 +38,7 @@ type Gui struct {
 	firebaseClient *firebase.Client
 	version        string
 	theme          *Theme
+	keybindings    *KeybindingManager // Effective keybindings, for help
 
 	// Projects state
 	projects             []firebase.Project
@@ -53,6 +55,8 @@ type Gui struct {
 	selectedTreeIdx int
 	expandedPaths   map[string]bool
 	docCache        map[string]map[string]any // Cache of fetched documents by path
+	fieldsCache     map[string]map[string]any // Typed Firestore fields of cached documents, for schema validation
+	timesCache      map[string]docTimes       // Create and update times of cached documents
 	collectionCache map[string][]string       // Cache of document paths per collection
 
 	// Details state
@@ -60,6 +64,8 @@ type Gui struct {
 	currentDocData     map[string]any
 	currentProjectInfo *firebase.ProjectDetails
 	detailsScrollPos   int
+	detailsSearch      *detailsSearch    // Last search of the details filter, with its matches
+	detailsHighlight   *detailsHighlight // Line to highlight when its document is shown, from a search hit
 
 	// Cached rendered content (avoid re-rendering on every Layout)
 	cachedDetailsContent string
@@ -83,8 +89,11 @@ type Gui struct {
 		modal       string
 		helpModal   string
 		queryModal  string
+		table       string
+		explorer    string
 		queryInput  string
 		querySelect string
+		prompt      string
 	}
 
 	// Current column: "projects", "collections", "tree", "details"
@@ -116,6 +125,10 @@ type Gui struct {
 	treeFilter        string
 	detailsFilter     string
 
+	// Document ID prefix search (tree filters starting with ^)
+	treeFilterCollection string          // Collection focused when the tree filter started
+	searchFound          map[string]bool // Documents found by the last search, marked in the tree
+
 	// Select mode (visual selection in tree)
 	selectMode     bool
 	selectedDocs   map[int]bool // indices of selected tree nodes
@@ -129,17 +142,57 @@ type Gui struct {
 	queryOrderBy     string
 	queryOrderDir    string // ASC or DESC
 	queryLimit       int
-	queryActiveRow   int    // Currently selected row in modal (0=filters, 1=orderBy, 2=limit, 3=buttons)
-	queryActiveCol   int    // Currently selected column/field in row
-	queryEditMode   bool   // True when editing a field value
-	queryEditBuffer string // Buffer for editing field value
-	queryResultMode bool   // True when showing query results instead of normal tree
+	queryActiveRow   int                        // Currently selected row in modal (0=text, 1=filters, 2=orderBy, 3=limit, 4=buttons)
+	queryActiveCol   int                        // Currently selected column/field in row
+	queryEditMode    bool                       // True when editing a field value
+	queryEditBuffer  string                     // Buffer for editing field value
+	queryResultMode  bool                       // True when showing query results instead of normal tree
+	queryParamValues map[string]string          // Last values given for $parameters
+	queryText        string                     // Query text that failed to parse
+	queryTextError   *firebase.QuerySyntaxError // Parse error of queryText, nil when the text row shows the builder's query
+	queryCodeOpen    bool                       // True when the modal shows the query as code
+	queryCodeLang    int                        // Selected tab of queryCodeLanguages
+	queryCodeScroll  int                        // First code line shown
 
 	// Query select popup state (for operators and types)
 	querySelectOpen     bool
 	querySelectItems    []string
 	querySelectIdx      int
 	querySelectCallback func(string) // Called when item is selected
+	querySelectHints    []string     // Dim text shown next to each item, e.g. field types
+
+	// Text prompt state (single-line input shown over everything)
+	promptOpen    bool
+	promptTitle   string
+	promptInitial string
+	promptSubmit  func(string) // Called with the entered text on Enter
+
+	// Running subtree export, nil when idle
+	exportCancel context.CancelFunc
+
+	// Table view of a collection, nil when closed
+	table *tableState
+
+	// JSON explorer over the details panel, nil when closed
+	explorer *explorerState
+
+	// Document diff
+	diffMark *diffSide // Left side, set with m
+	diffView *docDiff  // Diff shown in details while currentDocPath is its title
+
+	// jq program run across documents, shown in details while currentDocPath is its title
+	jqRunView *jqRun
+
+	// Schema report
+	schemaView    *firebase.Schema            // Schema shown in details while currentDocPath is its title
+	schemaSamples map[string]*firebase.Schema // Last sampled schema per collection, for field completion
+
+	// Schema validation
+	jsonSchemas map[string]*firebase.JSONSchema // Loaded JSON Schema files by path
+	invalidDocs map[string]int                  // Schema errors per document path, from validate collection
+
+	// Visited documents, for Ctrl-o / Ctrl-i
+	jumps jumpList
 
 	// Frame styling
 	roundedFrameRunes []rune
@@ -194,7 +247,12 @@ func NewGui(config *config.Config, firebaseClient *firebase.Client, version stri
 		expandedPaths:   make(map[string]bool),
 		selectedDocs:    make(map[int]bool),
 		docCache:        make(map[string]map[string]any),
+		fieldsCache:     make(map[string]map[string]any),
+		timesCache:      make(map[string]docTimes),
 		collectionCache: make(map[string][]string),
+		jsonSchemas:     make(map[string]*firebase.JSONSchema),
+		schemaSamples:   make(map[string]*firebase.Schema),
+		invalidDocs:     make(map[string]int),
 	}
 
 	// Set view names
@@ -207,8 +265,11 @@ func NewGui(config *config.Config, firebaseClient *firebase.Client, version stri
 	gui.views.modal = "modal"
 	gui.views.helpModal = "helpModal"
 	gui.views.queryModal = "queryModal"
+	gui.views.table = "table"
+	gui.views.explorer = "explorer"
 	gui.views.queryInput = "queryInput"
 	gui.views.querySelect = "querySelect"
+	gui.views.prompt = "prompt"
 	gui.views.background = "background"
 
 	// Configure gocui
@@ -228,10 +289,8 @@ func NewGui(config *config.Config, firebaseClient *firebase.Client, version stri
 	// Rounded frame characters: ─ │ ╭ ╮ ╰ ╯
 	gui.roundedFrameRunes = []rune{'─', '│', '╭', '╮', '╰', '╯'}
 
-	// Set layout function
-	g.SetManagerFunc(func(g *gocui.Gui) error {
-		return gui.Layout(g)
-	})
+	// Set layout functions (the prompt is drawn after the main layout so it stays on top)
+	g.SetManager(gocui.ManagerFunc(gui.Layout), gocui.ManagerFunc(gui.layoutPrompt))
 
 	// Set up keybindings
 	if err := gui.setKeybindings(); err != nil {
@@ -268,6 +327,21 @@ func (g *Gui) logCommand(command, description, status string) {
 	}
 }
 
+// updateCommand replaces the last log entry if it is the same running command,
+// so long-running jobs can report progress without flooding the history.
+func (g *Gui) updateCommand(command, description, status string) {
+	if n := len(g.commandHistory); n > 0 {
+		last := &g.commandHistory[n-1]
+		if last.Command == command && last.Status == "running" {
+			last.Timestamp = time.Now().Format("15:04:05")
+			last.Description = description
+			last.Status = status
+			return
+		}
+	}
+	g.logCommand(command, description, status)
+}
+
 func (g *Gui) Run() error {
 	defer g.g.Close()
 
@@ -293,7 +367,11 @@ func (g *Gui) Run() error {
 			authType = "local Firebase/gcloud"
 		}
 		g.g.Update(func(gui *gocui.Gui) error {
-			g.logCommand("auth", fmt.Sprintf("Using %s authentication", authType), "success")
+			if g.firebaseClient.IsOffline() {
+				g.logCommand("auth", "Browsing export offline (no authentication)", "success")
+			} else {
+				g.logCommand("auth", fmt.Sprintf("Using %s authentication", authType), "success")
+			}
 			return nil
 		})
 
@@ -324,7 +402,8 @@ func (g *Gui) Run() error {
 	if err := g.g.MainLoop(); err != nil && err != gocui.ErrQuit {
 		return err
 	}
-	return nil
+	// Quitting skips closeTable, so save a layout changed in an open table here
+	return g.saveTableLayout()
 }
 
 func (g *Gui) loadProjects() error {
@@ -346,6 +425,59 @@ func (g *Gui) loadCollections() error {
 	return nil
 }
 
+// docTimes are the create and update times of a cached document.
+type docTimes struct {
+	Created string
+	Updated string
+}
+
+// cacheDocument keeps a fetched document's data, typed fields and times by path.
+func (g *Gui) cacheDocument(doc firebase.Document) {
+	g.docCache[doc.Path] = doc.Data
+	g.fieldsCache[doc.Path] = doc.Fields
+	g.timesCache[doc.Path] = docTimes{Created: doc.CreateTime, Updated: doc.UpdateTime}
+}
+
+// projectCaches are what is known about documents of the current project.
+type projectCaches struct {
+	docs        map[string]map[string]any
+	fields      map[string]map[string]any
+	times       map[string]docTimes
+	collections map[string][]string
+	schemas     map[string]*firebase.Schema
+	invalidDocs map[string]int
+	searchFound map[string]bool
+}
+
+// currentCaches returns the document caches of the current project.
+func (g *Gui) currentCaches() projectCaches {
+	return projectCaches{g.docCache, g.fieldsCache, g.timesCache, g.collectionCache, g.schemaSamples, g.invalidDocs, g.searchFound}
+}
+
+// clearProjectCaches starts empty document caches when switching projects, so
+// a search or field completion never shows documents of the project left.
+func (g *Gui) clearProjectCaches() {
+	g.setProjectCaches(projectCaches{
+		docs:        make(map[string]map[string]any),
+		fields:      make(map[string]map[string]any),
+		times:       make(map[string]docTimes),
+		collections: make(map[string][]string),
+		schemas:     make(map[string]*firebase.Schema),
+		invalidDocs: make(map[string]int),
+	})
+}
+
+// setProjectCaches replaces the document caches, e.g. with saved ones.
+func (g *Gui) setProjectCaches(c projectCaches) {
+	g.docCache = c.docs
+	g.fieldsCache = c.fields
+	g.timesCache = c.times
+	g.collectionCache = c.collections
+	g.schemaSamples = c.schemas
+	g.invalidDocs = c.invalidDocs
+	g.searchFound = c.searchFound
+}
+
 // clearDetailsCache clears all cached details content and resets scroll
 func (g *Gui) clearDetailsCache() {
 	g.cachedDetailsContent = ""
@@ -354,6 +486,7 @@ func (g *Gui) clearDetailsCache() {
 	g.cachedDetailsHeader = ""
 	g.detailsViewDirty = true
 	g.detailsScrollPos = 0
+	g.detailsSearch = nil
 }
 
 // getLoadingText returns formatted loading text with animated spinner
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/gui/icons"
 )
 
 // State checking helpers
@@ -45,6 +46,7 @@ func (g *Gui) selectProject(gui *gocui.Gui) error {
 		}
 
 		g.currentProject = selectedProject.ID
+		g.clearProjectCaches()
 		g.collections = nil
 		g.treeNodes = nil
 		g.currentDocData = nil
@@ -100,7 +102,7 @@ func (g *Gui) selectCollection(gui *gocui.Gui) error {
 
 			// Cache all fetched documents
 			for _, doc := range docs {
-				g.docCache[doc.Path] = doc.Data
+				g.cacheDocument(doc)
 			}
 
 			for _, doc := range docs {
@@ -157,6 +159,7 @@ func (g *Gui) selectTreeNode(gui *gocui.Gui) error {
 			g.currentDocPath = nodePath
 			g.currentDocData = cachedData
 			g.clearDetailsCache()
+			g.recordVisit()
 			g.logCommand("cache", fmt.Sprintf("Using cached %s", nodeName), "success")
 			// Don't return - still need to load subcollections
 		}
@@ -168,7 +171,8 @@ func (g *Gui) selectTreeNode(gui *gocui.Gui) error {
 		}
 
 		go func() {
-			var docData map[string]any
+			var docData, docFields map[string]any
+			var times docTimes
 			if isCached {
 				docData = cachedData
 			} else {
@@ -182,6 +186,8 @@ func (g *Gui) selectTreeNode(gui *gocui.Gui) error {
 					return
 				}
 				docData = doc.Data
+				docFields = doc.Fields
+				times = docTimes{Created: doc.CreateTime, Updated: doc.UpdateTime}
 			}
 
 			subcols, err := g.firebaseClient.ListSubcollections(nodePath)
@@ -191,6 +197,11 @@ func (g *Gui) selectTreeNode(gui *gocui.Gui) error {
 				g.currentDocPath = nodePath
 				g.currentDocData = docData
 				g.docCache[nodePath] = docData // Cache for future use
+				if docFields != nil {
+					g.fieldsCache[nodePath] = docFields
+					g.timesCache[nodePath] = times
+				}
+				g.recordVisit()
 
 				if err != nil || len(subcols) == 0 {
 					if !isCached {
@@ -289,7 +300,7 @@ func (g *Gui) selectTreeNode(gui *gocui.Gui) error {
 				// Cache document data and collection contents
 				var docPaths []string
 				for _, doc := range docs {
-					g.docCache[doc.Path] = doc.Data
+					g.cacheDocument(doc)
 					docPaths = append(docPaths, doc.Path)
 				}
 				g.collectionCache[nodePath] = docPaths
@@ -375,49 +386,105 @@ func (g *Gui) collapseNode(idx int) {
 func (g *Gui) buildHelpPopup() {
 	items := []PopupItem{
 		{Key: "", Label: "Global", IsHeader: true},
-		{Key: "←/→ h/l", Label: "Switch panels"},
-		{Key: "↑/↓ j/k", Label: "Move up/down"},
-		{Key: "Space", Label: "Select / Expand", Action: g.doSpace},
-		{Key: "/", Label: "Filter / Search", Action: g.doStartFilter},
-		{Key: "Esc", Label: "Back / Collapse / Close"},
-		{Key: "r", Label: "Refresh", Action: g.doRefresh},
-		{Key: "@", Label: "Command log", Action: g.doToggleModal},
-		{Key: "?", Label: "This help"},
-		{Key: "q", Label: "Quit", Action: g.doQuit},
+		{Key: strings.TrimSpace(g.keyLabels("prevBlock", "nextBlock") + " " + g.altKeyLabels("prevBlock", "nextBlock")), Label: "Switch panels"},
+		{Key: strings.TrimSpace(g.altKeyLabels("prevItem", "nextItem") + " " + g.keyLabels("nextItem", "prevItem")), Label: "Move up/down"},
+		{Key: g.keyLabels("select"), Label: "Select / Expand", Action: g.doSpace},
+		{Key: g.keyLabels("startSearch"), Label: "Filter / Search", Action: g.doStartFilter},
+		{Key: g.keyLabels("return"), Label: "Back / Collapse / Close"},
+		{Key: g.keyLabels("refresh"), Label: "Refresh", Action: g.doRefresh},
+		{Key: g.keyLabels("commandLog"), Label: "Command log", Action: g.doToggleModal},
+		{Key: g.keyLabels("cancelExport"), Label: "Cancel running export", Action: g.doCancelExport},
+		{Key: g.keyLabels("optionMenu"), Label: "This help"},
+		{Key: g.keyLabels("quit"), Label: "Quit", Action: g.doQuit},
 		{Key: "", Label: g.getPanelName(), IsHeader: true},
 	}
 
 	switch g.currentColumn {
 	case "projects":
 		items = append(items,
-			PopupItem{Key: "Enter", Label: "Fetch project details", Action: g.doEnter},
-			PopupItem{Key: "Space", Label: "Select project", Action: g.doSpace},
+			PopupItem{Key: g.keyLabels("confirm"), Label: "Fetch project details", Action: g.doEnter},
+			PopupItem{Key: g.keyLabels("select"), Label: "Select project", Action: g.doSpace},
 		)
 	case "collections":
 		items = append(items,
-			PopupItem{Key: "Space", Label: "Load documents", Action: g.doSpace},
-			PopupItem{Key: "F", Label: "Query builder", Action: g.doOpenQuery},
+			PopupItem{Key: g.keyLabels("select"), Label: "Load documents", Action: g.doSpace},
+			PopupItem{Key: g.keyLabels("openQueryBuilder"), Label: "Query builder", Action: g.doOpenQuery},
+			PopupItem{Key: g.keyLabels("exportTree"), Label: "Export collection to disk", Action: g.doExportTree},
+			PopupItem{Key: g.keyLabels("import"), Label: "Import JSON / NDJSON file", Action: g.doImport},
+			PopupItem{Key: g.keyLabels("copyTo"), Label: "Copy to project / collection", Action: g.doCopyTo},
+			PopupItem{Key: g.keyLabels("openTable"), Label: "Table view of loaded documents", Action: g.doOpenTable},
+			PopupItem{Key: g.keyLabels("inferSchema"), Label: "Infer schema from sample", Action: g.doSchema},
+			PopupItem{Key: g.keyLabels("generateModels"), Label: "Generate TypeScript / Go / Python models", Action: g.doGenerateTypes},
+			PopupItem{Key: g.keyLabels("validateCollection"), Label: "Validate loaded documents against schema", Action: g.doValidateCollection},
+			PopupItem{Key: g.keyLabels("jqAcrossDocuments"), Label: "jq across loaded documents", Action: g.doJqRun},
+			PopupItem{Key: g.keyLabels("goToPath"), Label: "Go to path or console URL", Action: g.doGoToPath},
+			PopupItem{Key: g.keyLabels("addBookmark"), Label: "Bookmark collection", Action: g.doAddBookmark},
+			PopupItem{Key: g.keyLabels("openBookmarks"), Label: "Bookmarks", Action: g.doOpenBookmarks},
+			PopupItem{Key: g.keyLabels("openHistory"), Label: "Query history", Action: g.doOpenHistory},
 		)
 	case "tree":
 		items = append(items,
-			PopupItem{Key: "Space", Label: "Expand / Collapse", Action: g.doSpace},
-			PopupItem{Key: "Enter", Label: "Open in details", Action: g.doEnter},
-			PopupItem{Key: "v", Label: "Select mode (multi-select)", Action: g.doToggleSelectMode},
-			PopupItem{Key: "F", Label: "Query builder", Action: g.doOpenQuery},
-			PopupItem{Key: "c", Label: "Copy JSON to clipboard", Action: g.doCopyJSON},
-			PopupItem{Key: "s", Label: "Save JSON to Downloads", Action: g.doSaveJSON},
+			PopupItem{Key: g.keyLabels("select"), Label: "Expand / Collapse", Action: g.doSpace},
+			PopupItem{Key: g.keyLabels("confirm"), Label: "Open in details", Action: g.doEnter},
+			PopupItem{Key: g.keyLabels("openExplorer"), Label: "Explore document as a tree", Action: g.doOpenExplorer},
+			PopupItem{Key: g.keyLabels("jumpBack"), Label: "Back to previous document", Action: g.doJumpBack},
+			PopupItem{Key: g.keyLabels("goToPath"), Label: "Go to path or console URL", Action: g.doGoToPath},
+			PopupItem{Key: g.keyLabels("addBookmark"), Label: "Bookmark document / collection", Action: g.doAddBookmark},
+			PopupItem{Key: g.keyLabels("openBookmarks"), Label: "Bookmarks", Action: g.doOpenBookmarks},
+			PopupItem{Key: g.keyLabels("openHistory"), Label: "Query history", Action: g.doOpenHistory},
+			PopupItem{Key: g.keyLabels("toggleSelectMode"), Label: "Select mode (multi-select)", Action: g.doToggleSelectMode},
+			PopupItem{Key: g.keyLabels("openQueryBuilder"), Label: "Query builder", Action: g.doOpenQuery},
+			PopupItem{Key: g.keyLabels("copyJSON"), Label: "Copy JSON to clipboard", Action: g.doCopyJSON},
+			PopupItem{Key: g.keyLabels("saveJSON"), Label: "Save JSON to Downloads", Action: g.doSaveJSON},
+			PopupItem{Key: g.keyLabels("exportTree"), Label: "Export subtree to disk", Action: g.doExportTree},
+			PopupItem{Key: g.keyLabels("import"), Label: "Import into collection", Action: g.doImport},
+			PopupItem{Key: g.keyLabels("copyTo"), Label: "Copy to project / collection", Action: g.doCopyTo},
+			PopupItem{Key: g.keyLabels("openTable"), Label: "Table view of collection", Action: g.doOpenTable},
+			PopupItem{Key: g.keyLabels("inferSchema"), Label: "Infer schema of collection", Action: g.doSchema},
+			PopupItem{Key: g.keyLabels("generateModels"), Label: "Generate models for collection", Action: g.doGenerateTypes},
+			PopupItem{Key: g.keyLabels("validateCollection"), Label: "Validate collection against schema", Action: g.doValidateCollection},
+			PopupItem{Key: g.keyLabels("jqAcrossDocuments"), Label: "jq across selected / loaded documents", Action: g.doJqRun},
+			PopupItem{Key: g.keyLabels("markForDiff"), Label: "Mark document for diff", Action: g.doMarkDiff},
+			PopupItem{Key: g.keyLabels("diffWithMarked"), Label: "Diff with marked document", Action: g.doDiff},
 		)
 	case "details":
 		items = append(items,
-			PopupItem{Key: "j/k", Label: "Scroll content"},
-			PopupItem{Key: "Esc", Label: "Go back"},
-			PopupItem{Key: "c", Label: "Copy JSON to clipboard", Action: g.doCopyJSON},
-			PopupItem{Key: "s", Label: "Save JSON to Downloads", Action: g.doSaveJSON},
-			PopupItem{Key: "e", Label: "Open in editor", Action: g.doEditInEditor},
+			PopupItem{Key: g.keyLabels("nextItem", "prevItem"), Label: "Scroll content"},
+			PopupItem{Key: g.keyLabels("return"), Label: "Go back"},
+			PopupItem{Key: g.keyLabels("copyJSON"), Label: "Copy JSON to clipboard", Action: g.doCopyJSON},
+			PopupItem{Key: g.keyLabels("saveJSON"), Label: "Save JSON to Downloads (jq runs as NDJSON)", Action: g.doSaveJSON},
+			PopupItem{Key: g.keyLabels("edit"), Label: "Open in editor", Action: g.doEditInEditor},
+			PopupItem{Key: g.keyLabels("openExplorer"), Label: "Explore JSON (fold, copy path, jq)", Action: g.doOpenExplorer},
+			PopupItem{Key: g.keyLabels("confirm"), Label: "Follow reference", Action: g.followReferenceAction},
+			PopupItem{Key: g.keyLabels("nextMatch", "prevMatch"), Label: "Next / previous search match", Action: g.doNextMatch},
+			PopupItem{Key: g.keyLabels("jumpBack"), Label: "Back to previous document", Action: g.doJumpBack},
+			PopupItem{Key: g.keyLabels("jumpForward"), Label: "Forward to next document", Action: g.doJumpForward},
+			PopupItem{Key: g.keyLabels("goToPath"), Label: "Go to path or console URL", Action: g.doGoToPath},
+			PopupItem{Key: g.keyLabels("addBookmark"), Label: "Bookmark document", Action: g.doAddBookmark},
+			PopupItem{Key: g.keyLabels("openBookmarks"), Label: "Bookmarks", Action: g.doOpenBookmarks},
+			PopupItem{Key: g.keyLabels("openHistory"), Label: "Query history", Action: g.doOpenHistory},
+			PopupItem{Key: g.keyLabels("markForDiff"), Label: "Mark document for diff", Action: g.doMarkDiff},
+			PopupItem{Key: g.keyLabels("diffWithMarked"), Label: "Diff with marked document", Action: g.doDiff},
+			PopupItem{Key: g.keyLabels("toggleDiffIgnoreOrder"), Label: "Diff: ignore array order", Action: g.doToggleDiffIgnoreOrder},
 		)
 	}
 
-	g.helpPopup = NewPopup("Keyboard Shortcuts", items, g.theme, g.views.helpModal)
+	// Leave out actions that are unbound
+	shown := items[:0]
+	for _, item := range items {
+		if item.IsHeader || item.Key != "" {
+			shown = append(shown, item)
+		}
+	}
+
+	g.helpPopup = NewPopup(icons.KEYBOARD_ICON+" Keyboard Shortcuts", shown, g.theme, g.views.helpModal)
+}
+
+// openMenu shows a list of actions in the help popup; Enter runs the selected one.
+func (g *Gui) openMenu(title string, items []PopupItem) error {
+	g.helpPopup = NewPopup(title, items, g.theme, g.views.helpModal)
+	g.helpOpen = true
+	return g.Layout(g.g)
 }
 
 func (g *Gui) renderHelpContent(v *gocui.View) {
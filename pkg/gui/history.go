@@ -0,0 +1,121 @@
+package gui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/marjoballabani/lazyfire/pkg/config"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// recordQuery appends an executed query to the project's history.
+func recordQuery(project, collection string, opts firebase.QueryOptions, count int, latency time.Duration) error {
+	return config.AppendHistory(config.HistoryPath(project), config.HistoryEntry{
+		Collection: collection,
+		Filters:    queryFilterConfigs(opts.Filters),
+		OrderBy:    opts.OrderBy,
+		OrderDir:   opts.OrderDir,
+		Limit:      opts.Limit,
+		Time:       time.Now(),
+		Count:      count,
+		LatencyMs:  latency.Milliseconds(),
+	})
+}
+
+// historyLabel describes an executed query: collection, query and outcome.
+func historyLabel(e config.HistoryEntry) string {
+	summary := querySummary(e.Filters, e.OrderBy, e.OrderDir, e.Limit)
+	return fmt.Sprintf("%s \033[90m%s · %d docs · %dms\033[0m", e.Collection, summary, e.Count, e.LatencyMs)
+}
+
+// openQueryAt switches to the project if needed and expands the tree down to
+// a collection, then loads a query into the query builder with restore and
+// either runs it or opens the builder to edit it.
+func (g *Gui) openQueryAt(project, collection string, restore func(), run bool) error {
+	return g.navigateTo(project, collection, func() {
+		restore()
+		g.queryCollection = collection
+		g.queryNodeIdx = -1
+		if len(pathLevels(collection)) > 1 {
+			g.queryNodeIdx = g.treeIndex(collection)
+		}
+		_ = g.setFocus(g.g, "tree")
+		if run {
+			_ = g.executeQuery()
+			return
+		}
+		g.queryModalOpen = true
+		g.queryActiveRow = queryRowFilters
+		g.queryActiveCol = 0
+	})
+}
+
+// openHistoryAction lists the current project's executed queries matching
+// filter, newest first. Enter re-runs one, e opens it in the query builder
+// and / filters the list.
+func (g *Gui) openHistoryAction(filter string) error {
+	project := g.currentProject
+	if project == "" {
+		g.logCommand("history", "Select a project first", "error")
+		return nil
+	}
+	entries, err := config.LoadHistory(config.HistoryPath(project))
+	if err != nil {
+		g.logCommand("history", err.Error(), "error")
+		return nil
+	}
+	if len(entries) == 0 {
+		g.logCommand("history", "No queries run in "+project+" yet", "error")
+		return nil
+	}
+
+	filterKey := func() error {
+		return g.openPrompt("Filter history", filter, func(text string) {
+			_ = g.openHistoryAction(text)
+		})
+	}
+
+	title := "Query history of " + project
+	if filter != "" {
+		title = fmt.Sprintf("Query history matching %q", filter)
+	}
+	items := []PopupItem{{Label: title, IsHeader: true}}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if filter != "" && !FuzzyMatch(e.Collection+" "+querySummary(e.Filters, e.OrderBy, e.OrderDir, e.Limit), filter) {
+			continue
+		}
+		restore := func() {
+			g.restoreQueryState(e.Filters, e.OrderBy, e.OrderDir, e.Limit)
+		}
+		items = append(items, PopupItem{
+			Key:   e.Time.Local().Format("Jan 02 15:04"),
+			Label: historyLabel(e),
+			Action: func() error {
+				return g.openQueryAt(project, e.Collection, restore, true)
+			},
+			Keys: map[rune]func() error{
+				'/': filterKey,
+				'e': func() error {
+					return g.openQueryAt(project, e.Collection, restore, false)
+				},
+			},
+		})
+	}
+	if filter != "" {
+		items = append(items, PopupItem{
+			Key:    "/",
+			Label:  "Show all queries",
+			Action: func() error { return g.openHistoryAction("") },
+			Keys:   map[rune]func() error{'/': filterKey},
+		})
+	}
+
+	if err := g.openMenu("Query history", items); err != nil {
+		return err
+	}
+	g.helpPopup.Footer = g.footerHints([]keyHint{
+		{g.keyLabels("confirm"), "re-run"}, {"e", "edit in query builder"}, {"/", "filter"}, {g.keyLabels("return"), "close"},
+	})
+	return nil
+}
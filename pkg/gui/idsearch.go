@@ -0,0 +1,116 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// idSearchPrefix starts a tree filter that searches document IDs on the server.
+const idSearchPrefix = "^"
+
+// maxIDSearchResults caps the documents an ID prefix search adds to the tree.
+const maxIDSearchResults = 100
+
+// idSearchFilter returns the document ID prefix of a tree filter like
+// ^order_2025_, and whether the filter is an ID prefix search.
+func idSearchFilter(filter string) (string, bool) {
+	if !strings.HasPrefix(filter, idSearchPrefix) {
+		return "", false
+	}
+	return filter[len(idSearchPrefix):], true
+}
+
+// searchDocumentIDs finds the documents of a collection whose ID starts with
+// prefix with a range query on the server, and merges them into the tree.
+func (g *Gui) searchDocumentIDs(collection, prefix string) {
+	if collection == "" {
+		g.logCommand("search", "Select a collection or one of its documents to search its IDs", "error")
+		return
+	}
+	if prefix == "" {
+		return
+	}
+	g.logCommand("search", fmt.Sprintf("Searching %s for IDs starting with %q...", collection, prefix), "running")
+
+	go func() {
+		docs, err := g.firebaseClient.SearchDocumentIDs(collection, prefix, maxIDSearchResults)
+
+		g.g.Update(func(gui *gocui.Gui) error {
+			if err != nil {
+				g.updateCommand("search", fmt.Sprintf("Failed: %v", err), "error")
+				return nil
+			}
+
+			g.searchFound = make(map[string]bool)
+			for _, doc := range docs {
+				g.cacheDocument(doc)
+				g.searchFound[doc.Path] = true
+			}
+			added, ok := g.mergeTreeDocuments(collection, docs)
+			if !ok {
+				g.updateCommand("search", fmt.Sprintf("%s is no longer in the tree", collection), "error")
+				return nil
+			}
+
+			more := ""
+			if len(docs) == maxIDSearchResults {
+				more = fmt.Sprintf(" (first %d)", maxIDSearchResults)
+			}
+			g.updateCommand("search", fmt.Sprintf("%d documents of %s start with %q%s, %d added to the tree", len(docs), collection, prefix, more, added), "success")
+			return nil
+		})
+	}()
+}
+
+// mergeTreeDocuments adds the documents of a collection that are not in the
+// tree yet after its loaded documents: under its node, or at the top level
+// when it is the collection the tree shows. It returns how many were added and
+// false when the collection is not in the tree.
+func (g *Gui) mergeTreeDocuments(collection string, docs []firebase.Document) (int, bool) {
+	insertAt, depth := -1, 0
+	for i, node := range g.treeNodes {
+		if node.Type == "collection" && node.Path == collection {
+			depth = node.Depth + 1
+			insertAt = i + 1
+			for insertAt < len(g.treeNodes) && g.treeNodes[insertAt].Depth >= depth {
+				insertAt++
+			}
+			g.treeNodes[i].Expanded = true
+			g.treeNodes[i].HasChildren = true
+			break
+		}
+	}
+	if insertAt == -1 {
+		if collection != g.currentCollection {
+			return 0, false
+		}
+		insertAt = len(g.treeNodes)
+	}
+
+	present := make(map[string]bool, len(g.treeNodes))
+	for _, node := range g.treeNodes {
+		present[node.Path] = true
+	}
+	var added []TreeNode
+	for _, doc := range docs {
+		if !present[doc.Path] {
+			added = append(added, TreeNode{
+				Path:        doc.Path,
+				Name:        doc.ID,
+				Type:        "document",
+				Depth:       depth,
+				HasChildren: true,
+			})
+		}
+	}
+
+	nodes := make([]TreeNode, 0, len(g.treeNodes)+len(added))
+	nodes = append(nodes, g.treeNodes[:insertAt]...)
+	nodes = append(nodes, added...)
+	nodes = append(nodes, g.treeNodes[insertAt:]...)
+	g.treeNodes = nodes
+	return len(added), true
+}
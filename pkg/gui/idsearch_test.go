@@ -0,0 +1,74 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+func treePaths(nodes []TreeNode) []string {
+	var paths []string
+	for _, n := range nodes {
+		paths = append(paths, n.Path)
+	}
+	return paths
+}
+
+func TestMergeTreeDocuments(t *testing.T) {
+	g := &Gui{
+		currentCollection: "orders",
+		treeNodes: []TreeNode{
+			{Path: "orders/a", Name: "a", Type: "document"},
+			{Path: "orders/a/items", Name: "items", Type: "collection", Depth: 1},
+			{Path: "orders/a/items/i1", Name: "i1", Type: "document", Depth: 2},
+			{Path: "orders/b", Name: "b", Type: "document"},
+		},
+	}
+
+	added, ok := g.mergeTreeDocuments("orders/a/items", []firebase.Document{
+		{Path: "orders/a/items/i1", ID: "i1"},
+		{Path: "orders/a/items/i7", ID: "i7"},
+	})
+	if !ok || added != 1 {
+		t.Errorf("mergeTreeDocuments(orders/a/items) = %d, %v, expected 1, true", added, ok)
+	}
+	if g.treeNodes[3].Depth != 2 || !g.treeNodes[1].Expanded {
+		t.Errorf("found document should be a child of the expanded collection node, got %+v", g.treeNodes)
+	}
+
+	added, ok = g.mergeTreeDocuments("orders", []firebase.Document{
+		{Path: "orders/b", ID: "b"},
+		{Path: "orders/order_2025_10_01", ID: "order_2025_10_01"},
+	})
+	if !ok || added != 1 {
+		t.Errorf("mergeTreeDocuments(orders) = %d, %v, expected 1, true", added, ok)
+	}
+
+	expected := []string{"orders/a", "orders/a/items", "orders/a/items/i1", "orders/a/items/i7", "orders/b", "orders/order_2025_10_01"}
+	if got := treePaths(g.treeNodes); !reflect.DeepEqual(got, expected) {
+		t.Errorf("tree = %v, expected %v", got, expected)
+	}
+
+	if _, ok := g.mergeTreeDocuments("users", nil); ok {
+		t.Error("mergeTreeDocuments(users) should fail for a collection not in the tree")
+	}
+}
+
+func TestIDSearchTreeFilter(t *testing.T) {
+	g := &Gui{
+		treeFilter: "^order_2025_10",
+		treeNodes: []TreeNode{
+			{Path: "orders", Name: "orders", Type: "collection"},
+			{Path: "orders/order_2025_09_30", Name: "order_2025_09_30", Type: "document", Depth: 1},
+			{Path: "orders/order_2025_10_01", Name: "order_2025_10_01", Type: "document", Depth: 1},
+			{Path: "orders/Order_2025_10_02", Name: "Order_2025_10_02", Type: "document", Depth: 1},
+			{Path: "orders/x_order_2025_10", Name: "x_order_2025_10", Type: "document", Depth: 1},
+		},
+	}
+
+	expected := []string{"orders/order_2025_10_01"}
+	if got := treePaths(g.getFilteredTreeNodes()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("getFilteredTreeNodes() = %v, expected %v", got, expected)
+	}
+}
@@ -0,0 +1,162 @@
+package gui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// maxImportFailuresLogged limits how many individual failures go to the command log.
+const maxImportFailuresLogged = 5
+
+// importAction asks for a file, the field holding document IDs and an import mode,
+// then imports the file into the selected collection.
+func (g *Gui) importAction() error {
+	if g.firebaseClient.IsOffline() {
+		g.logCommand("import", "Importing is not available when browsing an export", "error")
+		return nil
+	}
+
+	collection := g.getImportCollection()
+	if collection == "" {
+		g.logCommand("import", "Select a collection to import into", "error")
+		return nil
+	}
+
+	return g.openPrompt(fmt.Sprintf("Import into %s from (JSON or NDJSON file)", collection), "", func(path string) {
+		if path == "" {
+			return
+		}
+		path = expandHome(path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			g.logCommand("import", fmt.Sprintf("Failed to read file: %v", err), "error")
+			return
+		}
+
+		_ = g.openPrompt("ID field (empty to generate IDs)", "id", func(idField string) {
+			records, err := firebase.ParseImportFile(data, idField)
+			if err != nil {
+				g.logCommand("import", fmt.Sprintf("%s: %v", filepath.Base(path), err), "error")
+				return
+			}
+			if len(records) == 0 {
+				g.logCommand("import", fmt.Sprintf("%s contains no documents", filepath.Base(path)), "error")
+				return
+			}
+			_ = g.openImportModeMenu(collection, filepath.Base(path), records)
+		})
+	})
+}
+
+// getImportCollection returns the collection selected in the current panel.
+func (g *Gui) getImportCollection() string {
+	switch g.currentColumn {
+	case "collections":
+		filtered := g.getFilteredCollections()
+		if g.selectedCollectionIdx < len(filtered) {
+			return filtered[g.selectedCollectionIdx].Path
+		}
+	case "tree":
+		filtered := g.getFilteredTreeNodes()
+		if g.selectedTreeIdx < len(filtered) && filtered[g.selectedTreeIdx].Type == "collection" {
+			return filtered[g.selectedTreeIdx].Path
+		}
+	}
+	return ""
+}
+
+// openImportModeMenu lets the user choose between skip-existing and overwrite,
+// or a dry run of either.
+func (g *Gui) openImportModeMenu(collection, fileName string, records []firebase.ImportRecord) error {
+	start := func(mode string, dryRun bool) func() error {
+		return func() error {
+			g.startImport(collection, records, mode, dryRun)
+			return g.Layout(g.g)
+		}
+	}
+
+	items := []PopupItem{
+		{Label: fmt.Sprintf("%d documents from %s", len(records), fileName), IsHeader: true},
+		{Key: "dry run", Label: "Preview skip, write nothing", Action: start(firebase.ImportSkipExisting, true)},
+		{Key: "dry run", Label: "Preview overwrite, write nothing", Action: start(firebase.ImportOverwrite, true)},
+		{Key: "skip", Label: "Create new, keep existing", Action: start(firebase.ImportSkipExisting, false)},
+		{Key: "overwrite", Label: "Create new, replace existing", Action: start(firebase.ImportOverwrite, false)},
+	}
+	return g.openMenu("Import into "+collection, items)
+}
+
+// startImport runs the import in the background and logs a summary when done.
+func (g *Gui) startImport(collection string, records []firebase.ImportRecord, mode string, dryRun bool) {
+	label := mode
+	if dryRun {
+		label += ", dry run"
+	}
+	g.logCommand("import", fmt.Sprintf("Importing %d documents into %s (%s)...", len(records), collection, label), "running")
+
+	go func() {
+		summary, err := g.firebaseClient.ImportDocuments(context.Background(), records, firebase.ImportOptions{
+			Collection: collection,
+			Mode:       mode,
+			DryRun:     dryRun,
+			Progress: func(done, total int) {
+				g.g.Update(func(gui *gocui.Gui) error {
+					g.updateCommand("import", fmt.Sprintf("%d/%d documents processed", done, total), "running")
+					return nil
+				})
+			},
+		})
+
+		g.g.Update(func(gui *gocui.Gui) error {
+			if summary != nil {
+				for i, f := range summary.Failed {
+					if i == maxImportFailuresLogged {
+						g.logCommand("import", fmt.Sprintf("...and %d more failures", len(summary.Failed)-i), "error")
+						break
+					}
+					g.logCommand("import", fmt.Sprintf("%s: %s", f.ID, f.Error), "error")
+				}
+			}
+
+			if err != nil {
+				g.logCommand("import", fmt.Sprintf("Failed: %v", err), "error")
+				return nil
+			}
+
+			status := "success"
+			if len(summary.Failed) > 0 {
+				status = "error"
+			}
+			g.logCommand("import", formatImportSummary(summary), status)
+
+			// Re-read the collection next time it is expanded
+			if !dryRun {
+				delete(g.collectionCache, collection)
+			}
+			return nil
+		})
+	}()
+}
+
+// formatImportSummary describes the outcome of an import in one line.
+func formatImportSummary(s *firebase.ImportSummary) string {
+	switch {
+	case s.DryRun && s.Mode == firebase.ImportOverwrite:
+		return fmt.Sprintf("Dry run: %d would be created, %d would be replaced, %d invalid",
+			len(s.Created), len(s.Overwritten), len(s.Failed))
+	case s.DryRun:
+		return fmt.Sprintf("Dry run: %d would be created, %d would be skipped as existing, %d invalid",
+			len(s.Created), len(s.Skipped), len(s.Failed))
+	case s.Mode == firebase.ImportOverwrite:
+		return fmt.Sprintf("Created %d, replaced %d, %d failed",
+			len(s.Created), len(s.Overwritten), len(s.Failed))
+	default:
+		return fmt.Sprintf("Created %d, skipped %d existing, %d failed",
+			len(s.Created), len(s.Skipped), len(s.Failed))
+	}
+}
+
@@ -0,0 +1,331 @@
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itchyny/gojq"
+	"github.com/jesseduffield/gocui"
+)
+
+// jqRun is a jq program run across many documents, shown in details while
+// currentDocPath is its title.
+type jqRun struct {
+	Program    string
+	Collection string   // Collection of the documents, names the saved file
+	Source     string   // What the documents are, e.g. "12 loaded documents of users"
+	Paths      []string // Documents fed to the program, in order
+	Results    []any
+	Err        error // jq error that stopped the run; the results before it are kept
+}
+
+// Title identifies the run; it is shown as the details path.
+func (r *jqRun) Title() string {
+	return "jq: " + r.Program
+}
+
+// NDJSON returns the results one JSON value per line.
+func (r *jqRun) NDJSON() ([]byte, error) {
+	var b strings.Builder
+	for _, result := range r.Results {
+		line, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	return []byte(b.String()), nil
+}
+
+// jqFields converts fields in Firestore's typed format for jq. They read like
+// the data shown in details, except integers are numbers so they compare as such.
+func jqFields(fields map[string]any) map[string]any {
+	out := make(map[string]any, len(fields))
+	for name, value := range fields {
+		out[name] = jqTypedValue(value)
+	}
+	return out
+}
+
+// jqTypedValue converts one value in Firestore's typed format for jq.
+func jqTypedValue(value any) any {
+	typed, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	for typ, v := range typed {
+		switch typ {
+		case "integerValue":
+			if s, ok := v.(string); ok {
+				if n, err := strconv.Atoi(s); err == nil {
+					return n
+				}
+			}
+			return v
+		case "mapValue":
+			m, _ := v.(map[string]any)
+			fields, _ := m["fields"].(map[string]any)
+			return jqFields(fields)
+		case "arrayValue":
+			m, _ := v.(map[string]any)
+			values, _ := m["values"].([]any)
+			arr := make([]any, len(values))
+			for i, item := range values {
+				arr[i] = jqTypedValue(item)
+			}
+			return arr
+		default:
+			return v
+		}
+	}
+	return nil
+}
+
+// jqInput is what a program sees of a cached document: its fields plus __id,
+// __path, __createTime and __updateTime (null when unknown, e.g. in backups).
+func (g *Gui) jqInput(path string) map[string]any {
+	var input map[string]any
+	if fields, ok := g.fieldsCache[path]; ok && fields != nil {
+		input = jqFields(fields)
+	} else {
+		input = make(map[string]any, len(g.docCache[path])+4)
+		for k, v := range g.docCache[path] {
+			input[k] = v
+		}
+	}
+
+	input["__id"] = path[strings.LastIndex(path, "/")+1:]
+	input["__path"] = path
+	input["__createTime"], input["__updateTime"] = nil, nil
+	if times, ok := g.timesCache[path]; ok {
+		if times.Created != "" {
+			input["__createTime"] = times.Created
+		}
+		if times.Updated != "" {
+			input["__updateTime"] = times.Updated
+		}
+	}
+	return input
+}
+
+// runJq streams the inputs through a compiled program and collects every
+// result, stopping at the first error.
+func runJq(code *gojq.Code, inputs []map[string]any) ([]any, error) {
+	var results []any
+	for _, input := range inputs {
+		iter := code.Run(input)
+		for {
+			result, ok := iter.Next()
+			if !ok {
+				break
+			}
+			if err, isErr := result.(error); isErr {
+				return results, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// jqRunDocuments returns the cached documents a program runs across: the
+// selected ones in select mode, otherwise the loaded documents or query
+// results of the focused collection. It also describes them and counts the
+// selected documents that are not loaded yet.
+func (g *Gui) jqRunDocuments() (paths []string, collection, source string, missing int) {
+	if g.selectMode && len(g.selectedDocs) > 0 {
+		filtered := g.getFilteredTreeNodes()
+		idxs := make([]int, 0, len(g.selectedDocs))
+		for idx := range g.selectedDocs {
+			idxs = append(idxs, idx)
+		}
+		sort.Ints(idxs)
+		for _, idx := range idxs {
+			if idx >= len(filtered) || filtered[idx].Type != "document" {
+				continue
+			}
+			path := filtered[idx].Path
+			if _, ok := g.docCache[path]; !ok {
+				missing++
+				continue
+			}
+			paths = append(paths, path)
+			if collection == "" {
+				collection = path[:strings.LastIndex(path, "/")]
+			}
+		}
+		return paths, collection, fmt.Sprintf("%d selected documents", len(paths)), missing
+	}
+
+	collection = g.getSelectedCollection()
+	if collection == "" {
+		return nil, "", "", 0
+	}
+	for _, row := range g.getTableRows(collection) {
+		if row.Data != nil {
+			paths = append(paths, row.Path)
+		}
+	}
+	what := "loaded documents"
+	if g.queryResultMode {
+		what = "query results"
+	}
+	return paths, collection, fmt.Sprintf("%d %s of %s", len(paths), what, collection), 0
+}
+
+// jqRunAction asks for a jq program and runs it across the selected or loaded documents.
+func (g *Gui) jqRunAction() error {
+	paths, collection, source, missing := g.jqRunDocuments()
+	if missing > 0 {
+		g.logCommand("jq", fmt.Sprintf("%d selected documents are not loaded, Space fetches them", missing), "error")
+	}
+	if len(paths) == 0 {
+		g.logCommand("jq", "Select documents or a collection with loaded documents", "error")
+		return nil
+	}
+
+	program := ""
+	if g.jqRunView != nil {
+		program = g.jqRunView.Program
+	}
+	return g.openPrompt("jq across "+source, program, func(text string) {
+		if text == "" {
+			return
+		}
+		run := &jqRun{Program: text, Collection: collection, Source: source, Paths: paths}
+		if err := g.runJqProgram(run); err != nil {
+			g.logCommand("jq", err.Error(), "error")
+			return
+		}
+		_ = g.showJqRun(run)
+	})
+}
+
+// runJqProgram compiles the run's program and fills in its results.
+func (g *Gui) runJqProgram(run *jqRun) error {
+	query, err := gojq.Parse(run.Program)
+	if err != nil {
+		return fmt.Errorf("jq parse error: %v", err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return fmt.Errorf("jq compile error: %v", err)
+	}
+
+	inputs := make([]map[string]any, len(run.Paths))
+	for i, path := range run.Paths {
+		inputs[i] = g.jqInput(path)
+	}
+	run.Results, run.Err = runJq(code, inputs)
+	return nil
+}
+
+// showJqRun puts the results of a run in the details panel and focuses it.
+// The results also become the current document data.
+func (g *Gui) showJqRun(run *jqRun) error {
+	g.jqRunView = run
+	g.currentDocData = map[string]any{"results": run.Results}
+	g.currentDocPath = run.Title()
+	g.clearDetailsCache()
+
+	if run.Err != nil {
+		g.logCommand("jq", fmt.Sprintf("%d results, stopped by: %v", len(run.Results), run.Err), "error")
+	} else {
+		g.logCommand("jq", fmt.Sprintf("%d results from %s", len(run.Results), run.Source), "success")
+	}
+
+	if g.currentColumn != "details" {
+		g.previousColumn = g.currentColumn
+	}
+	if err := g.setFocus(g.g, "details"); err != nil {
+		return err
+	}
+	return g.Layout(g.g)
+}
+
+// isJqRunShown reports whether the details panel is showing the current jq run.
+func (g *Gui) isJqRunShown() bool {
+	return g.jqRunView != nil && g.currentDocData != nil && g.currentDocPath == g.jqRunView.Title()
+}
+
+// renderJqRunDetails draws the current jq run in the details view.
+func (g *Gui) renderJqRunDetails(v *gocui.View) {
+	if g.cachedDetailsDocPath != g.currentDocPath || g.cachedDetailsContent == "" {
+		g.cachedDetailsContent = renderJqRun(g.jqRunView)
+		g.cachedDetailsLines = strings.Split(g.cachedDetailsContent, "\n")
+		g.cachedDetailsHeader = ""
+		g.cachedDetailsDocPath = g.currentDocPath
+		g.detailsViewDirty = true
+	}
+	if g.detailsViewDirty {
+		v.SetContent(g.cachedDetailsContent)
+		g.detailsViewDirty = false
+	}
+}
+
+// renderJqRun formats the results of a run, each as colorized JSON.
+func renderJqRun(run *jqRun) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("\033[36m─── %s ───\033[0m\n", run.Title()))
+	content.WriteString(fmt.Sprintf("\033[90m%d results from %s · s save / c copy as NDJSON\033[0m\n", len(run.Results), run.Source))
+	if run.Err != nil {
+		content.WriteString(fmt.Sprintf("\033[31mjq error: %v\033[0m\n", run.Err))
+	}
+	content.WriteString("\n")
+
+	for _, result := range run.Results {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			content.WriteString(fmt.Sprintf("%v\n", result))
+			continue
+		}
+		content.WriteString(colorizeJSON(string(data)))
+		content.WriteString("\n")
+	}
+	if len(run.Results) == 0 && run.Err == nil {
+		content.WriteString("\033[90mNo results\033[0m\n")
+	}
+	return content.String()
+}
+
+// copyJqRun puts the results of the shown run on the clipboard as NDJSON.
+func (g *Gui) copyJqRun() error {
+	data, err := g.jqRunView.NDJSON()
+	if err != nil {
+		g.logCommand("copy", fmt.Sprintf("Failed to marshal JSON: %v", err), "error")
+		return nil
+	}
+	if err := copyToClipboard(string(data)); err != nil {
+		g.logCommand("copy", fmt.Sprintf("Failed to copy: %v", err), "error")
+		return nil
+	}
+	g.logCommand("copy", fmt.Sprintf("Copied %d jq results as NDJSON", len(g.jqRunView.Results)), "success")
+	return nil
+}
+
+// saveJqRun writes the results of the shown run as NDJSON to the Downloads directory.
+func (g *Gui) saveJqRun() error {
+	data, err := g.jqRunView.NDJSON()
+	if err != nil {
+		g.logCommand("save", fmt.Sprintf("Failed to marshal JSON: %v", err), "error")
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s_jq.ndjson", strings.ReplaceAll(g.jqRunView.Collection, "/", "_"))
+	home, _ := os.UserHomeDir()
+	fullPath := filepath.Join(home, "Downloads", filename)
+
+	if err := os.WriteFile(fullPath, data, 0644); err != nil {
+		g.logCommand("save", fmt.Sprintf("Failed to save: %v", err), "error")
+		return nil
+	}
+
+	g.logCommand("save", fmt.Sprintf("Saved %d jq results to %s", len(g.jqRunView.Results), fullPath), "success")
+	return nil
+}
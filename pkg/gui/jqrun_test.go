@@ -0,0 +1,109 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/itchyny/gojq"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+func TestJqFields(t *testing.T) {
+	fields := map[string]any{
+		"age":   map[string]any{"integerValue": "31"},
+		"score": map[string]any{"doubleValue": 4.5},
+		"name":  map[string]any{"stringValue": "Ada"},
+		"gone":  map[string]any{"nullValue": nil},
+		"address": map[string]any{"mapValue": map[string]any{"fields": map[string]any{
+			"zip": map[string]any{"integerValue": "1010"},
+		}}},
+		"tags":  map[string]any{"arrayValue": map[string]any{"values": []any{map[string]any{"integerValue": "1"}, map[string]any{"booleanValue": true}}}},
+		"empty": map[string]any{"arrayValue": map[string]any{}},
+	}
+
+	expected := map[string]any{
+		"age":     31,
+		"score":   4.5,
+		"name":    "Ada",
+		"gone":    nil,
+		"address": map[string]any{"zip": 1010},
+		"tags":    []any{1, true},
+		"empty":   []any{},
+	}
+	if got := jqFields(fields); !reflect.DeepEqual(got, expected) {
+		t.Errorf("jqFields = %#v, expected %#v", got, expected)
+	}
+}
+
+func TestRunJqAcrossDocuments(t *testing.T) {
+	g := &Gui{
+		docCache:    make(map[string]map[string]any),
+		fieldsCache: make(map[string]map[string]any),
+		timesCache:  make(map[string]docTimes),
+	}
+	g.cacheDocument(firebase.Document{
+		Path:       "users/ada",
+		Fields:     map[string]any{"age": map[string]any{"integerValue": "36"}, "email": map[string]any{"stringValue": "ada@example.com"}},
+		CreateTime: "2025-01-02T03:04:05Z",
+	})
+	g.cacheDocument(firebase.Document{
+		Path:   "users/bob",
+		Fields: map[string]any{"age": map[string]any{"integerValue": "25"}, "email": map[string]any{"stringValue": "bob@example.com"}},
+	})
+
+	query, err := gojq.Parse(`select(.age > 30) | {id: .__id, email, created: .__createTime}, .__path`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := runJq(code, []map[string]any{g.jqInput("users/ada"), g.jqInput("users/bob")})
+	if err != nil {
+		t.Fatalf("runJq: %v", err)
+	}
+	expected := []any{
+		map[string]any{"id": "ada", "email": "ada@example.com", "created": "2025-01-02T03:04:05Z"},
+		"users/ada",
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("results = %#v, expected %#v", results, expected)
+	}
+
+	// An error stops the run but keeps the results before it
+	query, _ = gojq.Parse(`.email | if startswith("bob") then error("bad") else . end`)
+	code, _ = gojq.Compile(query)
+	results, err = runJq(code, []map[string]any{g.jqInput("users/ada"), g.jqInput("users/bob")})
+	if err == nil || !reflect.DeepEqual(results, []any{"ada@example.com"}) {
+		t.Errorf("runJq with error = %v, %v; expected [ada@example.com] and an error", results, err)
+	}
+}
+
+func TestJqRunNDJSON(t *testing.T) {
+	run := &jqRun{Results: []any{map[string]any{"id": "ada"}, 3, "x"}}
+	data, err := run.NDJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "{\"id\":\"ada\"}\n3\n\"x\"\n"; string(data) != expected {
+		t.Errorf("NDJSON = %q, expected %q", data, expected)
+	}
+}
+
+func TestJqResultDocument(t *testing.T) {
+	tests := []struct {
+		results  []any
+		expected map[string]any
+	}{
+		{[]any{map[string]any{"a": 1}}, map[string]any{"a": 1}},
+		{[]any{"x"}, map[string]any{"result": "x"}},
+		{[]any{1, 2}, map[string]any{"results": []any{1, 2}}},
+	}
+	for _, tt := range tests {
+		if got := jqResultDocument(tt.results); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("jqResultDocument(%v) = %v, expected %v", tt.results, got, tt.expected)
+		}
+	}
+}
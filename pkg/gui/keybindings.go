@@ -11,6 +11,17 @@ func (g *Gui) setKeybindings() error {
 	km.RegisterAll(g.filterBindings(km))
 	km.RegisterAll(g.actionBindings(km))
 	km.RegisterAll(g.mouseBindings())
+	km.RegisterAll(g.tableBindings())
+	km.RegisterAll(g.explorerBindings())
+
+	var overrides map[string][]string
+	if g.config != nil {
+		overrides = g.config.Keybinding
+	}
+	for _, problem := range km.Resolve(overrides) {
+		g.logCommand("keybinding", problem, "error")
+	}
+	g.keybindings = km
 
 	return km.Apply()
 }
@@ -19,22 +30,27 @@ func (g *Gui) setKeybindings() error {
 func (g *Gui) globalBindings(km *KeybindingManager) []*Binding {
 	return []*Binding{
 		{
-			Key:         gocui.KeyCtrlC,
-			Handler:     g.doQuit,
-			Description: "Force quit",
+			ID:               "quit",
+			Key:              'q',
+			Handler:          g.doQuit,
+			Description:      "Quit",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
 		},
 		{
-			Key:         'q',
+			ID:          "forceQuit",
+			Key:         gocui.KeyCtrlC,
 			Handler:     g.doQuit,
-			Description: "Quit",
+			Description: "Force quit",
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertQ,
-				ContextHelp:   g.blockAction,
-				ContextModal:  g.blockAction,
-				ContextQuery:  g.queryInsertChar('q'),
+				ContextTable:    g.doQuit,
+				ContextExplorer: g.doQuit,
 			},
 		},
 		{
+			ID:          "return",
 			Key:         gocui.KeyEsc,
 			Handler:     g.doEscape,
 			Description: "Close/Cancel",
@@ -44,21 +60,23 @@ func (g *Gui) globalBindings(km *KeybindingManager) []*Binding {
 			},
 		},
 		{
-			Key:         '?',
-			Handler:     g.doToggleHelp,
-			Description: "Show help",
+			ID:               "optionMenu",
+			Key:              '?',
+			Handler:          g.doToggleHelp,
+			Description:      "Show help",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertQuestion,
-				ContextQuery:  g.queryInsertChar('?'),
+				ContextHelp: g.doToggleHelp,
 			},
 		},
 		{
-			Key:         '@',
-			Handler:     g.doToggleModal,
-			Description: "Command log",
+			ID:               "commandLog",
+			Key:              '@',
+			Handler:          g.doToggleModal,
+			Description:      "Command log",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertAt,
-				ContextQuery:  g.queryInsertChar('@'),
+				ContextHelp: g.doToggleModal,
 			},
 		},
 	}
@@ -67,33 +85,9 @@ func (g *Gui) globalBindings(km *KeybindingManager) []*Binding {
 // navigationBindings - panel and list navigation
 func (g *Gui) navigationBindings(km *KeybindingManager) []*Binding {
 	return []*Binding{
-		// Arrow up/down - context aware
-		{
-			Key:         gocui.KeyArrowUp,
-			Handler:     g.doCursorUp,
-			Description: "Move up",
-			Contexts: map[Context]func() error{
-				ContextHelp:        g.helpMoveUp,
-				ContextModal:       g.blockAction,
-				ContextSelect:      g.selectMoveUp,
-				ContextQuery:       g.queryMoveUp,
-				ContextQuerySelect: g.querySelectMoveUp,
-			},
-		},
-		{
-			Key:         gocui.KeyArrowDown,
-			Handler:     g.doCursorDown,
-			Description: "Move down",
-			Contexts: map[Context]func() error{
-				ContextHelp:        g.helpMoveDown,
-				ContextModal:       g.blockAction,
-				ContextSelect:      g.selectMoveDown,
-				ContextQuery:       g.queryMoveDown,
-				ContextQuerySelect: g.querySelectMoveDown,
-			},
-		},
 		// Arrow left/right - context aware
 		{
+			ID:          "prevBlock",
 			Key:         gocui.KeyArrowLeft,
 			Handler:     g.doColumnLeft,
 			Description: "Move left",
@@ -105,6 +99,7 @@ func (g *Gui) navigationBindings(km *KeybindingManager) []*Binding {
 			},
 		},
 		{
+			ID:          "nextBlock",
 			Key:         gocui.KeyArrowRight,
 			Handler:     g.doColumnRight,
 			Description: "Move right",
@@ -117,11 +112,12 @@ func (g *Gui) navigationBindings(km *KeybindingManager) []*Binding {
 		},
 		// Vim keys - context aware
 		{
-			Key:         'j',
-			Handler:     g.doCursorDown,
-			Description: "Move down",
+			ID:               "nextItem",
+			Key:              'j',
+			Handler:          g.doCursorDown,
+			Description:      "Move down",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter:      g.filterInsertJ,
 				ContextHelp:        g.helpMoveDown,
 				ContextModal:       g.blockAction,
 				ContextSelect:      g.selectMoveDown,
@@ -130,67 +126,99 @@ func (g *Gui) navigationBindings(km *KeybindingManager) []*Binding {
 			},
 		},
 		{
-			Key:         'k',
+			ID:               "prevItem",
+			Key:              'k',
+			Handler:          g.doCursorUp,
+			Description:      "Move up",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextHelp:        g.helpMoveUp,
+				ContextModal:       g.blockAction,
+				ContextSelect:      g.selectMoveUp,
+				ContextQuery:       g.queryKeyK,
+				ContextQuerySelect: g.querySelectMoveUp,
+			},
+		},
+		// Arrow up/down - context aware, more keys of nextItem/prevItem
+		{
+			ID:          "prevItem",
+			Key:         gocui.KeyArrowUp,
 			Handler:     g.doCursorUp,
 			Description: "Move up",
 			Contexts: map[Context]func() error{
-				ContextFilter:      g.filterInsertK,
 				ContextHelp:        g.helpMoveUp,
 				ContextModal:       g.blockAction,
 				ContextSelect:      g.selectMoveUp,
-				ContextQuery:       g.queryKeyK,
+				ContextQuery:       g.queryMoveUp,
 				ContextQuerySelect: g.querySelectMoveUp,
 			},
 		},
 		{
-			Key:         'h',
-			Handler:     g.doColumnLeft,
-			Description: "Move left",
+			ID:          "nextItem",
+			Key:         gocui.KeyArrowDown,
+			Handler:     g.doCursorDown,
+			Description: "Move down",
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertH,
-				ContextHelp:   g.blockAction,
-				ContextModal:  g.blockAction,
-				ContextQuery:  g.queryKeyH,
+				ContextHelp:        g.helpMoveDown,
+				ContextModal:       g.blockAction,
+				ContextSelect:      g.selectMoveDown,
+				ContextQuery:       g.queryMoveDown,
+				ContextQuerySelect: g.querySelectMoveDown,
 			},
 		},
+		// h/l, more keys of prevBlock/nextBlock
 		{
-			Key:         'l',
-			Handler:     g.doColumnRight,
-			Description: "Move right",
+			ID:               "prevBlock",
+			Key:              'h',
+			Handler:          g.doColumnLeft,
+			Description:      "Move left",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertL,
-				ContextHelp:   g.blockAction,
-				ContextModal:  g.blockAction,
-				ContextQuery:  g.queryKeyL,
+				ContextModal: g.blockAction,
+				ContextQuery: g.queryKeyH,
+			},
+		},
+		{
+			ID:               "nextBlock",
+			Key:              'l',
+			Handler:          g.doColumnRight,
+			Description:      "Move right",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+				ContextQuery: g.queryKeyL,
 			},
 		},
 		// Tab
 		{
+			ID:          "togglePanel",
 			Key:         gocui.KeyTab,
 			Handler:     g.doNextColumn,
 			Description: "Next panel",
 			Contexts: map[Context]func() error{
-				ContextFilter: g.blockAction,
-				ContextHelp:   g.blockAction,
-				ContextModal:  g.blockAction,
-				ContextQuery:  g.queryNextField,
+				ContextFilter:      g.completeFilterField,
+				ContextHelp:        g.blockAction,
+				ContextModal:       g.blockAction,
+				ContextQuery:       g.queryNextField,
+				ContextQuerySelect: g.querySelectMoveDown,
+				ContextPrompt:      g.blockAction,
 			},
 		},
 		// Space - context aware
 		{
-			Key:         gocui.KeySpace,
-			Handler:     g.doSpace,
-			Description: "Select/Expand",
+			ID:               "select",
+			Key:              gocui.KeySpace,
+			Handler:          g.doSpace,
+			Description:      "Select/Expand",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertSpace,
-				ContextHelp:   g.blockAction,
 				ContextModal:  g.blockAction,
 				ContextSelect: g.doFetchSelectedDocs,
-				ContextQuery:  g.blockAction,
 			},
 		},
 		// Enter - context aware
 		{
+			ID:          "confirm",
 			Key:         gocui.KeyEnter,
 			Handler:     g.doEnter,
 			Description: "Confirm/Details",
@@ -208,48 +236,34 @@ func (g *Gui) navigationBindings(km *KeybindingManager) []*Binding {
 func (g *Gui) filterBindings(km *KeybindingManager) []*Binding {
 	bindings := []*Binding{
 		{
-			Key:         '/',
-			Handler:     g.doStartFilter,
-			Description: "Start filter",
+			ID:               "startSearch",
+			Key:              '/',
+			Handler:          g.doStartFilter,
+			Description:      "Start filter",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertSlash,
-				ContextHelp:   g.blockAction,
-				ContextModal:  g.blockAction,
-				ContextQuery:  g.queryInsertChar('/'),
+				ContextModal: g.blockAction,
 			},
 		},
 		{
-			Key:     gocui.KeyBackspace,
-			Handler: g.doFilterBackspace,
+			Key:         gocui.KeyBackspace,
+			Handler:     g.doFilterBackspace,
+			Description: "Backspace",
 			Contexts: map[Context]func() error{
 				ContextQuery: g.queryBackspace,
 			},
 		},
 		{
-			Key:     gocui.KeyBackspace2,
-			Handler: g.doFilterBackspace,
+			Key:         gocui.KeyBackspace2,
+			Handler:     g.doFilterBackspace,
+			Description: "Backspace",
 			Contexts: map[Context]func() error{
 				ContextQuery: g.queryBackspace,
 			},
 		},
 	}
 
-	// Character handlers for filter input (includes jq syntax chars)
-	// Exclude chars that have dedicated context-aware bindings: hjkl, csrqveFQ, ?@/
-	filterChars := "abdfgimnoptuwxyzABCDEGHIJKLMNOPRSTUVWXYZ0123456789"
-	filterChars += "-_. "
-	filterChars += "[]|(){}:\"'`,<>=!+*^$#~;&%\\"
-	for _, ch := range filterChars {
-		c := ch // capture for closure
-		bindings = append(bindings, &Binding{
-			Key:     c,
-			Handler: g.makeFilterCharAction(c),
-			Contexts: map[Context]func() error{
-				ContextQuery: g.queryInsertChar(c),
-			},
-		})
-	}
-
+	// Characters no action uses are bound to typing by KeybindingManager.Resolve
 	return bindings
 }
 
@@ -257,70 +271,274 @@ func (g *Gui) filterBindings(km *KeybindingManager) []*Binding {
 func (g *Gui) actionBindings(km *KeybindingManager) []*Binding {
 	return []*Binding{
 		{
-			Key:         'F',
-			Handler:     g.doOpenQuery,
-			Description: "Query builder",
+			ID:               "openQueryBuilder",
+			Key:              'F',
+			Handler:          g.doOpenQuery,
+			Description:      "Query builder",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertUpperF,
-				ContextHelp:   g.blockAction,
-				ContextModal:  g.blockAction,
-				ContextQuery:  g.queryInsertChar('F'),
+				ContextModal: g.blockAction,
 			},
 		},
 		{
-			Key:         'c',
-			Handler:     g.doCopyJSON,
-			Description: "Copy JSON",
+			ID:               "copyJSON",
+			Key:              'c',
+			Handler:          g.doCopyJSON,
+			Description:      "Copy JSON",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertC,
-				ContextHelp:   g.blockAction,
-				ContextModal:  g.blockAction,
-				ContextQuery:  g.queryInsertChar('c'),
+				ContextModal: g.blockAction,
 			},
 		},
 		{
-			Key:         's',
-			Handler:     g.doSaveJSON,
-			Description: "Save JSON",
+			ID:               "saveJSON",
+			Key:              's',
+			Handler:          g.doSaveJSON,
+			Description:      "Save JSON",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertS,
-				ContextHelp:   g.blockAction,
-				ContextModal:  g.blockAction,
-				ContextQuery:  g.queryInsertChar('s'),
+				ContextModal: g.blockAction,
 			},
 		},
 		{
-			Key:         'r',
-			Handler:     g.doRefresh,
-			Description: "Refresh",
+			ID:               "refresh",
+			Key:              'r',
+			Handler:          g.doRefresh,
+			Description:      "Refresh",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertR,
-				ContextHelp:   g.blockAction,
-				ContextModal:  g.blockAction,
-				ContextQuery:  g.queryInsertChar('r'),
+				ContextModal: g.blockAction,
 			},
 		},
 		{
-			Key:         'v',
-			Handler:     g.doToggleSelectMode,
-			Description: "Select mode",
+			ID:               "toggleSelectMode",
+			Key:              'v',
+			Handler:          g.doToggleSelectMode,
+			Description:      "Select mode",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertV,
-				ContextHelp:   g.blockAction,
 				ContextModal:  g.blockAction,
 				ContextSelect: g.doToggleSelectMode, // Toggle off
-				ContextQuery:  g.queryInsertChar('v'),
 			},
 		},
 		{
-			Key:         'e',
-			Handler:     g.doEditInEditor,
-			Description: "Edit in $EDITOR",
+			ID:               "exportTree",
+			Key:              'E',
+			Handler:          g.doExportTree,
+			Description:      "Export subtree",
+			BlockWhileTyping: true,
 			Contexts: map[Context]func() error{
-				ContextFilter: g.filterInsertE,
-				ContextHelp:   g.blockAction,
-				ContextModal:  g.blockAction,
-				ContextQuery:  g.queryInsertChar('e'),
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "import",
+			Key:              'I',
+			Handler:          g.doImport,
+			Description:      "Import JSON",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "copyTo",
+			Key:              'C',
+			Handler:          g.doCopyTo,
+			Description:      "Copy to",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "openTable",
+			Key:              'T',
+			Handler:          g.doOpenTable,
+			Description:      "Table view",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "inferSchema",
+			Key:              'S',
+			Handler:          g.doSchema,
+			Description:      "Infer schema",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "jqAcrossDocuments",
+			Key:              'J',
+			Handler:          g.doJqRun,
+			Description:      "jq across documents",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "generateModels",
+			Key:              'M',
+			Handler:          g.doGenerateTypes,
+			Description:      "Generate models",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "validateCollection",
+			Key:              'V',
+			Handler:          g.doValidateCollection,
+			Description:      "Validate collection",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "addBookmark",
+			Key:              'b',
+			Handler:          g.doAddBookmark,
+			Description:      "Bookmark",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "openBookmarks",
+			Key:              'B',
+			Handler:          g.doOpenBookmarks,
+			Description:      "Bookmarks",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "openHistory",
+			Key:              'H',
+			Handler:          g.doOpenHistory,
+			Description:      "Query history",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "openExplorer",
+			Key:              'x',
+			Handler:          g.doOpenExplorer,
+			Description:      "Explore JSON",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "markForDiff",
+			Key:              'm',
+			Handler:          g.doMarkDiff,
+			Description:      "Mark for diff",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "diffWithMarked",
+			Key:              'D',
+			Handler:          g.doDiff,
+			Description:      "Diff with marked",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "nextMatch",
+			Key:              'n',
+			Handler:          g.doNextMatch,
+			Description:      "Next search match",
+			BlockWhileTyping: true,
+		},
+		{
+			ID:               "prevMatch",
+			Key:              'N',
+			Handler:          g.doPrevMatch,
+			Description:      "Previous search match",
+			BlockWhileTyping: true,
+		},
+		{
+			ID:               "toggleDiffIgnoreOrder",
+			Key:              'w',
+			Handler:          g.doToggleDiffIgnoreOrder,
+			Description:      "Diff: ignore array order",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:          "cancelExport",
+			Key:         gocui.KeyCtrlX,
+			Handler:     g.doCancelExport,
+			Description: "Cancel export",
+		},
+		{
+			ID:               "goToPath",
+			Key:              gocui.KeyCtrlG,
+			Handler:          g.doGoToPath,
+			Description:      "Go to path",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "searchValues",
+			Key:              gocui.KeyCtrlF,
+			Handler:          g.doSearch,
+			Description:      "Search document values",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:               "jumpBack",
+			Key:              gocui.KeyCtrlO,
+			Handler:          g.doJumpBack,
+			Description:      "Back to previous document",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
+			},
+		},
+		{
+			ID:          "jumpForward",
+			Key:         gocui.KeyTab,
+			ViewName:    g.views.details,
+			Handler:     g.doJumpForward,
+			Description: "Forward to next document",
+			Contexts: map[Context]func() error{
+				ContextFilter: g.completeFilterField,
+			},
+		},
+		{
+			ID:               "edit",
+			Key:              'e',
+			Handler:          g.doEditInEditor,
+			Description:      "Edit in $EDITOR",
+			BlockWhileTyping: true,
+			Contexts: map[Context]func() error{
+				ContextModal: g.blockAction,
 			},
 		},
 	}
@@ -0,0 +1,96 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jesseduffield/gocui"
+)
+
+// disabledKey unbinds an action in the keybinding config.
+const disabledKey = "<disabled>"
+
+// keyName is a key in lazygit's <c-x> notation and how help shows it.
+type keyName struct {
+	Name  string
+	Key   gocui.Key
+	Label string
+}
+
+// keyNames lists the keys with a name. Entries without a name only label keys
+// that can't be configured.
+var keyNames = func() []keyName {
+	names := []keyName{
+		{"<enter>", gocui.KeyEnter, "Enter"},
+		{"<esc>", gocui.KeyEsc, "Esc"},
+		{"<tab>", gocui.KeyTab, "Tab"},
+		{"<backtab>", gocui.KeyBacktab, "Shift-Tab"},
+		{"<space>", gocui.KeySpace, "Space"},
+		{"<backspace>", gocui.KeyBackspace2, "Backspace"},
+		{"<up>", gocui.KeyArrowUp, "↑"},
+		{"<down>", gocui.KeyArrowDown, "↓"},
+		{"<left>", gocui.KeyArrowLeft, "←"},
+		{"<right>", gocui.KeyArrowRight, "→"},
+		{"<s-up>", gocui.KeyShiftArrowUp, "Shift-↑"},
+		{"<s-down>", gocui.KeyShiftArrowDown, "Shift-↓"},
+		{"<insert>", gocui.KeyInsert, "Insert"},
+		{"<delete>", gocui.KeyDelete, "Delete"},
+		{"<home>", gocui.KeyHome, "Home"},
+		{"<end>", gocui.KeyEnd, "End"},
+		{"<pgup>", gocui.KeyPgup, "PgUp"},
+		{"<pgdown>", gocui.KeyPgdn, "PgDn"},
+		{"<c-space>", gocui.KeyCtrlSpace, "Ctrl-Space"},
+		{"<c-/>", gocui.KeyCtrlSlash, "Ctrl-/"},
+		{"<c-\\>", gocui.KeyCtrlBackslash, "Ctrl-\\"},
+		{"<c-]>", gocui.KeyCtrlRsqBracket, "Ctrl-]"},
+		{"", gocui.KeyBackspace, "Backspace"}, // What some terminals send for Backspace
+	}
+	fkeys := []gocui.Key{gocui.KeyF1, gocui.KeyF2, gocui.KeyF3, gocui.KeyF4, gocui.KeyF5, gocui.KeyF6,
+		gocui.KeyF7, gocui.KeyF8, gocui.KeyF9, gocui.KeyF10, gocui.KeyF11, gocui.KeyF12}
+	for i, key := range fkeys {
+		names = append(names, keyName{fmt.Sprintf("<f%d>", i+1), key, fmt.Sprintf("F%d", i+1)})
+	}
+	ctrl := []gocui.Key{gocui.KeyCtrlA, gocui.KeyCtrlB, gocui.KeyCtrlC, gocui.KeyCtrlD, gocui.KeyCtrlE,
+		gocui.KeyCtrlF, gocui.KeyCtrlG, gocui.KeyCtrlH, gocui.KeyCtrlI, gocui.KeyCtrlJ, gocui.KeyCtrlK,
+		gocui.KeyCtrlL, gocui.KeyCtrlM, gocui.KeyCtrlN, gocui.KeyCtrlO, gocui.KeyCtrlP, gocui.KeyCtrlQ,
+		gocui.KeyCtrlR, gocui.KeyCtrlS, gocui.KeyCtrlT, gocui.KeyCtrlU, gocui.KeyCtrlV, gocui.KeyCtrlW,
+		gocui.KeyCtrlX, gocui.KeyCtrlY, gocui.KeyCtrlZ}
+	for i, key := range ctrl {
+		letter := string(rune('a' + i))
+		names = append(names, keyName{"<c-" + letter + ">", key, "Ctrl-" + letter})
+	}
+	return names
+}()
+
+// parseKey reads a key in lazygit's notation: a single character like F or ?,
+// or a name like <c-x>, <enter> or <f5>. It returns a rune or a gocui.Key.
+func parseKey(s string) (interface{}, error) {
+	if utf8.RuneCountInString(s) == 1 {
+		r, _ := utf8.DecodeRuneInString(s)
+		return r, nil
+	}
+	lower := strings.ToLower(s)
+	for _, n := range keyNames {
+		if n.Name != "" && n.Name == lower {
+			return n.Key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key %q", s)
+}
+
+// keyLabel shows a key in help, e.g. F, Ctrl-g or Enter.
+func keyLabel(key interface{}) string {
+	switch k := key.(type) {
+	case rune:
+		return string(k)
+	case gocui.Key:
+		for _, n := range keyNames {
+			if n.Key == k {
+				return n.Label
+			}
+		}
+		return fmt.Sprintf("key %d", k)
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/jesseduffield/gocui"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"F", 'F'},
+		{"?", '?'},
+		{"<c-x>", gocui.KeyCtrlX},
+		{"<C-G>", gocui.KeyCtrlG},
+		{"<enter>", gocui.KeyEnter},
+		{"<space>", gocui.KeySpace},
+		{"<pgdown>", gocui.KeyPgdn},
+		{"<f5>", gocui.KeyF5},
+		{"<c-/>", gocui.KeyCtrlSlash},
+	}
+	for _, tt := range tests {
+		got, err := parseKey(tt.input)
+		if err != nil || got != tt.expected {
+			t.Errorf("parseKey(%q) = %v, %v, expected %v", tt.input, got, err, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"", "ab", "<c-1>", "<nope>"} {
+		if _, err := parseKey(input); err == nil {
+			t.Errorf("parseKey(%q) should fail", input)
+		}
+	}
+}
+
+func TestKeyLabel(t *testing.T) {
+	tests := []struct {
+		key      interface{}
+		expected string
+	}{
+		{'q', "q"},
+		{gocui.KeyCtrlG, "Ctrl-g"},
+		{gocui.KeyEsc, "Esc"},
+		{gocui.KeyTab, "Tab"},
+		{gocui.KeyEnter, "Enter"},
+		{gocui.KeyArrowLeft, "←"},
+		{gocui.KeyF1, "F1"},
+		{gocui.KeyBackspace, "Backspace"},
+	}
+	for _, tt := range tests {
+		if got := keyLabel(tt.key); got != tt.expected {
+			t.Errorf("keyLabel(%v) = %q, expected %q", tt.key, got, tt.expected)
+		}
+	}
+}
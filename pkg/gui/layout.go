@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
 	"github.com/marjoballabani/lazyfire/pkg/gui/icons"
 )
 
@@ -240,6 +242,9 @@ func (g *Gui) Layout(gui *gocui.Gui) error {
 			gui.SelFrameColor = g.theme.FilterBorderColor
 			gui.SelFgColor = g.theme.FilterBorderColor
 			v.Title = " " + icons.DETAILS_ICON + " Details (filtered) "
+			if isDetailsSearch(g.detailsFilter) {
+				v.Title = " " + icons.DETAILS_ICON + " Details (n/N next/prev match) "
+			}
 			v.TitleColor = g.theme.FilterBorderColor
 			v.FrameColor = g.theme.FilterBorderColor
 		} else if isFocused {
@@ -254,6 +259,7 @@ func (g *Gui) Layout(gui *gocui.Gui) error {
 			v.FrameColor = g.theme.InactiveBorderColor
 		}
 		g.updateDetailsView(v)
+		v.Footer = g.detailsSearchFooter()
 		v.SetOrigin(0, g.detailsScrollPos)
 	}
 
@@ -293,8 +299,16 @@ func (g *Gui) Layout(gui *gocui.Gui) error {
 
 	// Query builder modal
 	if g.queryModalOpen {
-		modalWidth := 50
-		modalHeight := 20
+		modalWidth := 60
+		modalHeight := 24
+		if g.queryCodeOpen {
+			// Room for snippets with long lines
+			modalWidth = 90
+			modalHeight = maxY - 4
+		}
+		if modalWidth > maxX-4 {
+			modalWidth = maxX - 4
+		}
 		if modalHeight > maxY-4 {
 			modalHeight = maxY - 4
 		}
@@ -314,7 +328,13 @@ func (g *Gui) Layout(gui *gocui.Gui) error {
 		}
 
 		if v, err := gui.View(g.views.queryModal); err == nil {
-			g.renderQueryModal(v)
+			if g.queryCodeOpen {
+				v.Title = " Query Code "
+				g.renderQueryCode(v)
+			} else {
+				v.Title = " Query Builder "
+				g.renderQueryModal(v)
+			}
 		}
 
 		// Create editable input view when in edit mode
@@ -353,32 +373,9 @@ func (g *Gui) Layout(gui *gocui.Gui) error {
 
 		// Create select popup when selecting operator/type
 		if g.querySelectOpen {
-			selectWidth := 20
-			selectHeight := len(g.querySelectItems) + 2
-			if selectHeight > 12 {
-				selectHeight = 12
-			}
-			selectX := modalX + (modalWidth-selectWidth)/2
-			selectY := modalY + 4
-
-			if v, err := gui.SetView(g.views.querySelect, selectX, selectY, selectX+selectWidth, selectY+selectHeight, 0); err != nil {
-				if !errors.Is(err, gocui.ErrUnknownView) {
-					return err
-				}
-				v.Title = " Select "
-				v.TitleColor = g.theme.ActiveBorderColor
-				v.FrameColor = g.theme.ActiveBorderColor
-				v.FrameRunes = g.roundedFrameRunes
-				v.Highlight = true
-				v.SelBgColor = g.theme.SelectedLineBgColor
-				v.SelFgColor = gocui.ColorDefault
-			}
-
-			if v, err := gui.View(g.views.querySelect); err == nil {
-				g.renderQuerySelect(v)
-				if _, err := gui.SetCurrentView(g.views.querySelect); err != nil {
-					return fmt.Errorf("failed to set query select view: %w", err)
-				}
+			selectWidth := g.querySelectWidth(modalWidth - 4)
+			if err := g.layoutQuerySelect(gui, modalX+(modalWidth-selectWidth)/2, modalY+4, selectWidth); err != nil {
+				return err
 			}
 		} else {
 			_ = gui.DeleteView(g.views.querySelect)
@@ -393,13 +390,18 @@ func (g *Gui) Layout(gui *gocui.Gui) error {
 	} else {
 		_ = gui.DeleteView(g.views.queryModal)
 		_ = gui.DeleteView(g.views.queryInput)
-		_ = gui.DeleteView(g.views.querySelect)
+		if !g.filterInputActive {
+			_ = gui.DeleteView(g.views.querySelect)
+		}
 	}
 
-	// Help modal (keyboard shortcuts)
+	// Help modal (keyboard shortcuts, also used for action menus)
 	if g.helpOpen {
 		modalWidth := 50
 		modalHeight := 22
+		if g.helpPopup != nil && len(g.helpPopup.Items)+3 < modalHeight {
+			modalHeight = len(g.helpPopup.Items) + 3 // Items + blank line + footer
+		}
 		if modalHeight > maxY-4 {
 			modalHeight = maxY - 4
 		}
@@ -410,7 +412,6 @@ func (g *Gui) Layout(gui *gocui.Gui) error {
 			if !errors.Is(err, gocui.ErrUnknownView) {
 				return err
 			}
-			v.Title = " " + icons.KEYBOARD_ICON + " Keyboard Shortcuts "
 			v.TitleColor = g.theme.ActiveBorderColor
 			v.FrameColor = g.theme.ActiveBorderColor
 			v.FrameRunes = g.roundedFrameRunes
@@ -419,6 +420,9 @@ func (g *Gui) Layout(gui *gocui.Gui) error {
 		}
 
 		if v, err := gui.View(g.views.helpModal); err == nil {
+			if g.helpPopup != nil {
+				v.Title = " " + g.helpPopup.Title + " "
+			}
 			g.renderHelpContent(v)
 			if _, err := gui.SetCurrentView(g.views.helpModal); err != nil {
 				return fmt.Errorf("failed to set help view: %w", err)
@@ -481,6 +485,25 @@ func (g *Gui) Layout(gui *gocui.Gui) error {
 		_ = gui.DeleteView(g.views.modal)
 	}
 
+	// Table view (over the panels)
+	if g.table != nil {
+		return g.layoutTable(gui, maxX, maxY)
+	}
+	_ = gui.DeleteView(g.views.table)
+
+	// JSON explorer (over the details panel)
+	if g.explorer != nil {
+		return g.layoutExplorer(gui)
+	}
+	_ = gui.DeleteView(g.views.explorer)
+
+	// Completion popup of the details filter, above the filter input
+	if g.querySelectOpen && g.filterInputActive {
+		selectWidth := g.querySelectWidth(maxX - leftWidth - 2)
+		return g.layoutQuerySelect(gui, leftWidth, maxY-3-g.querySelectHeight(), selectWidth)
+	}
+	_ = gui.DeleteView(g.views.querySelect)
+
 	// Set current view
 	viewName := g.views.projects
 	switch g.currentColumn {
@@ -657,6 +680,12 @@ func (g *Gui) updateTreeView(v *gocui.View) {
 			if _, ok := g.docCache[node.Path]; ok {
 				cachedIndicator = " \033[33m·\033[0m" // Yellow dot for cached
 			}
+			if g.searchFound[node.Path] {
+				cachedIndicator += " \033[35m⌕\033[0m" // Found by ID prefix search
+			}
+			if n := g.invalidDocs[node.Path]; n > 0 {
+				cachedIndicator += fmt.Sprintf(" \033[31m✗%d\033[0m", n) // Schema errors
+			}
 		}
 
 		// Format: marker + indent + connector + arrow + colored_icon + name + cachedIndicator
@@ -687,6 +716,19 @@ func (g *Gui) updateDetailsView(v *gocui.View) {
 
 	// Show document data if available (highest priority)
 	if g.currentDocData != nil {
+		if g.isDiffShown() {
+			g.renderDiffDetails(v)
+			return
+		}
+		if g.isSchemaShown() {
+			g.renderSchemaDetails(v)
+			return
+		}
+		if g.isJqRunShown() {
+			g.renderJqRunDetails(v)
+			return
+		}
+
 		// When filtering details, always re-render to apply filter
 		detailsFilter := g.getDetailsFilter()
 		if detailsFilter != "" {
@@ -714,23 +756,24 @@ func (g *Gui) updateDetailsView(v *gocui.View) {
 			return
 		}
 
-		var content strings.Builder
-		content.WriteString(fmt.Sprintf("\033[36m─── %s ───\033[0m\n", g.currentDocPath))
-
-		// Show stats for actual documents
-		if strings.Contains(g.currentDocPath, "/") {
-			stats := calculateDocStats(g.currentDocData, g.currentDocPath)
-			content.WriteString(formatDocStats(stats))
-			content.WriteString("\n")
+		// Highlight the line of a search hit and scroll to it
+		rendered, jsonStart := g.documentContent(data)
+		if h := g.detailsHighlight; h != nil && h.Path == g.currentDocPath {
+			if line := highlightDetailsLine(string(data), h.Field); line >= 0 {
+				lines := strings.Split(rendered, "\n")
+				if i := jsonStart + line; i < len(lines) {
+					lines[i] = highlightLine(lines[i], g.theme.GetSelectedBgAnsiCode())
+					rendered = strings.Join(lines, "\n")
+					g.detailsScrollPos = max(0, i-3)
+				}
+			}
+		} else {
+			g.detailsHighlight = nil
 		}
-		content.WriteString("\n")
-
-		// Syntax highlighting with chroma
-		content.WriteString(colorizeJSON(string(data)))
 
 		g.cachedDetailsLines = strings.Split(string(data), "\n")
 		g.cachedDetailsHeader = ""
-		g.cachedDetailsContent = content.String()
+		g.cachedDetailsContent = rendered
 		g.cachedDetailsDocPath = g.currentDocPath
 		v.SetContent(g.cachedDetailsContent)
 		g.detailsViewDirty = false
@@ -761,6 +804,48 @@ func (g *Gui) updateDetailsView(v *gocui.View) {
 	}
 }
 
+// documentContent renders the shown document for the details panel: its path,
+// stats, validation and reference summaries and the colorized JSON in data.
+// It also returns the line of the content where the JSON starts.
+func (g *Gui) documentContent(data []byte) (string, int) {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("\033[36m─── %s ───\033[0m\n", g.currentDocPath))
+
+	// Show stats for actual documents
+	if strings.Contains(g.currentDocPath, "/") {
+		stats := calculateDocStats(g.currentDocData, g.currentDocPath)
+		content.WriteString(formatDocStats(stats))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	// Syntax highlighting with chroma, with schema errors next to their
+	// lines and references as links
+	validation := g.validateDocument(g.currentDocPath)
+	refs := g.documentReferences(g.currentDocPath)
+	if validation != nil {
+		content.WriteString(formatValidationSummary(validation))
+	}
+	if len(refs) > 0 {
+		content.WriteString(formatReferenceSummary(refs, g.footerHints([]keyHint{
+			{g.keyLabels("confirm"), "to follow"},
+			{g.keyLabels("jumpBack"), "to go back"},
+		})))
+	}
+	jsonStart := strings.Count(content.String(), "\n")
+	if validation != nil || len(refs) > 0 {
+		var errs []firebase.ValidationError
+		if validation != nil {
+			errs = validation.Errors
+		}
+		content.WriteString(annotateJSON(string(data), errs, refs))
+	} else {
+		content.WriteString(colorizeJSON(string(data)))
+	}
+
+	return content.String(), jsonStart
+}
+
 func (g *Gui) showProjectDetails(v *gocui.View) {
 	filtered := g.getFilteredProjects()
 	if len(filtered) == 0 || g.selectedProjectIndex >= len(filtered) {
@@ -937,6 +1022,9 @@ func (g *Gui) updateHelpView(v *gocui.View) {
 		}
 		filterPrompt := fmt.Sprintf(" \033[33mFilter %s:\033[0m %s\033[7m%s\033[0m%s", panelName, beforeCursor, cursorChar, rest)
 		hints := "  \033[90m(Enter to select, Esc to cancel)\033[0m"
+		if g.filterInputPanel == "details" {
+			hints = "  \033[90m(regex search, g/ grep, . jq · Enter to apply, Esc to cancel)\033[0m"
+		}
 		fmt.Fprintf(v, "%s%s", filterPrompt, hints)
 		return
 	}
@@ -944,7 +1032,8 @@ func (g *Gui) updateHelpView(v *gocui.View) {
 	// Show select mode status
 	if g.selectMode {
 		count := len(g.selectedDocs)
-		fmt.Fprintf(v, " \033[33m-- SELECT MODE --\033[0m  %d selected  \033[90m(j/k to extend, Space to fetch, Esc to cancel)\033[0m", count)
+		fmt.Fprintf(v, " \033[33m-- SELECT MODE --\033[0m  %d selected  \033[90m(%s to extend, %s to fetch, %s to cancel)\033[0m",
+			count, g.keyLabels("nextItem", "prevItem"), g.keyLabels("select"), g.keyLabels("return"))
 		return
 	}
 
@@ -955,19 +1044,42 @@ func (g *Gui) updateHelpView(v *gocui.View) {
 		return
 	}
 
-	helpText := " \033[36m←/→\033[0m cols  \033[36mj/k\033[0m move  \033[33mspace\033[0m select  \033[32mc\033[0m copy  \033[32ms\033[0m save  \033[35m/\033[0m filter  \033[33mQ\033[0m query  \033[35m?\033[0m help  \033[31mq\033[0m quit"
+	// Hints show the effective keys; unbound actions are left out
+	hints := []struct {
+		ids          []string
+		color, label string
+	}{
+		{[]string{"prevBlock", "nextBlock"}, "36", "cols"},
+		{[]string{"nextItem", "prevItem"}, "36", "move"},
+		{[]string{"select"}, "33", "select"},
+		{[]string{"copyJSON"}, "32", "copy"},
+		{[]string{"saveJSON"}, "32", "save"},
+		{[]string{"startSearch"}, "35", "filter"},
+		{[]string{"openQueryBuilder"}, "33", "query"},
+		{[]string{"optionMenu"}, "35", "help"},
+		{[]string{"quit"}, "31", "quit"},
+	}
+	var helpText strings.Builder
+	helpLen := 0 // Visible length without ANSI codes
+	for _, hint := range hints {
+		keys := g.keyLabels(hint.ids...)
+		if keys == "" {
+			continue
+		}
+		fmt.Fprintf(&helpText, " \033[%sm%s\033[0m %s ", hint.color, keys, hint.label)
+		helpLen += utf8.RuneCountInString(keys) + len(hint.label) + 3
+	}
 	versionText := fmt.Sprintf("\033[90mv%s\033[0m ", g.version)
 
 	// Calculate padding to right-align version
 	width, _ := v.Size()
-	helpLen := 85 // Approximate visible length without ANSI codes
 	versionLen := len(g.version) + 2
 	padding := width - helpLen - versionLen
 	if padding < 1 {
 		padding = 1
 	}
 
-	fmt.Fprintf(v, "%s%*s%s", helpText, padding, "", versionText)
+	fmt.Fprintf(v, "%s%*s%s", helpText.String(), padding, "", versionText)
 }
 
 // Firestore limits (https://firebase.google.com/docs/firestore/quotas)
@@ -1129,3 +1241,47 @@ func formatBytes(bytes int) string {
 	}
 	return fmt.Sprintf("%.2f MB", float64(bytes)/(1024*1024))
 }
+
+// querySelectWidth fits the select popup to its items and hints, at most maxWidth.
+func (g *Gui) querySelectWidth(maxWidth int) int {
+	width := 20
+	longest := 0
+	for _, item := range g.querySelectItems {
+		longest = max(longest, len([]rune(item)))
+	}
+	hint := 0
+	for _, h := range g.querySelectHints {
+		hint = max(hint, len([]rune(h))+2)
+	}
+	width = max(width, longest+hint+3)
+	return min(width, maxWidth)
+}
+
+// querySelectHeight is the height of the select popup, at most 12 rows.
+func (g *Gui) querySelectHeight() int {
+	return min(len(g.querySelectItems)+2, 12)
+}
+
+// layoutQuerySelect shows the select popup at x, y and focuses it.
+func (g *Gui) layoutQuerySelect(gui *gocui.Gui, x, y, width int) error {
+	if v, err := gui.SetView(g.views.querySelect, x, y, x+width, y+g.querySelectHeight(), 0); err != nil {
+		if !errors.Is(err, gocui.ErrUnknownView) {
+			return err
+		}
+		v.Title = " Select "
+		v.TitleColor = g.theme.ActiveBorderColor
+		v.FrameColor = g.theme.ActiveBorderColor
+		v.FrameRunes = g.roundedFrameRunes
+		v.Highlight = true
+		v.SelBgColor = g.theme.SelectedLineBgColor
+		v.SelFgColor = gocui.ColorDefault
+	}
+
+	if v, err := gui.View(g.views.querySelect); err == nil {
+		g.renderQuerySelect(v)
+		if _, err := gui.SetCurrentView(g.views.querySelect); err != nil {
+			return fmt.Errorf("failed to set query select view: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,528 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// pathLevels splits a Firestore path into the collection and document paths
+// leading to it: users/a/orders → [users users/a users/a/orders].
+func pathLevels(path string) []string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	levels := make([]string, len(parts))
+	for i := range parts {
+		levels[i] = strings.Join(parts[:i+1], "/")
+	}
+	return levels
+}
+
+// navFetch holds what navigateTo fetched for the levels missing from the tree.
+type navFetch struct {
+	collections []firebase.Collection            // Root collections, after a project switch
+	docs        map[string][]firebase.Document   // Documents per collection path
+	subcols     map[string][]firebase.Collection // Subcollections per document path
+	target      *firebase.Document               // Target document, unless cached
+}
+
+// navigateTo selects a document or collection in the tree, switching project
+// and fetching and expanding every level on the way. Documents outside the
+// first page of their collection are added to it. An empty project means the
+// current one. done runs once the target is selected.
+func (g *Gui) navigateTo(project, path string, done func()) error {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	if project == "" {
+		project = g.currentProject
+	}
+	levels := pathLevels(path)
+
+	// Snapshot what the tree already has, so only missing levels are fetched
+	switching := project != g.currentProject
+	rootLoaded := !switching && !g.queryResultMode && g.currentCollection == levels[0] && len(g.treeNodes) > 0
+	expanded := make(map[string]bool)
+	if rootLoaded {
+		for _, node := range g.treeNodes {
+			if node.Expanded {
+				expanded[node.Path] = true
+			}
+		}
+	}
+	cachedData, cached := g.docCache[path]
+	cached = cached && !switching
+
+	// Switch here rather than in the fetch, so the client's project only
+	// changes on the UI goroutine and later fetches go to the shown project.
+	// A failed fetch restores prev.
+	prev := g.saveProjectState()
+	if switching {
+		if err := g.firebaseClient.SetCurrentProject(project); err != nil {
+			g.logCommand("goto", fmt.Sprintf("Failed to switch to %s: %v", project, err), "error")
+			return nil
+		}
+		g.switchProject(project, nil)
+	}
+
+	g.logCommand("goto", fmt.Sprintf("Opening %s...", path), "running")
+	g.treeLoading = true
+
+	go func() {
+		fetch := navFetch{docs: make(map[string][]firebase.Document), subcols: make(map[string][]firebase.Collection)}
+		err := func() error {
+			if switching {
+				collections, err := g.firebaseClient.ListCollections()
+				if err != nil {
+					return err
+				}
+				fetch.collections = collections
+			}
+
+			for i, level := range levels {
+				last := i == len(levels)-1
+				switch {
+				case i%2 == 0:
+					if (i == 0 && rootLoaded) || expanded[level] {
+						continue
+					}
+					docs, err := g.firebaseClient.ListDocuments(level, 50)
+					if err != nil {
+						return err
+					}
+					fetch.docs[level] = docs
+				case !last:
+					if expanded[level] {
+						continue
+					}
+					subcols, err := g.firebaseClient.ListSubcollections(level)
+					if err != nil {
+						return err
+					}
+					fetch.subcols[level] = subcols
+				case !cached:
+					doc, err := g.firebaseClient.GetDocument(level)
+					if err != nil {
+						return err
+					}
+					fetch.target = doc
+				}
+			}
+			return nil
+		}()
+
+		g.g.Update(func(gui *gocui.Gui) error {
+			g.finishNavigate(path, levels, switching, prev, cachedData, fetch, err, done)
+			return nil
+		})
+	}()
+	return nil
+}
+
+// finishNavigate applies what navigateTo fetched, or on error restores the
+// project that was shown before it.
+func (g *Gui) finishNavigate(path string, levels []string, switching bool, prev projectState, cachedData map[string]any, fetch navFetch, err error, done func()) {
+	g.treeLoading = false
+	if err != nil {
+		if switching {
+			g.restoreProjectState(prev)
+		}
+		g.logCommand("goto", fmt.Sprintf("Failed to open %s: %v", path, err), "error")
+		return
+	}
+
+	if switching {
+		g.collections = fetch.collections
+	}
+	g.applyNavFetch(levels, fetch)
+
+	if len(levels)%2 == 0 {
+		if fetch.target != nil {
+			cachedData = fetch.target.Data
+			g.cacheDocument(*fetch.target)
+		}
+		g.currentDocPath = path
+		g.currentDocData = cachedData
+		g.clearDetailsCache()
+		g.recordVisit()
+	}
+
+	g.logCommand("goto", fmt.Sprintf("Opened %s", path), "success")
+	if done != nil {
+		done()
+	}
+}
+
+// switchProject makes project current with its root collections, like
+// selecting it in the projects panel.
+func (g *Gui) switchProject(project string, collections []firebase.Collection) {
+	g.clearProjectCaches()
+	g.currentProject = project
+	g.collections = collections
+	g.treeNodes = nil
+	g.currentDocData = nil
+	g.currentCollection = ""
+	g.currentDocPath = ""
+	g.currentProjectInfo = nil
+	g.selectedCollectionIdx = 0
+	g.selectedTreeIdx = 0
+
+	g.projectsFilter = ""
+	for i, p := range g.projects {
+		if p.ID == project {
+			g.selectedProjectIndex = i
+		}
+	}
+}
+
+// projectState is the part of the UI that switchProject replaces.
+type projectState struct {
+	project        string
+	info           *firebase.ProjectDetails
+	collections    []firebase.Collection
+	treeNodes      []TreeNode
+	docData        map[string]any
+	collection     string
+	docPath        string
+	collectionIdx  int
+	treeIdx        int
+	projectIdx     int
+	projectsFilter string
+	caches         projectCaches
+}
+
+// saveProjectState snapshots the current project and what is shown of it.
+func (g *Gui) saveProjectState() projectState {
+	return projectState{
+		project:        g.currentProject,
+		info:           g.currentProjectInfo,
+		collections:    g.collections,
+		treeNodes:      g.treeNodes,
+		docData:        g.currentDocData,
+		collection:     g.currentCollection,
+		docPath:        g.currentDocPath,
+		collectionIdx:  g.selectedCollectionIdx,
+		treeIdx:        g.selectedTreeIdx,
+		projectIdx:     g.selectedProjectIndex,
+		projectsFilter: g.projectsFilter,
+		caches:         g.currentCaches(),
+	}
+}
+
+// restoreProjectState switches the client and the UI back to s.
+func (g *Gui) restoreProjectState(s projectState) {
+	_ = g.firebaseClient.SetCurrentProject(s.project)
+	g.currentProject = s.project
+	g.currentProjectInfo = s.info
+	g.collections = s.collections
+	g.treeNodes = s.treeNodes
+	g.currentDocData = s.docData
+	g.currentCollection = s.collection
+	g.currentDocPath = s.docPath
+	g.selectedCollectionIdx = s.collectionIdx
+	g.selectedTreeIdx = s.treeIdx
+	g.selectedProjectIndex = s.projectIdx
+	g.projectsFilter = s.projectsFilter
+	g.setProjectCaches(s.caches)
+}
+
+// applyNavFetch expands the tree along levels with the fetched documents and
+// subcollections and selects the last level.
+func (g *Gui) applyNavFetch(levels []string, fetch navFetch) {
+	root := levels[0]
+	if docs, ok := fetch.docs[root]; ok {
+		g.currentCollection = root
+		g.queryResultMode = false
+		g.expandedPaths = make(map[string]bool)
+		g.treeNodes = g.documentNodes(docs, 0)
+		g.collectionCache[root] = documentPaths(docs)
+	}
+	g.collectionsFilter = ""
+	for i, c := range g.collections {
+		if c.Name == root {
+			g.selectedCollectionIdx = i
+		}
+	}
+
+	parent := -1 // Tree index of the previous level, -1 for the root collection
+	for i, level := range levels {
+		if i > 0 {
+			idx := g.treeIndex(level)
+			if idx == -1 {
+				// Not in the fetched page (or listed by a query): add it
+				node := TreeNode{Path: level, Name: level[strings.LastIndex(level, "/")+1:], Type: "collection", Depth: i - 1, HasChildren: true}
+				if i%2 == 1 {
+					node.Type = "document"
+				}
+				idx = g.subtreeEnd(parent)
+				g.insertTreeNodes(idx, []TreeNode{node})
+			}
+			parent = idx
+		}
+
+		// Expand the level with what was fetched for it
+		if parent == -1 || g.treeNodes[parent].Expanded {
+			continue
+		}
+		if docs, ok := fetch.docs[level]; ok {
+			g.collectionCache[level] = documentPaths(docs)
+			g.insertTreeNodes(parent+1, g.documentNodes(docs, i))
+			g.treeNodes[parent].Expanded = true
+		} else if subcols, ok := fetch.subcols[level]; ok {
+			nodes := make([]TreeNode, len(subcols))
+			for j, sub := range subcols {
+				nodes[j] = TreeNode{Path: sub.Path, Name: sub.Name, Type: "collection", Depth: i, HasChildren: true}
+			}
+			g.insertTreeNodes(parent+1, nodes)
+			g.treeNodes[parent].Expanded = true
+		}
+	}
+
+	g.treeFilter = ""
+	if parent >= 0 {
+		g.selectedTreeIdx = parent
+	} else {
+		g.selectedTreeIdx = 0
+	}
+}
+
+// documentNodes caches documents and returns them as tree nodes at depth.
+func (g *Gui) documentNodes(docs []firebase.Document, depth int) []TreeNode {
+	nodes := make([]TreeNode, len(docs))
+	for i, doc := range docs {
+		g.cacheDocument(doc)
+		nodes[i] = TreeNode{Path: doc.Path, Name: doc.ID, Type: "document", Depth: depth, HasChildren: true}
+	}
+	return nodes
+}
+
+func documentPaths(docs []firebase.Document) []string {
+	paths := make([]string, len(docs))
+	for i, doc := range docs {
+		paths[i] = doc.Path
+	}
+	return paths
+}
+
+// treeIndex returns the index of a path in treeNodes, or -1.
+func (g *Gui) treeIndex(path string) int {
+	for i, node := range g.treeNodes {
+		if node.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// subtreeEnd returns the index just past a node's descendants; for -1, the
+// end of the tree.
+func (g *Gui) subtreeEnd(idx int) int {
+	if idx < 0 {
+		return len(g.treeNodes)
+	}
+	end := idx + 1
+	for end < len(g.treeNodes) && g.treeNodes[end].Depth > g.treeNodes[idx].Depth {
+		end++
+	}
+	return end
+}
+
+// insertTreeNodes inserts nodes into the tree before index at.
+func (g *Gui) insertTreeNodes(at int, nodes []TreeNode) {
+	newNodes := make([]TreeNode, 0, len(g.treeNodes)+len(nodes))
+	newNodes = append(newNodes, g.treeNodes[:at]...)
+	newNodes = append(newNodes, nodes...)
+	newNodes = append(newNodes, g.treeNodes[at:]...)
+	g.treeNodes = newNodes
+}
+
+// parseGoTo reads a go-to target: a document or collection path, a Firestore
+// resource name (projects/p/databases/(default)/documents/users/a) or a
+// Firebase console URL. An empty project means the current one.
+func parseGoTo(input string) (jumpEntry, error) {
+	input = strings.TrimSpace(input)
+	var target jumpEntry
+	database := firebase.DefaultDatabase
+
+	switch {
+	case strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://"):
+		u, err := url.Parse(input)
+		if err != nil {
+			return target, err
+		}
+		// .../project/<project>/firestore[/databases/<database>]/data/<path>
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		for i := 0; i+1 < len(parts); i++ {
+			switch parts[i] {
+			case "project":
+				target.Project = parts[i+1]
+			case "databases":
+				database = parts[i+1]
+				if database == "-default-" {
+					database = firebase.DefaultDatabase
+				}
+			case "data":
+				target.Path = strings.Join(parts[i+1:], "/")
+				i = len(parts)
+			}
+		}
+		if target.Project == "" || target.Path == "" {
+			return target, errors.New("not a Firestore console URL of a collection or document")
+		}
+		// The console encodes the path as one segment with ~2F for slashes
+		target.Path = strings.NewReplacer("~2F", "/", "~2f", "/").Replace(target.Path)
+
+	case strings.HasPrefix(input, "projects/"):
+		parts := strings.SplitN(input, "/", 6)
+		if len(parts) < 6 || parts[2] != "databases" || parts[4] != "documents" {
+			return target, errors.New("expected projects/<project>/databases/<database>/documents/<path>")
+		}
+		target.Project, database, target.Path = parts[1], parts[3], parts[5]
+
+	default:
+		target.Path = input
+	}
+
+	if database != firebase.DefaultDatabase {
+		return target, fmt.Errorf("only the %s database is browsable", firebase.DefaultDatabase)
+	}
+	target.Path = strings.Trim(target.Path, "/")
+	if target.Path == "" {
+		return target, errors.New("enter a collection or document path")
+	}
+	for _, segment := range strings.Split(target.Path, "/") {
+		if segment == "" {
+			return target, fmt.Errorf("%s has an empty segment", target.Path)
+		}
+	}
+	return target, nil
+}
+
+// goToPathAction prompts for a path, resource name or console URL and opens it
+// in the tree.
+func (g *Gui) goToPathAction() error {
+	initial := g.currentDocPath
+	if !isDiffDocumentPath(initial) {
+		initial = g.getSelectedCollection()
+	}
+	return g.openPrompt("Go to path or console URL", initial, func(input string) {
+		target, err := parseGoTo(input)
+		if err != nil {
+			g.logCommand("goto", err.Error(), "error")
+			return
+		}
+		if len(pathLevels(target.Path))%2 == 1 {
+			// Collection: select it in the tree
+			_ = g.navigateTo(target.Project, target.Path, func() {
+				_ = g.setFocus(g.g, "tree")
+			})
+			return
+		}
+		_ = g.jumpTo(target.Project, target.Path)
+	})
+}
+
+// jumpEntry is a visited document in the jumplist.
+type jumpEntry struct {
+	Project string
+	Path    string
+}
+
+// maxJumps bounds the jumplist, dropping the oldest entries.
+const maxJumps = 100
+
+// jumpList is a vim-style list of visited documents with a position for
+// going back and forward.
+type jumpList struct {
+	entries []jumpEntry
+	pos     int
+}
+
+// push records a visit after the current position, dropping the entries
+// ahead of it. Visiting the current entry again is a no-op.
+func (j *jumpList) push(e jumpEntry) {
+	if j.pos < len(j.entries) && j.entries[j.pos] == e {
+		return
+	}
+	if len(j.entries) > 0 {
+		j.entries = j.entries[:j.pos+1]
+	}
+	j.entries = append(j.entries, e)
+	if len(j.entries) > maxJumps {
+		j.entries = j.entries[len(j.entries)-maxJumps:]
+	}
+	j.pos = len(j.entries) - 1
+}
+
+// back moves to the previous entry.
+func (j *jumpList) back() (jumpEntry, bool) {
+	if j.pos == 0 || j.pos >= len(j.entries) {
+		return jumpEntry{}, false
+	}
+	j.pos--
+	return j.entries[j.pos], true
+}
+
+// forward moves to the next entry.
+func (j *jumpList) forward() (jumpEntry, bool) {
+	if j.pos+1 >= len(j.entries) {
+		return jumpEntry{}, false
+	}
+	j.pos++
+	return j.entries[j.pos], true
+}
+
+// currentJump is the open document as a jumplist entry.
+func (g *Gui) currentJump() (jumpEntry, bool) {
+	if !isDiffDocumentPath(g.currentDocPath) || g.currentDocData == nil {
+		return jumpEntry{}, false
+	}
+	return jumpEntry{Project: g.currentProject, Path: g.currentDocPath}, true
+}
+
+// recordVisit adds the open document to the jumplist.
+func (g *Gui) recordVisit() {
+	if here, ok := g.currentJump(); ok {
+		g.jumps.push(here)
+	}
+}
+
+// jumpTo navigates to a document and focuses details; the open document and
+// the target are recorded in the jumplist.
+func (g *Gui) jumpTo(project, path string) error {
+	g.recordVisit()
+	return g.navigateTo(project, path, g.focusDetails)
+}
+
+// jumpBackAction returns to the previous document in the jumplist.
+func (g *Gui) jumpBackAction() error {
+	// Leave from the open document, even if it was opened outside the jumplist
+	g.recordVisit()
+	e, ok := g.jumps.back()
+	if !ok {
+		g.logCommand("jump", "Already at the oldest document", "error")
+		return nil
+	}
+	return g.navigateTo(e.Project, e.Path, g.focusDetails)
+}
+
+// jumpForwardAction goes to the next document in the jumplist.
+func (g *Gui) jumpForwardAction() error {
+	e, ok := g.jumps.forward()
+	if !ok {
+		return nil
+	}
+	return g.navigateTo(e.Project, e.Path, g.focusDetails)
+}
+
+// focusDetails moves focus to the details panel.
+func (g *Gui) focusDetails() {
+	if g.currentColumn != "details" {
+		g.previousColumn = g.currentColumn
+	}
+	_ = g.setFocus(g.g, "details")
+}
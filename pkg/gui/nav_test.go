@@ -0,0 +1,218 @@
+package gui
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+func TestPathLevels(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"users", []string{"users"}},
+		{"users/a", []string{"users", "users/a"}},
+		{"/users/a/orders/", []string{"users", "users/a", "users/a/orders"}},
+	}
+
+	for _, tt := range tests {
+		if got := pathLevels(tt.path); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("pathLevels(%q) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestParseGoTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected jumpEntry
+		err      bool
+	}{
+		{"document path", " users/abc/orders/o1 ", jumpEntry{"", "users/abc/orders/o1"}, false},
+		{"collection path", "/users/", jumpEntry{"", "users"}, false},
+		{"resource name", "projects/demo/databases/(default)/documents/users/abc", jumpEntry{"demo", "users/abc"}, false},
+		{"console URL", "https://console.firebase.google.com/project/demo/firestore/databases/-default-/data/~2Fusers~2Fabc", jumpEntry{"demo", "users/abc"}, false},
+		{"old console URL", "https://console.firebase.google.com/u/0/project/demo/firestore/data/users/abc/orders", jumpEntry{"demo", "users/abc/orders"}, false},
+		{"console URL without path", "https://console.firebase.google.com/project/demo/firestore", jumpEntry{}, true},
+		{"other database", "projects/demo/databases/eu/documents/users/abc", jumpEntry{}, true},
+		{"empty segment", "users//abc", jumpEntry{}, true},
+		{"empty", "  ", jumpEntry{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGoTo(tt.input)
+			if tt.err {
+				if err == nil {
+					t.Errorf("parseGoTo(%q) = %v, expected an error", tt.input, got)
+				}
+				return
+			}
+			if err != nil || got != tt.expected {
+				t.Errorf("parseGoTo(%q) = %v, %v, expected %v", tt.input, got, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestJumpList(t *testing.T) {
+	var j jumpList
+	if _, ok := j.back(); ok {
+		t.Error("back on an empty jumplist should fail")
+	}
+
+	a, b, c := jumpEntry{"p", "users/a"}, jumpEntry{"p", "users/b"}, jumpEntry{"q", "users/c"}
+	j.push(a)
+	j.push(b)
+	j.push(b) // Same document again: no-op
+	j.push(c)
+	if len(j.entries) != 3 {
+		t.Fatalf("entries = %v, expected 3", j.entries)
+	}
+
+	if e, _ := j.back(); e != b {
+		t.Errorf("back = %v, expected %v", e, b)
+	}
+	if e, _ := j.back(); e != a {
+		t.Errorf("back = %v, expected %v", e, a)
+	}
+	if _, ok := j.back(); ok {
+		t.Error("back past the oldest entry should fail")
+	}
+	if e, _ := j.forward(); e != b {
+		t.Errorf("forward = %v, expected %v", e, b)
+	}
+
+	// A new visit drops the entries ahead
+	d := jumpEntry{"p", "users/d"}
+	j.push(d)
+	if !reflect.DeepEqual(j.entries, []jumpEntry{a, b, d}) {
+		t.Errorf("entries after push = %v, expected [a b d]", j.entries)
+	}
+	if _, ok := j.forward(); ok {
+		t.Error("forward at the newest entry should fail")
+	}
+
+	for i := 0; i < maxJumps+10; i++ {
+		j.push(jumpEntry{"p", strings.Repeat("x", i+1)})
+	}
+	if len(j.entries) != maxJumps || j.pos != maxJumps-1 {
+		t.Errorf("jumplist holds %d entries at %d, expected %d at %d", len(j.entries), j.pos, maxJumps, maxJumps-1)
+	}
+}
+
+// treeShape renders tree nodes as indented paths with + for expanded nodes.
+func treeShape(nodes []TreeNode) string {
+	var lines []string
+	for _, n := range nodes {
+		mark := ""
+		if n.Expanded {
+			mark = "+"
+		}
+		lines = append(lines, strings.Repeat("  ", n.Depth)+n.Path+mark)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func newNavGui() *Gui {
+	return &Gui{
+		docCache:        make(map[string]map[string]any),
+		fieldsCache:     make(map[string]map[string]any),
+		timesCache:      make(map[string]docTimes),
+		collectionCache: make(map[string][]string),
+		collections:     []firebase.Collection{{Name: "products"}, {Name: "users"}},
+	}
+}
+
+func TestApplyNavFetch(t *testing.T) {
+	g := newNavGui()
+	fetch := navFetch{
+		docs: map[string][]firebase.Document{
+			"users":          {{ID: "a", Path: "users/a"}, {ID: "b", Path: "users/b"}},
+			"users/b/orders": {{ID: "o1", Path: "users/b/orders/o1"}},
+		},
+		subcols: map[string][]firebase.Collection{
+			"users/b": {{Name: "orders", Path: "users/b/orders"}, {Name: "tags", Path: "users/b/tags"}},
+		},
+	}
+
+	// o2 is not in the fetched page of orders: it is added after o1
+	g.applyNavFetch(pathLevels("users/b/orders/o2"), fetch)
+
+	expected := strings.Join([]string{
+		"users/a",
+		"users/b+",
+		"  users/b/orders+",
+		"    users/b/orders/o1",
+		"    users/b/orders/o2",
+		"  users/b/tags",
+	}, "\n")
+	if got := treeShape(g.treeNodes); got != expected {
+		t.Errorf("tree =\n%s\nexpected\n%s", got, expected)
+	}
+	if got := g.treeNodes[g.selectedTreeIdx].Path; got != "users/b/orders/o2" {
+		t.Errorf("selected %q, expected users/b/orders/o2", got)
+	}
+	if g.currentCollection != "users" || g.selectedCollectionIdx != 1 {
+		t.Errorf("collection = %q at %d, expected users at 1", g.currentCollection, g.selectedCollectionIdx)
+	}
+
+	// Levels already expanded are reused as they are
+	g.applyNavFetch(pathLevels("users/a"), navFetch{})
+	if got := treeShape(g.treeNodes); got != expected {
+		t.Errorf("tree after second navigation =\n%s\nexpected\n%s", got, expected)
+	}
+	if got := g.treeNodes[g.selectedTreeIdx].Path; got != "users/a" {
+		t.Errorf("selected %q, expected users/a", got)
+	}
+}
+
+func TestFinishNavigateFailedSwitch(t *testing.T) {
+	g := newNavGui()
+	g.firebaseClient = &firebase.Client{}
+	_ = g.firebaseClient.SetCurrentProject("p1")
+	g.currentProject = "p1"
+	g.projects = []firebase.Project{{ID: "p1"}, {ID: "p2"}}
+	g.currentCollection = "users"
+	g.treeNodes = []TreeNode{{Path: "users/a", Type: "document"}, {Path: "users/b", Type: "document"}}
+	g.selectedCollectionIdx = 1
+	g.selectedTreeIdx = 1
+	g.docCache["users/a"] = map[string]any{"name": "Ana"}
+	collections, tree := g.collections, g.treeNodes
+
+	// What navigateTo does before fetching from another project
+	prev := g.saveProjectState()
+	_ = g.firebaseClient.SetCurrentProject("p2")
+	g.switchProject("p2", nil)
+	if len(g.docCache) != 0 {
+		t.Errorf("documents of p1 still cached in p2: %v", g.docCache)
+	}
+
+	g.finishNavigate("orders/o1", pathLevels("orders/o1"), true, prev, nil, navFetch{}, errors.New("permission denied"), func() {
+		t.Error("done should not run after a failed fetch")
+	})
+
+	if g.currentProject != "p1" || g.firebaseClient.GetCurrentProject() != "p1" {
+		t.Errorf("project = %q, client %q, expected p1", g.currentProject, g.firebaseClient.GetCurrentProject())
+	}
+	if g.selectedProjectIndex != 0 {
+		t.Errorf("selected project %d, expected 0", g.selectedProjectIndex)
+	}
+	if !reflect.DeepEqual(g.collections, collections) || !reflect.DeepEqual(g.treeNodes, tree) {
+		t.Errorf("collections %v and tree %v were not restored", g.collections, g.treeNodes)
+	}
+	if g.currentCollection != "users" || g.selectedCollectionIdx != 1 || g.selectedTreeIdx != 1 {
+		t.Errorf("collection = %q at %d, tree index %d, expected users at 1, 1", g.currentCollection, g.selectedCollectionIdx, g.selectedTreeIdx)
+	}
+	if _, ok := g.docCache["users/a"]; !ok {
+		t.Error("documents of p1 were not restored")
+	}
+	if g.treeLoading {
+		t.Error("tree still loading after the failure")
+	}
+}
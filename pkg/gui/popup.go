@@ -12,6 +12,8 @@ type PopupItem struct {
 	Label    string       // Item label/description
 	IsHeader bool         // Headers are non-selectable section titles
 	Action   func() error // Action to execute on Enter (optional)
+	// Keys are extra actions on this item by key, e.g. 'd' to delete (optional)
+	Keys map[rune]func() error
 }
 
 // Popup represents a modal popup with selectable items
@@ -19,6 +21,8 @@ type Popup struct {
 	Title       string
 	Items       []PopupItem
 	SelectedIdx int
+	Footer      string // Replaces the default footer when set
+	OnCancel    func() // Runs when closed without running an item's action
 	Theme       *Theme
 	viewName    string
 }
@@ -89,7 +93,11 @@ func (p *Popup) Render(v *gocui.View) {
 	}
 
 	// Footer
-	fmt.Fprintf(v, "\n\033[90m  Enter to execute · Esc to close\033[0m")
+	footer := p.Footer
+	if footer == "" {
+		footer = "Enter to execute · Esc to close"
+	}
+	fmt.Fprintf(v, "\n\033[90m  %s\033[0m", footer)
 
 	// Use FocusPoint to position the cursor and enable native highlighting
 	v.FocusPoint(0, p.SelectedIdx, true)
@@ -0,0 +1,95 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+)
+
+// openPrompt shows a single-line text input over the current layout.
+// onSubmit is called with the entered text when Enter is pressed;
+// Esc closes the prompt without calling it.
+func (g *Gui) openPrompt(title, initial string, onSubmit func(string)) error {
+	g.promptOpen = true
+	g.promptTitle = title
+	g.promptInitial = initial
+	g.promptSubmit = onSubmit
+	// Recreate the view so it picks up the new initial text
+	_ = g.g.DeleteView(g.views.prompt)
+	return nil
+}
+
+// closePrompt hides the prompt.
+func (g *Gui) closePrompt() {
+	g.promptOpen = false
+	g.promptSubmit = nil
+}
+
+// promptEditor handles text input in the prompt view.
+func (g *Gui) promptEditor(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) bool {
+	switch key {
+	case gocui.KeyEnter:
+		text := strings.TrimSpace(v.TextArea.GetContent())
+		submit := g.promptSubmit
+		g.closePrompt()
+		if submit != nil {
+			submit(text)
+		}
+		return true
+	case gocui.KeyEsc:
+		g.closePrompt()
+		return true
+	default:
+		return gocui.DefaultEditor.Edit(v, key, ch, mod)
+	}
+}
+
+// layoutPrompt draws the prompt on top of everything else.
+// It runs as a separate manager after Layout so any panel or modal can open a prompt.
+func (g *Gui) layoutPrompt(gui *gocui.Gui) error {
+	if !g.promptOpen {
+		if err := gui.DeleteView(g.views.prompt); err == nil {
+			// Restore cursor state owned by the query builder
+			gui.Cursor = g.queryModalOpen && g.queryEditMode
+		}
+		return nil
+	}
+
+	maxX, maxY := gui.Size()
+	width := 70
+	if width > maxX-4 {
+		width = maxX - 4
+	}
+	x := (maxX - width) / 2
+	y := maxY/2 - 1
+
+	if v, err := gui.SetView(g.views.prompt, x, y, x+width, y+2, 0); err != nil {
+		if !errors.Is(err, gocui.ErrUnknownView) {
+			return err
+		}
+		v.TitleColor = g.theme.ActiveBorderColor
+		v.FrameColor = g.theme.ActiveBorderColor
+		v.FrameRunes = g.roundedFrameRunes
+		v.Editable = true
+		v.Editor = gocui.EditorFunc(g.promptEditor)
+		v.TextArea.Clear()
+		v.TextArea.TypeString(g.promptInitial)
+		v.RenderTextArea()
+	}
+
+	if v, err := gui.View(g.views.prompt); err == nil {
+		v.Title = " " + g.promptTitle + " "
+		v.Footer = "Enter confirm · Esc cancel"
+		gui.Cursor = true
+		if _, err := gui.SetViewOnTop(g.views.prompt); err != nil {
+			return err
+		}
+		if _, err := gui.SetCurrentView(g.views.prompt); err != nil {
+			return fmt.Errorf("failed to set prompt view: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -1,9 +1,11 @@
 package gui
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jesseduffield/gocui"
 	"github.com/marjoballabani/lazyfire/pkg/firebase"
@@ -11,7 +13,8 @@ import (
 
 // Query builder row indices
 const (
-	queryRowFilters = iota
+	queryRowText = iota
+	queryRowFilters
 	queryRowOrderBy
 	queryRowLimit
 	queryRowButtons
@@ -22,7 +25,7 @@ var queryOperators = []string{"==", "!=", "<", "<=", ">", ">=", "in", "not-in",
 
 // Available value types for query filters
 // For "in", "not-in", "array-contains-any" use array types
-var queryValueTypes = []string{"auto", "string", "integer", "double", "boolean", "null", "array"}
+var queryValueTypes = []string{"auto", "string", "integer", "double", "boolean", "null", "array", "timestamp", "reference", "geopoint", "json"}
 
 // openQueryModal opens the query builder modal.
 func (g *Gui) openQueryModal() error {
@@ -61,6 +64,8 @@ func (g *Gui) openQueryModal() error {
 	g.queryActiveCol = 0
 	g.queryEditMode = false
 	g.queryEditBuffer = ""
+	g.queryTextError = nil
+	g.queryCodeOpen = false
 
 	// Initialize with defaults if empty
 	if g.queryLimit == 0 {
@@ -84,6 +89,17 @@ func (g *Gui) queryInputEditor(v *gocui.View, key gocui.Key, ch rune, mod gocui.
 	case gocui.KeyEsc:
 		// Cancel edit
 		g.queryEditMode = false
+		g.queryTextError = nil
+		return true
+	case gocui.KeyTab:
+		switch {
+		case g.editingReferenceValue():
+			g.completeQueryReference(v)
+		case g.editingQueryField():
+			g.completeQueryField(v)
+		default:
+			return gocui.DefaultEditor.Edit(v, key, ch, mod)
+		}
 		return true
 	default:
 		// Let default editor handle other keys
@@ -94,6 +110,11 @@ func (g *Gui) queryInputEditor(v *gocui.View, key gocui.Key, ch rune, mod gocui.
 // getQueryEditFieldName returns the name of the field being edited.
 func (g *Gui) getQueryEditFieldName() string {
 	switch g.queryActiveRow {
+	case queryRowText:
+		if g.queryTextError != nil {
+			return fmt.Sprintf("Query (error at column %d)", g.queryTextError.Column)
+		}
+		return "Query"
 	case queryRowFilters:
 		if len(g.queryFilters) > 0 {
 			idx := g.queryActiveCol / 4 // 4 columns per filter
@@ -102,6 +123,11 @@ func (g *Gui) getQueryEditFieldName() string {
 			case 0:
 				return fmt.Sprintf("Filter %d Field", idx+1)
 			case 3:
+				if idx < len(g.queryFilters) {
+					if hint, ok := queryValueHints[g.queryFilters[idx].ValueType]; ok {
+						return fmt.Sprintf("Filter %d Value: %s", idx+1, hint)
+					}
+				}
 				return fmt.Sprintf("Filter %d Value", idx+1)
 			}
 		}
@@ -120,6 +146,16 @@ func (g *Gui) commitQueryEditFromView(v *gocui.View) {
 	g.queryEditMode = false
 
 	switch g.queryActiveRow {
+	case queryRowText:
+		// Stay in the input on errors so the text can be fixed
+		if err := g.applyQueryText(content); err != nil {
+			g.queryText = content
+			g.queryTextError = err
+			g.queryEditMode = true
+			return
+		}
+		g.queryTextError = nil
+
 	case queryRowFilters:
 		if len(g.queryFilters) > 0 {
 			idx := g.queryActiveCol / 4 // 4 columns per filter
@@ -144,10 +180,62 @@ func (g *Gui) commitQueryEditFromView(v *gocui.View) {
 	}
 }
 
+// applyQueryText parses query text and replaces the query builder's filters,
+// order and limit with it. Without a limit the default of 50 is used.
+func (g *Gui) applyQueryText(text string) *firebase.QuerySyntaxError {
+	opts, err := firebase.ParseQuery(text)
+	if err != nil {
+		var syntaxErr *firebase.QuerySyntaxError
+		if errors.As(err, &syntaxErr) {
+			return syntaxErr
+		}
+		return &firebase.QuerySyntaxError{Column: 1, Message: err.Error()}
+	}
+
+	g.queryFilters = opts.Filters
+	g.queryOrderBy = opts.OrderBy
+	g.queryOrderDir = opts.OrderDir
+	if g.queryOrderDir == "" {
+		g.queryOrderDir = "ASC"
+	}
+	g.queryLimit = opts.Limit
+	if g.queryLimit == 0 {
+		g.queryLimit = 50
+	}
+	return nil
+}
+
+// editQueryText starts editing the query builder's query as text.
+func (g *Gui) editQueryText() {
+	g.queryActiveRow = queryRowText
+	g.queryActiveCol = 0
+	g.startQueryEdit()
+}
+
+// queryTextWindow returns the part of text that fits in width runes around
+// the 1-based column col, and the offset of col within it.
+func queryTextWindow(text string, col, width int) (string, int) {
+	runes := []rune(text)
+	pos := col - 1
+	if len(runes) <= width {
+		return text, pos
+	}
+	start := pos - width/2
+	if start > len(runes)-width {
+		start = len(runes) - width
+	}
+	if start < 0 {
+		start = 0
+	}
+	return string(runes[start : start+width]), pos - start
+}
+
 // closeQueryModal closes the query builder without executing.
 func (g *Gui) closeQueryModal() error {
 	g.queryModalOpen = false
 	g.queryEditMode = false
+	g.queryCodeOpen = false
+	g.queryTextError = nil
 	return nil
 }
 
@@ -169,21 +257,89 @@ func (g *Gui) executeQuery() error {
 		return nil
 	}
 
+	// Firestore would reject the query: the problems are shown on their rows
+	if problems := firebase.ValidateQuery(g.currentQueryOptions()); len(problems) > 0 {
+		g.focusQueryProblem(problems[0])
+		g.logCommand("query", fmt.Sprintf("Fix %d query problem(s) before executing: %s", len(problems), problems[0].Message), "error")
+		return nil
+	}
+
 	g.queryModalOpen = false
+	return g.promptQueryParams(queryParams(g.queryFilters), make(map[string]string), func(values map[string]string) {
+		opts := g.currentQueryOptions()
+		opts.Filters = bindQueryParams(g.queryFilters, values)
+		if problems := firebase.ValidateQuery(opts); len(problems) > 0 {
+			g.logCommand("query", fmt.Sprintf("Invalid parameter value: %s", problems[0].Message), "error")
+			return
+		}
+		g.runQuery(opts)
+	})
+}
+
+// queryProblemColumns maps the filter parts of query problems to their column
+// within a filter row.
+var queryProblemColumns = map[string]int{
+	firebase.QueryPartField:    0,
+	firebase.QueryPartOperator: 1,
+	firebase.QueryPartValue:    3,
+}
+
+// focusQueryProblem moves the query builder's cursor to the part a problem points at.
+func (g *Gui) focusQueryProblem(p firebase.QueryProblem) {
+	switch p.Part {
+	case firebase.QueryPartOrderBy:
+		g.queryActiveRow = queryRowOrderBy
+		g.queryActiveCol = 0
+	case firebase.QueryPartLimit:
+		g.queryActiveRow = queryRowLimit
+		g.queryActiveCol = 0
+	default:
+		g.queryActiveRow = queryRowFilters
+		g.queryActiveCol = p.Filter*4 + queryProblemColumns[p.Part]
+	}
+}
+
+// queryProblemsAt returns the problems of a filter (-1 for order and limit)
+// and the set of parts they point at.
+func queryProblemsAt(problems []firebase.QueryProblem, filter int) ([]firebase.QueryProblem, map[string]bool) {
+	var at []firebase.QueryProblem
+	parts := make(map[string]bool)
+	for _, p := range problems {
+		if p.Filter == filter {
+			at = append(at, p)
+			parts[p.Part] = true
+		}
+	}
+	return at, parts
+}
+
+// currentQueryOptions returns the query builder's query, with parameters unbound.
+func (g *Gui) currentQueryOptions() firebase.QueryOptions {
+	return firebase.QueryOptions{
+		Filters:  g.queryFilters,
+		OrderBy:  g.queryOrderBy,
+		OrderDir: g.queryOrderDir,
+		Limit:    g.queryLimit,
+	}
+}
+
+// runQuery runs the query with its parameters bound and displays results in the tree.
+func (g *Gui) runQuery(opts firebase.QueryOptions) {
 	g.treeLoading = true
 	g.logCommand("query", fmt.Sprintf("Query on %s...", g.queryCollection), "running")
 
+	project := g.currentProject
 	collectionPath := g.queryCollection
 	nodeIdx := g.queryNodeIdx
 	go func() {
-		opts := firebase.QueryOptions{
-			Filters:  g.queryFilters,
-			OrderBy:  g.queryOrderBy,
-			OrderDir: g.queryOrderDir,
-			Limit:    g.queryLimit,
-		}
-
+		start := time.Now()
 		docs, err := g.firebaseClient.RunQuery(collectionPath, opts)
+		latency := time.Since(start)
+
+		var historyErr error
+		if err == nil {
+			historyErr = recordQuery(project, collectionPath, opts, len(docs), latency)
+		}
 
 		g.g.Update(func(gui *gocui.Gui) error {
 			g.treeLoading = false
@@ -192,10 +348,13 @@ func (g *Gui) executeQuery() error {
 				g.logCommand("query", fmt.Sprintf("Error: %v", err), "error")
 				return nil
 			}
+			if historyErr != nil {
+				g.logCommand("history", fmt.Sprintf("Failed to save query history: %v", historyErr), "error")
+			}
 
 			// Cache documents
 			for _, doc := range docs {
-				g.docCache[doc.Path] = doc.Data
+				g.cacheDocument(doc)
 			}
 
 			if nodeIdx == -1 {
@@ -249,12 +408,10 @@ func (g *Gui) executeQuery() error {
 				}
 			}
 
-			g.logCommand("query", fmt.Sprintf("Found %d documents", len(docs)), "success")
+			g.logCommand("query", fmt.Sprintf("Found %d documents in %dms", len(docs), latency.Milliseconds()), "success")
 			return nil
 		})
 	}()
-
-	return nil
 }
 
 // addQueryFilter adds a new empty filter to the query.
@@ -290,6 +447,9 @@ func (g *Gui) removeQueryFilter() {
 // handleQueryEnter handles Enter key in query modal.
 func (g *Gui) handleQueryEnter() error {
 	switch g.queryActiveRow {
+	case queryRowText:
+		g.startQueryEdit()
+
 	case queryRowFilters:
 		if len(g.queryFilters) == 0 {
 			g.addQueryFilter()
@@ -305,10 +465,17 @@ func (g *Gui) handleQueryEnter() error {
 		g.startQueryEdit()
 
 	case queryRowButtons:
-		if g.queryActiveCol == 0 {
+		switch g.queryActiveCol {
+		case 0:
 			return g.executeQuery()
-		} else {
+		case 1:
 			return g.clearQuery()
+		case 2:
+			return g.saveQueryAction()
+		case 3:
+			return g.loadQueryAction()
+		case 4:
+			return g.openQueryCode()
 		}
 	}
 
@@ -320,6 +487,10 @@ func (g *Gui) handleQueryEnter() error {
 // For operators/types: opens a selection popup.
 func (g *Gui) startQueryEdit() {
 	switch g.queryActiveRow {
+	case queryRowText:
+		g.queryEditBuffer = firebase.FormatQuery(g.currentQueryOptions())
+		g.queryEditMode = true
+
 	case queryRowFilters:
 		if len(g.queryFilters) > 0 {
 			idx := g.queryActiveCol / 4 // Each filter has 4 columns: field, operator, type, value
@@ -338,11 +509,7 @@ func (g *Gui) startQueryEdit() {
 						g.queryFilters[idx].ValueType = selected
 					})
 				case 3: // value - text edit
-					if s, ok := g.queryFilters[idx].Value.(string); ok {
-						g.queryEditBuffer = s
-					} else {
-						g.queryEditBuffer = fmt.Sprintf("%v", g.queryFilters[idx].Value)
-					}
+					g.queryEditBuffer = firebase.QueryValueText(g.queryFilters[idx])
 					g.queryEditMode = true
 				}
 			}
@@ -368,6 +535,7 @@ func (g *Gui) startQueryEdit() {
 // openQuerySelect opens the selection popup with given items.
 func (g *Gui) openQuerySelect(items []string, current string, callback func(string)) {
 	g.querySelectItems = items
+	g.querySelectHints = nil
 	g.querySelectCallback = callback
 	g.querySelectOpen = true
 
@@ -385,6 +553,7 @@ func (g *Gui) openQuerySelect(items []string, current string, callback func(stri
 func (g *Gui) closeQuerySelect() {
 	g.querySelectOpen = false
 	g.querySelectItems = nil
+	g.querySelectHints = nil
 	g.querySelectCallback = nil
 }
 
@@ -400,8 +569,18 @@ func (g *Gui) confirmQuerySelect() {
 func (g *Gui) renderQuerySelect(v *gocui.View) {
 	v.Clear()
 
+	width := 0
 	for _, item := range g.querySelectItems {
-		fmt.Fprintf(v, " %s\n", item)
+		if n := len([]rune(item)); n > width {
+			width = n
+		}
+	}
+	for i, item := range g.querySelectItems {
+		if i < len(g.querySelectHints) {
+			fmt.Fprintf(v, " %-*s  \033[90m%s\033[0m\n", width, item, g.querySelectHints[i])
+		} else {
+			fmt.Fprintf(v, " %s\n", item)
+		}
 	}
 
 	// Set cursor to selected item (gocui handles highlighting)
@@ -411,6 +590,9 @@ func (g *Gui) renderQuerySelect(v *gocui.View) {
 // getMaxColForRow returns the maximum column index for the current row.
 func (g *Gui) getMaxColForRow() int {
 	switch g.queryActiveRow {
+	case queryRowText:
+		return 0
+
 	case queryRowFilters:
 		if len(g.queryFilters) == 0 {
 			return 0
@@ -424,7 +606,7 @@ func (g *Gui) getMaxColForRow() int {
 		return 0
 
 	case queryRowButtons:
-		return 1 // Execute, Clear
+		return 4 // Execute, Clear, Save, Load, Code
 	}
 	return 0
 }
@@ -440,9 +622,42 @@ func (g *Gui) renderQueryModal(v *gocui.View) {
 	yellowColor := "\033[33m"
 	highlightBg := g.theme.GetSelectedBgAnsiCode()
 
+	redColor := "\033[31m"
+	problems := firebase.ValidateQuery(g.currentQueryOptions())
+
 	// Collection name
 	fmt.Fprintf(v, " %sCollection:%s %s\n\n", dimColor, resetColor, g.queryCollection)
 
+	// QUERY section: the builder's query as text, or the text that failed to parse
+	textWidth, _ := v.InnerSize()
+	textWidth -= 4
+	queryLabel := "QUERY:"
+	if g.queryActiveRow == queryRowText && !g.queryEditMode {
+		queryLabel = fmt.Sprintf("%sQUERY:%s", activeColor, resetColor)
+	}
+	fmt.Fprintf(v, " %s\n", queryLabel)
+	if g.queryTextError != nil {
+		text, caret := queryTextWindow(g.queryText, g.queryTextError.Column, textWidth)
+		fmt.Fprintf(v, "   %s\n", text)
+		fmt.Fprintf(v, "   %s%s^%s\n", strings.Repeat(" ", caret), redColor, resetColor)
+		fmt.Fprintf(v, "   %s%s%s\n", redColor, g.queryTextError.Error(), resetColor)
+	} else {
+		text := firebase.FormatQuery(g.currentQueryOptions())
+		if text == "" {
+			text = "(Enter or : to type a query)"
+		}
+		if runes := []rune(text); len(runes) > textWidth && textWidth > 1 {
+			text = string(runes[:textWidth-1]) + "…"
+		}
+		if g.queryActiveRow == queryRowText && !g.queryEditMode {
+			text = fmt.Sprintf("%s %s %s", highlightBg, text, resetColor)
+		} else {
+			text = fmt.Sprintf("%s%s%s", dimColor, text, resetColor)
+		}
+		fmt.Fprintf(v, "   %s\n", text)
+	}
+	fmt.Fprintln(v)
+
 	// WHERE section
 	whereLabel := "WHERE:"
 	if g.queryActiveRow == queryRowFilters && !g.queryEditMode {
@@ -469,7 +684,7 @@ func (g *Gui) renderQueryModal(v *gocui.View) {
 			if typeStr == "" {
 				typeStr = "auto"
 			}
-			valueStr := fmt.Sprintf("%v", f.Value)
+			valueStr := firebase.QueryValueText(f)
 			if valueStr == "" {
 				valueStr = "value"
 			}
@@ -479,6 +694,18 @@ func (g *Gui) renderQueryModal(v *gocui.View) {
 			// Format type in parentheses with yellow/dim color
 			typeDisplay := fmt.Sprintf("%s(%s)%s", yellowColor, typeStr, resetColor)
 
+			// Parts Firestore would reject are red
+			filterProblems, badParts := queryProblemsAt(problems, i)
+			if badParts[firebase.QueryPartField] {
+				fieldStr = fmt.Sprintf("%s%s%s", redColor, fieldStr, resetColor)
+			}
+			if badParts[firebase.QueryPartOperator] {
+				opDisplay = fmt.Sprintf("%s[%s]%s", redColor, opStr, resetColor)
+			}
+			if badParts[firebase.QueryPartValue] {
+				valueStr = fmt.Sprintf("%s%s%s", redColor, valueStr, resetColor)
+			}
+
 			// Highlight selected parts (4 columns: field, op, type, value)
 			if g.queryActiveRow == queryRowFilters && !g.queryEditMode {
 				baseIdx := i * 4
@@ -497,6 +724,12 @@ func (g *Gui) renderQueryModal(v *gocui.View) {
 			}
 
 			fmt.Fprintf(v, "   %s %s %s %s\n", fieldStr, opDisplay, typeDisplay, valueStr)
+			if preview, err := firebase.QueryValuePreview(firebase.QueryValueText(f), f.ValueType, time.Now()); err == nil && preview != "" {
+				fmt.Fprintf(v, "     %s= %s%s\n", dimColor, preview, resetColor)
+			}
+			for _, p := range filterProblems {
+				fmt.Fprintf(v, "     %s✗ %s%s\n", redColor, p.Message, resetColor)
+			}
 		}
 	}
 	fmt.Fprintln(v)
@@ -521,7 +754,14 @@ func (g *Gui) renderQueryModal(v *gocui.View) {
 			dirDisplay = fmt.Sprintf("%s [%s] %s", highlightBg, g.queryOrderDir, resetColor)
 		}
 	}
-	fmt.Fprintf(v, " %s  %s  %s\n\n", orderLabel, orderByStr, dirDisplay)
+	fmt.Fprintf(v, " %s  %s  %s\n", orderLabel, orderByStr, dirDisplay)
+	queryOptionProblems, _ := queryProblemsAt(problems, -1)
+	for _, p := range queryOptionProblems {
+		if p.Part == firebase.QueryPartOrderBy {
+			fmt.Fprintf(v, "   %s✗ %s%s\n", redColor, p.Message, resetColor)
+		}
+	}
+	fmt.Fprintln(v)
 
 	// LIMIT section
 	limitLabel := "LIMIT:"
@@ -532,19 +772,25 @@ func (g *Gui) renderQueryModal(v *gocui.View) {
 	if g.queryActiveRow == queryRowLimit && !g.queryEditMode {
 		limitStr = fmt.Sprintf("%s %s %s", highlightBg, limitStr, resetColor)
 	}
-	fmt.Fprintf(v, " %s  %s\n\n", limitLabel, limitStr)
+	fmt.Fprintf(v, " %s  %s\n", limitLabel, limitStr)
+	for _, p := range queryOptionProblems {
+		if p.Part == firebase.QueryPartLimit {
+			fmt.Fprintf(v, "   %s✗ %s%s\n", redColor, p.Message, resetColor)
+		}
+	}
+	fmt.Fprintln(v)
 
-	// Buttons: Execute, Clear
-	execBtn := "Execute"
-	clearBtn := "Clear"
-	if g.queryActiveRow == queryRowButtons && !g.queryEditMode {
-		if g.queryActiveCol == 0 {
-			execBtn = fmt.Sprintf("%s Execute %s", highlightBg, resetColor)
-		} else {
-			clearBtn = fmt.Sprintf("%s Clear %s", highlightBg, resetColor)
+	// Buttons: Execute, Clear, Save, Load, Code
+	buttons := []string{"Execute", "Clear", "Save", "Load", "Code"}
+	for i, label := range buttons {
+		if g.queryActiveRow == queryRowButtons && !g.queryEditMode && g.queryActiveCol == i {
+			buttons[i] = fmt.Sprintf("%s %s %s", highlightBg, label, resetColor)
+		} else if i == 0 && len(problems) > 0 {
+			// Execute is refused until the problems are fixed
+			buttons[i] = fmt.Sprintf("%s%s%s", dimColor, label, resetColor)
 		}
 	}
-	fmt.Fprintf(v, " [ %s ]  [ %s ]\n\n", execBtn, clearBtn)
+	fmt.Fprintf(v, " [ %s ]\n\n", strings.Join(buttons, " ]  [ "))
 
 	// Help
 	fmt.Fprintf(v, "%s ─────────────────────────────────────%s\n", dimColor, resetColor)
@@ -553,5 +799,6 @@ func (g *Gui) renderQueryModal(v *gocui.View) {
 	} else {
 		fmt.Fprintf(v, "%s j/k: rows  h/l: cols  Enter: edit%s\n", dimColor, resetColor)
 		fmt.Fprintf(v, "%s a: add filter  d: delete  Esc: close%s\n", dimColor, resetColor)
+		fmt.Fprintf(v, "%s b: bookmark  H: history  :: text query%s\n", dimColor, resetColor)
 	}
 }
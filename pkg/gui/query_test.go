@@ -0,0 +1,105 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+func TestApplyQueryText(t *testing.T) {
+	g := &Gui{queryLimit: 10, queryOrderDir: "DESC"}
+
+	if err := g.applyQueryText(`where status == "active" and age >= $minAge`); err != nil {
+		t.Fatalf("applyQueryText() error: %v", err)
+	}
+	if len(g.queryFilters) != 2 || g.queryFilters[1].Value != "$minAge" {
+		t.Errorf("queryFilters = %+v, expected the two filters", g.queryFilters)
+	}
+	if g.queryOrderBy != "" || g.queryOrderDir != "ASC" || g.queryLimit != 50 {
+		t.Errorf("order %q %q limit %d, expected no order, ASC and the default limit", g.queryOrderBy, g.queryOrderDir, g.queryLimit)
+	}
+
+	// The builder's query written as text reads back unchanged
+	text := firebase.FormatQuery(g.currentQueryOptions())
+	if text != `where status == "active" and age >= $minAge limit 50` {
+		t.Errorf("FormatQuery() = %q", text)
+	}
+
+	err := g.applyQueryText(`where status == "active" limit ten`)
+	if err == nil || err.Column != 32 {
+		t.Fatalf("applyQueryText() error = %v, expected a syntax error at column 32", err)
+	}
+	if len(g.queryFilters) != 2 || g.queryLimit != 50 {
+		t.Error("applyQueryText() should leave the builder unchanged on errors")
+	}
+}
+
+func TestQueryTextWindow(t *testing.T) {
+	tests := []struct {
+		text     string
+		col      int
+		width    int
+		expected string
+		caret    int
+	}{
+		{"where a == 1", 7, 20, "where a == 1", 6},
+		{"where a == 1", 13, 20, "where a == 1", 12},
+		{"0123456789abcdefghij", 15, 10, "9abcdefghi", 5},
+		{"0123456789abcdefghij", 2, 10, "0123456789", 1},
+		{"0123456789abcdefghij", 21, 10, "abcdefghij", 10},
+	}
+
+	for _, tt := range tests {
+		got, caret := queryTextWindow(tt.text, tt.col, tt.width)
+		if got != tt.expected || caret != tt.caret {
+			t.Errorf("queryTextWindow(%q, %d, %d) = %q, %d, expected %q, %d", tt.text, tt.col, tt.width, got, caret, tt.expected, tt.caret)
+		}
+	}
+}
+
+func TestFocusQueryProblem(t *testing.T) {
+	g := &Gui{}
+	tests := []struct {
+		problem firebase.QueryProblem
+		row     int
+		col     int
+	}{
+		{firebase.QueryProblem{Filter: 0, Part: firebase.QueryPartField}, queryRowFilters, 0},
+		{firebase.QueryProblem{Filter: 1, Part: firebase.QueryPartOperator}, queryRowFilters, 5},
+		{firebase.QueryProblem{Filter: 2, Part: firebase.QueryPartValue}, queryRowFilters, 11},
+		{firebase.QueryProblem{Filter: -1, Part: firebase.QueryPartOrderBy}, queryRowOrderBy, 0},
+		{firebase.QueryProblem{Filter: -1, Part: firebase.QueryPartLimit}, queryRowLimit, 0},
+	}
+
+	for _, tt := range tests {
+		g.focusQueryProblem(tt.problem)
+		if g.queryActiveRow != tt.row || g.queryActiveCol != tt.col {
+			t.Errorf("focusQueryProblem(%+v) moved to row %d col %d, expected row %d col %d",
+				tt.problem, g.queryActiveRow, g.queryActiveCol, tt.row, tt.col)
+		}
+	}
+}
+
+func TestCompleteReference(t *testing.T) {
+	known := []string{"users/", "users/ana", "users/anatole", "users/bob", "posts/", "posts/p1"}
+	tests := []struct {
+		prefix   string
+		limit    int
+		expected []string
+		common   string
+	}{
+		{"us", 10, []string{"users/", "users/ana", "users/anatole", "users/bob"}, "users/"},
+		{"/users/an", 10, []string{"users/ana", "users/anatole"}, "users/ana"},
+		{"users/b", 10, []string{"users/bob"}, "users/bob"},
+		{"users/", 2, []string{"users/ana", "users/anatole"}, "users/"},
+		{"orders", 10, nil, "orders"},
+	}
+
+	for _, tt := range tests {
+		matches, common := completeReference(known, tt.prefix, tt.limit)
+		if !reflect.DeepEqual(matches, tt.expected) || common != tt.common {
+			t.Errorf("completeReference(%q, %d) = %v, %q, expected %v, %q", tt.prefix, tt.limit, matches, common, tt.expected, tt.common)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// queryCodeLanguages lists the tabs of the query builder's code view, in order.
+var queryCodeLanguages = []struct {
+	Lang  string
+	Label string
+}{
+	{firebase.SnippetJS, "JS v9"},
+	{firebase.SnippetNode, "Node Admin"},
+	{firebase.SnippetGo, "Go"},
+	{firebase.SnippetPython, "Python"},
+	{firebase.SnippetREST, "REST"},
+}
+
+// openQueryCode shows the query builder's query as code instead of its rows.
+func (g *Gui) openQueryCode() error {
+	g.queryCodeOpen = true
+	g.queryCodeScroll = 0
+	return g.Layout(g.g)
+}
+
+// closeQueryCode goes back from the code view to the query builder's rows.
+func (g *Gui) closeQueryCode() error {
+	g.queryCodeOpen = false
+	return g.Layout(g.g)
+}
+
+// queryCode returns the query as code in the selected language.
+func (g *Gui) queryCode() (string, error) {
+	lang := queryCodeLanguages[g.queryCodeLang].Lang
+	return firebase.GenerateQueryCode(g.queryCollection, g.currentQueryOptions(), lang)
+}
+
+// switchQueryCodeLang moves to the next (delta 1) or previous (delta -1) language tab.
+func (g *Gui) switchQueryCodeLang(delta int) error {
+	n := len(queryCodeLanguages)
+	g.queryCodeLang = (g.queryCodeLang + delta + n) % n
+	g.queryCodeScroll = 0
+	return g.Layout(g.g)
+}
+
+// scrollQueryCode scrolls the code view by delta lines.
+func (g *Gui) scrollQueryCode(delta int) error {
+	g.queryCodeScroll += delta
+	if g.queryCodeScroll < 0 {
+		g.queryCodeScroll = 0
+	}
+	return g.Layout(g.g)
+}
+
+// copyQueryCode copies the code in the selected language to the clipboard.
+func (g *Gui) copyQueryCode() error {
+	code, err := g.queryCode()
+	if err == nil {
+		err = copyToClipboard(code)
+	}
+	if err != nil {
+		g.logCommand("code", fmt.Sprintf("Failed to copy: %v", err), "error")
+		return nil
+	}
+	g.logCommand("code", fmt.Sprintf("Copied %s query to clipboard", queryCodeLanguages[g.queryCodeLang].Label), "success")
+	return nil
+}
+
+// renderQueryCode renders the language tabs and the query as code.
+func (g *Gui) renderQueryCode(v *gocui.View) {
+	v.Clear()
+
+	resetColor := "\033[0m"
+	dimColor := "\033[90m"
+	highlightBg := g.theme.GetSelectedBgAnsiCode()
+
+	var tabs []string
+	for i, l := range queryCodeLanguages {
+		if i == g.queryCodeLang {
+			tabs = append(tabs, fmt.Sprintf("%s %s %s", highlightBg, l.Label, resetColor))
+		} else {
+			tabs = append(tabs, fmt.Sprintf("%s %s %s", dimColor, l.Label, resetColor))
+		}
+	}
+	fmt.Fprintf(v, " %s\n", strings.Join(tabs, "│"))
+	fmt.Fprintf(v, "%s ─────────────────────────────────────%s\n", dimColor, resetColor)
+
+	code, err := g.queryCode()
+	if err != nil {
+		fmt.Fprintf(v, " \033[31m%v%s\n", err, resetColor)
+		return
+	}
+	lines := strings.Split(strings.TrimRight(code, "\n"), "\n")
+
+	// Keep the tabs and help in place and scroll only the code
+	_, height := v.InnerSize()
+	visible := height - 5
+	if visible < 1 {
+		visible = 1
+	}
+	if last := len(lines) - visible; g.queryCodeScroll > last {
+		g.queryCodeScroll = last
+	}
+	if g.queryCodeScroll < 0 {
+		g.queryCodeScroll = 0
+	}
+	end := g.queryCodeScroll + visible
+	if end > len(lines) {
+		end = len(lines)
+	}
+	for _, line := range lines[g.queryCodeScroll:end] {
+		fmt.Fprintf(v, " %s\n", strings.ReplaceAll(line, "\t", "    "))
+	}
+	for i := end - g.queryCodeScroll; i < visible; i++ {
+		fmt.Fprintln(v)
+	}
+
+	fmt.Fprintf(v, "%s ─────────────────────────────────────%s\n", dimColor, resetColor)
+	fmt.Fprintf(v, "%s h/l: language  j/k: scroll  c/Enter: copy  Esc: back%s", dimColor, resetColor)
+}
@@ -0,0 +1,115 @@
+package gui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+)
+
+// maxReferenceCandidates caps the completion popup of reference values.
+const maxReferenceCandidates = 10
+
+// queryValueHints describe the input of typed filter values, shown in the
+// title of the value input.
+var queryValueHints = map[string]string{
+	"timestamp": "2025-01-01, RFC 3339 or now-7d",
+	"reference": "users/abc, Tab completes",
+	"geopoint":  "latitude, longitude",
+	"json":      `{"a": 1} or [1, 2]`,
+	"array":     "a, b, c",
+}
+
+// knownDocumentPaths returns the collection and document paths LazyFire has
+// seen in the current project. Collections end with "/".
+func (g *Gui) knownDocumentPaths() []string {
+	seen := make(map[string]bool)
+	addDoc := func(path string) {
+		seen[path] = true
+		if i := strings.LastIndex(path, "/"); i > 0 {
+			seen[path[:i+1]] = true
+		}
+	}
+	for _, c := range g.collections {
+		seen[c.Path+"/"] = true
+	}
+	for collection, docs := range g.collectionCache {
+		seen[collection+"/"] = true
+		for _, doc := range docs {
+			addDoc(doc)
+		}
+	}
+	for _, node := range g.treeNodes {
+		if node.Type == "collection" {
+			seen[node.Path+"/"] = true
+		} else {
+			addDoc(node.Path)
+		}
+	}
+	for path := range g.docCache {
+		addDoc(path)
+	}
+
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// completeReference returns the known paths starting with prefix, sorted and
+// at most limit, and their longest common prefix.
+func completeReference(known []string, prefix string, limit int) ([]string, string) {
+	prefix = strings.TrimLeft(prefix, "/")
+	var matches []string
+	for _, path := range known {
+		if strings.HasPrefix(path, prefix) && path != prefix {
+			matches = append(matches, path)
+		}
+	}
+	sort.Strings(matches)
+	if len(matches) == 0 {
+		return nil, prefix
+	}
+
+	common := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, common
+}
+
+// editingReferenceValue reports whether the query input edits a reference value.
+func (g *Gui) editingReferenceValue() bool {
+	if g.queryActiveRow != queryRowFilters || g.queryActiveCol%4 != 3 {
+		return false
+	}
+	idx := g.queryActiveCol / 4
+	return idx < len(g.queryFilters) && g.queryFilters[idx].ValueType == "reference"
+}
+
+// completeQueryReference completes the reference path typed in the query input
+// against known collections and documents: a single match or the common prefix
+// is filled in, several matches are offered in the select popup.
+func (g *Gui) completeQueryReference(v *gocui.View) {
+	matches, common := completeReference(g.knownDocumentPaths(), v.TextArea.GetContent(), maxReferenceCandidates)
+	setInput := func(text string) {
+		v.TextArea.Clear()
+		v.TextArea.TypeString(text)
+		v.RenderTextArea()
+	}
+	switch {
+	case len(matches) == 0:
+		g.logCommand("reference", "No known collection or document matches", "error")
+	case len(matches) == 1:
+		setInput(matches[0])
+	default:
+		setInput(common)
+		g.openQuerySelect(matches, "", setInput)
+	}
+}
@@ -0,0 +1,98 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// documentReferences returns the references held by a cached document.
+func (g *Gui) documentReferences(path string) []firebase.FieldReference {
+	if !isDiffDocumentPath(path) {
+		return nil
+	}
+	return firebase.DocumentReferences(g.fieldsCache[path])
+}
+
+// formatReferenceSummary is the line above a document holding references,
+// followed by hints on the keys to follow them.
+func formatReferenceSummary(refs []firebase.FieldReference, hints string) string {
+	noun := "references"
+	if len(refs) == 1 {
+		noun = "reference"
+	}
+	if hints != "" {
+		hints = " · " + hints
+	}
+	return fmt.Sprintf("\033[90m↪ %d %s%s\033[0m\n", len(refs), noun, hints)
+}
+
+// linkLine renders a JSON line holding a reference with its value as a link.
+func linkLine(line string) string {
+	i := strings.LastIndex(line, `"projects/`)
+	if i == -1 {
+		return colorizeLine(line)
+	}
+	value, rest := line[i:], ""
+	if strings.HasSuffix(value, ",") {
+		value, rest = value[:len(value)-1], ","
+	}
+
+	prefix := ""
+	if strings.TrimSpace(line[:i]) != "" {
+		prefix = colorizeLine(line[:i])
+	} else {
+		prefix = line[:i]
+	}
+	return prefix + "\033[4;36m" + value + "\033[0m" + rest
+}
+
+// followReferenceAction jumps to the document a reference of the open
+// document points to, asking which one when it holds several.
+func (g *Gui) followReferenceAction() error {
+	refs := g.documentReferences(g.currentDocPath)
+	switch len(refs) {
+	case 0:
+		g.logCommand("reference", "The open document holds no references", "error")
+		return nil
+	case 1:
+		return g.followReference(refs[0])
+	}
+
+	items := []PopupItem{{Label: fmt.Sprintf("References in %s", g.currentDocPath), IsHeader: true}}
+	for _, ref := range refs {
+		ref := ref
+		label := ref.Path
+		if ref.Project != g.currentProject {
+			label = ref.Project + ": " + ref.Path
+		}
+		items = append(items, PopupItem{
+			Key:   jqFilterPath(ref.Field),
+			Label: label,
+			Action: func() error {
+				return g.followReference(ref)
+			},
+		})
+	}
+	return g.openMenu("Follow reference", items)
+}
+
+// followReference opens the referenced document in the tree and details.
+func (g *Gui) followReference(ref firebase.FieldReference) error {
+	if ref.Database != firebase.DefaultDatabase {
+		g.logCommand("reference", fmt.Sprintf("Can't open %s: only the %s database is browsable", ref.String(), firebase.DefaultDatabase), "error")
+		return nil
+	}
+	return g.jumpTo(ref.Project, ref.Path)
+}
+
+// referenceAt returns the reference held by a field of the open document.
+func (g *Gui) referenceAt(field string) (firebase.FieldReference, bool) {
+	for _, ref := range g.documentReferences(g.currentDocPath) {
+		if ref.Field == field {
+			return ref, true
+		}
+	}
+	return firebase.FieldReference{}, false
+}
@@ -0,0 +1,48 @@
+package gui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+func TestLinkLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected string
+	}{
+		{
+			`  "author": "projects/demo/databases/(default)/documents/users/ana",`,
+			`"projects/demo/databases/(default)/documents/users/ana"` + "\033[0m,",
+		},
+		{
+			`    "projects/demo/databases/(default)/documents/users/bob"`,
+			"    \033[4;36m" + `"projects/demo/databases/(default)/documents/users/bob"` + "\033[0m",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := linkLine(tt.line); !strings.HasSuffix(got, tt.expected) {
+			t.Errorf("linkLine(%q) = %q, expected suffix %q", tt.line, got, tt.expected)
+		}
+		if got := stripANSI(linkLine(tt.line)); got != tt.line {
+			t.Errorf("linkLine(%q) text = %q, expected it unchanged", tt.line, got)
+		}
+	}
+}
+
+func TestAnnotateJSONLinks(t *testing.T) {
+	name := "projects/demo/databases/(default)/documents/users/ana"
+	text, _ := json.MarshalIndent(map[string]any{"author": name, "title": "Hi"}, "", "  ")
+	refs := []firebase.FieldReference{{Field: ".author", Reference: firebase.Reference{Project: "demo", Database: "(default)", Path: "users/ana"}}}
+
+	lines := strings.Split(annotateJSON(string(text), nil, refs), "\n")
+	if !strings.Contains(lines[1], "\033[4;36m\""+name) {
+		t.Errorf("reference line = %q, expected a link", lines[1])
+	}
+	if strings.Contains(lines[2], "\033[4;36m") {
+		t.Errorf("title line = %q, expected no link", lines[2])
+	}
+}
@@ -0,0 +1,235 @@
+package gui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/marjoballabani/lazyfire/pkg/config"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// queryParams returns the parameters used in filter values, in order of first use.
+func queryParams(filters []firebase.QueryFilter) []string {
+	var params []string
+	seen := make(map[string]bool)
+	for _, f := range filters {
+		s := firebase.QueryValueText(f)
+		for _, p := range firebase.QueryParamPattern.FindAllString(s, -1) {
+			if !seen[p] {
+				seen[p] = true
+				params = append(params, p)
+			}
+		}
+	}
+	return params
+}
+
+// bindQueryParams returns a copy of filters with parameters replaced by their values.
+func bindQueryParams(filters []firebase.QueryFilter, values map[string]string) []firebase.QueryFilter {
+	bound := make([]firebase.QueryFilter, len(filters))
+	bind := func(s string) string {
+		return firebase.QueryParamPattern.ReplaceAllStringFunc(s, func(p string) string {
+			if v, ok := values[p]; ok {
+				return v
+			}
+			return p
+		})
+	}
+	for i, f := range filters {
+		switch value := f.Value.(type) {
+		case string:
+			f.Value = bind(value)
+		case []interface{}:
+			// A list item that is only a parameter is typed by its value
+			items := make([]interface{}, len(value))
+			for j, item := range value {
+				items[j] = item
+				if s, ok := item.(string); ok {
+					if _, isParam := values[s]; isParam {
+						items[j] = firebase.AutoQueryValue(values[s])
+					} else {
+						items[j] = bind(s)
+					}
+				}
+			}
+			f.Value = items
+		}
+		bound[i] = f
+	}
+	return bound
+}
+
+// promptQueryParams asks for the value of each parameter in turn, starting
+// from the last values given, then calls done with all of them.
+func (g *Gui) promptQueryParams(params []string, values map[string]string, done func(map[string]string)) error {
+	if len(params) == 0 {
+		done(values)
+		return nil
+	}
+	if g.queryParamValues == nil {
+		g.queryParamValues = make(map[string]string)
+	}
+	param := params[0]
+	return g.openPrompt("Value for "+param, g.queryParamValues[param], func(text string) {
+		values[param] = text
+		g.queryParamValues[param] = text
+		_ = g.promptQueryParams(params[1:], values, done)
+	})
+}
+
+// queryFilterConfigs converts query filters for saving.
+func queryFilterConfigs(filters []firebase.QueryFilter) []config.QueryFilter {
+	var configs []config.QueryFilter
+	for _, f := range filters {
+		configs = append(configs, config.QueryFilter{
+			Field:     f.Field,
+			Operator:  f.Operator,
+			Value:     firebase.QueryValueText(f),
+			ValueType: f.ValueType,
+		})
+	}
+	return configs
+}
+
+// restoreQueryState loads saved filters, ordering and limit into the query builder.
+func (g *Gui) restoreQueryState(filters []config.QueryFilter, orderBy, orderDir string, limit int) {
+	g.queryFilters = nil
+	for _, f := range filters {
+		g.queryFilters = append(g.queryFilters, firebase.QueryFilter{Field: f.Field, Operator: f.Operator, Value: f.Value, ValueType: f.ValueType})
+	}
+	g.queryOrderBy = orderBy
+	g.queryOrderDir = orderDir
+	if g.queryOrderDir == "" {
+		g.queryOrderDir = "ASC"
+	}
+	g.queryLimit = limit
+	if g.queryLimit == 0 {
+		g.queryLimit = 50
+	}
+}
+
+// querySummary describes a query in one line: filters, order and limit.
+func querySummary(filters []config.QueryFilter, orderBy, orderDir string, limit int) string {
+	var parts []string
+	for _, f := range filters {
+		parts = append(parts, fmt.Sprintf("%s %s %s", f.Field, f.Operator, f.Value))
+	}
+	if orderBy != "" {
+		parts = append(parts, strings.TrimSpace("by "+orderBy+" "+orderDir))
+	}
+	if limit > 0 {
+		parts = append(parts, fmt.Sprintf("limit %d", limit))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// savedQueryScope is the popup's key column for a saved query.
+func savedQueryScope(q config.SavedQuery) string {
+	switch {
+	case q.Collection == "":
+		return "global"
+	case q.Project == "":
+		return q.Collection
+	}
+	return q.Project + ":" + q.Collection
+}
+
+// saveQueryAction asks for a name and a scope and saves the query builder's
+// state to the config file.
+func (g *Gui) saveQueryAction() error {
+	if g.queryCollection == "" {
+		return nil
+	}
+	collection := tableLayoutKey(g.queryCollection)
+	project := g.currentProject
+	return g.openPrompt("Save query as", "", func(name string) {
+		if name == "" {
+			return
+		}
+		query := config.SavedQuery{
+			Name:     name,
+			Filters:  queryFilterConfigs(g.queryFilters),
+			OrderBy:  g.queryOrderBy,
+			OrderDir: g.queryOrderDir,
+			Limit:    g.queryLimit,
+		}
+		save := func(project, collection string) func() error {
+			return func() error {
+				query.Project = project
+				query.Collection = collection
+				if err := g.config.SaveQuery(query); err != nil {
+					g.logCommand("query", fmt.Sprintf("Failed to save query: %v", err), "error")
+					return nil
+				}
+				g.logCommand("query", fmt.Sprintf("Saved query %q (%s)", name, savedQueryScope(query)), "success")
+				g.queryModalOpen = true
+				return nil
+			}
+		}
+
+		// The menu is drawn by the help popup, which the query modal would cover
+		g.queryModalOpen = false
+		_ = g.openMenu("Save "+name+" for", []PopupItem{
+			{Key: project, Label: collection + " in this project", Action: save(project, collection)},
+			{Key: "*", Label: collection + " in any project", Action: save("", collection)},
+			{Key: "global", Label: "Any collection", Action: save("", "")},
+		})
+		g.helpPopup.OnCancel = g.reopenQueryModal
+	})
+}
+
+// loadQueryAction lists the saved queries for the query's collection. Enter
+// loads one into the query builder, d deletes it.
+func (g *Gui) loadQueryAction() error {
+	queries := g.config.SavedQueriesFor(g.currentProject, g.queryCollection)
+	if len(queries) == 0 {
+		g.logCommand("query", fmt.Sprintf("No saved queries for %s", g.queryCollection), "error")
+		return nil
+	}
+
+	items := []PopupItem{{Label: "Saved queries for " + g.queryCollection, IsHeader: true}}
+	for _, q := range queries {
+		q := q
+		items = append(items, PopupItem{
+			Key:   savedQueryScope(q),
+			Label: fmt.Sprintf("%s \033[90m%s\033[0m", q.Name, querySummary(q.Filters, q.OrderBy, q.OrderDir, q.Limit)),
+			Action: func() error {
+				g.restoreQueryState(q.Filters, q.OrderBy, q.OrderDir, q.Limit)
+				g.queryModalOpen = true
+				g.queryActiveRow = queryRowButtons
+				g.queryActiveCol = 0
+				g.logCommand("query", fmt.Sprintf("Loaded query %q", q.Name), "success")
+				return nil
+			},
+			Keys: map[rune]func() error{
+				'd': func() error {
+					if err := g.config.DeleteSavedQuery(q); err != nil {
+						g.logCommand("query", fmt.Sprintf("Failed to delete query: %v", err), "error")
+						return nil
+					}
+					g.logCommand("query", fmt.Sprintf("Deleted query %q", q.Name), "success")
+					if len(g.config.SavedQueriesFor(g.currentProject, g.queryCollection)) == 0 {
+						g.queryModalOpen = true
+						return nil
+					}
+					return g.loadQueryAction()
+				},
+			},
+		})
+	}
+
+	g.queryModalOpen = false
+	if err := g.openMenu("Saved queries", items); err != nil {
+		return err
+	}
+	g.helpPopup.Footer = g.footerHints([]keyHint{
+		{g.keyLabels("confirm"), "load"}, {"d", "delete"}, {g.keyLabels("return"), "close"},
+	})
+	g.helpPopup.OnCancel = g.reopenQueryModal
+	return nil
+}
+
+// reopenQueryModal shows the query modal again when a menu over it is closed.
+func (g *Gui) reopenQueryModal() {
+	g.queryModalOpen = true
+}
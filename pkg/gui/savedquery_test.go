@@ -0,0 +1,56 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+func TestQueryParams(t *testing.T) {
+	filters := []firebase.QueryFilter{
+		{Field: "owner", Operator: "==", Value: "$userId"},
+		{Field: "path", Operator: "==", Value: "users/$userId/orders/$orderId"},
+		{Field: "total", Operator: ">", Value: 100},
+		{Field: "price", Operator: "==", Value: "$ 5"},
+	}
+
+	if got := queryParams(filters); !reflect.DeepEqual(got, []string{"$userId", "$orderId"}) {
+		t.Errorf("queryParams() = %v, expected [$userId $orderId]", got)
+	}
+
+	bound := bindQueryParams(filters, map[string]string{"$userId": "u1", "$orderId": "o9"})
+	expected := []any{"u1", "users/u1/orders/o9", 100, "$ 5"}
+	for i, f := range bound {
+		if f.Value != expected[i] {
+			t.Errorf("bound[%d].Value = %v, expected %v", i, f.Value, expected[i])
+		}
+	}
+	if filters[0].Value != "$userId" {
+		t.Error("bindQueryParams should not change the query builder's filters")
+	}
+
+	// A list item that is only a parameter is typed by its value
+	list := []firebase.QueryFilter{{Field: "level", Operator: "in", Value: []any{"$level", "a,$userId"}, ValueType: "array"}}
+	if got := queryParams(list); !reflect.DeepEqual(got, []string{"$level", "$userId"}) {
+		t.Errorf("queryParams() of a list = %v, expected [$level $userId]", got)
+	}
+	bound = bindQueryParams(list, map[string]string{"$level": "3", "$userId": "u1"})
+	if !reflect.DeepEqual(bound[0].Value, []any{int64(3), "a,u1"}) {
+		t.Errorf("bound list = %#v, expected [3 \"a,u1\"]", bound[0].Value)
+	}
+}
+
+func TestCancelledMenuReopensQueryModal(t *testing.T) {
+	g := &Gui{}
+	g.helpPopup = &Popup{OnCancel: g.reopenQueryModal}
+	g.helpOpen = true
+
+	g.closeHelpPopup()
+	if g.helpOpen || g.helpPopup != nil {
+		t.Error("menu should be closed")
+	}
+	if !g.queryModalOpen {
+		t.Error("query modal should be open again")
+	}
+}
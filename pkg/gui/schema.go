@@ -0,0 +1,178 @@
+package gui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// defaultSchemaSample is the suggested number of documents to sample.
+const defaultSchemaSample = 100
+
+// schemaAction asks for a sample size and infers the schema of the selected collection.
+func (g *Gui) schemaAction() error {
+	collection := g.getSelectedCollection()
+	if collection == "" {
+		g.logCommand("schema", "Select a collection or document to infer its schema", "error")
+		return nil
+	}
+	return g.promptSchemaSample("schema", collection, g.showSchema)
+}
+
+// promptSchemaSample asks how many documents of collection to sample, then
+// infers their schema in the background and passes it to done.
+func (g *Gui) promptSchemaSample(cmd, collection string, done func(*firebase.Schema) error) error {
+	return g.openPrompt(fmt.Sprintf("Infer schema of %s from (documents)", collection), strconv.Itoa(defaultSchemaSample), func(input string) {
+		limit, err := strconv.Atoi(strings.TrimSpace(input))
+		if err != nil || limit <= 0 {
+			g.logCommand(cmd, fmt.Sprintf("Invalid sample size %q", input), "error")
+			return
+		}
+		g.startSchemaSample(cmd, collection, limit, done)
+	})
+}
+
+// startSchemaSample samples documents in the background and passes the inferred schema to done.
+func (g *Gui) startSchemaSample(cmd, collection string, limit int, done func(*firebase.Schema) error) {
+	g.logCommand(cmd, fmt.Sprintf("Sampling %d documents of %s...", limit, collection), "running")
+
+	go func() {
+		schema, err := g.firebaseClient.SampleSchema(collection, limit)
+
+		g.g.Update(func(gui *gocui.Gui) error {
+			if err != nil {
+				g.updateCommand(cmd, fmt.Sprintf("Failed: %v", err), "error")
+				return nil
+			}
+			if schema.Documents == 0 {
+				g.updateCommand(cmd, fmt.Sprintf("%s has no documents", collection), "error")
+				return nil
+			}
+			g.updateCommand(cmd, fmt.Sprintf("%d fields in %d documents, %d type conflicts", len(schema.Fields), schema.Documents, schema.Conflicts()), "success")
+			g.schemaSamples[collection] = schema
+			return done(schema)
+		})
+	}()
+}
+
+// showSchema puts a schema report in the details panel and focuses it.
+func (g *Gui) showSchema(s *firebase.Schema) error {
+	g.schemaView = s
+	g.currentDocData = schemaData(s)
+	g.currentDocPath = schemaTitle(s)
+	g.clearDetailsCache()
+
+	if g.currentColumn != "details" {
+		g.previousColumn = g.currentColumn
+	}
+	if err := g.setFocus(g.g, "details"); err != nil {
+		return err
+	}
+	return g.Layout(g.g)
+}
+
+// isSchemaShown reports whether the details panel is showing the current schema report.
+func (g *Gui) isSchemaShown() bool {
+	return g.schemaView != nil && g.currentDocData != nil && g.currentDocPath == schemaTitle(g.schemaView)
+}
+
+// renderSchemaDetails draws the current schema report in the details view.
+func (g *Gui) renderSchemaDetails(v *gocui.View) {
+	if g.cachedDetailsDocPath != g.currentDocPath || g.cachedDetailsContent == "" {
+		g.cachedDetailsContent = renderSchema(g.schemaView)
+		g.cachedDetailsLines = strings.Split(g.cachedDetailsContent, "\n")
+		g.cachedDetailsHeader = ""
+		g.cachedDetailsDocPath = g.currentDocPath
+		g.detailsViewDirty = true
+	}
+	if g.detailsViewDirty {
+		v.SetContent(g.cachedDetailsContent)
+		g.detailsViewDirty = false
+	}
+}
+
+// schemaTitle identifies a schema report; it is shown as the details path.
+func schemaTitle(s *firebase.Schema) string {
+	return "schema: " + s.Collection
+}
+
+// schemaData returns the report as a JSON-friendly map (path -> stats) so copy
+// and save work on a schema like on a document.
+func schemaData(s *firebase.Schema) map[string]any {
+	data := make(map[string]any, len(s.Fields))
+	for _, f := range s.Fields {
+		types := make(map[string]any, len(f.Types))
+		for typ, n := range f.Types {
+			types[typ] = n
+		}
+		data[f.Path] = map[string]any{
+			"types":    types,
+			"present":  f.Present,
+			"nulls":    f.Nulls,
+			"examples": f.Examples,
+		}
+	}
+	return data
+}
+
+// percent formats n/total as a whole percentage, keeping small shares visible.
+func percent(n, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	p := n * 100 / total
+	if p == 0 && n > 0 {
+		return "<1%"
+	}
+	return fmt.Sprintf("%d%%", p)
+}
+
+// renderSchema formats a schema report with conflicting types highlighted.
+func renderSchema(s *firebase.Schema) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("\033[36m─── %s ───\033[0m\n", schemaTitle(s)))
+	content.WriteString(fmt.Sprintf("\033[90m%d documents sampled, %d fields\033[0m\n", s.Documents, len(s.Fields)))
+	if conflicts := s.Conflicts(); conflicts > 0 {
+		content.WriteString(fmt.Sprintf("\033[33mFields with conflicting types: %d\033[0m\n", conflicts))
+	}
+
+	for _, f := range s.Fields {
+		content.WriteString("\n")
+
+		names := f.TypeNames()
+		nonNull := f.Values - f.Nulls
+		var types []string
+		for i, typ := range names {
+			label := typ
+			if len(names) > 1 {
+				label += " " + percent(f.Types[typ], nonNull)
+				if i > 0 {
+					label = "\033[31m" + label + "\033[0m" // Minority types are the likely bugs
+				}
+			}
+			types = append(types, label)
+		}
+		if len(names) == 0 {
+			types = append(types, firebase.TypeNull)
+		}
+
+		if f.Conflict() {
+			content.WriteString(fmt.Sprintf("\033[33m! %s\033[0m  %s\n", f.Path, strings.Join(types, ", ")))
+		} else {
+			content.WriteString(fmt.Sprintf("  \033[34m%s\033[0m  %s\n", f.Path, strings.Join(types, ", ")))
+		}
+
+		stats := fmt.Sprintf("present %s", percent(f.Present, s.Documents))
+		if f.Nulls > 0 {
+			stats += fmt.Sprintf(", null %s", percent(f.Nulls, f.Values))
+		}
+		if len(f.Examples) > 0 {
+			stats += ", e.g. " + strings.Join(f.Examples, "  ")
+		}
+		content.WriteString(fmt.Sprintf("    \033[90m%s\033[0m\n", stats))
+	}
+	return content.String()
+}
@@ -0,0 +1,53 @@
+package gui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		n, total int
+		expected string
+	}{
+		{0, 0, "0%"},
+		{0, 10, "0%"},
+		{1, 1000, "<1%"},
+		{97, 100, "97%"},
+		{3, 3, "100%"},
+	}
+
+	for _, tt := range tests {
+		if got := percent(tt.n, tt.total); got != tt.expected {
+			t.Errorf("percent(%d, %d) = %q, expected %q", tt.n, tt.total, got, tt.expected)
+		}
+	}
+}
+
+func TestRenderSchema(t *testing.T) {
+	s := &firebase.Schema{
+		Collection: "users",
+		Documents:  100,
+		Fields: []*firebase.SchemaField{
+			{Path: "age", Types: map[string]int{"integer": 97, "string": 3}, Present: 100, Values: 100, Examples: []string{"30", `"41"`}},
+			{Path: "name", Types: map[string]int{"string": 90, "null": 10}, Present: 100, Values: 100, Nulls: 10},
+		},
+	}
+	output := stripANSI(renderSchema(s))
+
+	for _, want := range []string{
+		"schema: users",
+		"100 documents sampled, 2 fields",
+		"Fields with conflicting types: 1",
+		"! age  integer 97%, string 3%",
+		`present 100%, e.g. 30  "41"`,
+		"  name  string",
+		"present 100%, null 10%",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("renderSchema() missing %q in:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+const (
+	maxSearchHits     = 200  // Hits listed by a search
+	searchPageSize    = 300  // Documents fetched per page when searching a collection
+	maxSearchScan     = 6000 // Documents of a collection a search pages through
+	searchSnippetSide = 20   // Characters of context around a match
+)
+
+// searchOptions describe a full-text search over document values.
+type searchOptions struct {
+	Pattern       string
+	Regex         bool
+	CaseSensitive bool
+	Collection    string // Page through all documents of this collection instead of the cached ones
+}
+
+// searchHit is a document value matching a search.
+type searchHit struct {
+	Path    string // Document path
+	Field   string // jq-style path of the value, e.g. .address.city
+	Snippet string // The match with some context, highlighted
+}
+
+// detailsHighlight is a line of a document to highlight in the details panel.
+type detailsHighlight struct {
+	Path  string // Document path
+	Field string // jq-style path of the line
+}
+
+// compile turns the pattern into a regexp: literal unless Regex is set,
+// ignoring case unless CaseSensitive is set.
+func (o searchOptions) compile() (*regexp.Regexp, error) {
+	pattern := o.Pattern
+	if !o.Regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if !o.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// describe summarizes the options for the results title, e.g. "foo" (regex, case-sensitive).
+func (o searchOptions) describe() string {
+	var flags []string
+	if o.Regex {
+		flags = append(flags, "regex")
+	}
+	if o.CaseSensitive {
+		flags = append(flags, "case-sensitive")
+	}
+	s := strconv.Quote(o.Pattern)
+	if len(flags) > 0 {
+		s += " (" + strings.Join(flags, ", ") + ")"
+	}
+	return s
+}
+
+// searchDocument appends the hits among the scalar values of a document, in
+// the order of the JSON shown in details.
+func searchDocument(path string, data map[string]any, re *regexp.Regexp, hits []searchHit) []searchHit {
+	var walk func(field string, value any)
+	walk = func(field string, value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(diffKeyPath(field, k), v[k])
+			}
+		case []any:
+			for i, item := range v {
+				walk(diffIndexPath(field, i), item)
+			}
+		default:
+			text, ok := v.(string)
+			if !ok {
+				b, _ := json.Marshal(v)
+				text = string(b)
+			}
+			if loc := re.FindStringIndex(text); loc != nil {
+				hits = append(hits, searchHit{Path: path, Field: field, Snippet: searchSnippet(text, loc)})
+			}
+		}
+	}
+	walk(".", data)
+	return hits
+}
+
+// searchWhitespace matches runs of whitespace, shown as one space in snippets.
+var searchWhitespace = regexp.MustCompile(`\s+`)
+
+// searchSnippet shows the match at loc with up to searchSnippetSide
+// characters around it on one line, the match in bold yellow.
+func searchSnippet(text string, loc []int) string {
+	oneLine := func(s string) string {
+		return searchWhitespace.ReplaceAllString(s, " ")
+	}
+	before := []rune(text[:loc[0]])
+	after := []rune(text[loc[1]:])
+	prefix, suffix := "", ""
+	if len(before) > searchSnippetSide {
+		before, prefix = before[len(before)-searchSnippetSide:], "…"
+	}
+	if len(after) > searchSnippetSide {
+		after, suffix = after[:searchSnippetSide], "…"
+	}
+	return prefix + oneLine(string(before)) + "\033[1;33m" + oneLine(text[loc[0]:loc[1]]) + "\033[0m" + oneLine(string(after)) + suffix
+}
+
+// searchCachedDocuments searches the values of every cached document, by path.
+func (g *Gui) searchCachedDocuments(re *regexp.Regexp) []searchHit {
+	paths := make([]string, 0, len(g.docCache))
+	for path := range g.docCache {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var hits []searchHit
+	for _, path := range paths {
+		hits = searchDocument(path, g.docCache[path], re, hits)
+		if len(hits) >= maxSearchHits {
+			return hits[:maxSearchHits]
+		}
+	}
+	return hits
+}
+
+// searchAction asks for a pattern and searches the values of the cached documents.
+func (g *Gui) searchAction() error {
+	return g.promptSearch(searchOptions{})
+}
+
+// promptSearch asks for the pattern of a search with the given options.
+func (g *Gui) promptSearch(opts searchOptions) error {
+	return g.openPrompt("Search document values for", opts.Pattern, func(text string) {
+		if text == "" {
+			return
+		}
+		opts.Pattern = text
+		g.runSearch(opts)
+	})
+}
+
+// runSearch searches the cached documents and, when opts.Collection is set,
+// pages through the collection in the background, then lists the hits.
+func (g *Gui) runSearch(opts searchOptions) {
+	re, err := opts.compile()
+	if err != nil {
+		g.logCommand("search", fmt.Sprintf("Invalid regex: %v", err), "error")
+		return
+	}
+
+	if opts.Collection == "" {
+		hits := g.searchCachedDocuments(re)
+		g.logCommand("search", fmt.Sprintf("%d hits for %s in %d loaded documents", len(hits), opts.describe(), len(g.docCache)), "success")
+		_ = g.showSearchHits(opts, hits)
+		return
+	}
+
+	collection := opts.Collection
+	g.logCommand("search", fmt.Sprintf("Searching %s for %s...", collection, opts.describe()), "running")
+	go func() {
+		var hits []searchHit
+		var found []firebase.Document
+		scanned, startAfter := 0, ""
+		var err error
+		for scanned < maxSearchScan && len(hits) < maxSearchHits {
+			var docs []firebase.Document
+			docs, err = g.firebaseClient.ListDocumentsAfter(collection, startAfter, searchPageSize)
+			if err != nil || len(docs) == 0 {
+				break
+			}
+			for _, doc := range docs {
+				n := len(hits)
+				hits = searchDocument(doc.Path, doc.Data, re, hits)
+				if len(hits) > n {
+					found = append(found, doc)
+				}
+			}
+			scanned += len(docs)
+			startAfter = docs[len(docs)-1].Path
+
+			progress, hitCount := scanned, len(hits)
+			g.g.Update(func(gui *gocui.Gui) error {
+				g.updateCommand("search", fmt.Sprintf("Searching %s: %d documents, %d hits...", collection, progress, hitCount), "running")
+				return nil
+			})
+			if len(docs) < searchPageSize {
+				break
+			}
+		}
+
+		g.g.Update(func(gui *gocui.Gui) error {
+			if err != nil {
+				g.updateCommand("search", fmt.Sprintf("Failed: %v", err), "error")
+				return nil
+			}
+			// Keep matching documents so opening a hit needs no fetch
+			for _, doc := range found {
+				g.cacheDocument(doc)
+			}
+			if len(hits) > maxSearchHits {
+				hits = hits[:maxSearchHits]
+			}
+			g.updateCommand("search", fmt.Sprintf("%d hits for %s in %d documents of %s", len(hits), opts.describe(), scanned, collection), "success")
+			return g.showSearchHits(opts, hits)
+		})
+	}()
+}
+
+// showSearchHits lists the hits of a search as path → field: snippet. Enter
+// opens a hit's document with the line highlighted; r, i and a search again
+// with regex, case-sensitivity or the focused collection toggled.
+func (g *Gui) showSearchHits(opts searchOptions, hits []searchHit) error {
+	rerun := func(change func(*searchOptions)) func() error {
+		return func() error {
+			next := opts
+			change(&next)
+			g.runSearch(next)
+			return g.Layout(g.g)
+		}
+	}
+	collection := g.getSelectedCollection()
+	keys := map[rune]func() error{
+		'/': func() error { return g.promptSearch(opts) },
+		'r': rerun(func(o *searchOptions) { o.Regex = !o.Regex }),
+		'i': rerun(func(o *searchOptions) { o.CaseSensitive = !o.CaseSensitive }),
+		'a': rerun(func(o *searchOptions) {
+			if o.Collection == "" {
+				o.Collection = collection
+			} else {
+				o.Collection = ""
+			}
+		}),
+	}
+	if collection == "" && opts.Collection == "" {
+		keys['a'] = func() error {
+			g.logCommand("search", "Select a collection or one of its documents to search all of it", "error")
+			return nil
+		}
+	}
+
+	scope := "loaded documents"
+	if opts.Collection != "" {
+		scope = opts.Collection
+	}
+	items := []PopupItem{{Label: fmt.Sprintf("%s in %s", opts.describe(), scope), IsHeader: true}}
+	for i, hit := range hits {
+		items = append(items, PopupItem{
+			Key:    strconv.Itoa(i + 1),
+			Label:  fmt.Sprintf("%s \033[90m→\033[0m %s: %s", hit.Path, hit.Field, hit.Snippet),
+			Action: func() error { return g.openSearchHit(hit) },
+			Keys:   keys,
+		})
+	}
+	if len(hits) == 0 {
+		items = append(items, PopupItem{
+			Key:    "/",
+			Label:  "No matches, search again",
+			Action: keys['/'],
+			Keys:   keys,
+		})
+	}
+
+	if err := g.openMenu("Search", items); err != nil {
+		return err
+	}
+	g.helpPopup.Footer = g.footerHints([]keyHint{
+		{g.keyLabels("confirm"), "open"}, {"/", "search"}, {"r", "regex"}, {"i", "case"}, {"a", "all of the collection"}, {g.keyLabels("return"), "close"},
+	})
+	return nil
+}
+
+// openSearchHit opens the document of a hit with the matching line highlighted.
+func (g *Gui) openSearchHit(hit searchHit) error {
+	g.detailsHighlight = &detailsHighlight{Path: hit.Path, Field: hit.Field}
+	g.clearDetailsCache()
+	return g.jumpTo(g.currentProject, hit.Path)
+}
+
+// highlightDetailsLine returns the line of the details JSON holding field,
+// or -1. text is the JSON as json.MarshalIndent writes it.
+func highlightDetailsLine(text, field string) int {
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return -1
+	}
+	for i, p := range jsonLinePaths(value, ".", nil) {
+		if p == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// highlightLine gives a colorized line the background bg, restoring it after
+// each color reset within the line.
+func highlightLine(line, bg string) string {
+	return bg + strings.ReplaceAll(line, "\033[0m", "\033[0m"+bg) + "\033[0m"
+}
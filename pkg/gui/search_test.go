@@ -0,0 +1,88 @@
+package gui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchDocument(t *testing.T) {
+	data := map[string]any{
+		"name":       "Ana Lopez",
+		"email":      "ana@example.com",
+		"age":        float64(31),
+		"tags":       []any{"admin", "Analytics"},
+		"address":    map[string]any{"city": "Barcelona"},
+		"first name": "Ana",
+		"note":       nil,
+	}
+
+	tests := []struct {
+		opts     searchOptions
+		expected []string
+	}{
+		{searchOptions{Pattern: "ana"}, []string{".email", `["first name"]`, ".name", ".tags[1]"}},
+		{searchOptions{Pattern: "Ana", CaseSensitive: true}, []string{`["first name"]`, ".name", ".tags[1]"}},
+		{searchOptions{Pattern: `^a\w+$`, Regex: true}, []string{`["first name"]`, ".tags[0]", ".tags[1]"}},
+		{searchOptions{Pattern: "31"}, []string{".age"}},
+		{searchOptions{Pattern: "null"}, []string{".note"}},
+		{searchOptions{Pattern: "a.b", Regex: false}, nil},
+	}
+
+	for _, tt := range tests {
+		re, err := tt.opts.compile()
+		if err != nil {
+			t.Fatalf("compile(%+v) error: %v", tt.opts, err)
+		}
+		var fields []string
+		for _, hit := range searchDocument("users/ana", data, re, nil) {
+			if hit.Path != "users/ana" {
+				t.Errorf("hit path = %q, expected users/ana", hit.Path)
+			}
+			fields = append(fields, hit.Field)
+		}
+		if !reflect.DeepEqual(fields, tt.expected) {
+			t.Errorf("searchDocument(%+v) fields = %v, expected %v", tt.opts, fields, tt.expected)
+		}
+	}
+
+	if _, err := (searchOptions{Pattern: "(", Regex: true}).compile(); err == nil {
+		t.Error("compile() should fail for an invalid regex")
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	tests := []struct {
+		text     string
+		loc      []int
+		expected string
+	}{
+		{"hello world", []int{6, 11}, "hello \033[1;33mworld\033[0m"},
+		{"a very long text before the match and a long text after it", []int{28, 33},
+			"…ong text before the \033[1;33mmatch\033[0m and a long text aft…"},
+		{"line one\nline two", []int{9, 13}, "line one \033[1;33mline\033[0m two"},
+	}
+
+	for _, tt := range tests {
+		if got := searchSnippet(tt.text, tt.loc); got != tt.expected {
+			t.Errorf("searchSnippet(%q, %v) = %q, expected %q", tt.text, tt.loc, got, tt.expected)
+		}
+	}
+}
+
+func TestHighlightDetailsLine(t *testing.T) {
+	text := "{\n  \"address\": {\n    \"city\": \"Paris\"\n  },\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}"
+	tests := []struct {
+		field    string
+		expected int
+	}{
+		{".address.city", 2},
+		{".tags[1]", 6},
+		{".missing", -1},
+	}
+
+	for _, tt := range tests {
+		if got := highlightDetailsLine(text, tt.field); got != tt.expected {
+			t.Errorf("highlightDetailsLine(%q) = %d, expected %d", tt.field, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,691 @@
+package gui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jesseduffield/gocui"
+	"github.com/marjoballabani/lazyfire/pkg/config"
+)
+
+const (
+	tableMaxColumnWidth = 28
+	tableMinColumnWidth = 4
+	tableSeparator      = " │ "
+)
+
+// tableRow is one document in the table view.
+type tableRow struct {
+	Path string
+	ID   string
+	Data map[string]any
+}
+
+// tableState is the table view of the documents of one collection.
+type tableState struct {
+	collection string // Collection path
+	layoutKey  string // Key the layout is saved under
+	rows       []tableRow
+	original   []tableRow // Rows in tree order, for when sorting is turned off
+	columns    []string   // All columns in display order, hidden ones included
+	saved      config.TableLayout
+	hidden     map[string]bool
+	pinned     int // Leading visible columns kept in view while scrolling
+	sortBy     string
+	sortDesc   bool
+
+	row      int // Cursor row
+	col      int // Cursor column (index into visibleColumns)
+	firstRow int // First row shown
+	firstCol int // First unpinned column shown
+	changed  bool
+}
+
+// tableLayoutKey returns the key a collection's layout is saved under:
+// its path with document IDs replaced by "*".
+func tableLayoutKey(collection string) string {
+	segments := strings.Split(collection, "/")
+	for i := 1; i < len(segments); i += 2 {
+		segments[i] = "*"
+	}
+	return strings.Join(segments, "/")
+}
+
+// tableColumns returns the union of top-level fields of rows: saved columns
+// first in their saved order, then new ones alphabetically.
+func tableColumns(rows []tableRow, saved []string) []string {
+	present := make(map[string]bool)
+	for _, r := range rows {
+		for field := range r.Data {
+			present[field] = true
+		}
+	}
+
+	columns := make([]string, 0, len(present))
+	for _, c := range saved {
+		if present[c] {
+			columns = append(columns, c)
+			delete(present, c)
+		}
+	}
+	rest := make([]string, 0, len(present))
+	for c := range present {
+		rest = append(rest, c)
+	}
+	sort.Strings(rest)
+	return append(columns, rest...)
+}
+
+// formatTableCell renders a field value on one line.
+func formatTableCell(v any, ok bool) string {
+	if !ok {
+		return ""
+	}
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strings.Join(strings.Fields(val), " ")
+	case map[string]any, []any:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// fitTableCell pads or truncates s to exactly width characters.
+func fitTableCell(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n <= width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
+
+// tableNumber returns a value as float64 when it is numeric.
+func tableNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// compareTableValues orders two cells: missing values last, numbers numerically,
+// everything else by its displayed text.
+func compareTableValues(a any, aok bool, b any, bok bool) int {
+	switch {
+	case !aok && !bok:
+		return 0
+	case !aok:
+		return 1
+	case !bok:
+		return -1
+	}
+	if an, ok := tableNumber(a); ok {
+		if bn, ok := tableNumber(b); ok {
+			switch {
+			case an < bn:
+				return -1
+			case an > bn:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(formatTableCell(a, true), formatTableCell(b, true))
+}
+
+// sortTableRows sorts rows by a column, keeping documents without the field at the end.
+func sortTableRows(rows []tableRow, column string, desc bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, aok := rows[i].Data[column]
+		b, bok := rows[j].Data[column]
+		if !aok || !bok {
+			return aok && !bok
+		}
+		c := compareTableValues(a, aok, b, bok)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// newTableState builds the table for a collection, applying a saved layout.
+func newTableState(collection string, rows []tableRow, layout config.TableLayout) *tableState {
+	t := &tableState{
+		collection: collection,
+		layoutKey:  tableLayoutKey(collection),
+		rows:       append([]tableRow(nil), rows...),
+		original:   rows,
+		columns:    tableColumns(rows, layout.Columns),
+		saved:      layout,
+		hidden:     make(map[string]bool),
+		pinned:     layout.Pinned,
+		sortBy:     layout.SortBy,
+		sortDesc:   layout.SortDesc,
+	}
+	for _, c := range layout.Hidden {
+		t.hidden[c] = true
+	}
+	t.applySort()
+	t.clampPinned()
+	return t
+}
+
+// layout returns the current column layout for saving. Saved columns that no
+// loaded document has are kept, so a partial page does not forget them.
+func (t *tableState) layout() config.TableLayout {
+	layout := config.TableLayout{
+		Collection: t.layoutKey,
+		Columns:    append([]string(nil), t.columns...),
+		Pinned:     t.pinned,
+		SortBy:     t.sortBy,
+		SortDesc:   t.sortDesc,
+	}
+	for _, c := range t.columns {
+		if t.hidden[c] {
+			layout.Hidden = append(layout.Hidden, c)
+		}
+	}
+	for _, c := range t.saved.Columns {
+		if indexOf(t.columns, c) < 0 {
+			layout.Columns = append(layout.Columns, c)
+		}
+	}
+	for _, c := range t.saved.Hidden {
+		if indexOf(t.columns, c) < 0 {
+			layout.Hidden = append(layout.Hidden, c)
+		}
+	}
+	return layout
+}
+
+// visibleColumns returns the columns that are not hidden, in display order.
+func (t *tableState) visibleColumns() []string {
+	visible := make([]string, 0, len(t.columns))
+	for _, c := range t.columns {
+		if !t.hidden[c] {
+			visible = append(visible, c)
+		}
+	}
+	return visible
+}
+
+// currentColumn returns the column under the cursor ("" when all are hidden).
+func (t *tableState) currentColumn() string {
+	visible := t.visibleColumns()
+	if t.col >= len(visible) {
+		return ""
+	}
+	return visible[t.col]
+}
+
+func (t *tableState) applySort() {
+	copy(t.rows, t.original)
+	if t.sortBy != "" {
+		sortTableRows(t.rows, t.sortBy, t.sortDesc)
+	}
+}
+
+func (t *tableState) clampPinned() {
+	if n := len(t.visibleColumns()); t.pinned > n {
+		t.pinned = n
+	}
+	if t.pinned < 0 {
+		t.pinned = 0
+	}
+}
+
+// moveRow moves the cursor by delta rows.
+func (t *tableState) moveRow(delta int) {
+	t.row = max(0, min(t.row+delta, len(t.rows)-1))
+}
+
+// moveCol moves the cursor by delta columns.
+func (t *tableState) moveCol(delta int) {
+	t.col = max(0, min(t.col+delta, len(t.visibleColumns())-1))
+}
+
+// toggleSort sorts by the current column: ascending, then descending, then unsorted.
+func (t *tableState) toggleSort() {
+	column := t.currentColumn()
+	if column == "" {
+		return
+	}
+	switch {
+	case t.sortBy != column:
+		t.sortBy, t.sortDesc = column, false
+	case !t.sortDesc:
+		t.sortDesc = true
+	default:
+		t.sortBy, t.sortDesc = "", false
+	}
+	t.applySort()
+	t.changed = true
+}
+
+// togglePin pins every column up to the cursor, or unpins them when already pinned there.
+func (t *tableState) togglePin() {
+	if t.pinned == t.col+1 {
+		t.pinned = 0
+	} else {
+		t.pinned = t.col + 1
+	}
+	t.clampPinned()
+	t.firstCol = t.pinned
+	t.changed = true
+}
+
+// hideColumn hides the column under the cursor.
+func (t *tableState) hideColumn() {
+	column := t.currentColumn()
+	if column == "" {
+		return
+	}
+	t.hidden[column] = true
+	if t.col < t.pinned {
+		t.pinned--
+	}
+	t.moveCol(0)
+	t.clampPinned()
+	t.changed = true
+}
+
+// showAllColumns unhides every column.
+func (t *tableState) showAllColumns() {
+	if len(t.hidden) == 0 {
+		return
+	}
+	t.hidden = make(map[string]bool)
+	t.changed = true
+}
+
+// moveColumn moves the current column left (-1) or right (+1) among the visible ones.
+func (t *tableState) moveColumn(delta int) {
+	visible := t.visibleColumns()
+	target := t.col + delta
+	if t.col >= len(visible) || target < 0 || target >= len(visible) {
+		return
+	}
+	a, b := visible[t.col], visible[target]
+	ai, bi := indexOf(t.columns, a), indexOf(t.columns, b)
+	t.columns[ai], t.columns[bi] = t.columns[bi], t.columns[ai]
+	t.col = target
+	t.changed = true
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// columnWidth returns the display width of a column: its widest cell, capped.
+func (t *tableState) columnWidth(column string) int {
+	width := utf8.RuneCountInString(column) + 2 // Room for the sort arrow
+	for _, r := range t.rows {
+		v, ok := r.Data[column]
+		if w := utf8.RuneCountInString(formatTableCell(v, ok)); w > width {
+			width = w
+		}
+	}
+	return max(tableMinColumnWidth, min(width, tableMaxColumnWidth))
+}
+
+// scrollColumns returns the visible columns that fit in width: the pinned ones,
+// then a run of scrolled ones that includes the cursor column.
+func (t *tableState) scrollColumns(width int, widths map[string]int) []int {
+	visible := t.visibleColumns()
+	used := 0
+	var shown []int
+	for i := 0; i < t.pinned && i < len(visible); i++ {
+		used += widths[visible[i]] + len(tableSeparator)
+		shown = append(shown, i)
+	}
+
+	if t.firstCol < t.pinned {
+		t.firstCol = t.pinned
+	}
+	if t.col >= t.pinned && t.col < t.firstCol {
+		t.firstCol = t.col
+	}
+	// Scroll right until the cursor column fits
+	for t.col >= t.pinned && t.firstCol < t.col {
+		total := used
+		for i := t.firstCol; i <= t.col; i++ {
+			total += widths[visible[i]] + len(tableSeparator)
+		}
+		if total <= width {
+			break
+		}
+		t.firstCol++
+	}
+
+	for i := t.firstCol; i < len(visible); i++ {
+		w := widths[visible[i]] + len(tableSeparator)
+		if used+w > width && len(shown) > 0 {
+			break
+		}
+		used += w
+		shown = append(shown, i)
+	}
+	return shown
+}
+
+// render draws the table into a view of the given size.
+func (t *tableState) render(width, height int) string {
+	visible := t.visibleColumns()
+	widths := make(map[string]int, len(visible))
+	for _, c := range visible {
+		widths[c] = t.columnWidth(c)
+	}
+
+	idWidth := len("ID")
+	for _, r := range t.rows {
+		idWidth = max(idWidth, utf8.RuneCountInString(r.ID))
+	}
+	idWidth = min(idWidth, tableMaxColumnWidth)
+
+	shown := t.scrollColumns(width-idWidth-2-len(tableSeparator), widths)
+
+	var b strings.Builder
+
+	// Header
+	b.WriteString("  \033[1m" + fitTableCell("ID", idWidth) + "\033[0m")
+	for n, i := range shown {
+		sep := tableSeparator
+		if n == t.pinned && t.pinned > 0 {
+			sep = " ┃ " // Edge of the pinned columns
+		}
+		b.WriteString("\033[90m" + sep + "\033[0m")
+
+		name := visible[i]
+		if name == t.sortBy {
+			if t.sortDesc {
+				name += " ▼"
+			} else {
+				name += " ▲"
+			}
+		}
+		style := "\033[1;36m"
+		if i == t.col {
+			style = "\033[1;7;36m"
+		}
+		b.WriteString(style + fitTableCell(name, widths[visible[i]]) + "\033[0m")
+	}
+	b.WriteString("\n")
+
+	// Rows
+	rowsShown := max(1, height-1)
+	if t.row < t.firstRow {
+		t.firstRow = t.row
+	}
+	if t.row >= t.firstRow+rowsShown {
+		t.firstRow = t.row - rowsShown + 1
+	}
+	for r := t.firstRow; r < len(t.rows) && r < t.firstRow+rowsShown; r++ {
+		row := t.rows[r]
+		marker := "  "
+		idStyle := "\033[32m"
+		if r == t.row {
+			marker = "\033[33m▶\033[0m "
+			idStyle = "\033[1;32m"
+		}
+		b.WriteString(marker + idStyle + fitTableCell(row.ID, idWidth) + "\033[0m")
+
+		for n, i := range shown {
+			sep := tableSeparator
+			if n == t.pinned && t.pinned > 0 {
+				sep = " ┃ "
+			}
+			b.WriteString("\033[90m" + sep + "\033[0m")
+
+			v, ok := row.Data[visible[i]]
+			cell := fitTableCell(formatTableCell(v, ok), widths[visible[i]])
+			switch {
+			case r == t.row && i == t.col:
+				b.WriteString("\033[7m" + cell + "\033[0m")
+			case !ok:
+				b.WriteString(cell)
+			default:
+				b.WriteString(tableCellColor(v) + cell + "\033[0m")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// tableCellColor matches the colors used for JSON values in the details panel.
+func tableCellColor(v any) string {
+	switch v.(type) {
+	case string:
+		return "\033[32m"
+	case bool, nil:
+		return "\033[35m"
+	case map[string]any, []any:
+		return "\033[90m"
+	}
+	if _, ok := tableNumber(v); ok {
+		return "\033[33m"
+	}
+	return ""
+}
+
+// openTableAction shows the documents of the selected collection (or the collection
+// of the selected document, including query results) as a table.
+func (g *Gui) openTableAction() error {
+	collection := g.getSelectedCollection()
+	if collection == "" {
+		g.logCommand("table", "Select a collection or document to show as a table", "error")
+		return nil
+	}
+
+	rows := g.getTableRows(collection)
+	if len(rows) == 0 {
+		g.logCommand("table", fmt.Sprintf("No documents of %s loaded, expand it first", collection), "error")
+		return nil
+	}
+
+	layout, _ := g.config.GetTableLayout(tableLayoutKey(collection))
+	g.table = newTableState(collection, rows, layout)
+	return g.Layout(g.g)
+}
+
+// getSelectedCollection returns the selected collection, or the collection of the selected document.
+func (g *Gui) getSelectedCollection() string {
+	switch g.currentColumn {
+	case "collections":
+		filtered := g.getFilteredCollections()
+		if g.selectedCollectionIdx < len(filtered) {
+			return filtered[g.selectedCollectionIdx].Path
+		}
+	case "tree", "details":
+		filtered := g.getFilteredTreeNodes()
+		if g.selectedTreeIdx < len(filtered) {
+			node := filtered[g.selectedTreeIdx]
+			if node.Type == "collection" {
+				return node.Path
+			}
+			return node.Path[:strings.LastIndex(node.Path, "/")]
+		}
+	}
+	return ""
+}
+
+// getTableRows returns the loaded documents of a collection in tree order.
+func (g *Gui) getTableRows(collection string) []tableRow {
+	var rows []tableRow
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		rows = append(rows, tableRow{Path: path, ID: path[strings.LastIndex(path, "/")+1:], Data: g.docCache[path]})
+	}
+
+	for _, node := range g.treeNodes {
+		if node.Type == "document" && strings.HasPrefix(node.Path, collection+"/") && !strings.Contains(node.Path[len(collection)+1:], "/") {
+			add(node.Path)
+		}
+	}
+	for _, path := range g.collectionCache[collection] {
+		add(path)
+	}
+	return rows
+}
+
+// saveTableLayout saves the open table's layout when it was changed, so it
+// survives both closing the table and quitting with the table open.
+func (g *Gui) saveTableLayout() error {
+	t := g.table
+	if t == nil || !t.changed {
+		return nil
+	}
+	if err := g.config.SaveTableLayout(t.layout()); err != nil {
+		g.logCommand("table", fmt.Sprintf("Failed to save layout: %v", err), "error")
+		return err
+	}
+	t.changed = false
+	g.logCommand("table", fmt.Sprintf("Saved column layout for %s", t.layoutKey), "success")
+	return nil
+}
+
+// closeTable closes the table view, saving its layout when it was changed.
+func (g *Gui) closeTable() error {
+	_ = g.saveTableLayout()
+	g.table = nil
+	if err := g.setFocus(g.g, g.currentColumn); err != nil {
+		return err
+	}
+	return g.Layout(g.g)
+}
+
+// tableOpenRow shows the document under the cursor in the details panel.
+func (g *Gui) tableOpenRow() error {
+	t := g.table
+	if t == nil || t.row >= len(t.rows) {
+		return nil
+	}
+	row := t.rows[t.row]
+	if err := g.closeTable(); err != nil {
+		return err
+	}
+	if row.Data == nil {
+		return nil
+	}
+	g.currentDocData = row.Data
+	g.currentDocPath = row.Path
+	g.clearDetailsCache()
+	g.recordVisit()
+	if g.currentColumn != "details" {
+		g.previousColumn = g.currentColumn
+	}
+	if err := g.setFocus(g.g, "details"); err != nil {
+		return err
+	}
+	return g.Layout(g.g)
+}
+
+// tableAction wraps a table state change as a key handler.
+func (g *Gui) tableAction(f func(t *tableState)) func() error {
+	return func() error {
+		if g.table != nil {
+			f(g.table)
+		}
+		return g.Layout(g.g)
+	}
+}
+
+// tableBindings are active while the table view has focus.
+func (g *Gui) tableBindings() []*Binding {
+	view := g.views.table
+	down := g.tableAction(func(t *tableState) { t.moveRow(1) })
+	up := g.tableAction(func(t *tableState) { t.moveRow(-1) })
+	left := g.tableAction(func(t *tableState) { t.moveCol(-1) })
+	right := g.tableAction(func(t *tableState) { t.moveCol(1) })
+
+	return []*Binding{
+		{ID: "tableNextRow", Key: 'j', ViewName: view, Handler: down, Description: "Next row"},
+		{ID: "tableNextRow", Key: gocui.KeyArrowDown, ViewName: view, Handler: down, Description: "Next row"},
+		{ID: "tablePrevRow", Key: 'k', ViewName: view, Handler: up, Description: "Previous row"},
+		{ID: "tablePrevRow", Key: gocui.KeyArrowUp, ViewName: view, Handler: up, Description: "Previous row"},
+		{ID: "tablePrevColumn", Key: 'h', ViewName: view, Handler: left, Description: "Previous column"},
+		{ID: "tablePrevColumn", Key: gocui.KeyArrowLeft, ViewName: view, Handler: left, Description: "Previous column"},
+		{ID: "tableNextColumn", Key: 'l', ViewName: view, Handler: right, Description: "Next column"},
+		{ID: "tableNextColumn", Key: gocui.KeyArrowRight, ViewName: view, Handler: right, Description: "Next column"},
+		{ID: "tableFirstRow", Key: 'g', ViewName: view, Handler: g.tableAction(func(t *tableState) { t.row = 0 }), Description: "First row"},
+		{ID: "tableLastRow", Key: 'G', ViewName: view, Handler: g.tableAction(func(t *tableState) { t.moveRow(len(t.rows)) }), Description: "Last row"},
+		{ID: "tableSort", Key: 's', ViewName: view, Handler: g.tableAction((*tableState).toggleSort), Description: "Sort by column"},
+		{ID: "tablePin", Key: 'p', ViewName: view, Handler: g.tableAction((*tableState).togglePin), Description: "Pin columns"},
+		{ID: "tableHideColumn", Key: 'x', ViewName: view, Handler: g.tableAction((*tableState).hideColumn), Description: "Hide column"},
+		{ID: "tableShowAllColumns", Key: 'a', ViewName: view, Handler: g.tableAction((*tableState).showAllColumns), Description: "Show all columns"},
+		{ID: "tableMoveColumnLeft", Key: '<', ViewName: view, Handler: g.tableAction(func(t *tableState) { t.moveColumn(-1) }), Description: "Move column left"},
+		{ID: "tableMoveColumnRight", Key: '>', ViewName: view, Handler: g.tableAction(func(t *tableState) { t.moveColumn(1) }), Description: "Move column right"},
+		{ID: "tableOpenRow", Key: gocui.KeyEnter, ViewName: view, Handler: g.tableOpenRow, Description: "Open document"},
+		{ID: "tableClose", Key: gocui.KeyEsc, ViewName: view, Handler: g.closeTable, Description: "Close table"},
+		{ID: "tableClose", Key: 'T', ViewName: view, Handler: g.closeTable, Description: "Close table"},
+		{ID: "tableClose", Key: 'q', ViewName: view, Handler: g.closeTable, Description: "Close table"},
+	}
+}
+
+// layoutTable draws the table view over the panels.
+func (g *Gui) layoutTable(gui *gocui.Gui, maxX, maxY int) error {
+	t := g.table
+
+	x0, y0, x1, y1 := 1, 1, maxX-2, maxY-3
+	v, err := gui.SetView(g.views.table, x0, y0, x1, y1, 0)
+	if err != nil {
+		if !errors.Is(err, gocui.ErrUnknownView) {
+			return err
+		}
+		v.TitleColor = g.theme.ActiveBorderColor
+		v.FrameColor = g.theme.ActiveBorderColor
+		v.FrameRunes = g.roundedFrameRunes
+	}
+
+	hidden := len(t.columns) - len(t.visibleColumns())
+	title := fmt.Sprintf(" Table: %s (%d documents", t.collection, len(t.rows))
+	if hidden > 0 {
+		title += fmt.Sprintf(", %d hidden", hidden)
+	}
+	v.Title = title + ") "
+	v.Footer = g.footerHints([]keyHint{
+		{g.keyLabels("tableSort"), "sort"},
+		{g.keyLabels("tablePin"), "pin"},
+		{g.keyLabels("tableHideColumn"), "hide"},
+		{g.keyLabels("tableShowAllColumns"), "show all"},
+		{g.keyLabels("tableMoveColumnLeft", "tableMoveColumnRight"), "move"},
+		{g.keyLabels("tableOpenRow"), "open"},
+		{g.keyLabels("tableClose"), "close"},
+	})
+
+	width, height := v.InnerSize()
+	v.SetContent(t.render(width, height))
+
+	if _, err := gui.SetCurrentView(g.views.table); err != nil {
+		return fmt.Errorf("failed to set table view: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package gui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marjoballabani/lazyfire/pkg/config"
+)
+
+func newTestTable(layout config.TableLayout) *tableState {
+	rows := []tableRow{
+		{Path: "users/a", ID: "a", Data: map[string]any{"name": "Ana", "age": int64(30)}},
+		{Path: "users/b", ID: "b", Data: map[string]any{"name": "Bob", "age": int64(4), "city": "Tirana"}},
+		{Path: "users/c", ID: "c", Data: map[string]any{"name": "Cem"}},
+	}
+	return newTableState("users", rows, layout)
+}
+
+func tableIDs(t *tableState) []string {
+	var ids []string
+	for _, r := range t.rows {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestTableLayoutKey(t *testing.T) {
+	tests := []struct {
+		collection string
+		expected   string
+	}{
+		{"users", "users"},
+		{"users/abc/orders", "users/*/orders"},
+		{"a/b/c/d/e", "a/*/c/*/e"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.collection, func(t *testing.T) {
+			if got := tableLayoutKey(tt.collection); got != tt.expected {
+				t.Errorf("tableLayoutKey(%q) = %q, expected %q", tt.collection, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTableColumns(t *testing.T) {
+	table := newTestTable(config.TableLayout{Columns: []string{"name", "gone"}})
+	expected := []string{"name", "age", "city"}
+	if !reflect.DeepEqual(table.columns, expected) {
+		t.Errorf("columns = %v, expected %v", table.columns, expected)
+	}
+}
+
+func TestFormatTableCell(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		ok       bool
+		expected string
+	}{
+		{"missing", nil, false, ""},
+		{"null", nil, true, "null"},
+		{"string with newline", "a\n  b", true, "a b"},
+		{"number", int64(42), true, "42"},
+		{"map", map[string]any{"x": 1}, true, `{"x":1}`},
+		{"array", []any{"a", "b"}, true, `["a","b"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatTableCell(tt.value, tt.ok); got != tt.expected {
+				t.Errorf("formatTableCell(%v) = %q, expected %q", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFitTableCell(t *testing.T) {
+	tests := []struct {
+		input    string
+		width    int
+		expected string
+	}{
+		{"abc", 5, "abc  "},
+		{"abcdef", 4, "abc…"},
+		{"héllo", 5, "héllo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := fitTableCell(tt.input, tt.width); got != tt.expected {
+				t.Errorf("fitTableCell(%q, %d) = %q, expected %q", tt.input, tt.width, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTableSort(t *testing.T) {
+	table := newTestTable(config.TableLayout{})
+	table.col = indexOf(table.visibleColumns(), "age")
+
+	table.toggleSort()
+	if got := tableIDs(table); !reflect.DeepEqual(got, []string{"b", "a", "c"}) {
+		t.Errorf("ascending = %v, expected numeric order with missing last", got)
+	}
+	table.toggleSort()
+	if got := tableIDs(table); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("descending = %v, expected [a b c]", got)
+	}
+	table.toggleSort()
+	if got := tableIDs(table); !reflect.DeepEqual(got, []string{"a", "b", "c"}) || table.sortBy != "" {
+		t.Errorf("unsorted = %v (sortBy %q), expected original order", got, table.sortBy)
+	}
+}
+
+func TestTableColumnEditing(t *testing.T) {
+	table := newTestTable(config.TableLayout{})
+
+	// Move "age" right of "city"
+	table.moveColumn(1)
+	if !reflect.DeepEqual(table.columns, []string{"city", "age", "name"}) || table.col != 1 {
+		t.Errorf("after move: columns = %v, col = %d", table.columns, table.col)
+	}
+
+	table.togglePin()
+	if table.pinned != 2 {
+		t.Errorf("pinned = %d, expected 2", table.pinned)
+	}
+
+	table.hideColumn()
+	if !reflect.DeepEqual(table.visibleColumns(), []string{"city", "name"}) || table.pinned != 1 {
+		t.Errorf("after hide: visible = %v, pinned = %d", table.visibleColumns(), table.pinned)
+	}
+
+	layout := table.layout()
+	if layout.Collection != "users" || !reflect.DeepEqual(layout.Hidden, []string{"age"}) || layout.Pinned != 1 {
+		t.Errorf("layout = %+v", layout)
+	}
+
+	table.showAllColumns()
+	if len(table.visibleColumns()) != 3 {
+		t.Errorf("after show all: visible = %v", table.visibleColumns())
+	}
+}
+
+func TestTableLayoutKeepsUnloadedColumns(t *testing.T) {
+	table := newTestTable(config.TableLayout{Columns: []string{"email", "name"}, Hidden: []string{"secret"}})
+	layout := table.layout()
+	if !reflect.DeepEqual(layout.Columns, []string{"name", "age", "city", "email"}) {
+		t.Errorf("Columns = %v", layout.Columns)
+	}
+	if !reflect.DeepEqual(layout.Hidden, []string{"secret"}) {
+		t.Errorf("Hidden = %v", layout.Hidden)
+	}
+}
+
+func TestTableRenderScrollsToCursor(t *testing.T) {
+	table := newTestTable(config.TableLayout{})
+	table.pinned = 1 // "age"
+	table.col = 2    // "name"
+
+	output := stripANSI(table.render(30, 10))
+	header := strings.SplitN(output, "\n", 2)[0]
+	if !strings.Contains(header, "age") || !strings.Contains(header, "name") {
+		t.Errorf("header %q should keep the pinned column and show the cursor column", header)
+	}
+	if strings.Contains(header, "city") {
+		t.Errorf("header %q should have scrolled past city", header)
+	}
+}
+
+func TestSaveTableLayoutOnlyWhenChanged(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	g := &Gui{config: &config.Config{}, table: newTestTable(config.TableLayout{})}
+	if err := g.saveTableLayout(); err != nil || len(g.config.Tables) != 0 {
+		t.Fatalf("unchanged table: err = %v, tables = %v", err, g.config.Tables)
+	}
+	g.table.togglePin()
+	if err := g.saveTableLayout(); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.config.Tables) != 1 || g.config.Tables[0].Collection != "users" {
+		t.Fatalf("tables = %v, expected the users layout", g.config.Tables)
+	}
+	if g.table.changed {
+		t.Error("a saved table should not be saved again on quit")
+	}
+}
@@ -0,0 +1,258 @@
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+// documentSchema returns the JSON Schema configured for a collection, loading
+// the file on first use. The name is the schema file's base name.
+func (g *Gui) documentSchema(collection string) (*firebase.JSONSchema, string, error) {
+	rule, ok := g.config.SchemaFor(collection)
+	if !ok {
+		return nil, "", nil
+	}
+
+	path := rule.SchemaPath()
+	name := filepath.Base(path)
+	if schema, ok := g.jsonSchemas[path]; ok {
+		return schema, name, nil
+	}
+	schema, err := firebase.LoadJSONSchema(path)
+	if err != nil {
+		return nil, name, err
+	}
+	g.jsonSchemas[path] = schema
+	return schema, name, nil
+}
+
+// docValidation is the result of checking one document against its schema.
+type docValidation struct {
+	Schema string // Schema file name
+	Errors []firebase.ValidationError
+	Err    error // Set when the schema file could not be loaded
+}
+
+// validateDocument checks a cached document against its collection's schema.
+// It returns nil when no schema applies or the document's typed fields are unknown.
+func (g *Gui) validateDocument(path string) *docValidation {
+	if !isDiffDocumentPath(path) {
+		return nil
+	}
+	fields, cached := g.fieldsCache[path]
+	if !cached {
+		return nil
+	}
+
+	schema, name, err := g.documentSchema(path[:strings.LastIndex(path, "/")])
+	if err != nil {
+		return &docValidation{Schema: name, Err: err}
+	}
+	if schema == nil {
+		return nil
+	}
+
+	return &docValidation{Schema: name, Errors: schema.ValidateFields(fields)}
+}
+
+// validateCollectionAction validates every loaded document of the selected
+// collection and marks the violating ones in the tree with their error count.
+func (g *Gui) validateCollectionAction() error {
+	collection := g.getSelectedCollection()
+	if collection == "" {
+		g.logCommand("validate", "Select a collection or document to validate", "error")
+		return nil
+	}
+
+	schema, name, err := g.documentSchema(collection)
+	if err != nil {
+		g.logCommand("validate", fmt.Sprintf("Failed to load schema %s: %v", name, err), "error")
+		return nil
+	}
+	if schema == nil {
+		g.logCommand("validate", fmt.Sprintf("No schema configured for %s, add it under schemas: in config.yaml", collection), "error")
+		return nil
+	}
+
+	rows := g.getTableRows(collection)
+	if len(rows) == 0 {
+		g.logCommand("validate", fmt.Sprintf("No documents of %s loaded, expand or query it first", collection), "error")
+		return nil
+	}
+
+	var invalid []string
+	checked, total := 0, 0
+	for _, row := range rows {
+		result := g.validateDocument(row.Path)
+		if result == nil {
+			continue
+		}
+		checked++
+		total += len(result.Errors)
+		if len(result.Errors) > 0 {
+			g.invalidDocs[row.Path] = len(result.Errors)
+			invalid = append(invalid, row.Path)
+		} else {
+			delete(g.invalidDocs, row.Path)
+		}
+	}
+
+	g.clearDetailsCache() // Re-render the open document with its errors
+	if len(invalid) == 0 {
+		g.logCommand("validate", fmt.Sprintf("All %d documents of %s match %s", checked, collection, name), "success")
+		return nil
+	}
+	g.logCommand("validate", fmt.Sprintf("%d of %d documents violate %s (%d errors)", len(invalid), checked, name, total), "error")
+	return g.openInvalidDocsMenu(name, invalid)
+}
+
+// openInvalidDocsMenu lists violating documents; choosing one selects it in the tree.
+func (g *Gui) openInvalidDocsMenu(schemaName string, paths []string) error {
+	sort.Strings(paths)
+	items := []PopupItem{{Label: fmt.Sprintf("%d documents violate %s", len(paths), schemaName), IsHeader: true}}
+	for _, path := range paths {
+		path := path
+		items = append(items, PopupItem{
+			Key:   fmt.Sprintf("%d errors", g.invalidDocs[path]),
+			Label: path,
+			Action: func() error {
+				return g.selectTreePath(path)
+			},
+		})
+	}
+	return g.openMenu("Schema violations", items)
+}
+
+// selectTreePath moves the tree cursor to a node and opens it in details.
+func (g *Gui) selectTreePath(path string) error {
+	for i, node := range g.getFilteredTreeNodes() {
+		if node.Path == path {
+			g.selectedTreeIdx = i
+			g.currentDocPath = path
+			g.currentDocData = g.docCache[path]
+			g.clearDetailsCache()
+			g.recordVisit()
+			return g.setFocus(g.g, "tree")
+		}
+	}
+	return nil
+}
+
+// formatValidationSummary is the line above a validated document.
+func formatValidationSummary(v *docValidation) string {
+	switch {
+	case v.Err != nil:
+		return fmt.Sprintf("\033[33m! Schema %s not loaded: %v\033[0m\n", v.Schema, v.Err)
+	case len(v.Errors) == 0:
+		return fmt.Sprintf("\033[32m✓ Matches %s\033[0m\n", v.Schema)
+	case len(v.Errors) == 1:
+		return fmt.Sprintf("\033[31m✗ 1 schema error (%s)\033[0m\n", v.Schema)
+	}
+	return fmt.Sprintf("\033[31m✗ %d schema errors (%s)\033[0m\n", len(v.Errors), v.Schema)
+}
+
+// annotateJSON colorizes indented JSON and appends each validation error to
+// the line of the value it is about. Errors about a missing field go on the
+// line of the enclosing object. Reference fields are shown as links.
+func annotateJSON(text string, errs []firebase.ValidationError, refs []firebase.FieldReference) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return colorizeJSON(text)
+	}
+	lines := strings.Split(text, "\n")
+	paths := jsonLinePaths(value, ".", nil)
+
+	// First line of each path; closing brackets repeat their path
+	firstLine := make(map[string]int)
+	for i, p := range paths {
+		if _, ok := firstLine[p]; !ok {
+			firstLine[p] = i
+		}
+	}
+
+	notes := make(map[int][]string)
+	for _, e := range errs {
+		line, ok := firstLine[e.Path]
+		for p := e.Path; !ok && p != "."; {
+			p = parentJQPath(p)
+			line, ok = firstLine[p]
+		}
+		notes[line] = append(notes[line], e.Message)
+	}
+
+	links := make(map[int]bool)
+	for _, ref := range refs {
+		if line, ok := firstLine[ref.Field]; ok {
+			links[line] = true
+		}
+	}
+
+	var b strings.Builder
+	for i, line := range lines {
+		if links[i] {
+			b.WriteString(linkLine(line))
+		} else {
+			b.WriteString(colorizeLine(line))
+		}
+		if msgs := notes[i]; len(msgs) > 0 {
+			b.WriteString("  \033[31m✗ " + strings.Join(msgs, "; ") + "\033[0m")
+		}
+		if i < len(lines)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// jsonLinePaths returns the jq-style path of every line json.MarshalIndent
+// writes for value: a scalar takes one line, an object or array its opening
+// line, its members' lines and its closing line.
+func jsonLinePaths(value interface{}, path string, out []string) []string {
+	out = append(out, path)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return out
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out = jsonLinePaths(v[k], diffKeyPath(path, k), out)
+		}
+		out = append(out, path)
+	case []interface{}:
+		if len(v) == 0 {
+			return out
+		}
+		for i, item := range v {
+			out = jsonLinePaths(item, diffIndexPath(path, i), out)
+		}
+		out = append(out, path)
+	}
+	return out
+}
+
+// parentJQPath drops the last key or index from a jq-style path.
+func parentJQPath(path string) string {
+	var i int
+	switch {
+	case strings.HasSuffix(path, `"]`):
+		i = strings.LastIndex(path, `["`)
+	case strings.HasSuffix(path, "]"):
+		i = strings.LastIndex(path, "[")
+	default:
+		i = strings.LastIndex(path, ".")
+	}
+	if i <= 0 {
+		return "."
+	}
+	return path[:i]
+}
@@ -0,0 +1,78 @@
+package gui
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marjoballabani/lazyfire/pkg/firebase"
+)
+
+func TestJSONLinePaths(t *testing.T) {
+	data := map[string]any{
+		"name":       "Ana",
+		"tags":       []any{"a", "b"},
+		"address":    map[string]any{"city": "Tirana"},
+		"empty":      map[string]any{},
+		"first name": "A",
+	}
+	text, _ := json.MarshalIndent(data, "", "  ")
+	var value any
+	_ = json.Unmarshal(text, &value)
+
+	paths := jsonLinePaths(value, ".", nil)
+	lines := strings.Split(string(text), "\n")
+	if len(paths) != len(lines) {
+		t.Fatalf("got %d paths for %d lines:\n%s", len(paths), len(lines), text)
+	}
+
+	expected := []string{".", ".address", ".address.city", ".address", ".empty", `["first name"]`, ".name", ".tags", ".tags[0]", ".tags[1]", ".tags", "."}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("jsonLinePaths() = %q, expected %q", paths, expected)
+	}
+}
+
+func TestParentJQPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{".a", "."},
+		{".a.b", ".a"},
+		{".a[2]", ".a"},
+		{`.a["first name"]`, ".a"},
+		{`["first name"]`, "."},
+		{".", "."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := parentJQPath(tt.path); got != tt.expected {
+				t.Errorf("parentJQPath(%q) = %q, expected %q", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnnotateJSON(t *testing.T) {
+	text, _ := json.MarshalIndent(map[string]any{"age": "x", "address": map[string]any{"city": "T"}}, "", "  ")
+	errs := []firebase.ValidationError{
+		{Path: ".age", Message: "expected integer, got string"},
+		{Path: ".address", Message: `missing required field "zip"`},
+		{Path: ".address.street", Message: "field is not allowed"}, // Not shown: falls back to .address
+		{Path: ".", Message: `missing required field "name"`},
+	}
+
+	lines := strings.Split(stripANSI(annotateJSON(string(text), errs, nil)), "\n")
+	expected := map[int]string{
+		0: `✗ missing required field "name"`,
+		1: `"address": {  ✗ missing required field "zip"; field is not allowed`,
+		4: `"age": "x"  ✗ expected integer, got string`,
+	}
+	for i, want := range expected {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q, expected it to contain %q", i, lines[i], want)
+		}
+	}
+}